@@ -0,0 +1,252 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/toffysoft/go-hexagonal-example/internal/adapters/render"
+	"github.com/toffysoft/go-hexagonal-example/internal/adapters/repositories"
+	"github.com/toffysoft/go-hexagonal-example/internal/adapters/transfer"
+	"github.com/toffysoft/go-hexagonal-example/internal/core/domain"
+	"github.com/toffysoft/go-hexagonal-example/internal/core/ports"
+	"github.com/toffysoft/go-hexagonal-example/internal/core/services"
+	"github.com/toffysoft/go-hexagonal-example/internal/infrastructure/config"
+	"github.com/toffysoft/go-hexagonal-example/internal/infrastructure/database"
+)
+
+const blogsUsage = `usage: api blogs <command> [flags] [config flags]
+
+commands:
+  export [--format=jsonl|csv|markdown] [--author=NAME] [--created-after=TIME]
+         [--created-before=TIME] [--out=FILE]
+      write the matching blogs to FILE, or to stdout
+  import --file=FILE [--format=jsonl|csv|markdown] [--dry-run]
+         [--dedupe=none|slug|title_author] [--upsert]
+      import FILE ("-" for stdin) and print a report; exits 1 if a row failed
+  summarize [--batch-size=N]
+      derive the excerpt, word count, reading time and table of contents of
+      blogs written before they were stored, N blogs per transaction
+
+The format defaults to the file extension, or jsonl. TIME is RFC 3339 or YYYY-MM-DD.
+`
+
+var blogsFlags = map[string]bool{
+	"format":         false,
+	"author":         false,
+	"created-after":  false,
+	"created-before": false,
+	"out":            false,
+	"file":           false,
+	"dry-run":        true,
+	"dedupe":         false,
+	"upsert":         true,
+	"batch-size":     false,
+}
+
+var blogsCommands = map[string]bool{"export": true, "import": true, "summarize": true}
+
+// runBlogsCommand implements "api blogs ..." and returns the process exit code.
+func runBlogsCommand(args []string) int {
+	if len(args) == 0 || !blogsCommands[args[0]] {
+		fmt.Fprint(os.Stderr, blogsUsage)
+		return 2
+	}
+
+	command := args[0]
+	flags, rest := extractFlags(args[1:], blogsFlags)
+	cfg, err := config.LoadConfig(rest)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	db, err := database.InitDB(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to initialize database: %v\n", err)
+		return 1
+	}
+	summary := services.SummaryConfig{
+		Analyzer:       render.NewRenderer(),
+		ExcerptLength:  cfg.Content.ExcerptLength,
+		WordsPerMinute: cfg.Content.WordsPerMinute,
+	}
+	transferService := services.NewTransferService(
+		repositories.NewBlogRepository(db),
+		repositories.NewUnitOfWork(db),
+		repositories.NewOutboxRepository(db),
+		services.WithImportSummary(summary),
+	)
+
+	switch command {
+	case "export":
+		err = exportBlogs(transferService, flags)
+	case "import":
+		err = importBlogs(transferService, flags, int64(cfg.Import.MaxUnzippedSize))
+	case "summarize":
+		backfill := services.NewSummaryBackfill(repositories.NewBlogRepository(db), repositories.NewUnitOfWork(db), summary)
+		err = summarizeBlogs(backfill, flags)
+	}
+	if errors.Is(err, errRowsFailed) {
+		return 1
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
+
+var errRowsFailed = errors.New("some rows failed")
+
+// fileFormat is the --format flag, or else the format of the file extension.
+func fileFormat(flag, file string) (transfer.Format, error) {
+	if flag != "" {
+		return transfer.ParseFormat(flag)
+	}
+	if format, err := transfer.ParseFormat(filepath.Ext(file)); err == nil {
+		return format, nil
+	}
+	return transfer.JSONL, nil
+}
+
+func exportBlogs(transferService ports.TransferService, flags map[string]string) error {
+	format, err := fileFormat(flags["format"], flags["out"])
+	if err != nil {
+		return err
+	}
+	filter := domain.BlogFilter{Author: flags["author"]}
+	if value := flags["created-after"]; value != "" {
+		if filter.CreatedAfter, err = transfer.ParseTime(value); err != nil {
+			return err
+		}
+	}
+	if value := flags["created-before"]; value != "" {
+		if filter.CreatedBefore, err = transfer.ParseTime(value); err != nil {
+			return err
+		}
+	}
+
+	var out io.Writer = os.Stdout
+	if path := flags["out"]; path != "" && path != "-" {
+		file, err := os.Create(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		out = file
+	}
+
+	encoder := transfer.NewEncoder(out, format)
+	count := 0
+	err = transferService.ExportBlogs(context.Background(), filter, func(blogs []*domain.Blog) error {
+		for _, blog := range blogs {
+			if err := encoder.Encode(blog); err != nil {
+				return err
+			}
+		}
+		count += len(blogs)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if err := encoder.Close(); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "exported %d blogs\n", count)
+	return nil
+}
+
+func importBlogs(transferService ports.TransferService, flags map[string]string, maxUnzipped int64) error {
+	path := flags["file"]
+	if path == "" {
+		return errors.New("--file is required")
+	}
+	format, err := fileFormat(flags["format"], path)
+	if err != nil {
+		return err
+	}
+
+	var data []byte
+	if path == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return err
+	}
+	records, err := transfer.Decode(data, format, maxUnzipped)
+	if err != nil {
+		return err
+	}
+
+	opts := domain.ImportOptions{DedupeBy: domain.DedupeTitleAuthor}
+	if value, ok := flags["dedupe"]; ok {
+		opts.DedupeBy = domain.DedupeKey(value)
+	}
+	if opts.DryRun, err = boolFlag(flags, "dry-run"); err != nil {
+		return err
+	}
+	if opts.Upsert, err = boolFlag(flags, "upsert"); err != nil {
+		return err
+	}
+
+	report, err := transferService.ImportBlogs(context.Background(), records, opts)
+	if err != nil {
+		return err
+	}
+	printReport(os.Stdout, report)
+	if report.Failed > 0 {
+		return errRowsFailed
+	}
+	return nil
+}
+
+// defaultSummarizeBatchSize is the number of blogs summarized per
+// transaction without --batch-size.
+const defaultSummarizeBatchSize = 100
+
+func summarizeBlogs(backfill *services.SummaryBackfill, flags map[string]string) error {
+	batchSize := defaultSummarizeBatchSize
+	if value, ok := flags["batch-size"]; ok {
+		n, err := strconv.Atoi(value)
+		if err != nil || n <= 0 {
+			return fmt.Errorf("--batch-size must be a positive integer")
+		}
+		batchSize = n
+	}
+	updated, err := backfill.Run(context.Background(), batchSize)
+	fmt.Fprintf(os.Stderr, "summarized %d blogs\n", updated)
+	return err
+}
+
+func boolFlag(flags map[string]string, name string) (bool, error) {
+	value, ok := flags[name]
+	if !ok {
+		return false, nil
+	}
+	enabled, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("--%s: %w", name, err)
+	}
+	return enabled, nil
+}
+
+// printReport lists the rows that were not imported, then the totals.
+func printReport(w io.Writer, report *domain.ImportReport) {
+	for _, row := range report.Rows {
+		if row.Action == domain.ImportSkipped || row.Action == domain.ImportFailed {
+			fmt.Fprintf(w, "%s: %s (%s)\n", row.Source, row.Action, row.Reason)
+		}
+	}
+	fmt.Fprintf(w, "created %d, updated %d, skipped %d, failed %d\n",
+		report.Created, report.Updated, report.Skipped, report.Failed)
+	if report.DryRun {
+		fmt.Fprintln(w, "dry run: nothing was changed")
+	}
+}
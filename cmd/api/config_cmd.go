@@ -0,0 +1,91 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/toffysoft/go-hexagonal-example/internal/infrastructure/config"
+)
+
+const configUsage = `usage: api config <command> [flags]
+
+commands:
+  print [--format=yaml|env]  print the effective configuration with secrets redacted
+  validate                   report every configuration problem and exit non-zero if any
+`
+
+// runConfigCommand implements "api config ..." and returns the process exit code.
+func runConfigCommand(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, configUsage)
+		return 2
+	}
+
+	command, rest := args[0], args[1:]
+	format, rest := extractFormat(rest)
+
+	cfg, err := config.LoadConfig(rest)
+	var validationErr *config.ValidationError
+	if err != nil && !errors.As(err, &validationErr) {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	switch command {
+	case "print":
+		if err := cfg.Print(os.Stdout, format); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+	case "validate":
+		if validationErr == nil {
+			fmt.Println("configuration is valid")
+		}
+	default:
+		fmt.Fprint(os.Stderr, configUsage)
+		return 2
+	}
+
+	if validationErr != nil {
+		fmt.Fprintln(os.Stderr, validationErr)
+		return 1
+	}
+	return 0
+}
+
+// extractFormat removes --format from args, since it is not a configuration flag.
+func extractFormat(args []string) (string, []string) {
+	values, rest := extractFlags(args, map[string]bool{"format": false})
+	if format, ok := values["format"]; ok {
+		return format, rest
+	}
+	return "yaml", rest
+}
+
+// extractFlags removes the given command flags from args and returns their
+// values, leaving the configuration flags. flags maps each name to whether
+// it is boolean; a boolean flag takes no value unless written --name=value.
+func extractFlags(args []string, flags map[string]bool) (map[string]string, []string) {
+	values := make(map[string]string)
+	rest := make([]string, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		name, value, hasValue := strings.Cut(strings.TrimPrefix(args[i], "--"), "=")
+		boolean, known := flags[name]
+		switch {
+		case !strings.HasPrefix(args[i], "--") || !known:
+			rest = append(rest, args[i])
+		case hasValue:
+			values[name] = value
+		case boolean:
+			values[name] = "true"
+		case i+1 < len(args):
+			values[name] = args[i+1]
+			i++
+		default:
+			rest = append(rest, args[i])
+		}
+	}
+	return values, rest
+}
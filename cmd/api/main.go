@@ -1,73 +1,509 @@
 package main
 
 import (
+	"context"
+	"fmt"
 	"log"
+	"log/slog"
+	"net"
+	"os"
+	"os/signal"
+	"reflect"
+	"slices"
+	"sync"
+	"syscall"
+	"time"
 
+	"github.com/toffysoft/go-hexagonal-example/internal/adapters/cache"
+	"github.com/toffysoft/go-hexagonal-example/internal/adapters/changefeed"
+	"github.com/toffysoft/go-hexagonal-example/internal/adapters/eventbus"
+	bloggrpc "github.com/toffysoft/go-hexagonal-example/internal/adapters/grpc"
+	"github.com/toffysoft/go-hexagonal-example/internal/adapters/grpc/proto"
 	"github.com/toffysoft/go-hexagonal-example/internal/adapters/handlers"
+	"github.com/toffysoft/go-hexagonal-example/internal/adapters/health"
+	"github.com/toffysoft/go-hexagonal-example/internal/adapters/media"
+	"github.com/toffysoft/go-hexagonal-example/internal/adapters/metrics"
+	"github.com/toffysoft/go-hexagonal-example/internal/adapters/render"
 	"github.com/toffysoft/go-hexagonal-example/internal/adapters/repositories"
+	"github.com/toffysoft/go-hexagonal-example/internal/adapters/storage"
+	"github.com/toffysoft/go-hexagonal-example/internal/adapters/tracing"
+	"github.com/toffysoft/go-hexagonal-example/internal/adapters/webhooks"
+	"github.com/toffysoft/go-hexagonal-example/internal/core/domain"
+	"github.com/toffysoft/go-hexagonal-example/internal/core/ports"
 	"github.com/toffysoft/go-hexagonal-example/internal/core/services"
 	"github.com/toffysoft/go-hexagonal-example/internal/infrastructure/config"
 	"github.com/toffysoft/go-hexagonal-example/internal/infrastructure/database"
+	"github.com/toffysoft/go-hexagonal-example/internal/infrastructure/logging"
+	"github.com/toffysoft/go-hexagonal-example/internal/infrastructure/telemetry"
 	"github.com/toffysoft/go-hexagonal-example/pkg/errors"
 
 	"github.com/gofiber/fiber/v2"
-	"github.com/gofiber/fiber/v2/middleware/cors"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
 	"github.com/gofiber/fiber/v2/middleware/logger"
 	"github.com/gofiber/fiber/v2/middleware/recover"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"google.golang.org/grpc"
+	grpchealth "google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
 )
 
 func main() {
+	args := os.Args[1:]
+	if len(args) > 0 && args[0] == "config" {
+		os.Exit(runConfigCommand(args[1:]))
+	}
+	if len(args) > 0 && args[0] == "blogs" {
+		os.Exit(runBlogsCommand(args[1:]))
+	}
+
 	// Load configuration
-	cfg, err := config.LoadConfig()
+	configStore, err := config.NewStore(args)
 	if err != nil {
 		log.Fatalf("Failed to load configuration: %v", err)
 	}
+	cfg := configStore.Current()
+	logLevel := logging.Setup(cfg.Log)
+
+	// Stop background work on SIGINT or SIGTERM; the servers are drained below.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Initialize tracing
+	shutdownTracer, err := telemetry.InitTracer(context.Background(), cfg)
+	if err != nil {
+		log.Fatalf("Failed to initialize tracing: %v", err)
+	}
 
 	// Initialize database
 	db, err := database.InitDB(cfg)
 	if err != nil {
 		log.Fatalf("Failed to initialize database: %v", err)
 	}
+	if err := tracing.RegisterGORM(db); err != nil {
+		log.Fatalf("Failed to register database tracing: %v", err)
+	}
+
+	// Initialize metrics
+	appMetrics := metrics.New()
+	if err := appMetrics.RegisterDB(db); err != nil {
+		log.Fatalf("Failed to register database metrics: %v", err)
+	}
+
+	// Initialize health checks
+	healthRegistry := health.NewRegistry(cfg.Health.CheckTimeout)
+	healthRegistry.Register("database", health.DatabaseCheck(db))
+	healthRegistry.Register("migrations", health.MigrationsCheck(db, database.Models()...))
 
 	// Initialize repositories
-	blogRepo := repositories.NewBlogRepository(db)
+	var blogRepo ports.BlogRepository = repositories.NewBlogRepository(db)
+	if cfg.Cache.Enabled {
+		blogRepo = cache.NewBlogRepository(blogRepo, cache.Config{
+			Size:        cfg.Cache.Size,
+			TTL:         cfg.Cache.TTL,
+			NegativeTTL: cfg.Cache.NegativeTTL,
+		}, cache.WithMetrics(appMetrics))
+	}
+	var renderer ports.ContentRenderer = render.NewRenderer()
+	if cfg.Cache.Enabled {
+		renderer = cache.NewRenderer(renderer, cfg.Cache.RenderSize, appMetrics)
+	}
+	summary := services.SummaryConfig{
+		Analyzer:       render.NewRenderer(),
+		ExcerptLength:  cfg.Content.ExcerptLength,
+		WordsPerMinute: cfg.Content.WordsPerMinute,
+	}
+	outboxRepo := repositories.NewOutboxRepository(db)
+	unitOfWork := repositories.NewUnitOfWork(db)
 
 	// Initialize services
-	blogService := services.NewBlogService(blogRepo)
+	blogService := tracing.NewBlogService(services.NewBlogService(blogRepo,
+		services.WithUnitOfWork(unitOfWork),
+		services.WithOutbox(outboxRepo),
+		services.WithMetrics(appMetrics),
+		services.WithMaxBatchSize(cfg.Batch.MaxItems),
+		services.WithRenderer(renderer),
+		services.WithSummary(summary),
+	))
+
+	// Initialize the in-process event buses. The outbox relay marks an event
+	// published once relayBus returns, so handlers that must not miss events
+	// subscribe to it and have failed events retried. The others subscribe to
+	// eventBus, which in async mode runs them later and only logs failures.
+	relayBus := eventbus.NewSyncBus(eventbus.WithMetrics(appMetrics))
+	var eventBus ports.EventBus = relayBus
+	var closers []func(context.Context) error
+	if cfg.EventBus.Mode != "sync" {
+		asyncBus := eventbus.NewAsyncBus(cfg.EventBus.Workers, cfg.EventBus.QueueSize, eventbus.WithMetrics(appMetrics))
+		closers = append(closers, asyncBus.Close)
+		relayBus.Subscribe("async", asyncBus.Publish)
+		eventBus = asyncBus
+	}
+	eventBus.Subscribe("log", eventbus.LogHandler(slog.Default()))
+
+	// Feed blog changes to live streams, read from the outbox in the order
+	// they were recorded
+	changeFeed := changefeed.New(outboxRepo, changefeed.Config{
+		BufferSize:       cfg.Stream.BufferSize,
+		SubscriberBuffer: cfg.Stream.SubscriberBuffer,
+		PollInterval:     cfg.Stream.PollInterval,
+		GapTimeout:       cfg.Stream.GapTimeout,
+	})
+	if err := changeFeed.Load(ctx); err != nil {
+		log.Fatalf("Failed to load the change feed: %v", err)
+	}
+	go changeFeed.Run(ctx)
+
+	// Deliver blog events to webhooks
+	webhookRepo := repositories.NewWebhookRepository(db)
+	webhookService := services.NewWebhookService(webhookRepo, unitOfWork)
+	if cfg.Webhooks.Enabled {
+		dispatcher := services.NewWebhookDispatcher(webhookRepo,
+			webhooks.NewHTTPSender(webhooks.NewClient(cfg.Webhooks.Timeout)),
+			unitOfWork,
+			services.DispatcherConfig{
+				PollInterval:    cfg.Webhooks.PollInterval,
+				BatchSize:       cfg.Webhooks.BatchSize,
+				MaxAttempts:     cfg.Webhooks.MaxAttempts,
+				RetryBackoff:    cfg.Webhooks.RetryBackoff,
+				MaxRetryBackoff: cfg.Webhooks.MaxRetryBackoff,
+				DisableAfter:    cfg.Webhooks.DisableAfter,
+				Lease:           cfg.Webhooks.Lease,
+			})
+		relayBus.Subscribe("webhooks", dispatcher.HandleEvent)
+		go dispatcher.Run(ctx)
+	}
+
+	// Keep files uploaded to blogs, and remove those of deleted blogs
+	var attachmentHandler *handlers.AttachmentHandler
+	if cfg.Attachments.Enabled {
+		objectStore, err := storage.NewLocalStore(cfg.Attachments.Dir)
+		if err != nil {
+			log.Fatalf("Failed to initialize attachment storage: %v", err)
+		}
+		attachmentService := services.NewAttachmentService(repositories.NewAttachmentRepository(db), objectStore, blogService, media.NewThumbnailer(), services.AttachmentConfig{
+			MaxSize:         int64(cfg.Attachments.MaxSize),
+			AllowedTypes:    cfg.Attachments.AllowedTypes,
+			ThumbnailSize:   cfg.Attachments.ThumbnailSize,
+			CleanupInterval: cfg.Attachments.CleanupInterval,
+		})
+		eventBus.Subscribe("attachments", attachmentService.HandleEvent, domain.BlogDeleted)
+		go attachmentService.Run(ctx)
+		attachmentHandler = handlers.NewAttachmentHandler(attachmentService)
+	}
+
+	// Relay domain events from the outbox
+	if cfg.Outbox.RelayEnabled {
+		relay := services.NewOutboxRelay(outboxRepo, relayBus, unitOfWork, services.RelayConfig{
+			PollInterval:    cfg.Outbox.PollInterval,
+			BatchSize:       cfg.Outbox.BatchSize,
+			MaxAttempts:     cfg.Outbox.MaxAttempts,
+			RetryBackoff:    cfg.Outbox.RetryBackoff,
+			MaxRetryBackoff: cfg.Outbox.MaxRetryBackoff,
+		})
+		go relay.Run(ctx)
+	}
+
+	// Replay requests sent with an idempotency key, per instance or across
+	// instances
+	var idempotencyStore ports.IdempotencyStore = repositories.NewIdempotencyRepository(db)
+	if cfg.Idempotency.Store == "memory" {
+		idempotencyStore = repositories.NewMemoryIdempotencyStore()
+	}
+	idempotencyService := services.NewIdempotencyService(idempotencyStore, services.IdempotencyConfig{
+		TTL:             cfg.Idempotency.TTL,
+		LockTimeout:     cfg.Idempotency.LockTimeout,
+		Wait:            cfg.Idempotency.Wait,
+		PollInterval:    cfg.Idempotency.PollInterval,
+		CleanupInterval: cfg.Idempotency.CleanupInterval,
+	})
+	if cfg.Idempotency.Enabled {
+		go idempotencyService.Run(ctx)
+	}
+
+	// Limit request rates, per instance or across instances
+	var rateLimitStore ports.RateLimitStore = repositories.NewMemoryRateLimitStore()
+	if cfg.RateLimit.Store == "postgres" {
+		rateLimitStore = repositories.NewRateLimitRepository(db)
+	}
+	rateLimiter := services.NewRateLimiter(rateLimitStore, rateLimitPolicies(cfg.RateLimit)...)
+	go rateLimiter.Run(ctx, cfg.RateLimit.CleanupInterval)
 
 	// Initialize handlers
-	blogHandler := handlers.NewBlogHandler(blogService)
+	var vary []string
+	if cfg.Auth.Enabled {
+		// Callers without the right key are rejected, so a shared cache must
+		// not reuse a response across keys.
+		vary = append(vary, "X-API-Key")
+	}
+	blogHandler := handlers.NewBlogHandler(blogService, handlers.WithCachePolicy(func() handlers.CachePolicy {
+		c := configStore.Current().HTTPCache
+		return handlers.CachePolicy{GetBlog: c.GetBlog, ListBlogs: c.ListBlogs, Vary: vary}
+	}))
+	webhookHandler := handlers.NewWebhookHandler(webhookService)
+	streamHandler := handlers.NewStreamHandler(changeFeed, cfg.Stream.Heartbeat)
+	transferHandler := handlers.NewTransferHandler(services.NewTransferService(blogRepo, unitOfWork, outboxRepo, services.WithImportSummary(summary)), int64(cfg.Import.MaxUnzippedSize))
 
 	// Initialize Fiber app
 	app := fiber.New(fiber.Config{
 		ErrorHandler: customErrorHandler,
+		ReadTimeout:  cfg.HTTP.ReadTimeout,
+		WriteTimeout: cfg.HTTP.WriteTimeout,
+		IdleTimeout:  cfg.HTTP.IdleTimeout,
+		BodyLimit:    cfg.HTTP.BodyLimit,
+	})
+
+	// Runtime-tunable middlewares are rebuilt whenever the config is reloaded
+	corsHandler := handlers.NewSwappableHandler(newCORS(cfg.CORS))
+	var maintenance ports.MaintenanceState = func() (bool, string) {
+		m := configStore.Current().Maintenance
+		return m.Enabled, m.Message
+	}
+	metricsMiddleware := appMetrics.FiberMiddleware()
+	metricsHandler := adaptor.HTTPHandler(appMetrics.Handler())
+
+	configStore.Subscribe(func(old, new config.Config) {
+		logLevel.Set(logging.ParseLevel(new.Log.Level))
+		if !reflect.DeepEqual(old.CORS, new.CORS) {
+			corsHandler.Swap(newCORS(new.CORS))
+		}
+		if !reflect.DeepEqual(old.RateLimit, new.RateLimit) {
+			rateLimiter.Configure(rateLimitPolicies(new.RateLimit)...)
+		}
 	})
 
 	// Add middlewares
 	app.Use(recover.New()) // Recover from panics and sends 500 internal server error
+	app.Use(tracing.FiberMiddleware())
+	app.Use(func(c *fiber.Ctx) error {
+		if configStore.Current().Features.Metrics {
+			return metricsMiddleware(c)
+		}
+		return c.Next()
+	})
 	app.Use(logger.New(logger.Config{
 		Format: "[${time}] ${status} - ${latency} ${method} ${path}\n",
 	}))
-	app.Use(cors.New(cors.Config{
-		AllowOrigins: "*",
-		AllowMethods: "GET,POST,HEAD,PUT,DELETE,PATCH",
-		AllowHeaders: "Origin, Content-Type, Accept",
-	}))
+	app.Use(corsHandler.Handle)
 
 	// Setup routes
+	app.Get("/metrics", func(c *fiber.Ctx) error {
+		if !configStore.Current().Features.Metrics {
+			return fiber.ErrNotFound
+		}
+		return metricsHandler(c)
+	})
+	app.Get("/healthz", health.LivenessHandler())
+	app.Get("/readyz", health.ReadinessHandler(healthRegistry))
+
+	// Feeds are public, like the blog they syndicate, and limited by IP
+	if cfg.Feeds.Enabled {
+		feedHandler := handlers.NewFeedHandler(blogService, handlers.FeedConfig{
+			Title:        cfg.Feeds.Title,
+			Description:  cfg.Feeds.Description,
+			BaseURL:      cfg.Feeds.BaseURL,
+			ItemPath:     cfg.Feeds.ItemPath,
+			Limit:        cfg.Feeds.Limit,
+			MaxLimit:     cfg.Feeds.MaxLimit,
+			FullContent:  cfg.Feeds.FullContent,
+			CacheControl: cfg.Feeds.CacheControl,
+		})
+		feeds := app.Group("/feeds")
+		feeds.Use(handlers.Maintenance(maintenance, 30*time.Second))
+		feeds.Use(handlers.RateLimit(rateLimiter, cfg.RateLimit.UserHeader))
+		feeds.Get("/rss.xml", feedHandler.RSS)
+		feeds.Get("/atom.xml", feedHandler.Atom)
+		feeds.Get("/feed.json", feedHandler.JSONFeed)
+		feeds.Get("/authors/:author/rss.xml", feedHandler.RSS)
+		feeds.Get("/authors/:author/atom.xml", feedHandler.Atom)
+		feeds.Get("/authors/:author/feed.json", feedHandler.JSONFeed)
+		feeds.Get("/tags/:tag/rss.xml", feedHandler.RSS)
+		feeds.Get("/tags/:tag/atom.xml", feedHandler.Atom)
+		feeds.Get("/tags/:tag/feed.json", feedHandler.JSONFeed)
+	}
+
 	api := app.Group("/api")
+	api.Use(handlers.Maintenance(maintenance, 30*time.Second))
+	api.Use(handlers.NotAcceptable())
+	if cfg.Auth.Enabled {
+		api.Use(handlers.APIKeyAuth(slices.Concat(cfg.Auth.APIKeys, cfg.Auth.AdminAPIKeys)))
+	}
+	// After authentication, so that only verified keys get buckets of their own
+	api.Use(handlers.RateLimit(rateLimiter, cfg.RateLimit.UserHeader))
+	if cfg.Idempotency.Enabled {
+		api.Use(handlers.Idempotency(idempotencyService))
+	}
 	v1 := api.Group("/v1")
 
+	admin := v1.Group("/admin")
+	if cfg.Auth.Enabled {
+		admin.Use(handlers.APIKeyAuth(cfg.Auth.AdminAPIKeys))
+	}
+	admin.Get("/blogs/export", transferHandler.ExportBlogs)
+	admin.Post("/blogs/import", transferHandler.ImportBlogs)
+
+	// Webhooks receive every blog event, so only admins may register them.
+	hooks := admin.Group("/webhooks")
+	hooks.Post("/", webhookHandler.CreateWebhook)
+	hooks.Get("/", webhookHandler.ListWebhooks)
+	hooks.Get("/:id", webhookHandler.GetWebhook)
+	hooks.Put("/:id", webhookHandler.UpdateWebhook)
+	hooks.Delete("/:id", webhookHandler.DeleteWebhook)
+	hooks.Get("/:id/deliveries", webhookHandler.ListDeliveries)
+	hooks.Get("/:id/deliveries/:deliveryId", webhookHandler.GetDelivery)
+	hooks.Post("/:id/deliveries/:deliveryId/redeliver", webhookHandler.Redeliver)
+
 	blogs := v1.Group("/blogs")
 	blogs.Post("/", blogHandler.CreateBlog)
+	blogs.Get("/stream", streamHandler.StreamBlogs)
 	blogs.Get("/:id", blogHandler.GetBlog)
 	blogs.Put("/:id", blogHandler.UpdateBlog)
+	blogs.Patch("/:id", blogHandler.PatchBlog)
 	blogs.Delete("/:id", blogHandler.DeleteBlog)
 	blogs.Get("/", blogHandler.ListBlogs)
+	// Custom methods use the "resource:verb" form, so the colon is escaped.
+	v1.Post("/blogs\\:batchCreate", blogHandler.BatchCreateBlogs)
+	v1.Post("/blogs\\:batchUpdate", blogHandler.BatchUpdateBlogs)
+	v1.Post("/blogs\\:batchDelete", blogHandler.BatchDeleteBlogs)
+
+	if attachmentHandler != nil {
+		blogs.Post("/:id/attachments", attachmentHandler.UploadAttachment)
+		blogs.Get("/:id/attachments", attachmentHandler.ListAttachments)
+		blogs.Put("/:id/cover", attachmentHandler.SetCover)
+		blogs.Delete("/:id/cover", attachmentHandler.ClearCover)
+
+		attachments := v1.Group("/attachments")
+		attachments.Get("/:id", attachmentHandler.GetAttachment)
+		attachments.Get("/:id/content", attachmentHandler.GetAttachmentContent)
+		attachments.Get("/:id/thumbnail", attachmentHandler.GetAttachmentThumbnail)
+		attachments.Delete("/:id", attachmentHandler.DeleteAttachment)
+	}
 
-	// Start server
-	log.Printf("Starting server on %s", cfg.ServerAddress)
-	log.Fatal(app.Listen(cfg.ServerAddress))
+	// Reload runtime-tunable settings on SIGHUP or config file change
+	go func() {
+		if err := configStore.Watch(ctx); err != nil {
+			log.Printf("Config watcher stopped: %v", err)
+		}
+	}()
+
+	// Start gRPC server. It does not authenticate calls, so the rate limiter
+	// checks API keys itself.
+	var apiKeys []string
+	if cfg.Auth.Enabled {
+		apiKeys = slices.Concat(cfg.Auth.APIKeys, cfg.Auth.AdminAPIKeys)
+	}
+	unaryInterceptors := []grpc.UnaryServerInterceptor{
+		appMetrics.UnaryServerInterceptor(),
+		bloggrpc.MaintenanceUnaryInterceptor(maintenance),
+		bloggrpc.RateLimitUnaryInterceptor(rateLimiter, cfg.RateLimit.UserHeader, apiKeys),
+	}
+	if cfg.Idempotency.Enabled {
+		unaryInterceptors = append(unaryInterceptors, bloggrpc.IdempotencyUnaryInterceptor(idempotencyService,
+			proto.BlogService_CreateBlog_FullMethodName,
+			proto.BlogService_BatchCreateBlogs_FullMethodName,
+		))
+	}
+	grpcServer := grpc.NewServer(
+		grpc.MaxRecvMsgSize(cfg.GRPC.MaxRecvMsgSize),
+		grpc.MaxSendMsgSize(cfg.GRPC.MaxSendMsgSize),
+		grpc.StatsHandler(otelgrpc.NewServerHandler()),
+		grpc.ChainUnaryInterceptor(unaryInterceptors...),
+		grpc.ChainStreamInterceptor(
+			appMetrics.StreamServerInterceptor(),
+			bloggrpc.MaintenanceStreamInterceptor(maintenance),
+			bloggrpc.RateLimitStreamInterceptor(rateLimiter, cfg.RateLimit.UserHeader, apiKeys),
+		),
+	)
+	proto.RegisterBlogServiceServer(grpcServer, bloggrpc.NewBlogServer(blogService,
+		bloggrpc.WithChangeFeed(changeFeed),
+		bloggrpc.WithMaxMessageSize(cfg.GRPC.MaxSendMsgSize),
+	))
+	if cfg.Features.GRPCReflection {
+		reflection.Register(grpcServer)
+	}
+
+	healthServer := grpchealth.NewServer()
+	healthpb.RegisterHealthServer(grpcServer, healthServer)
+	go healthRegistry.WatchGRPC(ctx, healthServer, cfg.Health.CheckInterval, proto.BlogService_ServiceDesc.ServiceName)
+
+	grpcListener, err := net.Listen("tcp", cfg.GRPC.Address)
+	if err != nil {
+		log.Fatalf("Failed to listen on %s: %v", cfg.GRPC.Address, err)
+	}
+	// Serve until a server fails or a shutdown signal arrives
+	serveErrors := make(chan error, 2)
+	go func() {
+		log.Printf("Starting gRPC server on %s", cfg.GRPC.Address)
+		if err := grpcServer.Serve(grpcListener); err != nil {
+			serveErrors <- fmt.Errorf("gRPC server stopped: %w", err)
+		}
+	}()
+	go func() {
+		log.Printf("Starting server on %s", cfg.HTTP.Address)
+		if err := app.Listen(cfg.HTTP.Address); err != nil {
+			serveErrors <- fmt.Errorf("HTTP server stopped: %w", err)
+		}
+	}()
+
+	exitCode := 0
+	select {
+	case <-ctx.Done():
+		log.Printf("Shutting down")
+	case err := <-serveErrors:
+		log.Print(err)
+		exitCode = 1
+	}
+	stop()
+	shutdown(cfg.Service.ShutdownTimeout, app, grpcServer, closers, shutdownTracer)
+	os.Exit(exitCode)
+}
+
+// shutdown stops both servers from accepting requests and gives those in
+// flight until timeout to finish. It then runs closers, which drain work the
+// requests queued, and finally flushes the spans the tracer still buffers.
+func shutdown(timeout time.Duration, app *fiber.App, grpcServer *grpc.Server, closers []func(context.Context) error, shutdownTracer func(context.Context) error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		if err := app.ShutdownWithContext(ctx); err != nil {
+			log.Printf("HTTP server shutdown: %v", err)
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		stopped := make(chan struct{})
+		go func() {
+			grpcServer.GracefulStop()
+			close(stopped)
+		}()
+		select {
+		case <-stopped:
+		case <-ctx.Done():
+			// Streams such as WatchBlogs do not end on their own.
+			log.Printf("gRPC server shutdown: %v", ctx.Err())
+			grpcServer.Stop()
+			<-stopped
+		}
+	}()
+	wg.Wait()
+
+	drainCtx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	for _, closeFn := range closers {
+		if err := closeFn(drainCtx); err != nil {
+			log.Printf("Event bus shutdown: %v", err)
+		}
+	}
+
+	flushCtx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if err := shutdownTracer(flushCtx); err != nil {
+		log.Printf("Tracer shutdown: %v", err)
+	}
 }
 
 func customErrorHandler(c *fiber.Ctx, err error) error {
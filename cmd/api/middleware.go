@@ -0,0 +1,48 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/toffysoft/go-hexagonal-example/internal/core/domain"
+	"github.com/toffysoft/go-hexagonal-example/internal/infrastructure/config"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/cors"
+)
+
+func newCORS(cfg config.CORSConfig) fiber.Handler {
+	return cors.New(cors.Config{
+		AllowOrigins:     strings.Join(cfg.AllowOrigins, ","),
+		AllowMethods:     strings.Join(cfg.AllowMethods, ","),
+		AllowHeaders:     strings.Join(cfg.AllowHeaders, ", "),
+		ExposeHeaders:    strings.Join(cfg.ExposeHeaders, ", "),
+		AllowCredentials: cfg.AllowCredentials,
+		MaxAge:           cfg.MaxAge,
+	})
+}
+
+// rateLimitPolicies returns the configured policies followed by the default
+// one, or none when rate limiting is disabled.
+func rateLimitPolicies(cfg config.RateLimitConfig) []domain.RateLimitPolicy {
+	if !cfg.Enabled {
+		return nil
+	}
+	policies := make([]domain.RateLimitPolicy, 0, len(cfg.Policies)+1)
+	for _, spec := range cfg.Policies {
+		// The specs were checked when the config was validated.
+		if policy, err := domain.ParseRateLimitPolicy(spec); err == nil {
+			policies = append(policies, policy)
+		}
+	}
+	burst := cfg.Burst
+	if burst == 0 {
+		burst = cfg.Max
+	}
+	return append(policies, domain.RateLimitPolicy{
+		Name:     "default",
+		Limit:    cfg.Max,
+		Window:   cfg.Window,
+		Burst:    burst,
+		Identity: domain.RateLimitIdentity(cfg.Identity),
+	})
+}
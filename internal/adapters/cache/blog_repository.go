@@ -0,0 +1,273 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/toffysoft/go-hexagonal-example/internal/core/domain"
+	"github.com/toffysoft/go-hexagonal-example/internal/core/ports"
+	"github.com/toffysoft/go-hexagonal-example/pkg/errors"
+
+	"golang.org/x/sync/singleflight"
+	"gorm.io/gorm"
+)
+
+const cacheName = "blog"
+
+// Config tunes the blog cache.
+type Config struct {
+	// Size is the number of blogs kept in process.
+	Size int
+	// TTL bounds how stale a cached blog can be on an instance that did not
+	// make the change; the instance that did invalidates it on commit.
+	TTL time.Duration
+	// NegativeTTL is how long a blog that was not found is remembered.
+	NegativeTTL time.Duration
+}
+
+// Option configures optional collaborators of the BlogRepository.
+type Option func(*BlogRepository)
+
+// WithRemote adds a cache shared by every instance behind the in-process one.
+func WithRemote(remote ports.Cache) Option {
+	return func(r *BlogRepository) {
+		r.remote = remote
+	}
+}
+
+func WithMetrics(metrics ports.CacheMetrics) Option {
+	return func(r *BlogRepository) {
+		r.metrics = metrics
+	}
+}
+
+// BlogRepository caches GetByID and GetFields in front of another
+// BlogRepository, in process and optionally in a shared cache. Concurrent
+// misses for the same blog are coalesced into one read. Writes invalidate the blogs they touch
+// once their unit of work commits. Reads that need strong consistency or run
+// in a unit of work bypass the cache; every other method is passed through.
+type BlogRepository struct {
+	ports.BlogRepository
+	cfg     Config
+	local   *lru[uint, cachedBlog]
+	remote  ports.Cache
+	metrics ports.CacheMetrics
+	loads   singleflight.Group
+	now     func() time.Time
+}
+
+// cachedBlog is a cached read; a zero value caches a not-found.
+type cachedBlog struct {
+	Blog  domain.Blog `json:"blog"`
+	Found bool        `json:"found"`
+}
+
+func NewBlogRepository(next ports.BlogRepository, cfg Config, opts ...Option) *BlogRepository {
+	r := &BlogRepository{
+		BlogRepository: next,
+		cfg:            cfg,
+		local:          newLRU[uint, cachedBlog](cfg.Size),
+		metrics:        nopMetrics{},
+		now:            time.Now,
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+func (r *BlogRepository) GetByID(ctx context.Context, id uint) (*domain.Blog, error) {
+	if ports.IsStrongConsistency(ctx) || ports.InUnitOfWork(ctx) {
+		return r.BlogRepository.GetByID(ctx, id)
+	}
+	if entry, ok := r.local.get(id, r.now()); ok {
+		r.metrics.CacheHit(cacheName, "local", !entry.Found)
+		return entry.result(id)
+	}
+
+	// The load is shared, so one caller giving up must not fail the others.
+	loaded, err, _ := r.loads.Do(strconv.FormatUint(uint64(id), 10), func() (interface{}, error) {
+		return r.load(context.WithoutCancel(ctx), id)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return loaded.(cachedBlog).result(id)
+}
+
+// GetFields is served from the blog cached for GetByID, since reading the
+// whole blog from the cache is cheaper than part of it from the store.
+func (r *BlogRepository) GetFields(ctx context.Context, id uint, mask domain.FieldMask) (*domain.Blog, error) {
+	if ports.IsStrongConsistency(ctx) || ports.InUnitOfWork(ctx) {
+		return r.BlogRepository.GetFields(ctx, id, mask)
+	}
+	blog, err := r.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return mask.Apply(blog), nil
+}
+
+// load reads a blog missing from the local cache from the remote cache or
+// the repository, and caches it unless an invalidation raced with the read.
+func (r *BlogRepository) load(ctx context.Context, id uint) (cachedBlog, error) {
+	generation := r.local.generation()
+	entry, ok := r.getRemote(ctx, id)
+	if ok {
+		r.metrics.CacheHit(cacheName, "remote", !entry.Found)
+	} else {
+		r.metrics.CacheMiss(cacheName)
+		blog, err := r.BlogRepository.GetByID(ctx, id)
+		switch {
+		case err == nil:
+			entry = cachedBlog{Blog: *blog, Found: true}
+		case isNotFound(err):
+		default:
+			return cachedBlog{}, err
+		}
+	}
+
+	if r.local.addSince(generation, id, entry, r.now().Add(r.ttl(entry))) && !ok {
+		r.setRemote(ctx, id, entry)
+	}
+	return entry, nil
+}
+
+func (r *BlogRepository) Create(ctx context.Context, blog *domain.Blog) error {
+	if err := r.BlogRepository.Create(ctx, blog); err != nil {
+		return err
+	}
+	// The ID may have been cached as not found.
+	r.invalidate(ctx, blog.ID)
+	return nil
+}
+
+func (r *BlogRepository) Update(ctx context.Context, blog *domain.Blog) error {
+	if err := r.BlogRepository.Update(ctx, blog); err != nil {
+		return err
+	}
+	r.invalidate(ctx, blog.ID)
+	return nil
+}
+
+func (r *BlogRepository) Delete(ctx context.Context, id uint) error {
+	if err := r.BlogRepository.Delete(ctx, id); err != nil {
+		return err
+	}
+	r.invalidate(ctx, id)
+	return nil
+}
+
+func (r *BlogRepository) CreateBatch(ctx context.Context, blogs []*domain.Blog) error {
+	if err := r.BlogRepository.CreateBatch(ctx, blogs); err != nil {
+		return err
+	}
+	ids := make([]uint, len(blogs))
+	for i, blog := range blogs {
+		ids[i] = blog.ID
+	}
+	r.invalidate(ctx, ids...)
+	return nil
+}
+
+func (r *BlogRepository) DeleteBatch(ctx context.Context, ids []uint) error {
+	if err := r.BlogRepository.DeleteBatch(ctx, ids); err != nil {
+		return err
+	}
+	r.invalidate(ctx, ids...)
+	return nil
+}
+
+// invalidate drops the blogs from both caches once the change is committed,
+// so that no read can cache the old version after that.
+func (r *BlogRepository) invalidate(ctx context.Context, ids ...uint) {
+	ctx = context.WithoutCancel(ctx)
+	ports.AfterCommit(ctx, func() {
+		r.local.remove(ids...)
+		keys := make([]string, len(ids))
+		for i, id := range ids {
+			keys[i] = remoteKey(id)
+		}
+		if r.remote == nil {
+			return
+		}
+		if err := r.remote.Delete(ctx, keys...); err != nil {
+			slog.Warn("blog cache invalidation failed", "error", err)
+		}
+	})
+}
+
+func (r *BlogRepository) getRemote(ctx context.Context, id uint) (cachedBlog, bool) {
+	if r.remote == nil {
+		return cachedBlog{}, false
+	}
+	data, ok, err := r.remote.Get(ctx, remoteKey(id))
+	if err != nil {
+		slog.Warn("blog cache read failed", "error", err)
+		return cachedBlog{}, false
+	}
+	var entry cachedBlog
+	if !ok || json.Unmarshal(data, &entry) != nil {
+		return cachedBlog{}, false
+	}
+	return entry, true
+}
+
+func (r *BlogRepository) setRemote(ctx context.Context, id uint, entry cachedBlog) {
+	if r.remote == nil {
+		return
+	}
+	data, err := json.Marshal(entry)
+	if err == nil {
+		err = r.remote.Set(ctx, remoteKey(id), data, r.ttl(entry))
+	}
+	if err != nil {
+		slog.Warn("blog cache write failed", "error", err)
+	}
+}
+
+func (r *BlogRepository) ttl(entry cachedBlog) time.Duration {
+	if entry.Found {
+		return r.cfg.TTL
+	}
+	return r.cfg.NegativeTTL
+}
+
+// result returns a deep copy of the cached blog, so callers cannot change
+// the cache.
+func (e cachedBlog) result(id uint) (*domain.Blog, error) {
+	if !e.Found {
+		return nil, errors.NewNotFoundError(fmt.Sprintf("Blog with ID %d not found", id))
+	}
+	blog := e.Blog
+	blog.Tags = slices.Clone(blog.Tags)
+	blog.TOC = slices.Clone(blog.TOC)
+	if blog.CoverImageID != nil {
+		coverImageID := *blog.CoverImageID
+		blog.CoverImageID = &coverImageID
+	}
+	return &blog, nil
+}
+
+func remoteKey(id uint) string {
+	return "blog:" + strconv.FormatUint(uint64(id), 10)
+}
+
+func isNotFound(err error) bool {
+	var appErr errors.AppError
+	if stderrors.As(err, &appErr) {
+		return appErr.Type == errors.NotFound
+	}
+	return stderrors.Is(err, gorm.ErrRecordNotFound)
+}
+
+type nopMetrics struct{}
+
+func (nopMetrics) CacheHit(cache, tier string, negative bool) {}
+func (nopMetrics) CacheMiss(cache string)                     {}
@@ -0,0 +1,287 @@
+package cache_test
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/toffysoft/go-hexagonal-example/internal/adapters/cache"
+	"github.com/toffysoft/go-hexagonal-example/internal/adapters/repositories"
+	"github.com/toffysoft/go-hexagonal-example/internal/core/domain"
+	"github.com/toffysoft/go-hexagonal-example/internal/core/ports"
+	"github.com/toffysoft/go-hexagonal-example/pkg/errors"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingRepository counts the reads that reach the store, optionally
+// holding each one until release is closed.
+type countingRepository struct {
+	ports.BlogRepository
+	reads   atomic.Int32
+	release chan struct{}
+}
+
+func (r *countingRepository) GetByID(ctx context.Context, id uint) (*domain.Blog, error) {
+	r.reads.Add(1)
+	if r.release != nil {
+		<-r.release
+	}
+	return r.BlogRepository.GetByID(ctx, id)
+}
+
+// slowRepository reads the store, then holds the result until release is
+// closed, so that a change can be made while the read is in flight.
+type slowRepository struct {
+	ports.BlogRepository
+	read    chan struct{}
+	release chan struct{}
+}
+
+func (r *slowRepository) GetByID(ctx context.Context, id uint) (*domain.Blog, error) {
+	blog, err := r.BlogRepository.GetByID(ctx, id)
+	close(r.read)
+	<-r.release
+	return blog, err
+}
+
+type fakeCacheMetrics struct {
+	mu     sync.Mutex
+	hits   []string
+	misses int
+}
+
+func (m *fakeCacheMetrics) CacheHit(cache, tier string, negative bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if negative {
+		tier += "/negative"
+	}
+	m.hits = append(m.hits, tier)
+}
+
+func (m *fakeCacheMetrics) CacheMiss(cache string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.misses++
+}
+
+var testConfig = cache.Config{Size: 100, TTL: time.Minute, NegativeTTL: time.Minute}
+
+func setupCache(t *testing.T, opts ...cache.Option) (*cache.BlogRepository, *countingRepository, *repositories.MemoryStore) {
+	store := repositories.NewMemoryStore()
+	require.NoError(t, store.Blogs().Create(context.Background(), &domain.Blog{Title: "Hello", Content: "Body", Author: "alice"}))
+	counting := &countingRepository{BlogRepository: store.Blogs()}
+	return cache.NewBlogRepository(counting, testConfig, opts...), counting, store
+}
+
+func TestBlogRepositoryCachesReads(t *testing.T) {
+	ctx := context.Background()
+	metrics := &fakeCacheMetrics{}
+	repo, counting, _ := setupCache(t, cache.WithMetrics(metrics))
+
+	first, err := repo.GetByID(ctx, 1)
+	require.NoError(t, err)
+	first.Title = "Changed by the caller"
+	second, err := repo.GetByID(ctx, 1)
+	require.NoError(t, err)
+
+	assert.Equal(t, "Hello", second.Title)
+	assert.EqualValues(t, 1, counting.reads.Load())
+	assert.Equal(t, 1, metrics.misses)
+	assert.Equal(t, []string{"local"}, metrics.hits)
+}
+
+func TestBlogRepositoryReturnsDeepCopies(t *testing.T) {
+	ctx := context.Background()
+	store := repositories.NewMemoryStore()
+	cover := uint(7)
+	require.NoError(t, store.Blogs().Create(ctx, &domain.Blog{
+		Title: "Hello", Content: "# Intro", Author: "alice", Tags: []string{"go", "news"},
+		TOC: []domain.Heading{{Level: 1, Text: "Intro", ID: "intro"}}, CoverImageID: &cover,
+	}))
+	repo := cache.NewBlogRepository(store.Blogs(), testConfig)
+
+	first, err := repo.GetByID(ctx, 1)
+	require.NoError(t, err)
+	first.Tags[0] = "changed"
+	first.Tags = append(first.Tags, "added")
+	first.TOC[0].Text = "Changed"
+	*first.CoverImageID = 8
+	second, err := repo.GetByID(ctx, 1)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"go", "news"}, second.Tags)
+	assert.Equal(t, "Intro", second.TOC[0].Text)
+	assert.Equal(t, uint(7), *second.CoverImageID)
+}
+
+func TestBlogRepositoryCachesNotFound(t *testing.T) {
+	ctx := context.Background()
+	repo, counting, _ := setupCache(t)
+
+	for i := 0; i < 2; i++ {
+		_, err := repo.GetByID(ctx, 2)
+		require.Error(t, err)
+		assert.Equal(t, errors.NotFound, err.(errors.AppError).Type)
+	}
+	assert.EqualValues(t, 1, counting.reads.Load())
+
+	require.NoError(t, repo.Create(ctx, &domain.Blog{Title: "Second", Content: "Body", Author: "bob"}))
+	blog, err := repo.GetByID(ctx, 2)
+	require.NoError(t, err)
+	assert.Equal(t, "Second", blog.Title)
+}
+
+func TestBlogRepositoryInvalidatesOnCommit(t *testing.T) {
+	ctx := context.Background()
+	repo, _, store := setupCache(t)
+	uow := store.UnitOfWork()
+	_, err := repo.GetByID(ctx, 1)
+	require.NoError(t, err)
+
+	err = uow.Do(ctx, func(ctx context.Context) error {
+		require.NoError(t, repo.Update(ctx, &domain.Blog{ID: 1, Title: "Updated", Content: "Body", Author: "alice"}))
+
+		// Not committed yet: other readers still get the cached blog.
+		blog, err := repo.GetByID(context.Background(), 1)
+		require.NoError(t, err)
+		assert.Equal(t, "Hello", blog.Title)
+
+		// Reads in the unit of work see its own write.
+		blog, err = repo.GetByID(ctx, 1)
+		require.NoError(t, err)
+		assert.Equal(t, "Updated", blog.Title)
+		return nil
+	})
+	require.NoError(t, err)
+
+	blog, err := repo.GetByID(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, "Updated", blog.Title)
+
+	require.NoError(t, repo.Delete(ctx, 1))
+	_, err = repo.GetByID(ctx, 1)
+	assert.Error(t, err)
+}
+
+func TestBlogRepositoryDoesNotCacheRacingRead(t *testing.T) {
+	ctx := context.Background()
+	store := repositories.NewMemoryStore()
+	require.NoError(t, store.Blogs().Create(ctx, &domain.Blog{Title: "Hello", Content: "Body", Author: "alice"}))
+	slow := &slowRepository{BlogRepository: store.Blogs(), read: make(chan struct{}), release: make(chan struct{})}
+	repo := cache.NewBlogRepository(slow, testConfig)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		blog, err := repo.GetByID(ctx, 1)
+		assert.NoError(t, err)
+		assert.Equal(t, "Hello", blog.Title)
+	}()
+	<-slow.read
+	require.NoError(t, repo.Update(ctx, &domain.Blog{ID: 1, Title: "Updated", Content: "Body", Author: "alice"}))
+	close(slow.release)
+	<-done
+
+	// The read that started before the update must not have been cached.
+	slow.read = make(chan struct{})
+	blog, err := repo.GetByID(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, "Updated", blog.Title)
+}
+
+func TestBlogRepositoryKeepsCacheOnRollback(t *testing.T) {
+	ctx := context.Background()
+	repo, counting, store := setupCache(t)
+	_, err := repo.GetByID(ctx, 1)
+	require.NoError(t, err)
+
+	err = store.UnitOfWork().Do(ctx, func(ctx context.Context) error {
+		require.NoError(t, repo.Delete(ctx, 1))
+		return errors.NewConflictError("rolled back")
+	})
+	require.Error(t, err)
+
+	_, err = repo.GetByID(ctx, 1)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, counting.reads.Load())
+}
+
+func TestBlogRepositoryBypassesCacheForStrongReads(t *testing.T) {
+	ctx := context.Background()
+	repo, counting, _ := setupCache(t)
+
+	for i := 0; i < 2; i++ {
+		_, err := repo.GetByID(ports.WithStrongConsistency(ctx), 1)
+		require.NoError(t, err)
+	}
+	assert.EqualValues(t, 2, counting.reads.Load())
+}
+
+func TestBlogRepositoryCoalescesMisses(t *testing.T) {
+	repo, counting, _ := setupCache(t)
+	counting.release = make(chan struct{})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			blog, err := repo.GetByID(context.Background(), 1)
+			assert.NoError(t, err)
+			assert.Equal(t, "Hello", blog.Title)
+		}()
+	}
+	require.Eventually(t, func() bool { return counting.reads.Load() == 1 }, time.Second, time.Millisecond)
+	time.Sleep(10 * time.Millisecond)
+	close(counting.release)
+	wg.Wait()
+
+	assert.EqualValues(t, 1, counting.reads.Load())
+}
+
+func TestBlogRepositoryEvictsLeastRecentlyUsed(t *testing.T) {
+	ctx := context.Background()
+	store := repositories.NewMemoryStore()
+	for _, title := range []string{"One", "Two"} {
+		require.NoError(t, store.Blogs().Create(ctx, &domain.Blog{Title: title, Content: "Body", Author: "alice"}))
+	}
+	counting := &countingRepository{BlogRepository: store.Blogs()}
+	repo := cache.NewBlogRepository(counting, cache.Config{Size: 1, TTL: time.Minute, NegativeTTL: time.Minute})
+
+	for _, id := range []uint{1, 2, 1} {
+		_, err := repo.GetByID(ctx, id)
+		require.NoError(t, err)
+	}
+	assert.EqualValues(t, 3, counting.reads.Load())
+}
+
+func TestBlogRepositorySharesRemoteCache(t *testing.T) {
+	ctx := context.Background()
+	remote := cache.NewMemoryCache(100)
+	metrics := &fakeCacheMetrics{}
+	store := repositories.NewMemoryStore()
+	require.NoError(t, store.Blogs().Create(ctx, &domain.Blog{Title: "Hello", Content: "Body", Author: "alice"}))
+	counting := &countingRepository{BlogRepository: store.Blogs()}
+	instanceA := cache.NewBlogRepository(counting, testConfig, cache.WithRemote(remote))
+	instanceB := cache.NewBlogRepository(counting, testConfig, cache.WithRemote(remote), cache.WithMetrics(metrics))
+
+	_, err := instanceA.GetByID(ctx, 1)
+	require.NoError(t, err)
+	blog, err := instanceB.GetByID(ctx, 1)
+	require.NoError(t, err)
+
+	assert.Equal(t, "Hello", blog.Title)
+	assert.EqualValues(t, 1, counting.reads.Load())
+	assert.Equal(t, []string{"remote"}, metrics.hits)
+
+	// A write on one instance clears the shared copy.
+	require.NoError(t, instanceA.Update(ctx, &domain.Blog{ID: 1, Title: "Updated", Content: "Body", Author: "alice"}))
+	_, ok, err := remote.Get(ctx, "blog:1")
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
@@ -0,0 +1,39 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"time"
+
+	"github.com/toffysoft/go-hexagonal-example/internal/core/ports"
+)
+
+// MemoryCache is a ports.Cache kept in process. It stands in for a
+// distributed cache in tests and single-instance runs.
+type MemoryCache struct {
+	entries *lru[string, []byte]
+	now     func() time.Time
+}
+
+var _ ports.Cache = (*MemoryCache)(nil)
+
+func NewMemoryCache(size int) *MemoryCache {
+	return &MemoryCache{entries: newLRU[string, []byte](size), now: time.Now}
+}
+
+func (c *MemoryCache) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	value, ok := c.entries.get(key, c.now())
+	return bytes.Clone(value), ok, nil
+}
+
+func (c *MemoryCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	c.entries.add(key, bytes.Clone(value), c.now().Add(ttl))
+	return nil
+}
+
+func (c *MemoryCache) Delete(ctx context.Context, keys ...string) error {
+	for _, key := range keys {
+		c.entries.remove(key)
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package cache
+
+import (
+	"context"
+	"crypto/sha256"
+	"time"
+
+	"github.com/toffysoft/go-hexagonal-example/internal/core/domain"
+	"github.com/toffysoft/go-hexagonal-example/internal/core/ports"
+)
+
+const renderCacheName = "render"
+
+// Renderer caches rendered content in process, in front of another
+// ContentRenderer. Entries are keyed by a hash of the format and content, so
+// an updated blog misses and is rendered again; the rendering of its old
+// content ages out of the cache.
+type Renderer struct {
+	next    ports.ContentRenderer
+	local   *lru[[sha256.Size]byte, string]
+	metrics ports.CacheMetrics
+}
+
+var _ ports.ContentRenderer = (*Renderer)(nil)
+
+// NewRenderer keeps up to size renderings. metrics may be nil.
+func NewRenderer(next ports.ContentRenderer, size int, metrics ports.CacheMetrics) *Renderer {
+	if metrics == nil {
+		metrics = nopMetrics{}
+	}
+	return &Renderer{next: next, local: newLRU[[sha256.Size]byte, string](size), metrics: metrics}
+}
+
+func (r *Renderer) Render(ctx context.Context, format domain.ContentFormat, content string) (string, error) {
+	key := renderKey(format, content)
+	// What is cached never goes stale, so it only expires by eviction.
+	var never time.Time
+	if rendered, ok := r.local.get(key, never); ok {
+		r.metrics.CacheHit(renderCacheName, "local", false)
+		return rendered, nil
+	}
+	r.metrics.CacheMiss(renderCacheName)
+	rendered, err := r.next.Render(ctx, format, content)
+	if err != nil {
+		return "", err
+	}
+	r.local.add(key, rendered, never.Add(1<<63-1))
+	return rendered, nil
+}
+
+func renderKey(format domain.ContentFormat, content string) [sha256.Size]byte {
+	h := sha256.New()
+	h.Write([]byte(format))
+	h.Write([]byte{0})
+	h.Write([]byte(content))
+	var key [sha256.Size]byte
+	h.Sum(key[:0])
+	return key
+}
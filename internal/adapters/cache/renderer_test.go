@@ -0,0 +1,44 @@
+package cache_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/toffysoft/go-hexagonal-example/internal/adapters/cache"
+	"github.com/toffysoft/go-hexagonal-example/internal/core/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type countingRenderer struct {
+	renders int
+}
+
+func (r *countingRenderer) Render(ctx context.Context, format domain.ContentFormat, content string) (string, error) {
+	r.renders++
+	return "<p>" + content + "</p>", nil
+}
+
+func TestRendererCachesByContent(t *testing.T) {
+	ctx := context.Background()
+	next := &countingRenderer{}
+	metrics := &fakeCacheMetrics{}
+	renderer := cache.NewRenderer(next, 10, metrics)
+
+	for i := 0; i < 2; i++ {
+		out, err := renderer.Render(ctx, domain.ContentMarkdown, "one")
+		require.NoError(t, err)
+		assert.Equal(t, "<p>one</p>", out)
+	}
+	assert.Equal(t, 1, next.renders)
+	assert.Equal(t, []string{"local"}, metrics.hits)
+
+	// Changed content, or the same content in another format, is rendered
+	// again.
+	_, err := renderer.Render(ctx, domain.ContentMarkdown, "two")
+	require.NoError(t, err)
+	_, err = renderer.Render(ctx, domain.ContentPlain, "one")
+	require.NoError(t, err)
+	assert.Equal(t, 3, next.renders)
+}
@@ -0,0 +1,220 @@
+package changefeed
+
+import (
+	"context"
+	"log/slog"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/toffysoft/go-hexagonal-example/internal/core/domain"
+	"github.com/toffysoft/go-hexagonal-example/internal/core/ports"
+)
+
+// Config tunes a Feed.
+type Config struct {
+	// BufferSize is the number of recent changes kept for replay.
+	BufferSize int
+	// SubscriberBuffer is how many changes a subscriber may fall behind
+	// before it is dropped.
+	SubscriberBuffer int
+	// PollInterval is how long the feed waits after catching up with the
+	// outbox.
+	PollInterval time.Duration
+	// GapTimeout is how long a missing outbox ID is waited for. IDs are
+	// taken when a message is inserted but become visible when its
+	// transaction commits, so a later message can appear first. An ID still
+	// missing after GapTimeout belongs to a transaction that rolled back.
+	GapTimeout time.Duration
+}
+
+// Feed is a ports.ChangeFeed read from the outbox. Every instance reads the
+// outbox in ID order, so all of them deliver the same changes in the order
+// they were recorded. A cursor is the ID of the outbox message, so it resumes
+// on any instance and across restarts.
+type Feed struct {
+	outbox ports.OutboxRepository
+	cfg    Config
+	now    func() time.Time
+
+	mu sync.Mutex
+	// last is the ID of the last message read, and floor that of the last
+	// change dropped from the buffer.
+	last        uint
+	floor       uint
+	buffer      []entry
+	subscribers map[chan domain.BlogChange]uint
+}
+
+// entry is a buffered change and the ID of its outbox message.
+type entry struct {
+	id     uint
+	change domain.BlogChange
+}
+
+var _ ports.ChangeFeed = (*Feed)(nil)
+
+func New(outbox ports.OutboxRepository, cfg Config) *Feed {
+	return &Feed{
+		outbox:      outbox,
+		cfg:         cfg,
+		now:         time.Now,
+		buffer:      make([]entry, 0, cfg.BufferSize),
+		subscribers: make(map[chan domain.BlogChange]uint),
+	}
+}
+
+// Load fills the buffer with the latest changes, so that subscribers can
+// resume after a restart, and starts the feed after them. It is meant to be
+// called once, before Run.
+func (f *Feed) Load(ctx context.Context) error {
+	messages, err := f.outbox.Latest(ctx, f.cfg.BufferSize)
+	if err != nil {
+		return err
+	}
+	if len(messages) == f.cfg.BufferSize {
+		// Older changes may exist but cannot be replayed.
+		f.mu.Lock()
+		f.floor = messages[0].ID - 1
+		f.mu.Unlock()
+	}
+	for _, message := range messages {
+		f.add(message)
+	}
+	return nil
+}
+
+// Run reads new changes from the outbox until ctx is cancelled.
+func (f *Feed) Run(ctx context.Context) {
+	ticker := time.NewTicker(f.cfg.PollInterval)
+	defer ticker.Stop()
+	for {
+		f.drain(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// drain polls until the feed has caught up with the outbox.
+func (f *Feed) drain(ctx context.Context) {
+	for ctx.Err() == nil {
+		n, err := f.PollOnce(ctx)
+		if err != nil {
+			slog.Error("change feed poll failed", "error", err)
+			return
+		}
+		if n < f.cfg.BufferSize {
+			return
+		}
+	}
+}
+
+// PollOnce reads the messages added to the outbox since the last one read
+// and sends them to the subscribers. It stops early at a missing ID younger
+// than GapTimeout. It returns the number of messages read.
+func (f *Feed) PollOnce(ctx context.Context) (int, error) {
+	f.mu.Lock()
+	last := f.last
+	f.mu.Unlock()
+
+	messages, err := f.outbox.After(ctx, last, f.cfg.BufferSize)
+	if err != nil {
+		return 0, err
+	}
+	for i, message := range messages {
+		if message.ID != last+1 && f.now().Sub(message.CreatedAt) < f.cfg.GapTimeout {
+			return i, nil
+		}
+		f.add(message)
+		last = message.ID
+	}
+	return len(messages), nil
+}
+
+// add appends the change message carries to the buffer and sends it to the
+// subscribers that have not seen it yet.
+func (f *Feed) add(message *domain.OutboxMessage) {
+	event := message.Event()
+	payload, err := event.BlogPayload()
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.last = message.ID
+	if err != nil {
+		slog.Warn("change feed skipped an undecodable event", "event_id", event.ID, "error", err)
+		return
+	}
+
+	change := domain.BlogChange{
+		Cursor: strconv.FormatUint(uint64(message.ID), 10),
+		Event:  event,
+		Blog:   payload.Blog,
+	}
+	if len(f.buffer) == f.cfg.BufferSize {
+		f.floor = f.buffer[0].id
+		copy(f.buffer, f.buffer[1:])
+		f.buffer = f.buffer[:f.cfg.BufferSize-1]
+	}
+	f.buffer = append(f.buffer, entry{id: message.ID, change: change})
+
+	for ch, after := range f.subscribers {
+		if message.ID <= after {
+			continue
+		}
+		select {
+		case ch <- change:
+		default:
+			// A subscriber that cannot keep up is dropped rather than
+			// slowing down everyone else; it resumes from its last cursor.
+			slog.Warn("change feed subscriber dropped, too far behind", "cursor", change.Cursor)
+			delete(f.subscribers, ch)
+			close(ch)
+		}
+	}
+}
+
+func (f *Feed) Subscribe(ctx context.Context, cursor string) ([]domain.BlogChange, <-chan domain.BlogChange, bool) {
+	f.mu.Lock()
+	replay, after, resumed := f.since(cursor)
+	ch := make(chan domain.BlogChange, f.cfg.SubscriberBuffer)
+	f.subscribers[ch] = after
+	f.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		if _, ok := f.subscribers[ch]; ok {
+			delete(f.subscribers, ch)
+			close(ch)
+		}
+	}()
+	return replay, ch, resumed
+}
+
+// since returns the buffered changes after cursor and the ID after which
+// live changes are to be sent. f.mu must be held.
+func (f *Feed) since(cursor string) ([]domain.BlogChange, uint, bool) {
+	if cursor == "" {
+		return nil, f.last, true
+	}
+	id, err := strconv.ParseUint(cursor, 10, 0)
+	if err != nil || uint(id) < f.floor {
+		return nil, f.last, false
+	}
+	if uint(id) >= f.last {
+		// Another instance may have read further than this one.
+		return nil, uint(id), true
+	}
+
+	var replay []domain.BlogChange
+	for _, e := range f.buffer {
+		if e.id > uint(id) {
+			replay = append(replay, e.change)
+		}
+	}
+	return replay, f.last, true
+}
@@ -0,0 +1,200 @@
+package changefeed_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/toffysoft/go-hexagonal-example/internal/adapters/changefeed"
+	"github.com/toffysoft/go-hexagonal-example/internal/adapters/repositories"
+	"github.com/toffysoft/go-hexagonal-example/internal/core/domain"
+	"github.com/toffysoft/go-hexagonal-example/internal/core/ports"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newFeed(t *testing.T, outbox ports.OutboxRepository, bufferSize, subscriberBuffer int) *changefeed.Feed {
+	feed := changefeed.New(outbox, changefeed.Config{
+		BufferSize:       bufferSize,
+		SubscriberBuffer: subscriberBuffer,
+		PollInterval:     time.Second,
+		GapTimeout:       time.Minute,
+	})
+	require.NoError(t, feed.Load(context.Background()))
+	return feed
+}
+
+// record adds n blog created events to the outbox and polls them into feed.
+func record(t *testing.T, outbox ports.OutboxRepository, feed *changefeed.Feed, n int) {
+	for i := 1; i <= n; i++ {
+		require.NoError(t, outbox.Add(context.Background(), domain.NewBlogCreatedEvent(&domain.Blog{ID: uint(i)})))
+	}
+	for {
+		n, err := feed.PollOnce(context.Background())
+		require.NoError(t, err)
+		if n == 0 {
+			return
+		}
+	}
+}
+
+// gapOutbox returns fixed messages, whose IDs need not be consecutive.
+type gapOutbox struct {
+	ports.OutboxRepository
+	messages []*domain.OutboxMessage
+}
+
+func (o *gapOutbox) After(ctx context.Context, id uint, limit int) ([]*domain.OutboxMessage, error) {
+	var after []*domain.OutboxMessage
+	for _, message := range o.messages {
+		if message.ID > id && len(after) < limit {
+			after = append(after, message)
+		}
+	}
+	return after, nil
+}
+
+func (o *gapOutbox) Latest(ctx context.Context, limit int) ([]*domain.OutboxMessage, error) {
+	return nil, nil
+}
+
+func message(id uint, createdAt time.Time) *domain.OutboxMessage {
+	message := domain.NewOutboxMessage(domain.NewBlogCreatedEvent(&domain.Blog{ID: id}))
+	message.ID = id
+	message.CreatedAt = createdAt
+	return message
+}
+
+func TestFeed(t *testing.T) {
+	t.Run("LiveSubscribers", func(t *testing.T) {
+		outbox := repositories.NewMemoryOutbox()
+		feed := newFeed(t, outbox, 10, 10)
+		ctx, cancel := context.WithCancel(context.Background())
+		replay, live, resumed := feed.Subscribe(ctx, "")
+
+		record(t, outbox, feed, 2)
+
+		assert.Empty(t, replay)
+		assert.True(t, resumed)
+		first, second := <-live, <-live
+		assert.Equal(t, uint(1), first.Blog.ID)
+		assert.Equal(t, uint(2), second.Blog.ID)
+		assert.NotEqual(t, first.Cursor, second.Cursor)
+
+		cancel()
+		_, open := <-live
+		assert.False(t, open, "the channel should be closed when ctx ends")
+	})
+
+	t.Run("ResumesFromCursor", func(t *testing.T) {
+		outbox := repositories.NewMemoryOutbox()
+		feed := newFeed(t, outbox, 10, 10)
+		_, live, _ := feed.Subscribe(context.Background(), "")
+		record(t, outbox, feed, 3)
+		first := <-live
+
+		replay, _, resumed := feed.Subscribe(context.Background(), first.Cursor)
+
+		assert.True(t, resumed)
+		require.Len(t, replay, 2)
+		assert.Equal(t, uint(2), replay[0].Blog.ID)
+		assert.Equal(t, uint(3), replay[1].Blog.ID)
+	})
+
+	t.Run("ResumesOnAnotherInstance", func(t *testing.T) {
+		outbox := repositories.NewMemoryOutbox()
+		feed := newFeed(t, outbox, 10, 10)
+		_, live, _ := feed.Subscribe(context.Background(), "")
+		record(t, outbox, feed, 3)
+		first := <-live
+
+		// A feed started later loads the same changes with the same cursors.
+		other := newFeed(t, outbox, 10, 10)
+		replay, _, resumed := other.Subscribe(context.Background(), first.Cursor)
+
+		assert.True(t, resumed)
+		require.Len(t, replay, 2)
+		assert.Equal(t, uint(2), replay[0].Blog.ID)
+	})
+
+	t.Run("CursorAheadOfInstance", func(t *testing.T) {
+		outbox := repositories.NewMemoryOutbox()
+		feed := newFeed(t, outbox, 10, 10)
+		for i := 0; i < 3; i++ {
+			require.NoError(t, outbox.Add(context.Background(), domain.NewBlogCreatedEvent(&domain.Blog{ID: uint(i + 1)})))
+		}
+
+		// The cursor was handed out by an instance that already read "2".
+		replay, live, resumed := feed.Subscribe(context.Background(), "2")
+		_, err := feed.PollOnce(context.Background())
+		require.NoError(t, err)
+
+		assert.True(t, resumed)
+		assert.Empty(t, replay)
+		assert.Equal(t, uint(3), (<-live).Blog.ID)
+	})
+
+	t.Run("CursorOutsideBuffer", func(t *testing.T) {
+		outbox := repositories.NewMemoryOutbox()
+		feed := newFeed(t, outbox, 2, 10)
+		_, live, _ := feed.Subscribe(context.Background(), "")
+		record(t, outbox, feed, 4)
+		first := <-live
+
+		replay, _, resumed := feed.Subscribe(context.Background(), first.Cursor)
+		assert.False(t, resumed)
+		assert.Empty(t, replay)
+
+		_, _, resumed = feed.Subscribe(context.Background(), "earlier-process-1")
+		assert.False(t, resumed)
+	})
+
+	t.Run("DropsSlowSubscribers", func(t *testing.T) {
+		outbox := repositories.NewMemoryOutbox()
+		feed := newFeed(t, outbox, 10, 1)
+		_, live, _ := feed.Subscribe(context.Background(), "")
+
+		record(t, outbox, feed, 2)
+
+		_, ok := <-live
+		assert.True(t, ok)
+		_, ok = <-live
+		assert.False(t, ok, "the subscriber should be dropped once its buffer is full")
+	})
+
+	t.Run("WaitsForMissingIDs", func(t *testing.T) {
+		outbox := &gapOutbox{messages: []*domain.OutboxMessage{
+			message(1, time.Now()),
+			message(3, time.Now()),
+		}}
+		feed := newFeed(t, outbox, 10, 10)
+
+		n, err := feed.PollOnce(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, 1, n, "message 2 may still commit")
+
+		// Message 2 committed after all.
+		outbox.messages = append(outbox.messages, message(2, time.Now()))
+		outbox.messages[1], outbox.messages[2] = outbox.messages[2], outbox.messages[1]
+		n, err = feed.PollOnce(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, 2, n)
+	})
+
+	t.Run("SkipsExpiredGaps", func(t *testing.T) {
+		outbox := &gapOutbox{messages: []*domain.OutboxMessage{
+			message(1, time.Now().Add(-time.Hour)),
+			message(3, time.Now().Add(-time.Hour)),
+		}}
+		feed := newFeed(t, outbox, 10, 10)
+		_, live, _ := feed.Subscribe(context.Background(), "")
+
+		n, err := feed.PollOnce(context.Background())
+		require.NoError(t, err)
+
+		assert.Equal(t, 2, n)
+		assert.Equal(t, "1", (<-live).Cursor)
+		assert.Equal(t, "3", (<-live).Cursor)
+	})
+}
@@ -0,0 +1,107 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/toffysoft/go-hexagonal-example/internal/core/domain"
+	"github.com/toffysoft/go-hexagonal-example/internal/core/ports"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ErrClosed is returned by Publish once the bus has been closed.
+var ErrClosed = errors.New("event bus closed")
+
+type delivery struct {
+	ctx   context.Context
+	sub   subscription
+	event domain.Event
+}
+
+// AsyncBus hands deliveries to a fixed pool of workers through a bounded
+// queue. Publish blocks while the queue is full, so a slow subscriber slows
+// the publisher down instead of growing memory without bound. Handler errors
+// are logged and counted; they are not returned to the publisher.
+type AsyncBus struct {
+	registry
+	opts options
+
+	queue   chan delivery
+	workers sync.WaitGroup
+
+	mu     sync.RWMutex
+	closed bool
+}
+
+var _ ports.EventBus = (*AsyncBus)(nil)
+
+// NewAsyncBus starts workers goroutines consuming a queue of queueSize
+// deliveries.
+func NewAsyncBus(workers, queueSize int, opts ...Option) *AsyncBus {
+	b := &AsyncBus{
+		opts:  newOptions(opts),
+		queue: make(chan delivery, queueSize),
+	}
+	b.workers.Add(workers)
+	for i := 0; i < workers; i++ {
+		go b.work()
+	}
+	return b
+}
+
+// Publish queues one delivery per matching handler. It returns ctx.Err() if
+// ctx ends while waiting for room in the queue.
+func (b *AsyncBus) Publish(ctx context.Context, event domain.Event) error {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if b.closed {
+		return ErrClosed
+	}
+
+	// Handlers outlive the publishing call and run on other goroutines, so
+	// they get a fresh context: ctx may be cancelled, or bound to a unit of
+	// work that commits before they run. Only the trace span is carried over.
+	handlerCtx := trace.ContextWithSpan(context.Background(), trace.SpanFromContext(ctx))
+	for _, sub := range b.matching(event.Type) {
+		select {
+		case b.queue <- delivery{ctx: handlerCtx, sub: sub, event: event}:
+			b.opts.metrics.QueueDepth(len(b.queue))
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+func (b *AsyncBus) work() {
+	defer b.workers.Done()
+	for d := range b.queue {
+		b.opts.metrics.QueueDepth(len(b.queue))
+		_ = deliver(d.ctx, b.opts, d.sub, d.event)
+	}
+}
+
+// Close stops accepting events and waits until the queued deliveries have
+// been handled or ctx ends.
+func (b *AsyncBus) Close(ctx context.Context) error {
+	b.mu.Lock()
+	if !b.closed {
+		b.closed = true
+		close(b.queue)
+	}
+	b.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		b.workers.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,121 @@
+// Package eventbus implements the event bus port in process, either
+// synchronously or on a bounded pool of workers.
+package eventbus
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+	"sync"
+
+	"github.com/toffysoft/go-hexagonal-example/internal/core/domain"
+	"github.com/toffysoft/go-hexagonal-example/internal/core/ports"
+)
+
+// Option configures a bus.
+type Option func(*options)
+
+type options struct {
+	metrics ports.EventBusMetrics
+	logger  *slog.Logger
+}
+
+// WithMetrics records queue depth and handler failures.
+func WithMetrics(metrics ports.EventBusMetrics) Option {
+	return func(o *options) {
+		o.metrics = metrics
+	}
+}
+
+// WithLogger sets the logger handler failures are reported to.
+func WithLogger(logger *slog.Logger) Option {
+	return func(o *options) {
+		o.logger = logger
+	}
+}
+
+func newOptions(opts []Option) options {
+	o := options{metrics: nopMetrics{}, logger: slog.Default()}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+type subscription struct {
+	name    string
+	handler ports.EventHandler
+	types   map[domain.EventType]bool
+}
+
+func (s subscription) matches(eventType domain.EventType) bool {
+	return len(s.types) == 0 || s.types[eventType]
+}
+
+// registry holds the subscriptions of a bus.
+type registry struct {
+	mu            sync.RWMutex
+	subscriptions []subscription
+}
+
+func (r *registry) Subscribe(name string, handler ports.EventHandler, types ...domain.EventType) {
+	sub := subscription{name: name, handler: handler, types: make(map[domain.EventType]bool, len(types))}
+	for _, t := range types {
+		sub.types[t] = true
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.subscriptions = append(r.subscriptions, sub)
+}
+
+func (r *registry) matching(eventType domain.EventType) []subscription {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var subs []subscription
+	for _, sub := range r.subscriptions {
+		if sub.matches(eventType) {
+			subs = append(subs, sub)
+		}
+	}
+	return subs
+}
+
+// deliver calls the handler of sub, turning a panic into an error so that one
+// failing subscriber cannot affect the others.
+func deliver(ctx context.Context, o options, sub subscription, event domain.Event) (err error) {
+	panicked := false
+	defer func() {
+		if r := recover(); r != nil {
+			panicked = true
+			err = fmt.Errorf("subscriber %s panicked: %v", sub.name, r)
+			o.logger.Error("event handler panicked",
+				"subscriber", sub.name, "event_id", event.ID, "type", event.Type, "panic", r, "stack", string(debug.Stack()))
+		}
+		if err != nil {
+			o.metrics.HandlerFailed(sub.name, event.Type, panicked)
+		}
+	}()
+
+	if err := sub.handler(ctx, event); err != nil {
+		o.logger.Error("event handler failed",
+			"subscriber", sub.name, "event_id", event.ID, "type", event.Type, "error", err)
+		return fmt.Errorf("subscriber %s: %w", sub.name, err)
+	}
+	return nil
+}
+
+// LogHandler returns a handler that logs every event it receives.
+func LogHandler(logger *slog.Logger) ports.EventHandler {
+	return func(ctx context.Context, event domain.Event) error {
+		logger.InfoContext(ctx, "event received",
+			"event_id", event.ID, "type", event.Type, "blog_id", event.BlogID, "version", event.Version)
+		return nil
+	}
+}
+
+type nopMetrics struct{}
+
+func (nopMetrics) QueueDepth(int)                               {}
+func (nopMetrics) HandlerFailed(string, domain.EventType, bool) {}
@@ -0,0 +1,175 @@
+package eventbus_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/toffysoft/go-hexagonal-example/internal/adapters/eventbus"
+	"github.com/toffysoft/go-hexagonal-example/internal/adapters/repositories"
+	"github.com/toffysoft/go-hexagonal-example/internal/core/domain"
+	"github.com/toffysoft/go-hexagonal-example/internal/core/ports"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingMetrics struct {
+	mu       sync.Mutex
+	failures []string
+	maxDepth int
+}
+
+func (m *recordingMetrics) QueueDepth(depth int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if depth > m.maxDepth {
+		m.maxDepth = depth
+	}
+}
+
+func (m *recordingMetrics) HandlerFailed(subscriber string, eventType domain.EventType, panicked bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	reason := "error"
+	if panicked {
+		reason = "panic"
+	}
+	m.failures = append(m.failures, subscriber+":"+string(eventType)+":"+reason)
+}
+
+func TestSyncBus(t *testing.T) {
+	ctx := context.Background()
+	blog := &domain.Blog{ID: 1, Title: "Test Blog"}
+
+	t.Run("DeliversToMatchingSubscribers", func(t *testing.T) {
+		bus := eventbus.NewSyncBus()
+		var created, all []domain.EventType
+		bus.Subscribe("created", func(ctx context.Context, event domain.Event) error {
+			created = append(created, event.Type)
+			return nil
+		}, domain.BlogCreated)
+		bus.Subscribe("all", func(ctx context.Context, event domain.Event) error {
+			all = append(all, event.Type)
+			return nil
+		})
+
+		require.NoError(t, bus.Publish(ctx, domain.NewBlogCreatedEvent(blog)))
+		require.NoError(t, bus.Publish(ctx, domain.NewBlogDeletedEvent(blog)))
+
+		assert.Equal(t, []domain.EventType{domain.BlogCreated}, created)
+		assert.Equal(t, []domain.EventType{domain.BlogCreated, domain.BlogDeleted}, all)
+	})
+
+	t.Run("TypedSubscriber", func(t *testing.T) {
+		bus := eventbus.NewSyncBus()
+		var title string
+		bus.Subscribe("typed", ports.HandleBlogEvents(func(ctx context.Context, event domain.Event, payload domain.BlogEventPayload) error {
+			title = payload.Blog.Title
+			return nil
+		}), domain.BlogUpdated)
+
+		require.NoError(t, bus.Publish(ctx, domain.NewBlogUpdatedEvent(blog)))
+
+		assert.Equal(t, "Test Blog", title)
+	})
+
+	t.Run("IsolatesFailingSubscribers", func(t *testing.T) {
+		metrics := &recordingMetrics{}
+		bus := eventbus.NewSyncBus(eventbus.WithMetrics(metrics))
+		delivered := false
+		bus.Subscribe("panics", func(ctx context.Context, event domain.Event) error {
+			panic("boom")
+		})
+		bus.Subscribe("fails", func(ctx context.Context, event domain.Event) error {
+			return errors.New("unavailable")
+		})
+		bus.Subscribe("works", func(ctx context.Context, event domain.Event) error {
+			delivered = true
+			return nil
+		})
+
+		err := bus.Publish(ctx, domain.NewBlogCreatedEvent(blog))
+
+		assert.ErrorContains(t, err, "subscriber panics panicked: boom")
+		assert.ErrorContains(t, err, "subscriber fails: unavailable")
+		assert.True(t, delivered)
+		assert.Equal(t, []string{"panics:blog.created:panic", "fails:blog.created:error"}, metrics.failures)
+	})
+}
+
+func TestAsyncBus(t *testing.T) {
+	ctx := context.Background()
+	event := domain.NewBlogCreatedEvent(&domain.Blog{ID: 1})
+
+	t.Run("DeliversOnWorkers", func(t *testing.T) {
+		metrics := &recordingMetrics{}
+		bus := eventbus.NewAsyncBus(2, 10, eventbus.WithMetrics(metrics))
+		var mu sync.Mutex
+		count := 0
+		bus.Subscribe("counter", func(ctx context.Context, event domain.Event) error {
+			mu.Lock()
+			defer mu.Unlock()
+			count++
+			return nil
+		})
+		bus.Subscribe("panics", func(ctx context.Context, event domain.Event) error {
+			panic("boom")
+		})
+
+		for i := 0; i < 5; i++ {
+			require.NoError(t, bus.Publish(ctx, event))
+		}
+		require.NoError(t, bus.Close(ctx))
+
+		assert.Equal(t, 5, count)
+		assert.Len(t, metrics.failures, 5)
+		assert.ErrorIs(t, bus.Publish(ctx, event), eventbus.ErrClosed)
+	})
+
+	t.Run("AppliesBackpressure", func(t *testing.T) {
+		bus := eventbus.NewAsyncBus(1, 1)
+		release := make(chan struct{})
+		bus.Subscribe("slow", func(ctx context.Context, event domain.Event) error {
+			<-release
+			return nil
+		})
+
+		// One delivery is taken by the worker and one fills the queue.
+		require.NoError(t, bus.Publish(ctx, event))
+		require.NoError(t, bus.Publish(ctx, event))
+
+		full, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+		defer cancel()
+		assert.ErrorIs(t, bus.Publish(full, event), context.DeadlineExceeded)
+
+		close(release)
+		require.NoError(t, bus.Close(ctx))
+	})
+
+	t.Run("HandlersRunOutsideThePublishersUnitOfWork", func(t *testing.T) {
+		store := repositories.NewMemoryStore()
+		bus := eventbus.NewAsyncBus(1, 1)
+		handled := make(chan bool, 1)
+		bus.Subscribe("writer", func(ctx context.Context, event domain.Event) error {
+			handled <- ports.InUnitOfWork(ctx)
+			return store.Blogs().Create(ctx, &domain.Blog{Title: "From handler", Content: "Body", Author: "alice"})
+		})
+
+		// The publisher's unit of work rolls back after the handler has run.
+		err := store.UnitOfWork().Do(ctx, func(ctx context.Context) error {
+			require.NoError(t, bus.Publish(ctx, event))
+			assert.False(t, <-handled)
+			return errors.New("rolled back")
+		})
+		require.Error(t, err)
+		require.NoError(t, bus.Close(ctx))
+
+		blogs, err := store.Blogs().List(ctx)
+		require.NoError(t, err)
+		require.Len(t, blogs, 1)
+		assert.Equal(t, "From handler", blogs[0].Title)
+	})
+}
@@ -0,0 +1,33 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+
+	"github.com/toffysoft/go-hexagonal-example/internal/core/domain"
+	"github.com/toffysoft/go-hexagonal-example/internal/core/ports"
+)
+
+// SyncBus runs the handlers of an event one after another in Publish.
+type SyncBus struct {
+	registry
+	opts options
+}
+
+var _ ports.EventBus = (*SyncBus)(nil)
+
+func NewSyncBus(opts ...Option) *SyncBus {
+	return &SyncBus{opts: newOptions(opts)}
+}
+
+// Publish calls every matching handler, even if an earlier one failed, and
+// returns the joined errors of the failing ones.
+func (b *SyncBus) Publish(ctx context.Context, event domain.Event) error {
+	var errs []error
+	for _, sub := range b.matching(event.Type) {
+		if err := deliver(ctx, b.opts, sub, event); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
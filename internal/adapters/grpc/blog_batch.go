@@ -0,0 +1,78 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/toffysoft/go-hexagonal-example/internal/adapters/grpc/proto"
+	"github.com/toffysoft/go-hexagonal-example/internal/core/domain"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (s *BlogServer) BatchCreateBlogs(ctx context.Context, req *proto.BatchCreateBlogsRequest) (*proto.BatchResponse, error) {
+	blogs := make([]*domain.Blog, len(req.Requests))
+	for i, item := range req.Requests {
+		blogs[i] = &domain.Blog{
+			Title:         item.Title,
+			Content:       item.Content,
+			ContentFormat: domain.ContentFormat(item.ContentFormat),
+			Author:        item.Author,
+			Tags:          item.Tags,
+		}
+	}
+
+	results, err := s.blogService.BatchCreateBlogs(ctx, blogs, batchMode(req.Mode))
+	return toBatchResponse(results, err)
+}
+
+func (s *BlogServer) BatchDeleteBlogs(ctx context.Context, req *proto.BatchDeleteBlogsRequest) (*proto.BatchResponse, error) {
+	ids := make([]uint, len(req.Ids))
+	for i, id := range req.Ids {
+		ids[i] = uint(id)
+	}
+
+	results, err := s.blogService.BatchDeleteBlogs(ctx, ids, batchMode(req.Mode))
+	return toBatchResponse(results, err)
+}
+
+func batchMode(mode proto.BatchMode) domain.BatchMode {
+	if mode == proto.BatchMode_BEST_EFFORT {
+		return domain.BatchBestEffort
+	}
+	return domain.BatchAtomic
+}
+
+// toBatchResponse turns the outcome of a batch into a response. A rejected
+// atomic batch fails the call, with the first failing item in the message.
+func toBatchResponse(results []domain.BatchResult, err error) (*proto.BatchResponse, error) {
+	if err != nil {
+		message := err.Error()
+		for _, result := range results {
+			if result.Err != nil {
+				message = fmt.Sprintf("%s; item %d: %v", message, result.Index, result.Err)
+				break
+			}
+		}
+		return nil, status.Error(errorCode(err), message)
+	}
+
+	response := &proto.BatchResponse{Results: make([]*proto.BatchResult, len(results))}
+	for i, result := range results {
+		item := &proto.BatchResult{Index: uint32(result.Index)}
+		switch {
+		case result.Err != nil:
+			item.Code = uint32(errorCode(result.Err))
+			item.Message = result.Err.Error()
+		case result.Skipped():
+			item.Code = uint32(codes.Aborted)
+			item.Message = "not applied because another item failed"
+		default:
+			item.Code = uint32(codes.OK)
+			item.Blog = toProtoBlog(result.Blog)
+		}
+		response.Results[i] = item
+	}
+	return response, nil
+}
@@ -0,0 +1,82 @@
+package grpc_test
+
+import (
+	"context"
+	"testing"
+
+	bloggrpc "github.com/toffysoft/go-hexagonal-example/internal/adapters/grpc"
+	"github.com/toffysoft/go-hexagonal-example/internal/adapters/grpc/proto"
+	"github.com/toffysoft/go-hexagonal-example/internal/core/domain"
+	"github.com/toffysoft/go-hexagonal-example/pkg/errors"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestBatchCreateBlogs(t *testing.T) {
+	ctx := context.Background()
+	req := &proto.BatchCreateBlogsRequest{
+		Requests: []*proto.CreateBlogRequest{
+			{Title: "One", Content: "Content", Author: "alice"},
+			{Title: "Two", Author: "bob"},
+		},
+		Mode: proto.BatchMode_BEST_EFFORT,
+	}
+
+	t.Run("BestEffort", func(t *testing.T) {
+		mockService := new(MockBlogService)
+		server := bloggrpc.NewBlogServer(mockService)
+		results := []domain.BatchResult{
+			{Index: 0, Blog: &domain.Blog{ID: 1, Title: "One", Author: "alice"}},
+			{Index: 1, Err: errors.NewInvalidInputError("All fields are required")},
+		}
+		mockService.On("BatchCreateBlogs", ctx, mock.Anything, domain.BatchBestEffort).Return(results, nil).Once()
+
+		resp, err := server.BatchCreateBlogs(ctx, req)
+
+		require.NoError(t, err)
+		require.Len(t, resp.Results, 2)
+		assert.Equal(t, uint32(codes.OK), resp.Results[0].Code)
+		assert.Equal(t, uint64(1), resp.Results[0].Blog.Id)
+		assert.Equal(t, uint32(codes.InvalidArgument), resp.Results[1].Code)
+		assert.Equal(t, uint32(1), resp.Results[1].Index)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("AtomicRejected", func(t *testing.T) {
+		mockService := new(MockBlogService)
+		server := bloggrpc.NewBlogServer(mockService)
+		results := []domain.BatchResult{
+			{Index: 0},
+			{Index: 1, Err: errors.NewInvalidInputError("All fields are required")},
+		}
+		mockService.On("BatchCreateBlogs", ctx, mock.Anything, domain.BatchAtomic).
+			Return(results, errors.NewInvalidInputError("Batch rejected: 1 of 2 items failed")).Once()
+
+		atomic := &proto.BatchCreateBlogsRequest{Requests: req.Requests}
+		_, err := server.BatchCreateBlogs(ctx, atomic)
+
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+		assert.Contains(t, status.Convert(err).Message(), "item 1")
+	})
+}
+
+func TestBatchDeleteBlogs(t *testing.T) {
+	ctx := context.Background()
+	mockService := new(MockBlogService)
+	server := bloggrpc.NewBlogServer(mockService)
+	results := []domain.BatchResult{
+		{Index: 0, Blog: &domain.Blog{ID: 1}},
+		{Index: 1, Err: errors.NewNotFoundError("Blog with ID 2 not found")},
+	}
+	mockService.On("BatchDeleteBlogs", ctx, []uint{1, 2}, domain.BatchBestEffort).Return(results, nil).Once()
+
+	resp, err := server.BatchDeleteBlogs(ctx, &proto.BatchDeleteBlogsRequest{Ids: []uint64{1, 2}, Mode: proto.BatchMode_BEST_EFFORT})
+
+	require.NoError(t, err)
+	assert.Equal(t, uint32(codes.NotFound), resp.Results[1].Code)
+	mockService.AssertExpectations(t)
+}
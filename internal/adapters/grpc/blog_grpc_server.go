@@ -6,102 +6,167 @@ import (
 	"github.com/toffysoft/go-hexagonal-example/internal/adapters/grpc/proto"
 	"github.com/toffysoft/go-hexagonal-example/internal/core/domain"
 	"github.com/toffysoft/go-hexagonal-example/internal/core/ports"
+	"github.com/toffysoft/go-hexagonal-example/pkg/errors"
 
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
 )
 
 type BlogServer struct {
 	proto.UnimplementedBlogServiceServer
-	blogService ports.BlogService
+	blogService    ports.BlogService
+	changeFeed     ports.ChangeFeed
+	maxMessageSize int
 }
 
-func NewBlogServer(blogService ports.BlogService) *BlogServer {
-	return &BlogServer{blogService: blogService}
+// ServerOption configures optional collaborators of the BlogServer.
+type ServerOption func(*BlogServer)
+
+// WithChangeFeed enables WatchBlogs on the given feed.
+func WithChangeFeed(feed ports.ChangeFeed) ServerOption {
+	return func(s *BlogServer) {
+		s.changeFeed = feed
+	}
+}
+
+// WithMaxMessageSize caps the encoded size of each StreamBlogs chunk. It
+// should not exceed the send limit of the server or the receive limit of its
+// clients, which is 4 MiB by default.
+func WithMaxMessageSize(size int) ServerOption {
+	return func(s *BlogServer) {
+		s.maxMessageSize = size
+	}
+}
+
+func NewBlogServer(blogService ports.BlogService, opts ...ServerOption) *BlogServer {
+	s := &BlogServer{blogService: blogService, maxMessageSize: defaultMaxMessageSize}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
 func (s *BlogServer) CreateBlog(ctx context.Context, req *proto.CreateBlogRequest) (*proto.BlogResponse, error) {
 	blog := &domain.Blog{
-		Title:   req.Title,
-		Content: req.Content,
-		Author:  req.Author,
+		Title:         req.Title,
+		Content:       req.Content,
+		ContentFormat: domain.ContentFormat(req.ContentFormat),
+		Author:        req.Author,
+		Tags:          req.Tags,
 	}
 
-	err := s.blogService.CreateBlog(blog)
+	err := s.blogService.CreateBlog(ctx, blog)
 	if err != nil {
-		return nil, status.Errorf(codes.Internal, "Failed to create blog: %v", err)
+		return nil, status.Errorf(errorCode(err), "Failed to create blog: %v", err)
 	}
 
-	return &proto.BlogResponse{
-		Blog: &proto.Blog{
-			Id:      uint64(blog.ID),
-			Title:   blog.Title,
-			Content: blog.Content,
-			Author:  blog.Author,
-		},
-	}, nil
+	return &proto.BlogResponse{Blog: toProtoBlog(blog)}, nil
 }
 
-// Implement other methods (GetBlog, UpdateBlog, DeleteBlog, ListBlogs) similarly
-
+// ListBlogs returns every blog, without content in the SUMMARY view.
 func (s *BlogServer) ListBlogs(ctx context.Context, req *proto.ListBlogsRequest) (*proto.ListBlogsResponse, error) {
-	blogs, err := s.blogService.ListBlogs()
+	mask, err := readMask(req.GetReadMask(), req.RenderHtml)
+	if err != nil {
+		return nil, err
+	}
+	if req.GetReadMask() == nil && req.View != proto.BlogView_FULL {
+		mask = readMaskOf(domain.ListingFields, req.RenderHtml)
+	}
+	blogs, err := s.blogService.ListBlogFields(ctx, mask)
 	if err != nil {
-		return nil, status.Errorf(codes.Internal, "Failed to list blogs: %v", err)
+		return nil, status.Errorf(errorCode(err), "Failed to list blogs: %v", err)
 	}
 
 	var blogResponses []*proto.Blog
 	for _, blog := range blogs {
-		blogResponses = append(blogResponses, &proto.Blog{
-			Id:      uint64(blog.ID),
-			Title:   blog.Title,
-			Content: blog.Content,
-		})
+		blogResponses = append(blogResponses, toProtoBlog(blog))
 	}
 
 	return &proto.ListBlogsResponse{Blogs: blogResponses}, nil
 }
 
-// Implement other methods (GetBlog, UpdateBlog, DeleteBlog) similarly
-
 func (s *BlogServer) GetBlog(ctx context.Context, req *proto.GetBlogRequest) (*proto.BlogResponse, error) {
-	blog, err := s.blogService.GetBlog(uint(req.Id))
+	mask, err := readMask(req.GetReadMask(), req.RenderHtml)
+	if err != nil {
+		return nil, err
+	}
+	blog, err := s.blogService.GetBlogFields(ctx, uint(req.Id), mask)
 	if err != nil {
-		return nil, status.Errorf(codes.NotFound, "Blog not found: %v", err)
+		return nil, status.Errorf(errorCode(err), "Blog not found: %v", err)
 	}
 
-	return &proto.BlogResponse{
-		Blog: &proto.Blog{
-			Id:      uint64(blog.ID),
-			Title:   blog.Title,
-			Content: blog.Content,
-		},
-	}, nil
+	return &proto.BlogResponse{Blog: toProtoBlog(blog)}, nil
+}
+
+// readMask returns the fields selected by a read_mask, with rendered_html
+// when render is set.
+func readMask(m *fieldmaskpb.FieldMask, render bool) (domain.FieldMask, error) {
+	var mask domain.FieldMask
+	if paths := m.GetPaths(); len(paths) > 0 {
+		var err error
+		if mask, err = domain.ParseFieldMask(paths); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "read_mask: %v", err)
+		}
+	}
+	return readMaskOf(mask, render), nil
 }
 
+func readMaskOf(mask domain.FieldMask, render bool) domain.FieldMask {
+	if render {
+		return mask.With(domain.FieldRenderedHTML)
+	}
+	return mask
+}
+
+// UpdateBlog writes the fields listed in update_mask, or the non-empty
+// fields when there is no mask.
 func (s *BlogServer) UpdateBlog(ctx context.Context, req *proto.UpdateBlogRequest) (*proto.BlogResponse, error) {
-	blog := &domain.Blog{
-		ID:      uint(req.Id),
-		Title:   req.Title,
-		Content: req.Content,
+	values := map[string]string{
+		"title":          req.Title,
+		"content":        req.Content,
+		"content_format": req.ContentFormat,
+		"author":         req.Author,
 	}
 
-	err := s.blogService.UpdateBlog(blog)
+	var patch domain.FieldPatch
+	switch paths := req.GetUpdateMask().GetPaths(); {
+	case len(paths) == 0:
+		for field, value := range values {
+			if value != "" {
+				patch.Set(field, value)
+			}
+		}
+		if len(req.Tags) > 0 {
+			patch.SetTags(req.Tags)
+		}
+	case len(paths) == 1 && paths[0] == "*":
+		for field, value := range values {
+			patch.Set(field, value)
+		}
+		patch.SetTags(req.Tags)
+	default:
+		for _, path := range paths {
+			if path == "tags" {
+				patch.SetTags(req.Tags)
+				continue
+			}
+			if !patch.Set(path, values[path]) {
+				return nil, status.Errorf(codes.InvalidArgument, "update_mask: %q cannot be updated", path)
+			}
+		}
+	}
+
+	blog, err := s.blogService.PatchBlog(ctx, uint(req.Id), patch)
 	if err != nil {
-		return nil, status.Errorf(codes.Internal, "Failed to update blog: %v", err)
+		return nil, status.Errorf(errorCode(err), "Failed to update blog: %v", err)
 	}
 
-	return &proto.BlogResponse{
-		Blog: &proto.Blog{
-			Id:      uint64(blog.ID),
-			Title:   blog.Title,
-			Content: blog.Content,
-		},
-	}, nil
+	return &proto.BlogResponse{Blog: toProtoBlog(blog)}, nil
 }
 
 func (s *BlogServer) DeleteBlog(ctx context.Context, req *proto.DeleteBlogRequest) (*proto.DeleteBlogResponse, error) {
-	err := s.blogService.DeleteBlog(uint(req.Id))
+	err := s.blogService.DeleteBlog(ctx, uint(req.Id))
 	if err != nil {
 		return &proto.DeleteBlogResponse{
 			Success: false,
@@ -112,3 +177,31 @@ func (s *BlogServer) DeleteBlog(ctx context.Context, req *proto.DeleteBlogReques
 		Success: true,
 	}, nil
 }
+
+// errorCode maps application errors to gRPC status codes.
+func errorCode(err error) codes.Code {
+	appErr, ok := err.(errors.AppError)
+	if !ok {
+		return codes.Internal
+	}
+	switch appErr.Type {
+	case errors.NotFound:
+		return codes.NotFound
+	case errors.InvalidInput:
+		return codes.InvalidArgument
+	case errors.Unauthorized:
+		return codes.Unauthenticated
+	case errors.Forbidden:
+		return codes.PermissionDenied
+	case errors.Conflict:
+		return codes.Aborted
+	case errors.Unprocessable:
+		return codes.FailedPrecondition
+	case errors.TooLarge:
+		return codes.ResourceExhausted
+	case errors.Unsupported:
+		return codes.InvalidArgument
+	default:
+		return codes.Internal
+	}
+}
@@ -10,37 +10,95 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
 )
 
 type MockBlogService struct {
 	mock.Mock
 }
 
-func (m *MockBlogService) CreateBlog(blog *domain.Blog) error {
-	args := m.Called(blog)
+func (m *MockBlogService) CreateBlog(ctx context.Context, blog *domain.Blog) error {
+	args := m.Called(ctx, blog)
 	return args.Error(0)
 }
 
-func (m *MockBlogService) GetBlog(id uint) (*domain.Blog, error) {
-	args := m.Called(id)
+func (m *MockBlogService) GetBlog(ctx context.Context, id uint) (*domain.Blog, error) {
+	args := m.Called(ctx, id)
 	return args.Get(0).(*domain.Blog), args.Error(1)
 }
 
-func (m *MockBlogService) UpdateBlog(blog *domain.Blog) error {
-	args := m.Called(blog)
+func (m *MockBlogService) UpdateBlog(ctx context.Context, blog *domain.Blog) error {
+	args := m.Called(ctx, blog)
 	return args.Error(0)
 }
 
-func (m *MockBlogService) DeleteBlog(id uint) error {
-	args := m.Called(id)
+func (m *MockBlogService) DeleteBlog(ctx context.Context, id uint) error {
+	args := m.Called(ctx, id)
 	return args.Error(0)
 }
 
-func (m *MockBlogService) ListBlogs() ([]*domain.Blog, error) {
-	args := m.Called()
+func (m *MockBlogService) ListBlogs(ctx context.Context) ([]*domain.Blog, error) {
+	args := m.Called(ctx)
 	return args.Get(0).([]*domain.Blog), args.Error(1)
 }
 
+func (m *MockBlogService) RenderBlogs(ctx context.Context, blogs ...*domain.Blog) error {
+	args := m.Called(ctx, blogs)
+	return args.Error(0)
+}
+
+func (m *MockBlogService) GetBlogFields(ctx context.Context, id uint, mask domain.FieldMask) (*domain.Blog, error) {
+	args := m.Called(ctx, id, mask)
+	return args.Get(0).(*domain.Blog), args.Error(1)
+}
+
+func (m *MockBlogService) ListBlogFields(ctx context.Context, mask domain.FieldMask) ([]*domain.Blog, error) {
+	args := m.Called(ctx, mask)
+	return args.Get(0).([]*domain.Blog), args.Error(1)
+}
+
+func (m *MockBlogService) ListLatestBlogs(ctx context.Context, filter domain.BlogFilter, mask domain.FieldMask, limit int) ([]*domain.Blog, error) {
+	args := m.Called(ctx, filter, mask, limit)
+	return args.Get(0).([]*domain.Blog), args.Error(1)
+}
+
+func (m *MockBlogService) StreamBlogs(ctx context.Context, afterID uint, chunkSize int, fn func(blogs []*domain.Blog) error) error {
+	args := m.Called(ctx, afterID, chunkSize)
+	for _, chunk := range args.Get(0).([][]*domain.Blog) {
+		if err := fn(chunk); err != nil {
+			return err
+		}
+	}
+	return args.Error(1)
+}
+
+func (m *MockBlogService) PatchBlog(ctx context.Context, id uint, patch domain.BlogPatch) (*domain.Blog, error) {
+	args := m.Called(ctx, id, patch)
+	blog, _ := args.Get(0).(*domain.Blog)
+	return blog, args.Error(1)
+}
+
+func (m *MockBlogService) BatchCreateBlogs(ctx context.Context, blogs []*domain.Blog, mode domain.BatchMode) ([]domain.BatchResult, error) {
+	args := m.Called(ctx, blogs, mode)
+	results, _ := args.Get(0).([]domain.BatchResult)
+	return results, args.Error(1)
+}
+
+func (m *MockBlogService) BatchUpdateBlogs(ctx context.Context, blogs []*domain.Blog, mode domain.BatchMode) ([]domain.BatchResult, error) {
+	args := m.Called(ctx, blogs, mode)
+	results, _ := args.Get(0).([]domain.BatchResult)
+	return results, args.Error(1)
+}
+
+func (m *MockBlogService) BatchDeleteBlogs(ctx context.Context, ids []uint, mode domain.BatchMode) ([]domain.BatchResult, error) {
+	args := m.Called(ctx, ids, mode)
+	results, _ := args.Get(0).([]domain.BatchResult)
+	return results, args.Error(1)
+}
+
 // Implement other methods...
 
 func TestCreateBlog(t *testing.T) {
@@ -51,9 +109,12 @@ func TestCreateBlog(t *testing.T) {
 		Title:   "Test Blog",
 		Content: "Test Content",
 		Author:  "Test Author",
+		Tags:    []string{"go", "web-dev"},
 	}
 
-	mockService.On("CreateBlog", mock.AnythingOfType("*domain.Blog")).Return(nil)
+	mockService.On("CreateBlog", mock.Anything, mock.MatchedBy(func(blog *domain.Blog) bool {
+		return assert.ObjectsAreEqual(req.Tags, blog.Tags)
+	})).Return(nil)
 
 	resp, err := server.CreateBlog(context.Background(), req)
 
@@ -62,6 +123,7 @@ func TestCreateBlog(t *testing.T) {
 	assert.Equal(t, req.Title, resp.Blog.Title)
 	assert.Equal(t, req.Content, resp.Blog.Content)
 	assert.Equal(t, req.Author, resp.Blog.Author)
+	assert.Equal(t, req.Tags, resp.Blog.Tags)
 
 	mockService.AssertExpectations(t)
 }
@@ -77,9 +139,9 @@ func TestListBlogs(t *testing.T) {
 		{ID: 2, Title: "Test Blog 2", Content: "Test Content 2", Author: "Test Author 2"},
 	}
 
-	mockService.On("ListBlogs").Return(blogs, nil)
+	mockService.On("ListBlogFields", mock.Anything, domain.FieldMask(nil)).Return(blogs, nil)
 
-	resp, err := server.ListBlogs(context.Background(), &proto.ListBlogsRequest{})
+	resp, err := server.ListBlogs(context.Background(), &proto.ListBlogsRequest{View: proto.BlogView_FULL})
 
 	assert.NoError(t, err)
 	assert.NotNil(t, resp)
@@ -89,11 +151,61 @@ func TestListBlogs(t *testing.T) {
 		assert.Equal(t, blog.ID, uint(resp.Blogs[i].Id))
 		assert.Equal(t, blog.Title, resp.Blogs[i].Title)
 		assert.Equal(t, blog.Content, resp.Blogs[i].Content)
+		assert.Equal(t, blog.Author, resp.Blogs[i].Author)
 	}
 
 	mockService.AssertExpectations(t)
 }
 
+func TestListBlogsSummaryView(t *testing.T) {
+	mockService := new(MockBlogService)
+	server := grpc.NewBlogServer(mockService)
+	mockService.On("ListBlogFields", mock.Anything, domain.ListingFields).Return([]*domain.Blog{{
+		ID: 1, Title: "Title", Excerpt: "Content", WordCount: 1, ReadingMinutes: 1,
+		TOC: []domain.Heading{{Level: 2, Text: "Start", ID: "start"}},
+	}}, nil)
+
+	for _, view := range []proto.BlogView{proto.BlogView_BLOG_VIEW_UNSPECIFIED, proto.BlogView_SUMMARY} {
+		resp, err := server.ListBlogs(context.Background(), &proto.ListBlogsRequest{View: view})
+
+		assert.NoError(t, err)
+		require.Len(t, resp.Blogs, 1)
+		assert.Empty(t, resp.Blogs[0].Content)
+		assert.Equal(t, "Content", resp.Blogs[0].Excerpt)
+		assert.Equal(t, int32(1), resp.Blogs[0].WordCount)
+		assert.Equal(t, "start", resp.Blogs[0].Toc[0].Id)
+	}
+	mockService.AssertExpectations(t)
+}
+
+func TestReadMask(t *testing.T) {
+	mockService := new(MockBlogService)
+	server := grpc.NewBlogServer(mockService)
+	mask := domain.FieldMask{domain.FieldTitle, domain.FieldRenderedHTML}
+	mockService.On("GetBlogFields", mock.Anything, uint(1), mask).Return(&domain.Blog{ID: 1, Title: "Title", RenderedHTML: "<p>Content</p>"}, nil)
+	mockService.On("ListBlogFields", mock.Anything, domain.FieldMask{domain.FieldID, domain.FieldAuthor}).Return([]*domain.Blog{{ID: 1, Author: "alice"}}, nil)
+
+	resp, err := server.GetBlog(context.Background(), &proto.GetBlogRequest{
+		Id: 1, RenderHtml: true, ReadMask: &fieldmaskpb.FieldMask{Paths: []string{"title"}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "Title", resp.Blog.Title)
+	assert.Equal(t, "<p>Content</p>", resp.Blog.RenderedHtml)
+
+	// read_mask takes the place of the view.
+	list, err := server.ListBlogs(context.Background(), &proto.ListBlogsRequest{
+		View: proto.BlogView_SUMMARY, ReadMask: &fieldmaskpb.FieldMask{Paths: []string{"author", "id"}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "alice", list.Blogs[0].Author)
+
+	_, err = server.GetBlog(context.Background(), &proto.GetBlogRequest{
+		Id: 1, ReadMask: &fieldmaskpb.FieldMask{Paths: []string{"title", "password"}},
+	})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	mockService.AssertExpectations(t)
+}
+
 // Implement other test cases...
 
 func TestGetBlog(t *testing.T) {
@@ -106,7 +218,7 @@ func TestGetBlog(t *testing.T) {
 		Content: "Test Content",
 	}
 
-	mockService.On("GetBlog", uint(1)).Return(blog, nil)
+	mockService.On("GetBlogFields", mock.Anything, uint(1), domain.FieldMask(nil)).Return(blog, nil)
 
 	resp, err := server.GetBlog(context.Background(), &proto.GetBlogRequest{Id: 1})
 
@@ -122,25 +234,69 @@ func TestGetBlog(t *testing.T) {
 // Implement other test cases...
 
 func TestUpdateBlog(t *testing.T) {
-	mockService := new(MockBlogService)
-	server := grpc.NewBlogServer(mockService)
-
-	req := &proto.UpdateBlogRequest{
-		Id:      1,
-		Title:   "Test Blog",
-		Content: "Test Content",
-		Author:  "Test Author",
+	// update runs req against a stored blog and returns what was saved.
+	update := func(t *testing.T, req *proto.UpdateBlogRequest) (*domain.Blog, error) {
+		mockService := new(MockBlogService)
+		server := grpc.NewBlogServer(mockService)
+		stored := &domain.Blog{ID: 1, Title: "Old Title", Content: "Old Content", Author: "Old Author", Tags: []string{"old"}}
+
+		mockService.On("PatchBlog", mock.Anything, uint(1), mock.Anything).Return(stored, nil).Run(func(args mock.Arguments) {
+			require.NoError(t, args.Get(2).(domain.BlogPatch).Apply(stored))
+		}).Maybe()
+
+		resp, err := server.UpdateBlog(context.Background(), req)
+		if err != nil {
+			return nil, err
+		}
+		assert.Equal(t, req.Id, uint64(resp.Blog.Id))
+		assert.Equal(t, stored.Author, resp.Blog.Author)
+		return stored, nil
 	}
 
-	mockService.On("UpdateBlog", mock.AnythingOfType("*domain.Blog")).Return(nil)
-
-	resp, err := server.UpdateBlog(context.Background(), req)
-
-	assert.NoError(t, err)
-	assert.NotNil(t, resp)
-	assert.Equal(t, req.Id, uint64(resp.Blog.Id))
-
-	mockService.AssertExpectations(t)
+	t.Run("WithoutMask", func(t *testing.T) {
+		blog, err := update(t, &proto.UpdateBlogRequest{Id: 1, Title: "Test Blog", Author: "Test Author"})
+
+		require.NoError(t, err)
+		assert.Equal(t, "Test Blog", blog.Title)
+		assert.Equal(t, "Old Content", blog.Content)
+		assert.Equal(t, "Test Author", blog.Author)
+		assert.Equal(t, []string{"old"}, blog.Tags)
+	})
+
+	t.Run("Tags", func(t *testing.T) {
+		blog, err := update(t, &proto.UpdateBlogRequest{Id: 1, Tags: []string{"go"}})
+
+		require.NoError(t, err)
+		assert.Equal(t, "Old Title", blog.Title)
+		assert.Equal(t, []string{"go"}, blog.Tags)
+	})
+
+	t.Run("MaskClearsTags", func(t *testing.T) {
+		blog, err := update(t, &proto.UpdateBlogRequest{Id: 1, UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"tags"}}})
+
+		require.NoError(t, err)
+		assert.Equal(t, "Old Title", blog.Title)
+		assert.Empty(t, blog.Tags)
+		assert.NotNil(t, blog.Tags)
+	})
+
+	t.Run("MaskClearsListedFields", func(t *testing.T) {
+		blog, err := update(t, &proto.UpdateBlogRequest{
+			Id:         1,
+			Title:      "Ignored",
+			UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"content"}},
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, "Old Title", blog.Title)
+		assert.Equal(t, "", blog.Content)
+	})
+
+	t.Run("UnknownPath", func(t *testing.T) {
+		_, err := update(t, &proto.UpdateBlogRequest{Id: 1, UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"id"}}})
+
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
 }
 
 // Implement other test cases...
@@ -149,7 +305,7 @@ func TestDeleteBlog(t *testing.T) {
 	mockService := new(MockBlogService)
 	server := grpc.NewBlogServer(mockService)
 
-	mockService.On("DeleteBlog", uint(1)).Return(nil)
+	mockService.On("DeleteBlog", mock.Anything, uint(1)).Return(nil)
 
 	resp, err := server.DeleteBlog(context.Background(), &proto.DeleteBlogRequest{Id: 1})
 
@@ -0,0 +1,158 @@
+package grpc
+
+import (
+	"github.com/toffysoft/go-hexagonal-example/internal/adapters/grpc/proto"
+	"github.com/toffysoft/go-hexagonal-example/internal/core/domain"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protowire"
+	protobuf "google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	defaultChunkSize = 100
+	maxChunkSize     = 1000
+	// defaultMaxMessageSize is the largest message gRPC clients receive
+	// unless configured otherwise.
+	defaultMaxMessageSize = 4 << 20
+)
+
+var eventTypes = map[domain.EventType]proto.BlogEvent_Type{
+	domain.BlogCreated: proto.BlogEvent_CREATED,
+	domain.BlogUpdated: proto.BlogEvent_UPDATED,
+	domain.BlogDeleted: proto.BlogEvent_DELETED,
+}
+
+func (s *BlogServer) StreamBlogs(req *proto.StreamBlogsRequest, stream proto.BlogService_StreamBlogsServer) error {
+	chunkSize := int(req.ChunkSize)
+	if chunkSize == 0 {
+		chunkSize = defaultChunkSize
+	}
+	if chunkSize > maxChunkSize {
+		return status.Errorf(codes.InvalidArgument, "chunk_size must not exceed %d", maxChunkSize)
+	}
+
+	err := s.blogService.StreamBlogs(stream.Context(), uint(req.AfterId), chunkSize, func(blogs []*domain.Blog) error {
+		// Blogs with long content are split over several chunks, so that no
+		// message exceeds the maximum size.
+		chunk, size := &proto.BlogChunk{}, 0
+		for _, blog := range blogs {
+			message := toProtoBlog(blog)
+			n := protowire.SizeTag(1) + protowire.SizeBytes(protobuf.Size(message))
+			if n > s.maxMessageSize {
+				return status.Errorf(codes.ResourceExhausted, "blog %d is larger than the maximum message size of %d bytes", blog.ID, s.maxMessageSize)
+			}
+			if size+n > s.maxMessageSize {
+				if err := stream.Send(chunk); err != nil {
+					return err
+				}
+				chunk, size = &proto.BlogChunk{}, 0
+			}
+			chunk.Blogs = append(chunk.Blogs, message)
+			size += n
+		}
+		return stream.Send(chunk)
+	})
+	if err != nil {
+		if _, ok := status.FromError(err); ok {
+			return err
+		}
+		if ctxErr := stream.Context().Err(); ctxErr != nil {
+			return status.FromContextError(ctxErr).Err()
+		}
+		return status.Errorf(codes.Internal, "Failed to stream blogs: %v", err)
+	}
+	return nil
+}
+
+// WatchBlogs sends the changes after req.ResumeToken, then every new change
+// until the client cancels. Each event's resume token can be used to resume
+// right after it.
+func (s *BlogServer) WatchBlogs(req *proto.WatchBlogsRequest, stream proto.BlogService_WatchBlogsServer) error {
+	if s.changeFeed == nil {
+		return status.Error(codes.Unimplemented, "WatchBlogs is not enabled")
+	}
+
+	ctx := stream.Context()
+	replay, live, resumed := s.changeFeed.Subscribe(ctx, req.ResumeToken)
+	if req.ResumeToken != "" && !resumed {
+		return status.Error(codes.FailedPrecondition, "resume token expired; reload the blogs and watch again without a token")
+	}
+	// Headers tell the client the watch is established and no change will
+	// be missed from here on.
+	if err := stream.SendHeader(metadata.MD{}); err != nil {
+		return err
+	}
+
+	send := func(change domain.BlogChange) error {
+		if req.Author != "" && (change.Blog == nil || change.Blog.Author != req.Author) {
+			return nil
+		}
+		return stream.Send(toProtoEvent(change))
+	}
+	for _, change := range replay {
+		if err := send(change); err != nil {
+			return err
+		}
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return status.FromContextError(ctx.Err()).Err()
+		case change, ok := <-live:
+			if !ok {
+				if ctx.Err() != nil {
+					return status.FromContextError(ctx.Err()).Err()
+				}
+				return status.Error(codes.Unavailable, "watcher fell behind; resume with the last resume token")
+			}
+			if err := send(change); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func toProtoBlog(blog *domain.Blog) *proto.Blog {
+	response := &proto.Blog{
+		Id:             uint64(blog.ID),
+		Title:          blog.Title,
+		Content:        blog.Content,
+		Author:         blog.Author,
+		ContentFormat:  string(blog.ContentFormat),
+		RenderedHtml:   blog.RenderedHTML,
+		Excerpt:        blog.Excerpt,
+		WordCount:      int32(blog.WordCount),
+		ReadingMinutes: int32(blog.ReadingMinutes),
+		Toc:            toProtoHeadings(blog.TOC),
+		Tags:           blog.Tags,
+	}
+	if blog.CoverImageID != nil {
+		response.CoverImageId = uint64(*blog.CoverImageID)
+	}
+	return response
+}
+
+func toProtoHeadings(headings []domain.Heading) []*proto.Heading {
+	var toc []*proto.Heading
+	for _, heading := range headings {
+		toc = append(toc, &proto.Heading{Level: int32(heading.Level), Text: heading.Text, Id: heading.ID})
+	}
+	return toc
+}
+
+func toProtoEvent(change domain.BlogChange) *proto.BlogEvent {
+	event := &proto.BlogEvent{
+		EventId:     change.Event.ID,
+		Type:        eventTypes[change.Event.Type],
+		OccurredAt:  timestamppb.New(change.Event.OccurredAt),
+		ResumeToken: change.Cursor,
+	}
+	if change.Blog != nil {
+		event.Blog = toProtoBlog(change.Blog)
+	}
+	return event
+}
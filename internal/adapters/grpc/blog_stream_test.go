@@ -0,0 +1,177 @@
+package grpc_test
+
+import (
+	"context"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/toffysoft/go-hexagonal-example/internal/adapters/changefeed"
+	bloggrpc "github.com/toffysoft/go-hexagonal-example/internal/adapters/grpc"
+	"github.com/toffysoft/go-hexagonal-example/internal/adapters/grpc/proto"
+	"github.com/toffysoft/go-hexagonal-example/internal/adapters/repositories"
+	"github.com/toffysoft/go-hexagonal-example/internal/core/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	protobuf "google.golang.org/protobuf/proto"
+)
+
+func dial(t *testing.T, server *bloggrpc.BlogServer) proto.BlogServiceClient {
+	listener := bufconn.Listen(1024 * 1024)
+	grpcServer := grpc.NewServer()
+	proto.RegisterBlogServiceServer(grpcServer, server)
+	go grpcServer.Serve(listener)
+	t.Cleanup(grpcServer.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return proto.NewBlogServiceClient(conn)
+}
+
+func TestStreamBlogs(t *testing.T) {
+	mockService := new(MockBlogService)
+	client := dial(t, bloggrpc.NewBlogServer(mockService))
+
+	chunks := [][]*domain.Blog{
+		{{ID: 1, Title: "One", Author: "alice"}, {ID: 2, Title: "Two", Author: "bob"}},
+		{{ID: 3, Title: "Three", Author: "carol"}},
+	}
+	mockService.On("StreamBlogs", mock.Anything, uint(0), 2).Return(chunks, nil).Once()
+
+	stream, err := client.StreamBlogs(context.Background(), &proto.StreamBlogsRequest{ChunkSize: 2})
+	require.NoError(t, err)
+
+	var received []*proto.BlogChunk
+	for {
+		chunk, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		received = append(received, chunk)
+	}
+
+	require.Len(t, received, 2)
+	assert.Len(t, received[0].Blogs, 2)
+	assert.Equal(t, "bob", received[0].Blogs[1].Author)
+	assert.Equal(t, uint64(3), received[1].Blogs[0].Id)
+	mockService.AssertExpectations(t)
+
+	stream, err = client.StreamBlogs(context.Background(), &proto.StreamBlogsRequest{ChunkSize: 5000})
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestStreamBlogsLargeContent(t *testing.T) {
+	t.Run("SplitsChunks", func(t *testing.T) {
+		mockService := new(MockBlogService)
+		client := dial(t, bloggrpc.NewBlogServer(mockService))
+
+		// Ten blogs of 1 MiB exceed the 4 MiB a client accepts by default.
+		blogs := make([]*domain.Blog, 10)
+		for i := range blogs {
+			blogs[i] = &domain.Blog{ID: uint(i + 1), Content: strings.Repeat("x", 1<<20)}
+		}
+		mockService.On("StreamBlogs", mock.Anything, uint(0), 10).Return([][]*domain.Blog{blogs}, nil).Once()
+
+		stream, err := client.StreamBlogs(context.Background(), &proto.StreamBlogsRequest{ChunkSize: 10})
+		require.NoError(t, err)
+
+		var ids []uint64
+		chunks := 0
+		for {
+			chunk, err := stream.Recv()
+			if err == io.EOF {
+				break
+			}
+			require.NoError(t, err)
+			assert.LessOrEqual(t, protobuf.Size(chunk), 4<<20)
+			for _, blog := range chunk.Blogs {
+				ids = append(ids, blog.Id)
+			}
+			chunks++
+		}
+
+		assert.Equal(t, []uint64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}, ids)
+		assert.Greater(t, chunks, 2)
+	})
+
+	t.Run("BlogTooLarge", func(t *testing.T) {
+		mockService := new(MockBlogService)
+		client := dial(t, bloggrpc.NewBlogServer(mockService, bloggrpc.WithMaxMessageSize(1024)))
+		chunks := [][]*domain.Blog{{{ID: 1, Content: strings.Repeat("x", 2048)}}}
+		mockService.On("StreamBlogs", mock.Anything, uint(0), 100).Return(chunks, nil).Once()
+
+		stream, err := client.StreamBlogs(context.Background(), &proto.StreamBlogsRequest{})
+		require.NoError(t, err)
+		_, err = stream.Recv()
+
+		assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+	})
+}
+
+func TestWatchBlogs(t *testing.T) {
+	outbox := repositories.NewMemoryOutbox()
+	feed := changefeed.New(outbox, changefeed.Config{BufferSize: 10, SubscriberBuffer: 10, PollInterval: time.Second, GapTimeout: time.Second})
+	require.NoError(t, feed.Load(context.Background()))
+	client := dial(t, bloggrpc.NewBlogServer(new(MockBlogService), bloggrpc.WithChangeFeed(feed)))
+	publish := func(event domain.Event) {
+		require.NoError(t, outbox.Add(context.Background(), event))
+		_, err := feed.PollOnce(context.Background())
+		require.NoError(t, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	watch, err := client.WatchBlogs(ctx, &proto.WatchBlogsRequest{Author: "alice"})
+	require.NoError(t, err)
+	// Wait until the watcher is subscribed before publishing.
+	_, err = watch.Header()
+	require.NoError(t, err)
+
+	publish(domain.NewBlogCreatedEvent(&domain.Blog{ID: 1, Author: "bob"}))
+	publish(domain.NewBlogCreatedEvent(&domain.Blog{ID: 2, Author: "alice"}))
+	publish(domain.NewBlogDeletedEvent(&domain.Blog{ID: 2, Author: "alice"}))
+
+	first, err := watch.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, proto.BlogEvent_CREATED, first.Type)
+	assert.Equal(t, uint64(2), first.Blog.Id)
+	second, err := watch.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, proto.BlogEvent_DELETED, second.Type)
+
+	t.Run("Resume", func(t *testing.T) {
+		resumed, err := client.WatchBlogs(ctx, &proto.WatchBlogsRequest{ResumeToken: first.ResumeToken})
+		require.NoError(t, err)
+		event, err := resumed.Recv()
+		require.NoError(t, err)
+		assert.Equal(t, second.EventId, event.EventId)
+	})
+
+	t.Run("ExpiredToken", func(t *testing.T) {
+		expired, err := client.WatchBlogs(ctx, &proto.WatchBlogsRequest{ResumeToken: "earlier-process-1"})
+		require.NoError(t, err)
+		_, err = expired.Recv()
+		assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+	})
+
+	t.Run("Cancel", func(t *testing.T) {
+		cancel()
+		_, err := watch.Recv()
+		assert.Equal(t, codes.Canceled, status.Code(err))
+	})
+}
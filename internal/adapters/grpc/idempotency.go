@@ -0,0 +1,149 @@
+package grpc
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"log/slog"
+	"slices"
+
+	"github.com/toffysoft/go-hexagonal-example/internal/core/domain"
+	"github.com/toffysoft/go-hexagonal-example/internal/core/ports"
+
+	spb "google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+const (
+	// IdempotencyKeyMetadata is the metadata key carrying the client's idempotency key.
+	IdempotencyKeyMetadata = "idempotency-key"
+	// IdempotentReplayedMetadata marks a response replayed from an earlier call.
+	IdempotentReplayedMetadata = "idempotent-replayed"
+)
+
+// IdempotencyUnaryInterceptor makes calls to methods that carry an
+// idempotency-key metadata entry safe to retry, like the HTTP Idempotency
+// middleware: repeats get the stored response or status. Reusing a key for a
+// different request fails with FailedPrecondition, and a repeat arriving
+// while the first call is in progress with Aborted. Calls failing with a
+// transient code are not stored, so they can be retried with the same key.
+func IdempotencyUnaryInterceptor(service ports.IdempotencyService, methods ...string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		msg, ok := req.(proto.Message)
+		key := firstMetadata(ctx, IdempotencyKeyMetadata)
+		if !ok || key == "" || !slices.Contains(methods, info.FullMethod) {
+			return handler(ctx, req)
+		}
+
+		fingerprint, err := callFingerprint(info.FullMethod, msg)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "Failed to read request: %v", err)
+		}
+		record, err := service.Begin(ctx, "grpc:"+callerScope(firstMetadata(ctx, "x-api-key")), key, fingerprint)
+		if err != nil {
+			return nil, status.Error(errorCode(err), err.Error())
+		}
+		if record.Completed {
+			_ = grpc.SetHeader(ctx, metadata.Pairs(IdempotentReplayedMetadata, "true"))
+			return replay(record)
+		}
+
+		// The outcome is recorded even if the client went away meanwhile.
+		storeCtx := context.WithoutCancel(ctx)
+		resp, callErr := handler(ctx, req)
+		response, storable := storedResponse(resp, callErr)
+		if storable {
+			if err := service.Complete(storeCtx, record, response); err != nil {
+				slog.Error("failed to store idempotent response", "error", err)
+			}
+		} else if err := service.Release(storeCtx, record); err != nil {
+			slog.Error("failed to release idempotency key", "error", err)
+		}
+		return resp, callErr
+	}
+}
+
+// storedResponse encodes the outcome of a call for replay: the response as an
+// Any for a successful call, or the status of a failed one. Transient
+// failures are not storable.
+func storedResponse(resp interface{}, callErr error) (domain.IdempotentResponse, bool) {
+	if callErr != nil {
+		st := status.Convert(callErr)
+		switch st.Code() {
+		case codes.Unknown, codes.Internal, codes.Unavailable, codes.DeadlineExceeded,
+			codes.Canceled, codes.ResourceExhausted, codes.Aborted:
+			return domain.IdempotentResponse{}, false
+		}
+		body, err := proto.Marshal(st.Proto())
+		if err != nil {
+			return domain.IdempotentResponse{}, false
+		}
+		return domain.IdempotentResponse{StatusCode: int(st.Code()), Body: body}, true
+	}
+
+	msg, ok := resp.(proto.Message)
+	if !ok {
+		return domain.IdempotentResponse{}, false
+	}
+	packed, err := anypb.New(msg)
+	if err != nil {
+		return domain.IdempotentResponse{}, false
+	}
+	body, err := proto.Marshal(packed)
+	if err != nil {
+		return domain.IdempotentResponse{}, false
+	}
+	return domain.IdempotentResponse{StatusCode: int(codes.OK), Body: body}, true
+}
+
+func replay(record *domain.IdempotencyRecord) (interface{}, error) {
+	if codes.Code(record.StatusCode) != codes.OK {
+		var st spb.Status
+		if err := proto.Unmarshal(record.Body, &st); err != nil {
+			return nil, status.Errorf(codes.Internal, "Failed to replay response: %v", err)
+		}
+		return nil, status.ErrorProto(&st)
+	}
+	var packed anypb.Any
+	if err := proto.Unmarshal(record.Body, &packed); err != nil {
+		return nil, status.Errorf(codes.Internal, "Failed to replay response: %v", err)
+	}
+	resp, err := packed.UnmarshalNew()
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "Failed to replay response: %v", err)
+	}
+	return resp, nil
+}
+
+func callFingerprint(fullMethod string, req proto.Message) (string, error) {
+	body, err := proto.MarshalOptions{Deterministic: true}.Marshal(req)
+	if err != nil {
+		return "", err
+	}
+	h := sha256.New()
+	h.Write([]byte(fullMethod + "\n"))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// callerScope separates the keys of different API keys, without storing them.
+func callerScope(apiKey string) string {
+	if apiKey == "" {
+		return "anonymous"
+	}
+	sum := sha256.Sum256([]byte(apiKey))
+	return hex.EncodeToString(sum[:8])
+}
+
+func firstMetadata(ctx context.Context, key string) string {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
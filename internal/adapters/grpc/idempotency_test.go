@@ -0,0 +1,113 @@
+package grpc_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/toffysoft/go-hexagonal-example/internal/adapters/grpc"
+	"github.com/toffysoft/go-hexagonal-example/internal/adapters/grpc/proto"
+	"github.com/toffysoft/go-hexagonal-example/internal/adapters/repositories"
+	"github.com/toffysoft/go-hexagonal-example/internal/core/services"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	grpclib "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	protolib "google.golang.org/protobuf/proto"
+)
+
+func TestIdempotencyUnaryInterceptor(t *testing.T) {
+	setup := func() grpclib.UnaryServerInterceptor {
+		service := services.NewIdempotencyService(repositories.NewMemoryIdempotencyStore(), services.IdempotencyConfig{
+			TTL:          time.Hour,
+			LockTimeout:  time.Minute,
+			PollInterval: time.Millisecond,
+		})
+		return grpc.IdempotencyUnaryInterceptor(service, proto.BlogService_CreateBlog_FullMethodName)
+	}
+	info := &grpclib.UnaryServerInfo{FullMethod: proto.BlogService_CreateBlog_FullMethodName}
+	withKey := func(key string) context.Context {
+		return metadata.NewIncomingContext(context.Background(), metadata.Pairs(grpc.IdempotencyKeyMetadata, key))
+	}
+	req := &proto.CreateBlogRequest{Title: "Hello", Content: "First post body", Author: "alice"}
+
+	t.Run("ReplaysResponse", func(t *testing.T) {
+		interceptor, calls := setup(), 0
+		handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+			calls++
+			return &proto.BlogResponse{Blog: &proto.Blog{Id: uint64(calls), Title: "Hello"}}, nil
+		}
+
+		first, err := interceptor(withKey("key-1"), req, info, handler)
+		require.NoError(t, err)
+		second, err := interceptor(withKey("key-1"), req, info, handler)
+		require.NoError(t, err)
+
+		assert.Equal(t, 1, calls)
+		assert.True(t, protolib.Equal(first.(protolib.Message), second.(protolib.Message)))
+	})
+
+	t.Run("ReplaysStatus", func(t *testing.T) {
+		interceptor, calls := setup(), 0
+		handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+			calls++
+			return nil, status.Error(codes.InvalidArgument, "title is required")
+		}
+
+		_, err := interceptor(withKey("key-1"), req, info, handler)
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+		_, err = interceptor(withKey("key-1"), req, info, handler)
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+		assert.Equal(t, "title is required", status.Convert(err).Message())
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("RetriesTransientFailure", func(t *testing.T) {
+		interceptor, calls := setup(), 0
+		fail := status.Error(codes.Unavailable, "database is down")
+		handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+			calls++
+			if fail != nil {
+				return nil, fail
+			}
+			return &proto.BlogResponse{}, nil
+		}
+
+		_, err := interceptor(withKey("key-1"), req, info, handler)
+		assert.Equal(t, codes.Unavailable, status.Code(err))
+		fail = nil
+		_, err = interceptor(withKey("key-1"), req, info, handler)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, calls)
+	})
+
+	t.Run("RejectsDifferentRequest", func(t *testing.T) {
+		interceptor := setup()
+		handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+			return &proto.BlogResponse{}, nil
+		}
+
+		_, err := interceptor(withKey("key-1"), req, info, handler)
+		require.NoError(t, err)
+		_, err = interceptor(withKey("key-1"), &proto.CreateBlogRequest{Title: "Other"}, info, handler)
+		assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+	})
+
+	t.Run("IgnoresOtherMethods", func(t *testing.T) {
+		interceptor, calls := setup(), 0
+		handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+			calls++
+			return &proto.BlogResponse{}, nil
+		}
+		other := &grpclib.UnaryServerInfo{FullMethod: proto.BlogService_GetBlog_FullMethodName}
+
+		for i := 0; i < 2; i++ {
+			_, err := interceptor(withKey("key-1"), &proto.GetBlogRequest{Id: 1}, other, handler)
+			require.NoError(t, err)
+		}
+		assert.Equal(t, 2, calls)
+	})
+}
@@ -0,0 +1,37 @@
+package grpc
+
+import (
+	"context"
+	"strings"
+
+	"github.com/toffysoft/go-hexagonal-example/internal/core/ports"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// MaintenanceUnaryInterceptor rejects calls with codes.Unavailable while state
+// reports maintenance mode. Health checks are always let through.
+func MaintenanceUnaryInterceptor(state ports.MaintenanceState) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if enabled, message := state(); enabled && !isHealthMethod(info.FullMethod) {
+			return nil, status.Error(codes.Unavailable, message)
+		}
+		return handler(ctx, req)
+	}
+}
+
+// MaintenanceStreamInterceptor is the streaming counterpart of MaintenanceUnaryInterceptor.
+func MaintenanceStreamInterceptor(state ports.MaintenanceState) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if enabled, message := state(); enabled && !isHealthMethod(info.FullMethod) {
+			return status.Error(codes.Unavailable, message)
+		}
+		return handler(srv, ss)
+	}
+}
+
+func isHealthMethod(fullMethod string) bool {
+	return strings.HasPrefix(fullMethod, "/grpc.health.v1.Health/")
+}
@@ -0,0 +1,35 @@
+package grpc_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/toffysoft/go-hexagonal-example/internal/adapters/grpc"
+
+	"github.com/stretchr/testify/assert"
+	grpclib "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestMaintenanceUnaryInterceptor(t *testing.T) {
+	enabled := true
+	interceptor := grpc.MaintenanceUnaryInterceptor(func() (bool, string) {
+		return enabled, "down for maintenance"
+	})
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return "ok", nil
+	}
+
+	_, err := interceptor(context.Background(), nil, &grpclib.UnaryServerInfo{FullMethod: "/blog.BlogService/GetBlog"}, handler)
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+
+	resp, err := interceptor(context.Background(), nil, &grpclib.UnaryServerInfo{FullMethod: "/grpc.health.v1.Health/Check"}, handler)
+	assert.NoError(t, err)
+	assert.Equal(t, "ok", resp)
+
+	enabled = false
+	resp, err = interceptor(context.Background(), nil, &grpclib.UnaryServerInfo{FullMethod: "/blog.BlogService/GetBlog"}, handler)
+	assert.NoError(t, err)
+	assert.Equal(t, "ok", resp)
+}
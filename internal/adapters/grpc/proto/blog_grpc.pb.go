@@ -19,11 +19,15 @@ import (
 const _ = grpc.SupportPackageIsVersion7
 
 const (
-	BlogService_CreateBlog_FullMethodName = "/blog.BlogService/CreateBlog"
-	BlogService_GetBlog_FullMethodName    = "/blog.BlogService/GetBlog"
-	BlogService_UpdateBlog_FullMethodName = "/blog.BlogService/UpdateBlog"
-	BlogService_DeleteBlog_FullMethodName = "/blog.BlogService/DeleteBlog"
-	BlogService_ListBlogs_FullMethodName  = "/blog.BlogService/ListBlogs"
+	BlogService_CreateBlog_FullMethodName       = "/blog.BlogService/CreateBlog"
+	BlogService_GetBlog_FullMethodName          = "/blog.BlogService/GetBlog"
+	BlogService_UpdateBlog_FullMethodName       = "/blog.BlogService/UpdateBlog"
+	BlogService_DeleteBlog_FullMethodName       = "/blog.BlogService/DeleteBlog"
+	BlogService_ListBlogs_FullMethodName        = "/blog.BlogService/ListBlogs"
+	BlogService_StreamBlogs_FullMethodName      = "/blog.BlogService/StreamBlogs"
+	BlogService_WatchBlogs_FullMethodName       = "/blog.BlogService/WatchBlogs"
+	BlogService_BatchCreateBlogs_FullMethodName = "/blog.BlogService/BatchCreateBlogs"
+	BlogService_BatchDeleteBlogs_FullMethodName = "/blog.BlogService/BatchDeleteBlogs"
 )
 
 // BlogServiceClient is the client API for BlogService service.
@@ -35,6 +39,14 @@ type BlogServiceClient interface {
 	UpdateBlog(ctx context.Context, in *UpdateBlogRequest, opts ...grpc.CallOption) (*BlogResponse, error)
 	DeleteBlog(ctx context.Context, in *DeleteBlogRequest, opts ...grpc.CallOption) (*DeleteBlogResponse, error)
 	ListBlogs(ctx context.Context, in *ListBlogsRequest, opts ...grpc.CallOption) (*ListBlogsResponse, error)
+	// StreamBlogs sends every blog in ascending ID order, in chunks.
+	StreamBlogs(ctx context.Context, in *StreamBlogsRequest, opts ...grpc.CallOption) (BlogService_StreamBlogsClient, error)
+	// WatchBlogs streams blog changes as they happen.
+	WatchBlogs(ctx context.Context, in *WatchBlogsRequest, opts ...grpc.CallOption) (BlogService_WatchBlogsClient, error)
+	// BatchCreateBlogs creates many blogs at once and reports each outcome.
+	BatchCreateBlogs(ctx context.Context, in *BatchCreateBlogsRequest, opts ...grpc.CallOption) (*BatchResponse, error)
+	// BatchDeleteBlogs deletes many blogs at once and reports each outcome.
+	BatchDeleteBlogs(ctx context.Context, in *BatchDeleteBlogsRequest, opts ...grpc.CallOption) (*BatchResponse, error)
 }
 
 type blogServiceClient struct {
@@ -90,6 +102,88 @@ func (c *blogServiceClient) ListBlogs(ctx context.Context, in *ListBlogsRequest,
 	return out, nil
 }
 
+func (c *blogServiceClient) StreamBlogs(ctx context.Context, in *StreamBlogsRequest, opts ...grpc.CallOption) (BlogService_StreamBlogsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &BlogService_ServiceDesc.Streams[0], BlogService_StreamBlogs_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &blogServiceStreamBlogsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type BlogService_StreamBlogsClient interface {
+	Recv() (*BlogChunk, error)
+	grpc.ClientStream
+}
+
+type blogServiceStreamBlogsClient struct {
+	grpc.ClientStream
+}
+
+func (x *blogServiceStreamBlogsClient) Recv() (*BlogChunk, error) {
+	m := new(BlogChunk)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *blogServiceClient) WatchBlogs(ctx context.Context, in *WatchBlogsRequest, opts ...grpc.CallOption) (BlogService_WatchBlogsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &BlogService_ServiceDesc.Streams[1], BlogService_WatchBlogs_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &blogServiceWatchBlogsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type BlogService_WatchBlogsClient interface {
+	Recv() (*BlogEvent, error)
+	grpc.ClientStream
+}
+
+type blogServiceWatchBlogsClient struct {
+	grpc.ClientStream
+}
+
+func (x *blogServiceWatchBlogsClient) Recv() (*BlogEvent, error) {
+	m := new(BlogEvent)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *blogServiceClient) BatchCreateBlogs(ctx context.Context, in *BatchCreateBlogsRequest, opts ...grpc.CallOption) (*BatchResponse, error) {
+	out := new(BatchResponse)
+	err := c.cc.Invoke(ctx, BlogService_BatchCreateBlogs_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *blogServiceClient) BatchDeleteBlogs(ctx context.Context, in *BatchDeleteBlogsRequest, opts ...grpc.CallOption) (*BatchResponse, error) {
+	out := new(BatchResponse)
+	err := c.cc.Invoke(ctx, BlogService_BatchDeleteBlogs_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // BlogServiceServer is the server API for BlogService service.
 // All implementations must embed UnimplementedBlogServiceServer
 // for forward compatibility
@@ -99,6 +193,14 @@ type BlogServiceServer interface {
 	UpdateBlog(context.Context, *UpdateBlogRequest) (*BlogResponse, error)
 	DeleteBlog(context.Context, *DeleteBlogRequest) (*DeleteBlogResponse, error)
 	ListBlogs(context.Context, *ListBlogsRequest) (*ListBlogsResponse, error)
+	// StreamBlogs sends every blog in ascending ID order, in chunks.
+	StreamBlogs(*StreamBlogsRequest, BlogService_StreamBlogsServer) error
+	// WatchBlogs streams blog changes as they happen.
+	WatchBlogs(*WatchBlogsRequest, BlogService_WatchBlogsServer) error
+	// BatchCreateBlogs creates many blogs at once and reports each outcome.
+	BatchCreateBlogs(context.Context, *BatchCreateBlogsRequest) (*BatchResponse, error)
+	// BatchDeleteBlogs deletes many blogs at once and reports each outcome.
+	BatchDeleteBlogs(context.Context, *BatchDeleteBlogsRequest) (*BatchResponse, error)
 	mustEmbedUnimplementedBlogServiceServer()
 }
 
@@ -121,6 +223,18 @@ func (UnimplementedBlogServiceServer) DeleteBlog(context.Context, *DeleteBlogReq
 func (UnimplementedBlogServiceServer) ListBlogs(context.Context, *ListBlogsRequest) (*ListBlogsResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ListBlogs not implemented")
 }
+func (UnimplementedBlogServiceServer) StreamBlogs(*StreamBlogsRequest, BlogService_StreamBlogsServer) error {
+	return status.Errorf(codes.Unimplemented, "method StreamBlogs not implemented")
+}
+func (UnimplementedBlogServiceServer) WatchBlogs(*WatchBlogsRequest, BlogService_WatchBlogsServer) error {
+	return status.Errorf(codes.Unimplemented, "method WatchBlogs not implemented")
+}
+func (UnimplementedBlogServiceServer) BatchCreateBlogs(context.Context, *BatchCreateBlogsRequest) (*BatchResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BatchCreateBlogs not implemented")
+}
+func (UnimplementedBlogServiceServer) BatchDeleteBlogs(context.Context, *BatchDeleteBlogsRequest) (*BatchResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BatchDeleteBlogs not implemented")
+}
 func (UnimplementedBlogServiceServer) mustEmbedUnimplementedBlogServiceServer() {}
 
 // UnsafeBlogServiceServer may be embedded to opt out of forward compatibility for this service.
@@ -224,6 +338,84 @@ func _BlogService_ListBlogs_Handler(srv interface{}, ctx context.Context, dec fu
 	return interceptor(ctx, in, info, handler)
 }
 
+func _BlogService_StreamBlogs_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamBlogsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(BlogServiceServer).StreamBlogs(m, &blogServiceStreamBlogsServer{stream})
+}
+
+type BlogService_StreamBlogsServer interface {
+	Send(*BlogChunk) error
+	grpc.ServerStream
+}
+
+type blogServiceStreamBlogsServer struct {
+	grpc.ServerStream
+}
+
+func (x *blogServiceStreamBlogsServer) Send(m *BlogChunk) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _BlogService_WatchBlogs_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchBlogsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(BlogServiceServer).WatchBlogs(m, &blogServiceWatchBlogsServer{stream})
+}
+
+type BlogService_WatchBlogsServer interface {
+	Send(*BlogEvent) error
+	grpc.ServerStream
+}
+
+type blogServiceWatchBlogsServer struct {
+	grpc.ServerStream
+}
+
+func (x *blogServiceWatchBlogsServer) Send(m *BlogEvent) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _BlogService_BatchCreateBlogs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchCreateBlogsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BlogServiceServer).BatchCreateBlogs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BlogService_BatchCreateBlogs_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BlogServiceServer).BatchCreateBlogs(ctx, req.(*BatchCreateBlogsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _BlogService_BatchDeleteBlogs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchDeleteBlogsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BlogServiceServer).BatchDeleteBlogs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BlogService_BatchDeleteBlogs_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BlogServiceServer).BatchDeleteBlogs(ctx, req.(*BatchDeleteBlogsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // BlogService_ServiceDesc is the grpc.ServiceDesc for BlogService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -251,7 +443,26 @@ var BlogService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "ListBlogs",
 			Handler:    _BlogService_ListBlogs_Handler,
 		},
+		{
+			MethodName: "BatchCreateBlogs",
+			Handler:    _BlogService_BatchCreateBlogs_Handler,
+		},
+		{
+			MethodName: "BatchDeleteBlogs",
+			Handler:    _BlogService_BatchDeleteBlogs_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamBlogs",
+			Handler:       _BlogService_StreamBlogs_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "WatchBlogs",
+			Handler:       _BlogService_WatchBlogs_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "blog.proto",
 }
@@ -0,0 +1,118 @@
+package grpc
+
+import (
+	"context"
+	"crypto/subtle"
+	"log/slog"
+	"math"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/toffysoft/go-hexagonal-example/internal/core/domain"
+	"github.com/toffysoft/go-hexagonal-example/internal/core/ports"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// RateLimitUnaryInterceptor applies the limiter's policies to calls, matched
+// by full method name, like the HTTP RateLimit middleware. Calls over the
+// limit fail with codes.ResourceExhausted. The ratelimit-* and retry-after
+// header metadata mirror the HTTP headers. Health checks are never limited.
+// The x-api-key metadata only identifies a caller when it is one of keys, and
+// only such callers can name a user; other calls are identified by IP.
+func RateLimitUnaryInterceptor(limiter ports.RateLimiter, userMetadata string, keys []string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		header, err := checkRateLimit(ctx, limiter, userMetadata, keys, info.FullMethod)
+		if header != nil {
+			_ = grpc.SetHeader(ctx, header)
+		}
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// RateLimitStreamInterceptor is the streaming counterpart of
+// RateLimitUnaryInterceptor; a stream takes one token when it is opened.
+func RateLimitStreamInterceptor(limiter ports.RateLimiter, userMetadata string, keys []string) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		header, err := checkRateLimit(ss.Context(), limiter, userMetadata, keys, info.FullMethod)
+		if header != nil {
+			_ = ss.SetHeader(header)
+		}
+		if err != nil {
+			return err
+		}
+		return handler(srv, ss)
+	}
+}
+
+// checkRateLimit returns the header metadata to send, and an error if the
+// call is over the limit.
+func checkRateLimit(ctx context.Context, limiter ports.RateLimiter, userMetadata string, keys []string, fullMethod string) (metadata.MD, error) {
+	if isHealthMethod(fullMethod) {
+		return nil, nil
+	}
+	policy, ok := limiter.Policy("", fullMethod)
+	if !ok {
+		return nil, nil
+	}
+	decision, err := limiter.Allow(ctx, policy, callIdentity(ctx, policy.Identity, userMetadata, keys))
+	if err != nil {
+		slog.Error("rate limit check failed", "error", err)
+		return nil, nil
+	}
+
+	header := metadata.Pairs(
+		"ratelimit-policy", policy.Header(),
+		"ratelimit-limit", strconv.Itoa(decision.Limit),
+		"ratelimit-remaining", strconv.Itoa(decision.Remaining),
+		"ratelimit-reset", ceilSeconds(decision.Reset),
+	)
+	if !decision.Allowed {
+		header.Set("retry-after", ceilSeconds(decision.RetryAfter))
+		return header, status.Errorf(codes.ResourceExhausted, "Too many requests, retry in %ss", ceilSeconds(decision.RetryAfter))
+	}
+	return header, nil
+}
+
+func callIdentity(ctx context.Context, identity domain.RateLimitIdentity, userMetadata string, keys []string) string {
+	if key := firstMetadata(ctx, "x-api-key"); identity != domain.IdentityIP && validKey(key, keys) {
+		if identity == domain.IdentityUser {
+			if user := firstMetadata(ctx, userMetadata); user != "" {
+				return "user:" + callerScope(key) + ":" + user
+			}
+		}
+		return "key:" + callerScope(key)
+	}
+	if p, ok := peer.FromContext(ctx); ok {
+		if host, _, err := net.SplitHostPort(p.Addr.String()); err == nil {
+			return "ip:" + host
+		}
+		return "ip:" + p.Addr.String()
+	}
+	return "ip:unknown"
+}
+
+// validKey reports whether key is one of keys, in constant time.
+func validKey(key string, keys []string) bool {
+	if key == "" {
+		return false
+	}
+	for _, k := range keys {
+		if subtle.ConstantTimeCompare([]byte(k), []byte(key)) == 1 {
+			return true
+		}
+	}
+	return false
+}
+
+func ceilSeconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
@@ -0,0 +1,73 @@
+package grpc_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/toffysoft/go-hexagonal-example/internal/adapters/grpc"
+	"github.com/toffysoft/go-hexagonal-example/internal/adapters/repositories"
+	"github.com/toffysoft/go-hexagonal-example/internal/core/domain"
+	"github.com/toffysoft/go-hexagonal-example/internal/core/services"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	grpclib "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestRateLimitUnaryInterceptor(t *testing.T) {
+	policy, err := domain.ParseRateLimitPolicy("/blog.BlogService/ 1/1m")
+	require.NoError(t, err)
+	interceptor := grpc.RateLimitUnaryInterceptor(services.NewRateLimiter(repositories.NewMemoryRateLimitStore(), policy), "x-user-id", []string{"alice", "bob"})
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return "ok", nil
+	}
+	caller := func(key string) context.Context {
+		return metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-api-key", key))
+	}
+	info := &grpclib.UnaryServerInfo{FullMethod: "/blog.BlogService/GetBlog"}
+
+	resp, err := interceptor(caller("alice"), nil, info, handler)
+	assert.NoError(t, err)
+	assert.Equal(t, "ok", resp)
+
+	_, err = interceptor(caller("alice"), nil, info, handler)
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+	_, err = interceptor(caller("bob"), nil, info, handler)
+	assert.NoError(t, err)
+
+	for i := 0; i < 2; i++ {
+		_, err = interceptor(caller("alice"), nil, &grpclib.UnaryServerInfo{FullMethod: "/grpc.health.v1.Health/Check"}, handler)
+		assert.NoError(t, err)
+	}
+
+	// Unknown keys do not get buckets of their own.
+	_, err = interceptor(caller("random-1"), nil, info, handler)
+	assert.NoError(t, err)
+	_, err = interceptor(caller("random-2"), nil, info, handler)
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+}
+
+func TestRateLimitUnaryInterceptorScopesUsersToTheirKey(t *testing.T) {
+	policy, err := domain.ParseRateLimitPolicy("/blog.BlogService/ 1/1m by=user")
+	require.NoError(t, err)
+	interceptor := grpc.RateLimitUnaryInterceptor(services.NewRateLimiter(repositories.NewMemoryRateLimitStore(), policy), "x-user-id", []string{"alice", "bob"})
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return "ok", nil
+	}
+	caller := func(key, user string) context.Context {
+		return metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-api-key", key, "x-user-id", user))
+	}
+	info := &grpclib.UnaryServerInfo{FullMethod: "/blog.BlogService/GetBlog"}
+
+	_, err = interceptor(caller("alice", "carol"), nil, info, handler)
+	assert.NoError(t, err)
+	_, err = interceptor(caller("alice", "carol"), nil, info, handler)
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+	_, err = interceptor(caller("bob", "carol"), nil, info, handler)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,183 @@
+package handlers
+
+import (
+	"mime"
+	"strconv"
+
+	"github.com/toffysoft/go-hexagonal-example/internal/core/domain"
+	"github.com/toffysoft/go-hexagonal-example/internal/core/ports"
+	"github.com/toffysoft/go-hexagonal-example/pkg/utils"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+// attachmentCacheControl lets clients keep attachment content for good: the
+// content of an attachment ID never changes.
+const attachmentCacheControl = "public, max-age=31536000, immutable"
+
+type AttachmentHandler struct {
+	attachmentService ports.AttachmentService
+	validate          *validator.Validate
+}
+
+func NewAttachmentHandler(attachmentService ports.AttachmentService) *AttachmentHandler {
+	return &AttachmentHandler{
+		attachmentService: attachmentService,
+		validate:          validator.New(),
+	}
+}
+
+// AttachmentResponse tells whether the attachment has a thumbnail, at
+// /attachments/:id/thumbnail.
+type AttachmentResponse struct {
+	*domain.Attachment
+	Thumbnail bool `json:"thumbnail"`
+}
+
+func attachmentResponse(attachment *domain.Attachment) AttachmentResponse {
+	return AttachmentResponse{Attachment: attachment, Thumbnail: attachment.HasThumbnail()}
+}
+
+// UploadAttachment stores the multipart "file" field as an attachment of the
+// blog. Its type is sniffed from its content.
+func (h *AttachmentHandler) UploadAttachment(c *fiber.Ctx) error {
+	blogID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid blog ID")
+	}
+	header, err := c.FormFile("file")
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, `Multipart upload has no "file" field`)
+	}
+	file, err := header.Open()
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Unreadable upload")
+	}
+	defer file.Close()
+
+	attachment, err := h.attachmentService.Upload(c.UserContext(), uint(blogID), header.Filename, file)
+	if err != nil {
+		return sendError(c, err, "Failed to upload attachment")
+	}
+	return utils.SendSuccessResponse(c, fiber.StatusCreated, "Attachment uploaded successfully", attachmentResponse(attachment))
+}
+
+func (h *AttachmentHandler) ListAttachments(c *fiber.Ctx) error {
+	blogID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid blog ID")
+	}
+
+	attachments, err := h.attachmentService.ListAttachments(c.UserContext(), uint(blogID))
+	if err != nil {
+		return sendError(c, err, "Failed to retrieve attachments")
+	}
+	responses := make([]AttachmentResponse, len(attachments))
+	for i, attachment := range attachments {
+		responses[i] = attachmentResponse(attachment)
+	}
+	return utils.SendSuccessResponse(c, fiber.StatusOK, "Attachments retrieved successfully", responses)
+}
+
+func (h *AttachmentHandler) GetAttachment(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid attachment ID")
+	}
+
+	attachment, err := h.attachmentService.GetAttachment(c.UserContext(), uint(id))
+	if err != nil {
+		return sendError(c, err, "Failed to retrieve attachment")
+	}
+	return utils.SendSuccessResponse(c, fiber.StatusOK, "Attachment retrieved successfully", attachmentResponse(attachment))
+}
+
+// GetAttachmentContent sends the bytes of an attachment.
+func (h *AttachmentHandler) GetAttachmentContent(c *fiber.Ctx) error {
+	return h.sendContent(c, false)
+}
+
+// GetAttachmentThumbnail sends the thumbnail of an image attachment.
+func (h *AttachmentHandler) GetAttachmentThumbnail(c *fiber.Ctx) error {
+	return h.sendContent(c, true)
+}
+
+func (h *AttachmentHandler) sendContent(c *fiber.Ctx, thumbnail bool) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid attachment ID")
+	}
+
+	attachment, content, err := h.attachmentService.OpenAttachment(c.UserContext(), uint(id), thumbnail)
+	if err != nil {
+		return sendError(c, err, "Failed to retrieve attachment")
+	}
+	contentType, size := attachment.ContentType, int(attachment.Size)
+	if thumbnail {
+		contentType, size = attachment.ThumbnailType, -1
+	}
+	// Only images are shown in the browser; anything else is downloaded,
+	// and never sniffed into something that could run scripts.
+	disposition := "attachment"
+	if attachment.IsImage() {
+		disposition = "inline"
+	}
+	c.Set(fiber.HeaderContentType, contentType)
+	c.Set(fiber.HeaderContentDisposition, mime.FormatMediaType(disposition, map[string]string{"filename": attachment.Filename}))
+	c.Set(fiber.HeaderXContentTypeOptions, "nosniff")
+	c.Set(fiber.HeaderCacheControl, attachmentCacheControl)
+	// The stream is closed once it has been sent.
+	return c.SendStream(content, size)
+}
+
+func (h *AttachmentHandler) DeleteAttachment(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid attachment ID")
+	}
+
+	if err := h.attachmentService.DeleteAttachment(c.UserContext(), uint(id)); err != nil {
+		return sendError(c, err, "Failed to delete attachment")
+	}
+	return utils.SendSuccessResponse(c, fiber.StatusOK, "Attachment deleted successfully", nil)
+}
+
+type SetCoverRequest struct {
+	AttachmentID uint `json:"attachment_id" validate:"required"`
+}
+
+// SetCover makes an image attachment of the blog its cover image.
+func (h *AttachmentHandler) SetCover(c *fiber.Ctx) error {
+	blogID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid blog ID")
+	}
+	var req SetCoverRequest
+	if err := utils.ParseBody(c, &req); err != nil {
+		return utils.SendBodyError(c, err)
+	}
+	if err := h.validate.Struct(req); err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, utils.ValidatorErrors(err))
+	}
+
+	blog, err := h.attachmentService.SetCover(c.UserContext(), uint(blogID), req.AttachmentID)
+	if err != nil {
+		return sendError(c, err, "Failed to set cover image")
+	}
+	return utils.SendSuccessResponse(c, fiber.StatusOK, "Cover image set successfully", blog)
+}
+
+// ClearCover removes the cover image of the blog. The attachment is kept.
+func (h *AttachmentHandler) ClearCover(c *fiber.Ctx) error {
+	blogID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid blog ID")
+	}
+
+	blog, err := h.attachmentService.SetCover(c.UserContext(), uint(blogID), 0)
+	if err != nil {
+		return sendError(c, err, "Failed to clear cover image")
+	}
+	return utils.SendSuccessResponse(c, fiber.StatusOK, "Cover image cleared successfully", blog)
+}
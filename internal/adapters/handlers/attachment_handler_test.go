@@ -0,0 +1,155 @@
+package handlers_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/toffysoft/go-hexagonal-example/internal/adapters/handlers"
+	"github.com/toffysoft/go-hexagonal-example/internal/adapters/media"
+	"github.com/toffysoft/go-hexagonal-example/internal/adapters/repositories"
+	"github.com/toffysoft/go-hexagonal-example/internal/adapters/storage"
+	"github.com/toffysoft/go-hexagonal-example/internal/core/domain"
+	"github.com/toffysoft/go-hexagonal-example/internal/core/services"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newAttachmentApp(t *testing.T) (*fiber.App, *domain.Blog) {
+	store := repositories.NewMemoryStore()
+	blogService := services.NewBlogService(store.Blogs(), services.WithUnitOfWork(store.UnitOfWork()))
+	blog := &domain.Blog{Title: "Title", Content: "Content", Author: "alice"}
+	require.NoError(t, blogService.CreateBlog(context.Background(), blog))
+	attachmentService := services.NewAttachmentService(repositories.NewMemoryAttachmentRepository(store.Blogs()),
+		storage.NewMemoryStore(), blogService, media.NewThumbnailer(), services.AttachmentConfig{MaxSize: 1 << 20})
+
+	h := handlers.NewAttachmentHandler(attachmentService)
+	app := fiber.New()
+	app.Post("/api/v1/blogs/:id/attachments", h.UploadAttachment)
+	app.Get("/api/v1/blogs/:id/attachments", h.ListAttachments)
+	app.Put("/api/v1/blogs/:id/cover", h.SetCover)
+	app.Delete("/api/v1/blogs/:id/cover", h.ClearCover)
+	app.Get("/api/v1/attachments/:id", h.GetAttachment)
+	app.Get("/api/v1/attachments/:id/content", h.GetAttachmentContent)
+	app.Get("/api/v1/attachments/:id/thumbnail", h.GetAttachmentThumbnail)
+	app.Delete("/api/v1/attachments/:id", h.DeleteAttachment)
+	return app, blog
+}
+
+func upload(t *testing.T, app *fiber.App, blogID uint, filename string, content []byte) (*http.Response, map[string]interface{}) {
+	var buf bytes.Buffer
+	form := multipart.NewWriter(&buf)
+	file, err := form.CreateFormFile("file", filename)
+	require.NoError(t, err)
+	_, err = file.Write(content)
+	require.NoError(t, err)
+	require.NoError(t, form.Close())
+
+	req := httptest.NewRequest("POST", fmt.Sprintf("/api/v1/blogs/%d/attachments", blogID), &buf)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	var body struct {
+		Data map[string]interface{} `json:"data"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	return resp, body.Data
+}
+
+func TestAttachmentHandler(t *testing.T) {
+	var img bytes.Buffer
+	require.NoError(t, png.Encode(&img, image.NewGray(image.Rect(0, 0, 640, 480))))
+
+	t.Run("UploadAndDownload", func(t *testing.T) {
+		app, blog := newAttachmentApp(t)
+
+		resp, data := upload(t, app, blog.ID, "photo.png", img.Bytes())
+
+		require.Equal(t, fiber.StatusCreated, resp.StatusCode)
+		assert.Equal(t, "image/png", data["content_type"])
+		assert.Equal(t, true, data["thumbnail"])
+		assert.Equal(t, float64(640), data["width"])
+		id := uint(data["id"].(float64))
+
+		resp, err := app.Test(httptest.NewRequest("GET", fmt.Sprintf("/api/v1/attachments/%d/content", id), nil))
+		require.NoError(t, err)
+		require.Equal(t, fiber.StatusOK, resp.StatusCode)
+		assert.Equal(t, "image/png", resp.Header.Get("Content-Type"))
+		assert.Equal(t, "nosniff", resp.Header.Get("X-Content-Type-Options"))
+		assert.Equal(t, `inline; filename=photo.png`, resp.Header.Get("Content-Disposition"))
+		content, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		assert.Equal(t, img.Bytes(), content)
+
+		resp, err = app.Test(httptest.NewRequest("GET", fmt.Sprintf("/api/v1/attachments/%d/thumbnail", id), nil))
+		require.NoError(t, err)
+		require.Equal(t, fiber.StatusOK, resp.StatusCode)
+		thumb, err := png.Decode(resp.Body)
+		require.NoError(t, err)
+		assert.Equal(t, image.Pt(320, 240), thumb.Bounds().Size())
+	})
+
+	t.Run("RejectsSniffedType", func(t *testing.T) {
+		app, blog := newAttachmentApp(t)
+
+		resp, _ := upload(t, app, blog.ID, "photo.png", []byte("<html><script>alert(1)</script></html>"))
+
+		assert.Equal(t, fiber.StatusUnsupportedMediaType, resp.StatusCode)
+	})
+
+	t.Run("RejectsTooLarge", func(t *testing.T) {
+		app, blog := newAttachmentApp(t)
+
+		resp, _ := upload(t, app, blog.ID, "big.pdf", append([]byte("%PDF-1.4\n"), make([]byte, 1<<20)...))
+
+		assert.Equal(t, fiber.StatusRequestEntityTooLarge, resp.StatusCode)
+	})
+
+	t.Run("Cover", func(t *testing.T) {
+		app, blog := newAttachmentApp(t)
+		_, data := upload(t, app, blog.ID, "photo.png", img.Bytes())
+		id := data["id"].(float64)
+
+		req := httptest.NewRequest("PUT", fmt.Sprintf("/api/v1/blogs/%d/cover", blog.ID), strings.NewReader(fmt.Sprintf(`{"attachment_id":%v}`, id)))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		require.Equal(t, fiber.StatusOK, resp.StatusCode)
+		var body struct {
+			Data map[string]interface{} `json:"data"`
+		}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+		assert.Equal(t, id, body.Data["cover_image_id"])
+
+		resp, err = app.Test(httptest.NewRequest("DELETE", fmt.Sprintf("/api/v1/blogs/%d/cover", blog.ID), nil))
+		require.NoError(t, err)
+		require.Equal(t, fiber.StatusOK, resp.StatusCode)
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+		assert.Nil(t, body.Data["cover_image_id"])
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		app, blog := newAttachmentApp(t)
+		_, data := upload(t, app, blog.ID, "report.pdf", []byte("%PDF-1.4"))
+		target := fmt.Sprintf("/api/v1/attachments/%v", data["id"])
+
+		resp, err := app.Test(httptest.NewRequest("DELETE", target, nil))
+		require.NoError(t, err)
+		require.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+		resp, err = app.Test(httptest.NewRequest("GET", target+"/content", nil))
+		require.NoError(t, err)
+		assert.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+	})
+}
@@ -0,0 +1,35 @@
+package handlers
+
+import (
+	"crypto/subtle"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/keyauth"
+)
+
+// apiKeyLocal is the local under which APIKeyAuth keeps the verified key.
+const apiKeyLocal = "apiKey"
+
+// APIKeyAuth rejects requests whose X-API-Key header does not match one of
+// keys. The key of an accepted request can be read with verifiedAPIKey.
+func APIKeyAuth(keys []string) fiber.Handler {
+	return keyauth.New(keyauth.Config{
+		KeyLookup:  "header:X-API-Key",
+		ContextKey: apiKeyLocal,
+		Validator: func(c *fiber.Ctx, key string) (bool, error) {
+			for _, k := range keys {
+				if subtle.ConstantTimeCompare([]byte(k), []byte(key)) == 1 {
+					return true, nil
+				}
+			}
+			return false, keyauth.ErrMissingOrMalformedAPIKey
+		},
+	})
+}
+
+// verifiedAPIKey returns the API key that APIKeyAuth accepted, or "" if the
+// request did not go through it.
+func verifiedAPIKey(c *fiber.Ctx) string {
+	key, _ := c.Locals(apiKeyLocal).(string)
+	return key
+}
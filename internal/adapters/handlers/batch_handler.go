@@ -0,0 +1,198 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/toffysoft/go-hexagonal-example/internal/core/domain"
+	"github.com/toffysoft/go-hexagonal-example/pkg/errors"
+	"github.com/toffysoft/go-hexagonal-example/pkg/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Batch requests default to all-or-nothing.
+type BatchCreateBlogsRequest struct {
+	Mode  domain.BatchMode    `json:"mode"`
+	Items []CreateBlogRequest `json:"items"`
+}
+
+type BatchUpdateBlogItem struct {
+	ID uint `json:"id" validate:"required"`
+	UpdateBlogRequest
+}
+
+type BatchUpdateBlogsRequest struct {
+	Mode  domain.BatchMode      `json:"mode"`
+	Items []BatchUpdateBlogItem `json:"items"`
+}
+
+type BatchDeleteBlogsRequest struct {
+	Mode domain.BatchMode `json:"mode"`
+	IDs  []uint           `json:"ids"`
+}
+
+// Batch item statuses.
+const (
+	BatchItemSucceeded = "succeeded"
+	BatchItemFailed    = "failed"
+	// BatchItemSkipped marks a valid item of an atomic batch that was not
+	// applied because another item failed.
+	BatchItemSkipped = "skipped"
+)
+
+type BatchItemResult struct {
+	Index  int          `json:"index"`
+	Status string       `json:"status"`
+	Blog   *domain.Blog `json:"blog,omitempty"`
+	Error  string       `json:"error,omitempty"`
+}
+
+type BatchResponse struct {
+	Mode      domain.BatchMode  `json:"mode"`
+	Succeeded int               `json:"succeeded"`
+	Failed    int               `json:"failed"`
+	Results   []BatchItemResult `json:"results"`
+}
+
+type batchFunc func(ctx context.Context, mode domain.BatchMode) ([]domain.BatchResult, error)
+
+func (h *BlogHandler) BatchCreateBlogs(c *fiber.Ctx) error {
+	var req BatchCreateBlogsRequest
+	if err := utils.ParseBody(c, &req); err != nil {
+		return utils.SendBodyError(c, err)
+	}
+
+	invalid := make([]error, len(req.Items))
+	var blogs []*domain.Blog
+	var positions []int
+	for i, item := range req.Items {
+		if err := h.validate.Struct(item); err != nil {
+			invalid[i] = errors.NewInvalidInputError(utils.ValidatorErrors(err))
+			continue
+		}
+		blogs = append(blogs, &domain.Blog{
+			Title:         item.Title,
+			Content:       item.Content,
+			ContentFormat: domain.ContentFormat(item.ContentFormat),
+			Author:        item.Author,
+		})
+		positions = append(positions, i)
+	}
+
+	return h.runBatch(c, req.Mode, "created", invalid, positions, func(ctx context.Context, mode domain.BatchMode) ([]domain.BatchResult, error) {
+		return h.blogService.BatchCreateBlogs(ctx, blogs, mode)
+	})
+}
+
+func (h *BlogHandler) BatchUpdateBlogs(c *fiber.Ctx) error {
+	var req BatchUpdateBlogsRequest
+	if err := utils.ParseBody(c, &req); err != nil {
+		return utils.SendBodyError(c, err)
+	}
+
+	invalid := make([]error, len(req.Items))
+	var blogs []*domain.Blog
+	var positions []int
+	for i, item := range req.Items {
+		if err := h.validate.Struct(item); err != nil {
+			invalid[i] = errors.NewInvalidInputError(utils.ValidatorErrors(err))
+			continue
+		}
+		blogs = append(blogs, &domain.Blog{
+			ID:            item.ID,
+			Title:         item.Title,
+			Content:       item.Content,
+			ContentFormat: domain.ContentFormat(item.ContentFormat),
+			Author:        item.Author,
+		})
+		positions = append(positions, i)
+	}
+
+	return h.runBatch(c, req.Mode, "updated", invalid, positions, func(ctx context.Context, mode domain.BatchMode) ([]domain.BatchResult, error) {
+		return h.blogService.BatchUpdateBlogs(ctx, blogs, mode)
+	})
+}
+
+func (h *BlogHandler) BatchDeleteBlogs(c *fiber.Ctx) error {
+	var req BatchDeleteBlogsRequest
+	if err := utils.ParseBody(c, &req); err != nil {
+		return utils.SendBodyError(c, err)
+	}
+
+	positions := make([]int, len(req.IDs))
+	for i := range positions {
+		positions[i] = i
+	}
+
+	return h.runBatch(c, req.Mode, "deleted", make([]error, len(req.IDs)), positions, func(ctx context.Context, mode domain.BatchMode) ([]domain.BatchResult, error) {
+		return h.blogService.BatchDeleteBlogs(ctx, req.IDs, mode)
+	})
+}
+
+// runBatch runs the items that passed request validation through run and
+// merges its results with the validation failures. positions maps the i-th
+// item given to run to its index in the request.
+func (h *BlogHandler) runBatch(c *fiber.Ctx, mode domain.BatchMode, verb string, invalid []error, positions []int, run batchFunc) error {
+	if mode == "" {
+		mode = domain.BatchAtomic
+	}
+	if len(invalid) == 0 {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Batch must contain at least one item")
+	}
+
+	results := make([]domain.BatchResult, len(invalid))
+	failed := 0
+	for i, err := range invalid {
+		results[i] = domain.BatchResult{Index: i, Err: err}
+		if err != nil {
+			failed++
+		}
+	}
+
+	var runErr error
+	if failed > 0 && mode == domain.BatchAtomic {
+		runErr = errors.NewInvalidInputError(fmt.Sprintf("Batch rejected: %d of %d items failed", failed, len(invalid)))
+	} else if len(positions) > 0 {
+		served, err := run(c.UserContext(), mode)
+		if err != nil && served == nil {
+			return sendError(c, err, "Failed to process batch")
+		}
+		for j, result := range served {
+			result.Index = positions[j]
+			results[positions[j]] = result
+		}
+		runErr = err
+	}
+
+	response := BatchResponse{Mode: mode, Results: make([]BatchItemResult, len(results))}
+	for i, result := range results {
+		item := BatchItemResult{Index: result.Index, Blog: result.Blog}
+		switch {
+		case result.Err != nil:
+			item.Status = BatchItemFailed
+			item.Error = result.Err.Error()
+			response.Failed++
+		case result.Skipped():
+			item.Status = BatchItemSkipped
+		default:
+			item.Status = BatchItemSucceeded
+			response.Succeeded++
+		}
+		response.Results[i] = item
+	}
+
+	if runErr != nil {
+		status := fiber.StatusInternalServerError
+		if appErr, ok := runErr.(errors.AppError); ok {
+			status = appErr.StatusCode()
+		}
+		return utils.SendResponse(c, status, false, runErr.Error(), response)
+	}
+
+	message := fmt.Sprintf("%d of %d blogs %s", response.Succeeded, len(results), verb)
+	if response.Failed > 0 {
+		return utils.SendResponse(c, fiber.StatusMultiStatus, false, message, response)
+	}
+	return utils.SendResponse(c, fiber.StatusOK, true, message, response)
+}
@@ -0,0 +1,93 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/toffysoft/go-hexagonal-example/internal/adapters/handlers"
+	"github.com/toffysoft/go-hexagonal-example/internal/adapters/repositories"
+	"github.com/toffysoft/go-hexagonal-example/internal/core/services"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type batchBody struct {
+	Success bool                   `json:"success"`
+	Message string                 `json:"message"`
+	Data    handlers.BatchResponse `json:"data"`
+}
+
+func batchApp() *fiber.App {
+	store := repositories.NewMemoryStore()
+	blogService := services.NewBlogService(store.Blogs(), services.WithUnitOfWork(store.UnitOfWork()))
+	blogHandler := handlers.NewBlogHandler(blogService)
+
+	app := fiber.New()
+	app.Post("/api/v1/blogs\\:batchCreate", blogHandler.BatchCreateBlogs)
+	app.Post("/api/v1/blogs\\:batchDelete", blogHandler.BatchDeleteBlogs)
+	return app
+}
+
+func postBatch(t *testing.T, app *fiber.App, target, body string) (int, batchBody) {
+	req := httptest.NewRequest("POST", target, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+
+	var decoded batchBody
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&decoded))
+	return resp.StatusCode, decoded
+}
+
+const batchItems = `[
+	{"title": "First post", "content": "Long enough content", "author": "alice"},
+	{"title": "x", "content": "Long enough content", "author": "bob"},
+	{"title": "Third post", "content": "Long enough content", "author": "carol"}
+]`
+
+func TestBatchCreateBlogs(t *testing.T) {
+	t.Run("AtomicRejectsWholeBatch", func(t *testing.T) {
+		app := batchApp()
+
+		status, body := postBatch(t, app, "/api/v1/blogs:batchCreate", `{"items": `+batchItems+`}`)
+
+		assert.Equal(t, fiber.StatusBadRequest, status)
+		assert.False(t, body.Success)
+		assert.Equal(t, 0, body.Data.Succeeded)
+		assert.Equal(t, handlers.BatchItemSkipped, body.Data.Results[0].Status)
+		assert.Equal(t, handlers.BatchItemFailed, body.Data.Results[1].Status)
+
+		status, body = postBatch(t, app, "/api/v1/blogs:batchDelete", `{"ids": [1]}`)
+		assert.Equal(t, fiber.StatusNotFound, status)
+	})
+
+	t.Run("BestEffortReportsEachItem", func(t *testing.T) {
+		app := batchApp()
+
+		status, body := postBatch(t, app, "/api/v1/blogs:batchCreate", `{"mode": "best_effort", "items": `+batchItems+`}`)
+
+		assert.Equal(t, fiber.StatusMultiStatus, status)
+		assert.Equal(t, 2, body.Data.Succeeded)
+		assert.Equal(t, 1, body.Data.Failed)
+		require.Len(t, body.Data.Results, 3)
+		assert.Equal(t, 2, body.Data.Results[2].Index)
+		assert.Equal(t, "carol", body.Data.Results[2].Blog.Author)
+		assert.Contains(t, body.Data.Results[1].Error, "Title")
+
+		status, body = postBatch(t, app, "/api/v1/blogs:batchDelete", `{"mode": "best_effort", "ids": [1, 2, 99]}`)
+		assert.Equal(t, fiber.StatusMultiStatus, status)
+		assert.Equal(t, 2, body.Data.Succeeded)
+		assert.Equal(t, handlers.BatchItemFailed, body.Data.Results[2].Status)
+	})
+
+	t.Run("EmptyBatch", func(t *testing.T) {
+		status, body := postBatch(t, batchApp(), "/api/v1/blogs:batchCreate", `{"items": []}`)
+
+		assert.Equal(t, fiber.StatusBadRequest, status)
+		assert.False(t, body.Success)
+	})
+}
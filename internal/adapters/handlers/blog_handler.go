@@ -1,7 +1,10 @@
 package handlers
 
 import (
+	"encoding/json"
+	"fmt"
 	"strconv"
+	"strings"
 
 	"github.com/toffysoft/go-hexagonal-example/internal/core/domain"
 	"github.com/toffysoft/go-hexagonal-example/internal/core/ports"
@@ -15,25 +18,44 @@ import (
 type BlogHandler struct {
 	blogService ports.BlogService
 	validate    *validator.Validate
+	cachePolicy func() CachePolicy
 }
 
-func NewBlogHandler(blogService ports.BlogService) *BlogHandler {
-	return &BlogHandler{
+// BlogHandlerOption configures optional behaviour of the BlogHandler.
+type BlogHandlerOption func(*BlogHandler)
+
+// WithCachePolicy sets the caching headers of blog reads. The policy is
+// looked up on every request, so it can follow config reloads.
+func WithCachePolicy(policy func() CachePolicy) BlogHandlerOption {
+	return func(h *BlogHandler) {
+		h.cachePolicy = policy
+	}
+}
+
+func NewBlogHandler(blogService ports.BlogService, opts ...BlogHandlerOption) *BlogHandler {
+	h := &BlogHandler{
 		blogService: blogService,
 		validate:    validator.New(),
+		cachePolicy: func() CachePolicy { return CachePolicy{} },
+	}
+	for _, opt := range opts {
+		opt(h)
 	}
+	return h
 }
 
 type CreateBlogRequest struct {
-	Title   string `json:"title" validate:"required,min=3,max=100"`
-	Content string `json:"content" validate:"required,min=10"`
-	Author  string `json:"author" validate:"required,min=2,max=50"`
+	Title         string   `json:"title" validate:"required,min=3,max=100"`
+	Content       string   `json:"content" validate:"required,min=10"`
+	ContentFormat string   `json:"content_format" validate:"omitempty,oneof=markdown html plain"`
+	Author        string   `json:"author" validate:"required,min=2,max=50"`
+	Tags          []string `json:"tags" validate:"max=10,dive,max=50"`
 }
 
 func (h *BlogHandler) CreateBlog(c *fiber.Ctx) error {
 	var req CreateBlogRequest
-	if err := c.BodyParser(&req); err != nil {
-		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid request body")
+	if err := utils.ParseBody(c, &req); err != nil {
+		return utils.SendBodyError(c, err)
 	}
 
 	if err := h.validate.Struct(req); err != nil {
@@ -41,12 +63,14 @@ func (h *BlogHandler) CreateBlog(c *fiber.Ctx) error {
 	}
 
 	blog := &domain.Blog{
-		Title:   req.Title,
-		Content: req.Content,
-		Author:  req.Author,
+		Title:         req.Title,
+		Content:       req.Content,
+		ContentFormat: domain.ContentFormat(req.ContentFormat),
+		Author:        req.Author,
+		Tags:          req.Tags,
 	}
 
-	if err := h.blogService.CreateBlog(blog); err != nil {
+	if err := h.blogService.CreateBlog(c.UserContext(), blog); err != nil {
 		if appErr, ok := err.(errors.AppError); ok {
 			return utils.SendErrorResponse(c, appErr.StatusCode(), appErr.Error())
 		}
@@ -57,9 +81,13 @@ func (h *BlogHandler) CreateBlog(c *fiber.Ctx) error {
 }
 
 type UpdateBlogRequest struct {
-	Title   string `json:"title" validate:"omitempty,min=3,max=100"`
-	Content string `json:"content" validate:"omitempty,min=10"`
-	Author  string `json:"author" validate:"omitempty,min=2,max=50"`
+	Title         string `json:"title" validate:"omitempty,min=3,max=100"`
+	Content       string `json:"content" validate:"omitempty,min=10"`
+	ContentFormat string `json:"content_format" validate:"omitempty,oneof=markdown html plain"`
+	Author        string `json:"author" validate:"omitempty,min=2,max=50"`
+	// Tags replace the tags of the blog when present; an empty list clears
+	// them.
+	Tags []string `json:"tags" validate:"max=10,dive,max=50"`
 }
 
 func (h *BlogHandler) UpdateBlog(c *fiber.Ctx) error {
@@ -69,15 +97,17 @@ func (h *BlogHandler) UpdateBlog(c *fiber.Ctx) error {
 	}
 
 	var req UpdateBlogRequest
-	if err := c.BodyParser(&req); err != nil {
-		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid request body")
+	if err := utils.ParseBody(c, &req); err != nil {
+		return utils.SendBodyError(c, err)
 	}
 
 	if err := h.validate.Struct(req); err != nil {
 		return utils.SendErrorResponse(c, fiber.StatusBadRequest, utils.ValidatorErrors(err))
 	}
 
-	blog, err := h.blogService.GetBlog(uint(id))
+	// Read from the primary so the update is applied to the latest version
+	ctx := ports.WithStrongConsistency(c.UserContext())
+	blog, err := h.blogService.GetBlog(ctx, uint(id))
 	if err != nil {
 		return utils.SendErrorResponse(c, fiber.StatusNotFound, "Blog not found")
 	}
@@ -88,24 +118,172 @@ func (h *BlogHandler) UpdateBlog(c *fiber.Ctx) error {
 	if req.Content != "" {
 		blog.Content = req.Content
 	}
+	if req.ContentFormat != "" {
+		blog.ContentFormat = domain.ContentFormat(req.ContentFormat)
+	}
 	if req.Author != "" {
 		blog.Author = req.Author
 	}
+	if req.Tags != nil {
+		blog.Tags = req.Tags
+	}
 
-	if err := h.blogService.UpdateBlog(blog); err != nil {
-		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to update blog")
+	if err := h.blogService.UpdateBlog(ctx, blog); err != nil {
+		return sendError(c, err, "Failed to update blog")
 	}
 
 	return utils.SendSuccessResponse(c, fiber.StatusOK, "Blog updated successfully", blog)
 }
 
+// Media types of PATCH request bodies.
+const (
+	MIMEMergePatch = "application/merge-patch+json"
+	MIMEJSONPatch  = "application/json-patch+json"
+)
+
+// PatchBlog applies an RFC 7396 merge patch (application/merge-patch+json or
+// application/json) or an RFC 6902 JSON Patch (application/json-patch+json).
+// Unlike UpdateBlog it can clear a field, with null in a merge patch or a
+// "remove" operation.
+func (h *BlogHandler) PatchBlog(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid blog ID")
+	}
+
+	var patch domain.BlogPatch
+	mediaType, _, _ := strings.Cut(c.Get(fiber.HeaderContentType), ";")
+	switch strings.ToLower(strings.TrimSpace(mediaType)) {
+	case MIMEMergePatch, fiber.MIMEApplicationJSON:
+		fields, problem := parseMergePatch(c.Body())
+		if problem != "" {
+			return utils.SendErrorResponse(c, fiber.StatusBadRequest, problem)
+		}
+		if err := h.validateFields(fields); err != nil {
+			return utils.SendErrorResponse(c, fiber.StatusBadRequest, utils.ValidatorErrors(err))
+		}
+		patch = fields
+	case MIMEJSONPatch:
+		var operations domain.JSONPatch
+		if err := json.Unmarshal(c.Body(), &operations); err != nil {
+			return utils.SendErrorResponse(c, fiber.StatusBadRequest, "JSON Patch must be an array of operations")
+		}
+		for _, op := range operations {
+			var fields domain.FieldPatch
+			var value string
+			if (op.Op == "add" || op.Op == "replace") && json.Unmarshal(op.Value, &value) == nil &&
+				fields.Set(strings.TrimPrefix(op.Path, "/"), value) {
+				if err := h.validateFields(fields); err != nil {
+					return utils.SendErrorResponse(c, fiber.StatusBadRequest, utils.ValidatorErrors(err))
+				}
+			}
+		}
+		patch = validatedPatch{JSONPatch: operations, validate: h.validateFields}
+	default:
+		c.Set("Accept-Patch", MIMEMergePatch+", "+MIMEJSONPatch)
+		return utils.SendErrorResponse(c, fiber.StatusUnsupportedMediaType,
+			"Content-Type must be "+MIMEMergePatch+" or "+MIMEJSONPatch)
+	}
+
+	blog, err := h.blogService.PatchBlog(c.UserContext(), uint(id), patch)
+	if err != nil {
+		return sendError(c, err, "Failed to update blog")
+	}
+
+	return utils.SendSuccessResponse(c, fiber.StatusOK, "Blog updated successfully", blog)
+}
+
+// validatedPatch checks the fields written by a JSON Patch once it is
+// applied, since copy and move write values the request does not hold.
+type validatedPatch struct {
+	domain.JSONPatch
+	validate func(domain.FieldPatch) error
+}
+
+func (p validatedPatch) Apply(blog *domain.Blog) error {
+	patched := *blog
+	if err := p.JSONPatch.Apply(&patched); err != nil {
+		return err
+	}
+	if err := p.validate(p.Written(&patched)); err != nil {
+		return errors.NewUnprocessableError(utils.ValidatorErrors(err))
+	}
+	*blog = patched
+	return nil
+}
+
+// parseMergePatch reads a merge patch of the blog fields. Since every field
+// always exists, null clears it. problem is set when the patch is invalid.
+func parseMergePatch(body []byte) (patch domain.FieldPatch, problem string) {
+	var document map[string]json.RawMessage
+	if err := json.Unmarshal(body, &document); err != nil || document == nil {
+		return patch, "Merge patch must be a JSON object"
+	}
+	for field, raw := range document {
+		var value *string
+		if err := json.Unmarshal(raw, &value); err != nil {
+			return patch, fmt.Sprintf("%s must be a string or null", field)
+		}
+		if value == nil {
+			value = new(string)
+		}
+		if !patch.Set(field, *value) {
+			return patch, fmt.Sprintf("%s cannot be patched", field)
+		}
+	}
+	return patch, ""
+}
+
+// validateFields checks the values set by a patch with the rules of
+// UpdateBlogRequest, under which an empty value is allowed.
+func (h *BlogHandler) validateFields(fields domain.FieldPatch) error {
+	var req UpdateBlogRequest
+	if fields.Title != nil {
+		req.Title = *fields.Title
+	}
+	if fields.Content != nil {
+		req.Content = *fields.Content
+	}
+	if fields.ContentFormat != nil {
+		req.ContentFormat = *fields.ContentFormat
+	}
+	if fields.Author != nil {
+		req.Author = *fields.Author
+	}
+	return h.validate.Struct(req)
+}
+
+// renderOption reads the render query parameter of blog reads. render=html
+// adds the content rendered as sanitised HTML to each blog.
+func renderOption(c *fiber.Ctx) (render string, ok bool) {
+	switch render = c.Query("render"); render {
+	case "", "html":
+		return render, true
+	}
+	return "", false
+}
+
 func (h *BlogHandler) GetBlog(c *fiber.Ctx) error {
+	policy := h.cachePolicy()
+	c.Vary(policy.Vary...)
+
 	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
 	if err != nil {
 		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid blog ID")
 	}
+	render, ok := renderOption(c)
+	if !ok {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "render must be html")
+	}
+	mask, _, err := fieldsOption(c)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, err.Error())
+	}
+	if render != "" {
+		mask = mask.With(domain.FieldRenderedHTML)
+	}
 
-	blog, err := h.blogService.GetBlog(uint(id))
+	blog, err := h.blogService.GetBlogFields(c.UserContext(), uint(id), mask)
 	if err != nil {
 		if appErr, ok := err.(errors.AppError); ok {
 			return utils.SendErrorResponse(c, appErr.StatusCode(), appErr.Error())
@@ -113,7 +291,12 @@ func (h *BlogHandler) GetBlog(c *fiber.Ctx) error {
 		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to retrieve blog")
 	}
 
-	return utils.SendSuccessResponse(c, fiber.StatusOK, "Blog retrieved successfully", blog)
+	v := blogValidators(blog)
+	v.variant = strings.Join(mask, ",")
+	if conditional(c, policy.GetBlog, v) {
+		return nil
+	}
+	return utils.SendSuccessResponse(c, fiber.StatusOK, "Blog retrieved successfully", project(blog, mask))
 }
 
 func (h *BlogHandler) DeleteBlog(c *fiber.Ctx) error {
@@ -122,18 +305,55 @@ func (h *BlogHandler) DeleteBlog(c *fiber.Ctx) error {
 		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid blog ID")
 	}
 
-	if err := h.blogService.DeleteBlog(uint(id)); err != nil {
+	if err := h.blogService.DeleteBlog(c.UserContext(), uint(id)); err != nil {
 		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to delete blog")
 	}
 
 	return utils.SendSuccessResponse(c, fiber.StatusOK, "Blog deleted successfully", nil)
 }
 
+// Views of ListBlogs.
+const (
+	ViewFull    = "full"
+	ViewSummary = "summary"
+)
+
+// ListBlogs returns every blog. By default the content is left out, with the
+// excerpt and other metadata in its place; view=full returns it, and fields
+// selects the fields to return instead.
 func (h *BlogHandler) ListBlogs(c *fiber.Ctx) error {
-	blogs, err := h.blogService.ListBlogs()
+	policy := h.cachePolicy()
+	c.Vary(policy.Vary...)
+
+	render, ok := renderOption(c)
+	if !ok {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "render must be html")
+	}
+	mask, selected, err := fieldsOption(c)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, err.Error())
+	}
+	switch view := c.Query("view"); {
+	case view != "" && view != ViewFull && view != ViewSummary:
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "view must be full or summary")
+	case view != "" && selected:
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "view and fields are exclusive")
+	case !selected && view != ViewFull:
+		mask = domain.ListingFields
+	}
+	if render != "" {
+		mask = mask.With(domain.FieldRenderedHTML)
+	}
+
+	blogs, err := h.blogService.ListBlogFields(c.UserContext(), mask)
 	if err != nil {
-		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to retrieve blogs")
+		return sendError(c, err, "Failed to retrieve blogs")
 	}
 
-	return utils.SendSuccessResponse(c, fiber.StatusOK, "Blogs retrieved successfully", blogs)
+	v := listValidators(blogs)
+	v.variant = strings.Join(mask, ",")
+	if conditional(c, policy.ListBlogs, v) {
+		return nil
+	}
+	return utils.SendSuccessResponse(c, fiber.StatusOK, "Blogs retrieved successfully", projectAll(blogs, mask))
 }
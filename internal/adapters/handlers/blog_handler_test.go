@@ -0,0 +1,522 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/toffysoft/go-hexagonal-example/internal/adapters/handlers"
+	"github.com/toffysoft/go-hexagonal-example/internal/adapters/render"
+	"github.com/toffysoft/go-hexagonal-example/internal/adapters/repositories"
+	"github.com/toffysoft/go-hexagonal-example/internal/core/domain"
+	"github.com/toffysoft/go-hexagonal-example/internal/core/ports"
+	"github.com/toffysoft/go-hexagonal-example/internal/core/services"
+	"github.com/toffysoft/go-hexagonal-example/pkg/errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type blogBody struct {
+	Success bool        `json:"success"`
+	Message string      `json:"message"`
+	Data    domain.Blog `json:"data"`
+}
+
+// rejectingUpdates fails every UpdateBlog with err.
+type rejectingUpdates struct {
+	ports.BlogService
+	err error
+}
+
+func (s rejectingUpdates) UpdateBlog(ctx context.Context, blog *domain.Blog) error {
+	return s.err
+}
+
+func TestUpdateBlog(t *testing.T) {
+	store := repositories.NewMemoryStore()
+	blogService := services.NewBlogService(store.Blogs(), services.WithUnitOfWork(store.UnitOfWork()))
+	require.NoError(t, blogService.CreateBlog(context.Background(), &domain.Blog{
+		Title: "Original title", Content: "Original content", Author: "alice",
+	}))
+	update := func(t *testing.T, service ports.BlogService, body string) (int, blogBody) {
+		app := fiber.New()
+		app.Put("/api/v1/blogs/:id", handlers.NewBlogHandler(service).UpdateBlog)
+		req := httptest.NewRequest("PUT", "/api/v1/blogs/1", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+
+		var decoded blogBody
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&decoded))
+		return resp.StatusCode, decoded
+	}
+
+	t.Run("Tags", func(t *testing.T) {
+		status, body := update(t, blogService, `{"tags": ["Go", "Web Dev"]}`)
+
+		require.Equal(t, fiber.StatusOK, status)
+		assert.Equal(t, []string{"go", "web-dev"}, body.Data.Tags)
+	})
+
+	t.Run("InvalidTagsAreBadRequests", func(t *testing.T) {
+		service := rejectingUpdates{BlogService: blogService, err: errors.NewInvalidInputError("a blog can have at most 10 tags")}
+
+		status, body := update(t, service, `{"tags": ["go"]}`)
+
+		assert.Equal(t, fiber.StatusBadRequest, status)
+		assert.Equal(t, "a blog can have at most 10 tags", body.Message)
+	})
+
+	t.Run("BlogDeletedMeanwhile", func(t *testing.T) {
+		service := rejectingUpdates{BlogService: blogService, err: errors.NewNotFoundError("Blog with ID 1 not found")}
+
+		status, _ := update(t, service, `{"title": "New title"}`)
+
+		assert.Equal(t, fiber.StatusNotFound, status)
+	})
+}
+
+func TestPatchBlog(t *testing.T) {
+	setup := func(t *testing.T) *fiber.App {
+		store := repositories.NewMemoryStore()
+		blogService := services.NewBlogService(store.Blogs(), services.WithUnitOfWork(store.UnitOfWork()))
+		require.NoError(t, blogService.CreateBlog(context.Background(), &domain.Blog{
+			Title: "Original title", Content: "Original content", Author: "alice",
+		}))
+
+		app := fiber.New()
+		app.Patch("/api/v1/blogs/:id", handlers.NewBlogHandler(blogService).PatchBlog)
+		return app
+	}
+	patch := func(t *testing.T, app *fiber.App, contentType, body string) (int, blogBody) {
+		req := httptest.NewRequest("PATCH", "/api/v1/blogs/1", strings.NewReader(body))
+		req.Header.Set("Content-Type", contentType)
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+
+		var decoded blogBody
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&decoded))
+		return resp.StatusCode, decoded
+	}
+
+	t.Run("MergePatch", func(t *testing.T) {
+		status, body := patch(t, setup(t), handlers.MIMEMergePatch, `{"title": "New title", "content_format": null}`)
+
+		require.Equal(t, fiber.StatusOK, status)
+		assert.Equal(t, "New title", body.Data.Title)
+		assert.Equal(t, domain.DefaultContentFormat, body.Data.ContentFormat)
+		assert.Equal(t, "alice", body.Data.Author)
+	})
+
+	t.Run("MergePatchCannotClearRequiredField", func(t *testing.T) {
+		app := setup(t)
+
+		for _, field := range []string{"title", "content", "author"} {
+			status, _ := patch(t, app, handlers.MIMEMergePatch, fmt.Sprintf(`{%q: null}`, field))
+			assert.Equal(t, fiber.StatusUnprocessableEntity, status, field)
+		}
+
+		_, body := patch(t, app, handlers.MIMEMergePatch, `{}`)
+		assert.Equal(t, "Original title", body.Data.Title)
+	})
+
+	t.Run("MergePatchRejectsReadOnlyField", func(t *testing.T) {
+		status, body := patch(t, setup(t), handlers.MIMEMergePatch, `{"id": 7}`)
+
+		assert.Equal(t, fiber.StatusBadRequest, status)
+		assert.Contains(t, body.Message, "id")
+	})
+
+	t.Run("MergePatchValidatesValues", func(t *testing.T) {
+		status, _ := patch(t, setup(t), "application/json; charset=utf-8", `{"title": "x"}`)
+
+		assert.Equal(t, fiber.StatusBadRequest, status)
+	})
+
+	t.Run("JSONPatch", func(t *testing.T) {
+		status, body := patch(t, setup(t), handlers.MIMEJSONPatch, `[
+			{"op": "test", "path": "/author", "value": "alice"},
+			{"op": "copy", "from": "/title", "path": "/content"},
+			{"op": "replace", "path": "/author", "value": "bob"}
+		]`)
+
+		require.Equal(t, fiber.StatusOK, status)
+		assert.Equal(t, "Original title", body.Data.Content)
+		assert.Equal(t, "bob", body.Data.Author)
+	})
+
+	t.Run("JSONPatchCannotClearRequiredField", func(t *testing.T) {
+		app := setup(t)
+
+		for _, ops := range []string{
+			`[{"op": "remove", "path": "/author"}]`,
+			`[{"op": "replace", "path": "/title", "value": ""}]`,
+			`[{"op": "move", "from": "/title", "path": "/content"}]`,
+		} {
+			status, _ := patch(t, app, handlers.MIMEJSONPatch, ops)
+			assert.Equal(t, fiber.StatusUnprocessableEntity, status, ops)
+		}
+
+		_, body := patch(t, app, handlers.MIMEMergePatch, `{}`)
+		assert.Equal(t, "Original title", body.Data.Title)
+		assert.Equal(t, "alice", body.Data.Author)
+	})
+
+	t.Run("JSONPatchValidatesCopiedValues", func(t *testing.T) {
+		app := setup(t)
+
+		// An author of five letters is too short for content, and content is
+		// too long for a title.
+		for _, ops := range []string{
+			`[{"op": "copy", "from": "/author", "path": "/content"}]`,
+			`[{"op": "replace", "path": "/content", "value": "` + strings.Repeat("x", 101) + `"},
+			  {"op": "copy", "from": "/content", "path": "/title"}]`,
+		} {
+			status, body := patch(t, app, handlers.MIMEJSONPatch, ops)
+			assert.Equal(t, fiber.StatusUnprocessableEntity, status, ops)
+			assert.NotEmpty(t, body.Message)
+		}
+
+		_, body := patch(t, app, handlers.MIMEMergePatch, `{}`)
+		assert.Equal(t, "Original content", body.Data.Content)
+	})
+
+	t.Run("JSONPatchFailedTestChangesNothing", func(t *testing.T) {
+		app := setup(t)
+
+		status, _ := patch(t, app, handlers.MIMEJSONPatch, `[
+			{"op": "remove", "path": "/content"},
+			{"op": "test", "path": "/author", "value": "bob"}
+		]`)
+		assert.Equal(t, fiber.StatusConflict, status)
+
+		_, body := patch(t, app, handlers.MIMEMergePatch, `{}`)
+		assert.Equal(t, "Original content", body.Data.Content)
+	})
+
+	t.Run("UnsupportedMediaType", func(t *testing.T) {
+		req := httptest.NewRequest("PATCH", "/api/v1/blogs/1", strings.NewReader("title=x"))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		resp, err := setup(t).Test(req)
+		require.NoError(t, err)
+
+		assert.Equal(t, fiber.StatusUnsupportedMediaType, resp.StatusCode)
+		assert.Contains(t, resp.Header.Get("Accept-Patch"), handlers.MIMEJSONPatch)
+	})
+}
+
+func TestConditionalGet(t *testing.T) {
+	setup := func(t *testing.T) (*fiber.App, ports.BlogService) {
+		store := repositories.NewMemoryStore()
+		blogService := services.NewBlogService(store.Blogs(), services.WithUnitOfWork(store.UnitOfWork()))
+		for _, title := range []string{"First title", "Second title"} {
+			require.NoError(t, blogService.CreateBlog(context.Background(), &domain.Blog{
+				Title: title, Content: "Some content", Author: "alice",
+			}))
+		}
+
+		h := handlers.NewBlogHandler(blogService, handlers.WithCachePolicy(func() handlers.CachePolicy {
+			return handlers.CachePolicy{GetBlog: "public, max-age=60", ListBlogs: "no-cache", Vary: []string{"X-API-Key"}}
+		}))
+		app := fiber.New()
+		app.Get("/api/v1/blogs/:id", h.GetBlog)
+		app.Get("/api/v1/blogs", h.ListBlogs)
+		return app, blogService
+	}
+	get := func(t *testing.T, app *fiber.App, target string, header http.Header) *http.Response {
+		req := httptest.NewRequest("GET", target, nil)
+		for name, values := range header {
+			req.Header[name] = values
+		}
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		return resp
+	}
+
+	t.Run("SendsValidators", func(t *testing.T) {
+		app, _ := setup(t)
+		resp := get(t, app, "/api/v1/blogs/1", nil)
+
+		require.Equal(t, fiber.StatusOK, resp.StatusCode)
+		assert.True(t, strings.HasPrefix(resp.Header.Get("ETag"), `W/"`))
+		_, err := http.ParseTime(resp.Header.Get("Last-Modified"))
+		assert.NoError(t, err)
+		assert.Equal(t, "public, max-age=60", resp.Header.Get("Cache-Control"))
+		assert.Equal(t, "X-API-Key, Accept", resp.Header.Get("Vary"))
+	})
+
+	t.Run("IfNoneMatch", func(t *testing.T) {
+		app, blogService := setup(t)
+		etag := get(t, app, "/api/v1/blogs/1", nil).Header.Get("ETag")
+
+		resp := get(t, app, "/api/v1/blogs/1", http.Header{"If-None-Match": {`"other", ` + etag}})
+		assert.Equal(t, fiber.StatusNotModified, resp.StatusCode)
+		assert.Equal(t, etag, resp.Header.Get("ETag"))
+		assert.Equal(t, "public, max-age=60", resp.Header.Get("Cache-Control"))
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		assert.Empty(t, body)
+
+		time.Sleep(time.Millisecond)
+		require.NoError(t, blogService.UpdateBlog(context.Background(), &domain.Blog{
+			ID: 1, Title: "Changed title", Content: "Some content", Author: "alice",
+		}))
+		resp = get(t, app, "/api/v1/blogs/1", http.Header{"If-None-Match": {etag}})
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+		assert.NotEqual(t, etag, resp.Header.Get("ETag"))
+	})
+
+	t.Run("IfModifiedSince", func(t *testing.T) {
+		app, _ := setup(t)
+		lastModified := get(t, app, "/api/v1/blogs/1", nil).Header.Get("Last-Modified")
+
+		resp := get(t, app, "/api/v1/blogs/1", http.Header{"If-Modified-Since": {lastModified}})
+		assert.Equal(t, fiber.StatusNotModified, resp.StatusCode)
+
+		earlier := time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat)
+		resp = get(t, app, "/api/v1/blogs/1", http.Header{"If-Modified-Since": {earlier}})
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+		// If-None-Match takes precedence.
+		resp = get(t, app, "/api/v1/blogs/1", http.Header{"If-None-Match": {`"other"`}, "If-Modified-Since": {lastModified}})
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	})
+
+	t.Run("ListChangesOnDelete", func(t *testing.T) {
+		app, blogService := setup(t)
+		resp := get(t, app, "/api/v1/blogs", nil)
+		etag, lastModified := resp.Header.Get("ETag"), resp.Header.Get("Last-Modified")
+		assert.Equal(t, "no-cache", resp.Header.Get("Cache-Control"))
+
+		resp = get(t, app, "/api/v1/blogs", http.Header{"If-None-Match": {etag}})
+		assert.Equal(t, fiber.StatusNotModified, resp.StatusCode)
+
+		require.NoError(t, blogService.DeleteBlog(context.Background(), 1))
+		resp = get(t, app, "/api/v1/blogs", http.Header{"If-None-Match": {etag}})
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+		resp = get(t, app, "/api/v1/blogs", http.Header{"If-Modified-Since": {lastModified}})
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	})
+}
+
+func TestNotAcceptableBeforeChange(t *testing.T) {
+	store := repositories.NewMemoryStore()
+	blogService := services.NewBlogService(store.Blogs())
+	app := fiber.New()
+	app.Use(handlers.NotAcceptable())
+	app.Post("/api/v1/blogs", handlers.NewBlogHandler(blogService).CreateBlog)
+
+	req := httptest.NewRequest("POST", "/api/v1/blogs", strings.NewReader(`{"title": "Title", "content": "Some content", "author": "alice"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "text/csv")
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+
+	assert.Equal(t, fiber.StatusNotAcceptable, resp.StatusCode)
+	blogs, err := blogService.ListBlogs(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, blogs)
+}
+
+func TestRenderHTML(t *testing.T) {
+	store := repositories.NewMemoryStore()
+	blogService := services.NewBlogService(store.Blogs(), services.WithRenderer(render.NewRenderer()))
+	h := handlers.NewBlogHandler(blogService)
+	app := fiber.New()
+	app.Post("/api/v1/blogs", h.CreateBlog)
+	app.Get("/api/v1/blogs/:id", h.GetBlog)
+	app.Get("/api/v1/blogs", h.ListBlogs)
+	do := func(t *testing.T, method, target, body string) (*http.Response, []byte) {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		data, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return resp, data
+	}
+
+	resp, _ := do(t, "POST", "/api/v1/blogs", `{"title": "Title", "content": "Some **bold** <script>x</script>", "author": "alice"}`)
+	require.Equal(t, fiber.StatusCreated, resp.StatusCode)
+
+	t.Run("Source", func(t *testing.T) {
+		resp, data := do(t, "GET", "/api/v1/blogs/1", "")
+
+		require.Equal(t, fiber.StatusOK, resp.StatusCode)
+		var body blogBody
+		require.NoError(t, json.Unmarshal(data, &body))
+		assert.Equal(t, domain.ContentMarkdown, body.Data.ContentFormat)
+		assert.Empty(t, body.Data.RenderedHTML)
+	})
+
+	t.Run("Rendered", func(t *testing.T) {
+		source, _ := do(t, "GET", "/api/v1/blogs/1", "")
+		resp, data := do(t, "GET", "/api/v1/blogs/1?render=html", "")
+
+		require.Equal(t, fiber.StatusOK, resp.StatusCode)
+		var body blogBody
+		require.NoError(t, json.Unmarshal(data, &body))
+		assert.Equal(t, "<p>Some <strong>bold</strong> </p>\n", body.Data.RenderedHTML)
+		assert.NotEqual(t, source.Header.Get("ETag"), resp.Header.Get("ETag"))
+	})
+
+	t.Run("RenderedList", func(t *testing.T) {
+		resp, data := do(t, "GET", "/api/v1/blogs?render=html", "")
+
+		require.Equal(t, fiber.StatusOK, resp.StatusCode)
+		var body struct {
+			Data []domain.Blog `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(data, &body))
+		require.Len(t, body.Data, 1)
+		assert.Equal(t, "<p>Some <strong>bold</strong> </p>\n", body.Data[0].RenderedHTML)
+	})
+
+	t.Run("UnknownRender", func(t *testing.T) {
+		resp, _ := do(t, "GET", "/api/v1/blogs/1?render=pdf", "")
+
+		assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("UnknownFormat", func(t *testing.T) {
+		resp, _ := do(t, "POST", "/api/v1/blogs", `{"title": "Title", "content": "Some content", "content_format": "rst", "author": "alice"}`)
+
+		assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+	})
+}
+
+func TestListSummaryView(t *testing.T) {
+	store := repositories.NewMemoryStore()
+	blogService := services.NewBlogService(store.Blogs(), services.WithSummary(services.SummaryConfig{Analyzer: render.NewRenderer()}))
+	require.NoError(t, blogService.CreateBlog(context.Background(), &domain.Blog{
+		Title: "Title", Content: "## Start\n\nSome **bold** words", Author: "alice",
+	}))
+	h := handlers.NewBlogHandler(blogService)
+	app := fiber.New()
+	app.Get("/api/v1/blogs", h.ListBlogs)
+	get := func(t *testing.T, target string) (*http.Response, []byte) {
+		resp, err := app.Test(httptest.NewRequest("GET", target, nil))
+		require.NoError(t, err)
+		data, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return resp, data
+	}
+
+	t.Run("Summary", func(t *testing.T) {
+		resp, data := get(t, "/api/v1/blogs?view=summary")
+
+		require.Equal(t, fiber.StatusOK, resp.StatusCode)
+		var body struct {
+			Data []map[string]interface{} `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(data, &body))
+		require.Len(t, body.Data, 1)
+		assert.NotContains(t, body.Data[0], "content")
+		assert.Equal(t, "Some bold words", body.Data[0]["excerpt"])
+		assert.Equal(t, float64(4), body.Data[0]["word_count"])
+		assert.Equal(t, []interface{}{map[string]interface{}{"level": float64(2), "text": "Start", "id": "start"}}, body.Data[0]["toc"])
+	})
+
+	t.Run("UnknownView", func(t *testing.T) {
+		resp, _ := get(t, "/api/v1/blogs?view=tiny")
+
+		assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("SummaryIsTheDefault", func(t *testing.T) {
+		resp, data := get(t, "/api/v1/blogs")
+
+		require.Equal(t, fiber.StatusOK, resp.StatusCode)
+		assert.NotContains(t, string(data), `"content"`)
+		assert.Contains(t, string(data), `"excerpt"`)
+	})
+
+	t.Run("Full", func(t *testing.T) {
+		resp, data := get(t, "/api/v1/blogs?view=full")
+
+		require.Equal(t, fiber.StatusOK, resp.StatusCode)
+		assert.Contains(t, string(data), `"content"`)
+	})
+}
+
+func TestSparseFieldsets(t *testing.T) {
+	store := repositories.NewMemoryStore()
+	blogService := services.NewBlogService(store.Blogs(), services.WithRenderer(render.NewRenderer()))
+	blog := &domain.Blog{Title: "Title", Content: "Some *words*", Author: "alice"}
+	require.NoError(t, blogService.CreateBlog(context.Background(), blog))
+	h := handlers.NewBlogHandler(blogService)
+	app := fiber.New()
+	app.Get("/api/v1/blogs", h.ListBlogs)
+	app.Get("/api/v1/blogs/:id", h.GetBlog)
+	get := func(t *testing.T, target string) (*http.Response, map[string]json.RawMessage) {
+		resp, err := app.Test(httptest.NewRequest("GET", target, nil))
+		require.NoError(t, err)
+		var body map[string]json.RawMessage
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+		return resp, body
+	}
+	keys := func(t *testing.T, data json.RawMessage) []string {
+		var fields map[string]interface{}
+		require.NoError(t, json.Unmarshal(data, &fields))
+		var names []string
+		for name := range fields {
+			names = append(names, name)
+		}
+		return names
+	}
+	target := fmt.Sprintf("/api/v1/blogs/%d", blog.ID)
+
+	t.Run("Get", func(t *testing.T) {
+		resp, body := get(t, target+"?fields=title,author")
+
+		require.Equal(t, fiber.StatusOK, resp.StatusCode)
+		assert.ElementsMatch(t, []string{"title", "author"}, keys(t, body["data"]))
+	})
+
+	t.Run("GetRendered", func(t *testing.T) {
+		resp, body := get(t, target+"?fields=title&render=html")
+
+		require.Equal(t, fiber.StatusOK, resp.StatusCode)
+		assert.ElementsMatch(t, []string{"title", "rendered_html"}, keys(t, body["data"]))
+		assert.Contains(t, string(body["data"]), "words")
+	})
+
+	t.Run("List", func(t *testing.T) {
+		resp, body := get(t, "/api/v1/blogs?fields=id,word_count")
+
+		require.Equal(t, fiber.StatusOK, resp.StatusCode)
+		var blogs []json.RawMessage
+		require.NoError(t, json.Unmarshal(body["data"], &blogs))
+		require.Len(t, blogs, 1)
+		assert.ElementsMatch(t, []string{"id", "word_count"}, keys(t, blogs[0]))
+	})
+
+	t.Run("ListRenderedSummary", func(t *testing.T) {
+		resp, body := get(t, "/api/v1/blogs?render=html")
+
+		require.Equal(t, fiber.StatusOK, resp.StatusCode)
+		assert.Contains(t, string(body["data"]), "rendered_html")
+		assert.NotContains(t, string(body["data"]), `"content"`)
+	})
+
+	t.Run("UnknownField", func(t *testing.T) {
+		resp, body := get(t, target+"?fields=title,password")
+
+		assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+		assert.Contains(t, string(body["message"]), "password")
+	})
+
+	t.Run("ViewAndFields", func(t *testing.T) {
+		resp, _ := get(t, "/api/v1/blogs?view=full&fields=title")
+
+		assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+	})
+}
@@ -0,0 +1,140 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/toffysoft/go-hexagonal-example/internal/core/domain"
+	"github.com/toffysoft/go-hexagonal-example/pkg/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// CachePolicy sets the Cache-Control header sent with each cacheable route,
+// e.g. "public, max-age=60", and the request headers that select between
+// representations, which are sent as Vary. An empty directive sends no
+// Cache-Control.
+type CachePolicy struct {
+	GetBlog   string
+	ListBlogs string
+	Vary      []string
+}
+
+// validators identify the version of a representation, so that a client or
+// cache holding it can revalidate without downloading it again.
+type validators struct {
+	// version hashes the versions of the blogs in the response.
+	version      hash.Hash
+	lastModified time.Time
+	list         bool
+	// exactDates is false when lastModified can stay the same across a
+	// change, in which case If-Modified-Since is not evaluated.
+	exactDates bool
+	// variant names request options that change the representation, such
+	// as rendering the content.
+	variant string
+}
+
+// blogValidators derives the validators of a blog from its UpdatedAt, which
+// changes on every write.
+func blogValidators(blog *domain.Blog) validators {
+	h := sha256.New()
+	writeVersion(h, blog)
+	return validators{version: h, lastModified: blog.UpdatedAt, exactDates: true}
+}
+
+// listValidators derives the validators of a list of blogs. The ETag covers
+// every blog in order, so it also changes when one is deleted; Last-Modified,
+// the latest UpdatedAt, does not, and is only sent for information.
+func listValidators(blogs []*domain.Blog) validators {
+	h := sha256.New()
+	var lastModified time.Time
+	for _, blog := range blogs {
+		writeVersion(h, blog)
+		if blog.UpdatedAt.After(lastModified) {
+			lastModified = blog.UpdatedAt
+		}
+	}
+	return validators{version: h, lastModified: lastModified, list: true}
+}
+
+func writeVersion(h hash.Hash, blog *domain.Blog) {
+	fmt.Fprintf(h, "%d:%d;", blog.ID, blog.UpdatedAt.UnixNano())
+}
+
+// entityTag returns the ETag of the version in a representation. It is weak
+// because it names a version rather than particular bytes.
+func (v validators) entityTag(format string) string {
+	v.version.Write([]byte(format))
+	if v.variant != "" {
+		v.version.Write([]byte(";" + v.variant))
+	}
+	return `W/"` + hex.EncodeToString(v.version.Sum(nil)[:16]) + `"`
+}
+
+// conditional sets the validators and caching headers of a response and
+// reports whether the request already holds the current version, in which
+// case it has been answered with 304 Not Modified and no body.
+func conditional(c *fiber.Ctx, cacheControl string, v validators) bool {
+	format := utils.Negotiate(c, v.list)
+	if format == "" {
+		// Left to the response, which is 406 Not Acceptable.
+		return false
+	}
+	c.Vary(fiber.HeaderAccept)
+	return revalidate(c, cacheControl, v, format)
+}
+
+// revalidate is conditional for a response whose format does not depend on
+// Accept.
+func revalidate(c *fiber.Ctx, cacheControl string, v validators, format string) bool {
+	etag := v.entityTag(format)
+	c.Set(fiber.HeaderETag, etag)
+	if !v.lastModified.IsZero() {
+		c.Set(fiber.HeaderLastModified, v.lastModified.UTC().Format(http.TimeFormat))
+	}
+	if cacheControl != "" {
+		c.Set(fiber.HeaderCacheControl, cacheControl)
+	}
+	if !notModified(c, etag, v) {
+		return false
+	}
+	c.Status(fiber.StatusNotModified)
+	return true
+}
+
+// notModified evaluates If-None-Match and If-Modified-Since in the order of
+// RFC 9110 section 13.2.2: If-Modified-Since only counts without
+// If-None-Match.
+func notModified(c *fiber.Ctx, etag string, v validators) bool {
+	if header := c.Get(fiber.HeaderIfNoneMatch); header != "" {
+		return etagMatches(header, etag)
+	}
+	header := c.Get(fiber.HeaderIfModifiedSince)
+	if header == "" || !v.exactDates || v.lastModified.IsZero() {
+		return false
+	}
+	since, err := http.ParseTime(header)
+	if err != nil {
+		return false
+	}
+	// HTTP dates have whole seconds.
+	return !v.lastModified.Truncate(time.Second).After(since)
+}
+
+// etagMatches reports whether any tag listed in an If-None-Match header
+// weakly matches etag.
+func etagMatches(header, etag string) bool {
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || strings.TrimPrefix(tag, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
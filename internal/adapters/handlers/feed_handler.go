@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+
+	"github.com/toffysoft/go-hexagonal-example/internal/adapters/syndication"
+	"github.com/toffysoft/go-hexagonal-example/internal/core/domain"
+	"github.com/toffysoft/go-hexagonal-example/internal/core/ports"
+	"github.com/toffysoft/go-hexagonal-example/pkg/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// feedFields are the fields of the blogs listed in feeds.
+var feedFields = domain.FieldMask{
+	domain.FieldID, domain.FieldTitle, domain.FieldAuthor, domain.FieldTags,
+	domain.FieldExcerpt, domain.FieldCreatedAt, domain.FieldUpdatedAt,
+}
+
+// FeedConfig describes the feeds of the blog. Links are resolved against
+// BaseURL, or the URL the request was made to when it is empty; ItemPath
+// names a blog with {id} and optionally {slug}. Feeds hold the Limit newest
+// blogs, or up to MaxLimit with the limit query parameter, with their
+// content as HTML when FullContent is set and their excerpt otherwise.
+type FeedConfig struct {
+	Title        string
+	Description  string
+	BaseURL      string
+	ItemPath     string
+	Limit        int
+	MaxLimit     int
+	FullContent  bool
+	CacheControl string
+}
+
+type FeedHandler struct {
+	blogService ports.BlogService
+	cfg         FeedConfig
+}
+
+func NewFeedHandler(blogService ports.BlogService, cfg FeedConfig) *FeedHandler {
+	return &FeedHandler{blogService: blogService, cfg: cfg}
+}
+
+// RSS sends the feed as RSS 2.0. Under a route with an :author or :tag
+// parameter, the feed only has the blogs of that author or with that tag.
+func (h *FeedHandler) RSS(c *fiber.Ctx) error {
+	return h.sendFeed(c, syndication.RSS)
+}
+
+// Atom sends the feed as Atom, like RSS.
+func (h *FeedHandler) Atom(c *fiber.Ctx) error {
+	return h.sendFeed(c, syndication.Atom)
+}
+
+// JSONFeed sends the feed as JSON Feed, like RSS.
+func (h *FeedHandler) JSONFeed(c *fiber.Ctx) error {
+	return h.sendFeed(c, syndication.JSONFeed)
+}
+
+func (h *FeedHandler) sendFeed(c *fiber.Ctx, format syndication.Format) error {
+	limit := h.cfg.Limit
+	if value := c.Query("limit"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 || n > h.cfg.MaxLimit {
+			return utils.SendErrorResponse(c, fiber.StatusBadRequest, fmt.Sprintf("limit must be between 1 and %d", h.cfg.MaxLimit))
+		}
+		limit = n
+	}
+	var filter domain.BlogFilter
+	var err error
+	if filter.Author, err = url.PathUnescape(c.Params("author")); err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid author")
+	}
+	tag, err := url.PathUnescape(c.Params("tag"))
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid tag")
+	}
+	filter.Tag = domain.Slugify(tag)
+
+	mask := feedFields
+	if h.cfg.FullContent {
+		mask = mask.With(domain.FieldRenderedHTML)
+	}
+	blogs, err := h.blogService.ListLatestBlogs(c.UserContext(), filter, mask, limit)
+	if err != nil {
+		return sendError(c, err, "Failed to retrieve blogs")
+	}
+	if len(blogs) == 0 && filter.Author != "" {
+		return utils.SendErrorResponse(c, fiber.StatusNotFound, fmt.Sprintf("No blogs by %s", filter.Author))
+	}
+	if len(blogs) == 0 && filter.Tag != "" {
+		return utils.SendErrorResponse(c, fiber.StatusNotFound, fmt.Sprintf("No blogs tagged %s", filter.Tag))
+	}
+	if revalidate(c, h.cfg.CacheControl, listValidators(blogs), format.ContentType()) {
+		return nil
+	}
+
+	base := h.cfg.BaseURL
+	if base == "" {
+		base = c.BaseURL()
+	}
+	feed := &syndication.Feed{
+		Title:       h.cfg.Title,
+		Description: h.cfg.Description,
+		Link:        syndication.Resolve(base, "/"),
+		FeedURL:     syndication.Resolve(base, c.OriginalURL()),
+		Items:       make([]syndication.Item, len(blogs)),
+	}
+	switch {
+	case filter.Author != "":
+		feed.Title = fmt.Sprintf("%s: posts by %s", h.cfg.Title, filter.Author)
+		feed.Description = fmt.Sprintf("The latest posts by %s", filter.Author)
+	case filter.Tag != "":
+		feed.Title = fmt.Sprintf("%s: posts tagged %s", h.cfg.Title, filter.Tag)
+		feed.Description = fmt.Sprintf("The latest posts tagged %s", filter.Tag)
+	}
+	for i, blog := range blogs {
+		feed.Items[i] = syndication.NewItem(blog, base, h.cfg.ItemPath)
+	}
+	data, err := syndication.Encode(feed, format)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to encode feed")
+	}
+	c.Set(fiber.HeaderContentType, format.ContentType())
+	return c.Send(data)
+}
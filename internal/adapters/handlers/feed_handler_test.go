@@ -0,0 +1,192 @@
+package handlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/toffysoft/go-hexagonal-example/internal/adapters/handlers"
+	"github.com/toffysoft/go-hexagonal-example/internal/adapters/render"
+	"github.com/toffysoft/go-hexagonal-example/internal/adapters/repositories"
+	"github.com/toffysoft/go-hexagonal-example/internal/core/domain"
+	"github.com/toffysoft/go-hexagonal-example/internal/core/services"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newFeedApp(t *testing.T) *fiber.App {
+	store := repositories.NewMemoryStore()
+	renderer := render.NewRenderer()
+	blogService := services.NewBlogService(store.Blogs(), services.WithRenderer(renderer),
+		services.WithSummary(services.SummaryConfig{Analyzer: renderer}))
+	for _, blog := range []*domain.Blog{
+		{Title: "First post", Content: "The *first* post", Author: "alice", Tags: []string{"Go", "news"}},
+		{Title: "Second post", Content: "The second post", Author: "bob", Tags: []string{"go"}},
+		{Title: "Third post", Content: "The third post", Author: "alice"},
+	} {
+		require.NoError(t, blogService.CreateBlog(context.Background(), blog))
+	}
+
+	h := handlers.NewFeedHandler(blogService, handlers.FeedConfig{
+		Title:        "Blog",
+		Description:  "The latest posts",
+		BaseURL:      "https://example.com",
+		ItemPath:     "/blogs/{id}/{slug}",
+		Limit:        2,
+		MaxLimit:     10,
+		FullContent:  true,
+		CacheControl: "public, max-age=300",
+	})
+	app := fiber.New()
+	app.Get("/feeds/rss.xml", h.RSS)
+	app.Get("/feeds/atom.xml", h.Atom)
+	app.Get("/feeds/feed.json", h.JSONFeed)
+	app.Get("/feeds/authors/:author/rss.xml", h.RSS)
+	app.Get("/feeds/tags/:tag/rss.xml", h.RSS)
+	return app
+}
+
+type rssFeed struct {
+	Channel struct {
+		Title string `xml:"title"`
+		Items []struct {
+			Title      string   `xml:"title"`
+			Link       string   `xml:"link"`
+			Content    string   `xml:"http://purl.org/rss/1.0/modules/content/ encoded"`
+			Creator    string   `xml:"http://purl.org/dc/elements/1.1/ creator"`
+			Categories []string `xml:"category"`
+		} `xml:"item"`
+	} `xml:"channel"`
+}
+
+func getRSS(t *testing.T, app *fiber.App, target string) rssFeed {
+	resp, err := app.Test(httptest.NewRequest("GET", target, nil))
+	require.NoError(t, err)
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	var feed rssFeed
+	require.NoError(t, xml.NewDecoder(resp.Body).Decode(&feed))
+	return feed
+}
+
+func TestFeeds(t *testing.T) {
+	app := newFeedApp(t)
+
+	t.Run("RSS", func(t *testing.T) {
+		resp, err := app.Test(httptest.NewRequest("GET", "/feeds/rss.xml", nil))
+		require.NoError(t, err)
+		assert.Equal(t, "application/rss+xml; charset=utf-8", resp.Header.Get("Content-Type"))
+		assert.Equal(t, "public, max-age=300", resp.Header.Get("Cache-Control"))
+		assert.NotEmpty(t, resp.Header.Get("Last-Modified"))
+
+		feed := getRSS(t, app, "/feeds/rss.xml")
+		require.Len(t, feed.Channel.Items, 2)
+		item := feed.Channel.Items[0]
+		assert.Equal(t, "Third post", item.Title)
+		assert.Equal(t, "https://example.com/blogs/3/third-post", item.Link)
+		assert.Equal(t, "alice", item.Creator)
+		assert.Contains(t, item.Content, "<p>The third post</p>")
+		assert.Equal(t, "Second post", feed.Channel.Items[1].Title)
+	})
+
+	t.Run("Limit", func(t *testing.T) {
+		feed := getRSS(t, app, "/feeds/rss.xml?limit=3")
+		assert.Len(t, feed.Channel.Items, 3)
+
+		for _, limit := range []string{"0", "11", "many"} {
+			resp, err := app.Test(httptest.NewRequest("GET", "/feeds/rss.xml?limit="+limit, nil))
+			require.NoError(t, err)
+			assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode, limit)
+		}
+	})
+
+	t.Run("Author", func(t *testing.T) {
+		feed := getRSS(t, app, "/feeds/authors/alice/rss.xml?limit=10")
+		assert.Equal(t, "Blog: posts by alice", feed.Channel.Title)
+		require.Len(t, feed.Channel.Items, 2)
+		assert.Equal(t, "Third post", feed.Channel.Items[0].Title)
+		assert.Equal(t, "First post", feed.Channel.Items[1].Title)
+
+		resp, err := app.Test(httptest.NewRequest("GET", "/feeds/authors/carol/rss.xml", nil))
+		require.NoError(t, err)
+		assert.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+	})
+
+	t.Run("Tag", func(t *testing.T) {
+		feed := getRSS(t, app, "/feeds/tags/go/rss.xml")
+		assert.Equal(t, "Blog: posts tagged go", feed.Channel.Title)
+		require.Len(t, feed.Channel.Items, 2)
+		assert.Equal(t, "Second post", feed.Channel.Items[0].Title)
+		assert.Equal(t, []string{"go"}, feed.Channel.Items[0].Categories)
+		assert.Equal(t, "First post", feed.Channel.Items[1].Title)
+		assert.Equal(t, []string{"go", "news"}, feed.Channel.Items[1].Categories)
+
+		// Tags are matched as slugs.
+		feed = getRSS(t, app, "/feeds/tags/News/rss.xml")
+		require.Len(t, feed.Channel.Items, 1)
+		assert.Equal(t, "First post", feed.Channel.Items[0].Title)
+
+		resp, err := app.Test(httptest.NewRequest("GET", "/feeds/tags/rust/rss.xml", nil))
+		require.NoError(t, err)
+		assert.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+	})
+
+	t.Run("Atom", func(t *testing.T) {
+		resp, err := app.Test(httptest.NewRequest("GET", "/feeds/atom.xml", nil))
+		require.NoError(t, err)
+		require.Equal(t, fiber.StatusOK, resp.StatusCode)
+		assert.Equal(t, "application/atom+xml; charset=utf-8", resp.Header.Get("Content-Type"))
+		var feed struct {
+			XMLName xml.Name `xml:"http://www.w3.org/2005/Atom feed"`
+			ID      string   `xml:"id"`
+			Entries []struct {
+				Title string `xml:"title"`
+			} `xml:"entry"`
+		}
+		require.NoError(t, xml.NewDecoder(resp.Body).Decode(&feed))
+		assert.Equal(t, "https://example.com/feeds/atom.xml", feed.ID)
+		assert.Len(t, feed.Entries, 2)
+	})
+
+	t.Run("JSONFeed", func(t *testing.T) {
+		resp, err := app.Test(httptest.NewRequest("GET", "/feeds/feed.json", nil))
+		require.NoError(t, err)
+		require.Equal(t, fiber.StatusOK, resp.StatusCode)
+		assert.Equal(t, "application/feed+json; charset=utf-8", resp.Header.Get("Content-Type"))
+		var feed struct {
+			Version string `json:"version"`
+			Items   []struct {
+				Title string `json:"title"`
+			} `json:"items"`
+		}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&feed))
+		assert.Equal(t, "https://jsonfeed.org/version/1.1", feed.Version)
+		assert.Len(t, feed.Items, 2)
+	})
+
+	t.Run("ConditionalGet", func(t *testing.T) {
+		resp, err := app.Test(httptest.NewRequest("GET", "/feeds/rss.xml", nil))
+		require.NoError(t, err)
+		etag := resp.Header.Get("ETag")
+		require.NotEmpty(t, etag)
+
+		req := httptest.NewRequest("GET", "/feeds/rss.xml", nil)
+		req.Header.Set("If-None-Match", etag)
+		resp, err = app.Test(req)
+		require.NoError(t, err)
+		assert.Equal(t, fiber.StatusNotModified, resp.StatusCode)
+		body, _ := io.ReadAll(resp.Body)
+		assert.Empty(t, body)
+
+		// Each format has its own ETag.
+		req = httptest.NewRequest("GET", "/feeds/atom.xml", nil)
+		req.Header.Set("If-None-Match", etag)
+		resp, err = app.Test(req)
+		require.NoError(t, err)
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+	})
+}
@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+
+	"github.com/toffysoft/go-hexagonal-example/internal/core/domain"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// fieldsOption reads the fields query parameter of blog reads, a comma
+// separated list of field names. ok is false when the request has none.
+func fieldsOption(c *fiber.Ctx) (mask domain.FieldMask, ok bool, err error) {
+	value := c.Query("fields")
+	if value == "" {
+		return nil, false, nil
+	}
+	mask, err = domain.ParseFieldMask(strings.Split(value, ","))
+	return mask, true, err
+}
+
+// partialBlog encodes only the fields of a mask of a blog.
+type partialBlog struct {
+	blog *domain.Blog
+	mask domain.FieldMask
+}
+
+func (p partialBlog) MarshalJSON() ([]byte, error) {
+	data, err := json.Marshal(p.blog)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	var b bytes.Buffer
+	b.WriteByte('{')
+	for _, name := range p.mask {
+		value, ok := fields[name]
+		if !ok {
+			// rendered_html is omitted when empty.
+			value = json.RawMessage(`""`)
+		}
+		if b.Len() > 1 {
+			b.WriteByte(',')
+		}
+		key, _ := json.Marshal(name)
+		b.Write(key)
+		b.WriteByte(':')
+		b.Write(value)
+	}
+	b.WriteByte('}')
+	return b.Bytes(), nil
+}
+
+// project returns what to encode of blog for a read with mask.
+func project(blog *domain.Blog, mask domain.FieldMask) interface{} {
+	if mask.All() {
+		return blog
+	}
+	return partialBlog{blog: blog, mask: mask}
+}
+
+func projectAll(blogs []*domain.Blog, mask domain.FieldMask) interface{} {
+	if mask.All() {
+		return blogs
+	}
+	projected := make([]partialBlog, len(blogs))
+	for i, blog := range blogs {
+		projected[i] = partialBlog{blog: blog, mask: mask}
+	}
+	return projected
+}
@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"log/slog"
+
+	"github.com/toffysoft/go-hexagonal-example/internal/core/domain"
+	"github.com/toffysoft/go-hexagonal-example/internal/core/ports"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	// HeaderIdempotencyKey carries the client's idempotency key.
+	HeaderIdempotencyKey = "Idempotency-Key"
+	// HeaderIdempotentReplayed marks a response replayed from an earlier request.
+	HeaderIdempotentReplayed = "Idempotent-Replayed"
+)
+
+// Idempotency makes POST requests that carry an Idempotency-Key header safe to
+// retry: the first request with a key is processed and its response stored,
+// repeats get the stored response. Reusing a key for a different request is
+// rejected with 422, and a repeat that arrives while the first request is
+// still in progress with 409. Server errors are not stored, so the request
+// can be retried with the same key.
+func Idempotency(service ports.IdempotencyService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		key := c.Get(HeaderIdempotencyKey)
+		if c.Method() != fiber.MethodPost || key == "" {
+			return c.Next()
+		}
+
+		ctx := c.UserContext()
+		record, err := service.Begin(ctx, "http:"+callerScope(c.Get("X-API-Key")), key, requestFingerprint(c))
+		if err != nil {
+			return sendError(c, err, "Failed to check idempotency key")
+		}
+		if record.Completed {
+			c.Set(HeaderIdempotentReplayed, "true")
+			c.Set(fiber.HeaderContentType, record.ContentType)
+			return c.Status(record.StatusCode).Send(record.Body)
+		}
+
+		// The outcome is recorded even if the client went away meanwhile.
+		ctx = context.WithoutCancel(ctx)
+		err = c.Next()
+		resp := c.Response()
+		if err != nil || resp.StatusCode() >= fiber.StatusInternalServerError || resp.IsBodyStream() {
+			if releaseErr := service.Release(ctx, record); releaseErr != nil {
+				slog.Error("failed to release idempotency key", "error", releaseErr)
+			}
+			return err
+		}
+		response := domain.IdempotentResponse{
+			StatusCode:  resp.StatusCode(),
+			ContentType: string(resp.Header.ContentType()),
+			Body:        bytes.Clone(resp.Body()),
+		}
+		if err := service.Complete(ctx, record, response); err != nil {
+			slog.Error("failed to store idempotent response", "error", err)
+		}
+		return nil
+	}
+}
+
+// callerScope separates the keys of different API keys, without storing them.
+func callerScope(apiKey string) string {
+	if apiKey == "" {
+		return "anonymous"
+	}
+	sum := sha256.Sum256([]byte(apiKey))
+	return hex.EncodeToString(sum[:8])
+}
+
+func requestFingerprint(c *fiber.Ctx) string {
+	h := sha256.New()
+	h.Write([]byte(c.Method() + " " + c.OriginalURL() + "\n"))
+	h.Write(c.Body())
+	return hex.EncodeToString(h.Sum(nil))
+}
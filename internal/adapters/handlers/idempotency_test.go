@@ -0,0 +1,102 @@
+package handlers_test
+
+import (
+	"context"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/toffysoft/go-hexagonal-example/internal/adapters/handlers"
+	"github.com/toffysoft/go-hexagonal-example/internal/adapters/repositories"
+	"github.com/toffysoft/go-hexagonal-example/internal/core/services"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIdempotency(t *testing.T) {
+	type response struct {
+		status   int
+		body     string
+		replayed string
+	}
+	setup := func(t *testing.T) (*fiber.App, *repositories.MemoryStore) {
+		store := repositories.NewMemoryStore()
+		blogService := services.NewBlogService(store.Blogs(), services.WithUnitOfWork(store.UnitOfWork()))
+		idempotencyService := services.NewIdempotencyService(repositories.NewMemoryIdempotencyStore(), services.IdempotencyConfig{
+			TTL:          time.Hour,
+			LockTimeout:  time.Minute,
+			PollInterval: time.Millisecond,
+		})
+
+		app := fiber.New()
+		app.Use(handlers.Idempotency(idempotencyService))
+		app.Post("/api/v1/blogs", handlers.NewBlogHandler(blogService).CreateBlog)
+		return app, store
+	}
+	post := func(t *testing.T, app *fiber.App, key, body string) response {
+		req := httptest.NewRequest("POST", "/api/v1/blogs", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if key != "" {
+			req.Header.Set(handlers.HeaderIdempotencyKey, key)
+		}
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		data, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return response{resp.StatusCode, string(data), resp.Header.Get(handlers.HeaderIdempotentReplayed)}
+	}
+	countBlogs := func(t *testing.T, store *repositories.MemoryStore) int {
+		blogs, err := store.Blogs().List(context.Background())
+		require.NoError(t, err)
+		return len(blogs)
+	}
+	const blog = `{"title": "Hello", "content": "First post body", "author": "alice"}`
+
+	t.Run("ReplaysRepeatedRequest", func(t *testing.T) {
+		app, store := setup(t)
+
+		first := post(t, app, "key-1", blog)
+		second := post(t, app, "key-1", blog)
+
+		assert.Equal(t, fiber.StatusCreated, first.status)
+		assert.Empty(t, first.replayed)
+		assert.Equal(t, first.status, second.status)
+		assert.Equal(t, first.body, second.body)
+		assert.Equal(t, "true", second.replayed)
+		assert.Equal(t, 1, countBlogs(t, store))
+	})
+
+	t.Run("RejectsKeyReuseWithDifferentBody", func(t *testing.T) {
+		app, store := setup(t)
+
+		post(t, app, "key-1", blog)
+		resp := post(t, app, "key-1", `{"title": "Other", "content": "Other post body", "author": "bob"}`)
+
+		assert.Equal(t, fiber.StatusUnprocessableEntity, resp.status)
+		assert.Equal(t, 1, countBlogs(t, store))
+	})
+
+	t.Run("WithoutKey", func(t *testing.T) {
+		app, store := setup(t)
+
+		post(t, app, "", blog)
+		post(t, app, "", blog)
+
+		assert.Equal(t, 2, countBlogs(t, store))
+	})
+
+	t.Run("ReplaysClientErrors", func(t *testing.T) {
+		app, _ := setup(t)
+
+		first := post(t, app, "key-1", `{"title": ""}`)
+		second := post(t, app, "key-1", `{"title": ""}`)
+
+		assert.Equal(t, fiber.StatusBadRequest, first.status)
+		assert.Equal(t, fiber.StatusBadRequest, second.status)
+		assert.Equal(t, "true", second.replayed)
+	})
+}
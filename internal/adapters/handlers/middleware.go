@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/toffysoft/go-hexagonal-example/internal/core/ports"
+	"github.com/toffysoft/go-hexagonal-example/pkg/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// SwappableHandler is a middleware whose implementation can be replaced while
+// the app is serving, e.g. to rebuild CORS or rate limiting after a config reload.
+type SwappableHandler struct {
+	handler atomic.Value
+}
+
+func NewSwappableHandler(handler fiber.Handler) *SwappableHandler {
+	s := &SwappableHandler{}
+	s.Swap(handler)
+	return s
+}
+
+// Swap replaces the handler used for subsequent requests.
+func (s *SwappableHandler) Swap(handler fiber.Handler) {
+	s.handler.Store(handler)
+}
+
+func (s *SwappableHandler) Handle(c *fiber.Ctx) error {
+	return s.handler.Load().(fiber.Handler)(c)
+}
+
+// Maintenance rejects requests with 503 and a Retry-After header while state reports maintenance mode.
+func Maintenance(state ports.MaintenanceState, retryAfter time.Duration) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		enabled, message := state()
+		if !enabled {
+			return c.Next()
+		}
+		c.Set(fiber.HeaderRetryAfter, strconv.Itoa(int(retryAfter.Seconds())))
+		return utils.SendErrorResponse(c, fiber.StatusServiceUnavailable, message)
+	}
+}
+
+// NotAcceptable responds 406 to a request that changes something when none of
+// the representations of its response is acceptable, before the change is
+// made. Reads are left to the response, which also knows whether it is a
+// list that can be CSV.
+func NotAcceptable() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		switch c.Method() {
+		case fiber.MethodGet, fiber.MethodHead, fiber.MethodOptions:
+			return c.Next()
+		}
+		if utils.Negotiate(c, false) == "" {
+			c.Vary(fiber.HeaderAccept)
+			return utils.SendNotAcceptable(c, false)
+		}
+		return c.Next()
+	}
+}
@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"log/slog"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/toffysoft/go-hexagonal-example/internal/core/domain"
+	"github.com/toffysoft/go-hexagonal-example/internal/core/ports"
+	"github.com/toffysoft/go-hexagonal-example/pkg/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// RateLimit takes a token from the caller's bucket under the policy matching
+// the request, and rejects the request with 429 and Retry-After when the
+// bucket is empty. Responses carry RateLimit-Policy, RateLimit-Limit,
+// RateLimit-Remaining and RateLimit-Reset headers. Callers are identified as
+// the policy says: by the userHeader value, by API key, or by IP, falling back
+// in that order. Only a key verified by APIKeyAuth counts, and only callers
+// with one can name a user, so RateLimit goes after it: otherwise any client
+// could get a fresh bucket by sending a new value. Users are scoped to the
+// key, so a caller cannot use up another key's buckets by naming its users.
+// If the store fails,
+// requests are let through.
+func RateLimit(limiter ports.RateLimiter, userHeader string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		policy, ok := limiter.Policy(c.Method(), c.Path())
+		if !ok {
+			return c.Next()
+		}
+		decision, err := limiter.Allow(c.UserContext(), policy, rateLimitIdentity(c, policy.Identity, userHeader))
+		if err != nil {
+			slog.Error("rate limit check failed", "error", err)
+			return c.Next()
+		}
+
+		c.Set("RateLimit-Policy", policy.Header())
+		c.Set("RateLimit-Limit", strconv.Itoa(decision.Limit))
+		c.Set("RateLimit-Remaining", strconv.Itoa(decision.Remaining))
+		c.Set("RateLimit-Reset", ceilSeconds(decision.Reset))
+		if !decision.Allowed {
+			c.Set(fiber.HeaderRetryAfter, ceilSeconds(decision.RetryAfter))
+			return utils.SendErrorResponse(c, fiber.StatusTooManyRequests, "Too many requests")
+		}
+		return c.Next()
+	}
+}
+
+func rateLimitIdentity(c *fiber.Ctx, identity domain.RateLimitIdentity, userHeader string) string {
+	key := verifiedAPIKey(c)
+	if identity != domain.IdentityIP && key != "" {
+		if identity == domain.IdentityUser {
+			if user := c.Get(userHeader); user != "" {
+				return "user:" + callerScope(key) + ":" + user
+			}
+		}
+		return "key:" + callerScope(key)
+	}
+	return "ip:" + c.IP()
+}
+
+func ceilSeconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
@@ -0,0 +1,115 @@
+package handlers_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/toffysoft/go-hexagonal-example/internal/adapters/handlers"
+	"github.com/toffysoft/go-hexagonal-example/internal/adapters/repositories"
+	"github.com/toffysoft/go-hexagonal-example/internal/core/domain"
+	"github.com/toffysoft/go-hexagonal-example/internal/core/services"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimit(t *testing.T) {
+	setupWithKeys := func(t *testing.T, keys []string, specs ...string) *fiber.App {
+		var policies []domain.RateLimitPolicy
+		for _, spec := range specs {
+			policy, err := domain.ParseRateLimitPolicy(spec)
+			require.NoError(t, err)
+			policies = append(policies, policy)
+		}
+		limiter := services.NewRateLimiter(repositories.NewMemoryRateLimitStore(), policies...)
+
+		app := fiber.New()
+		if keys != nil {
+			app.Use(handlers.APIKeyAuth(keys))
+		}
+		app.Use(handlers.RateLimit(limiter, "X-User-ID"))
+		app.All("/*", func(c *fiber.Ctx) error { return c.SendStatus(fiber.StatusNoContent) })
+		return app
+	}
+	setup := func(t *testing.T, specs ...string) *fiber.App {
+		return setupWithKeys(t, nil, specs...)
+	}
+	send := func(t *testing.T, app *fiber.App, method, path string, headers map[string]string) *http.Response {
+		req := httptest.NewRequest(method, path, nil)
+		for name, value := range headers {
+			req.Header.Set(name, value)
+		}
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		return resp
+	}
+
+	t.Run("RejectsWhenBucketIsEmpty", func(t *testing.T) {
+		app := setup(t, "/api 2/1m")
+
+		first := send(t, app, "GET", "/api/v1/blogs", nil)
+		assert.Equal(t, fiber.StatusNoContent, first.StatusCode)
+		assert.Equal(t, "2;w=60;burst=2", first.Header.Get("RateLimit-Policy"))
+		assert.Equal(t, "2", first.Header.Get("RateLimit-Limit"))
+		assert.Equal(t, "1", first.Header.Get("RateLimit-Remaining"))
+		assert.Equal(t, "30", first.Header.Get("RateLimit-Reset"))
+
+		send(t, app, "GET", "/api/v1/blogs", nil)
+		limited := send(t, app, "GET", "/api/v1/blogs", nil)
+		assert.Equal(t, fiber.StatusTooManyRequests, limited.StatusCode)
+		assert.Equal(t, "0", limited.Header.Get("RateLimit-Remaining"))
+		assert.Equal(t, "30", limited.Header.Get(fiber.HeaderRetryAfter))
+	})
+
+	t.Run("SeparatesRoutesAndIdentities", func(t *testing.T) {
+		app := setupWithKeys(t, []string{"alice-key", "bob-key"}, "POST /api/v1/blogs 1/1m by=api_key", "/api 100/1m by=user")
+
+		alice := map[string]string{"X-API-Key": "alice-key"}
+		assert.Equal(t, fiber.StatusNoContent, send(t, app, "POST", "/api/v1/blogs", alice).StatusCode)
+		assert.Equal(t, fiber.StatusTooManyRequests, send(t, app, "POST", "/api/v1/blogs", alice).StatusCode)
+		assert.Equal(t, fiber.StatusNoContent, send(t, app, "POST", "/api/v1/blogs", map[string]string{"X-API-Key": "bob-key"}).StatusCode)
+
+		reads := send(t, app, "GET", "/api/v1/blogs", map[string]string{"X-API-Key": "alice-key", "X-User-ID": "alice"})
+		assert.Equal(t, fiber.StatusNoContent, reads.StatusCode)
+		assert.Equal(t, "99", reads.Header.Get("RateLimit-Remaining"))
+	})
+
+	t.Run("UsersAreScopedToTheirKey", func(t *testing.T) {
+		app := setupWithKeys(t, []string{"alice-key", "bob-key"}, "/api 1/1m by=user")
+
+		alice := map[string]string{"X-API-Key": "alice-key", "X-User-ID": "carol"}
+		assert.Equal(t, fiber.StatusNoContent, send(t, app, "GET", "/api/v1/blogs", alice).StatusCode)
+		assert.Equal(t, fiber.StatusTooManyRequests, send(t, app, "GET", "/api/v1/blogs", alice).StatusCode)
+
+		bob := map[string]string{"X-API-Key": "bob-key", "X-User-ID": "carol"}
+		assert.Equal(t, fiber.StatusNoContent, send(t, app, "GET", "/api/v1/blogs", bob).StatusCode)
+	})
+
+	t.Run("UnverifiedHeadersDoNotGetBuckets", func(t *testing.T) {
+		app := setup(t, "/api 2/1m by=user")
+
+		var statuses []int
+		for i := 0; i < 3; i++ {
+			resp := send(t, app, "GET", "/api/v1/blogs", map[string]string{
+				"X-API-Key": fmt.Sprintf("random-key-%d", i),
+				"X-User-ID": fmt.Sprintf("random-user-%d", i),
+			})
+			statuses = append(statuses, resp.StatusCode)
+		}
+
+		assert.Equal(t, []int{fiber.StatusNoContent, fiber.StatusNoContent, fiber.StatusTooManyRequests}, statuses)
+	})
+
+	t.Run("UnmatchedRequestsAreNotLimited", func(t *testing.T) {
+		app := setup(t, "/api 1/1m")
+
+		for i := 0; i < 3; i++ {
+			resp := send(t, app, "GET", "/healthz", nil)
+			assert.Equal(t, fiber.StatusNoContent, resp.StatusCode)
+			assert.Empty(t, resp.Header.Get("RateLimit-Limit"))
+		}
+	})
+}
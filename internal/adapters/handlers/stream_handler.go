@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/toffysoft/go-hexagonal-example/internal/core/domain"
+	"github.com/toffysoft/go-hexagonal-example/internal/core/ports"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// streamRetry tells EventSource clients how long to wait before reconnecting.
+const streamRetry = 3 * time.Second
+
+// StreamHandler serves the blog change feed as Server-Sent Events.
+type StreamHandler struct {
+	feed      ports.ChangeFeed
+	heartbeat time.Duration
+}
+
+func NewStreamHandler(feed ports.ChangeFeed, heartbeat time.Duration) *StreamHandler {
+	return &StreamHandler{feed: feed, heartbeat: heartbeat}
+}
+
+// BlogChangeMessage is the data of a blog change event on the stream.
+type BlogChangeMessage struct {
+	EventID    string           `json:"event_id"`
+	Type       domain.EventType `json:"type"`
+	BlogID     uint             `json:"blog_id"`
+	OccurredAt time.Time        `json:"occurred_at"`
+	Blog       *domain.Blog     `json:"blog,omitempty"`
+}
+
+// StreamBlogs streams blog changes. Each message carries the change cursor as
+// its id, so a reconnecting client's Last-Event-ID resumes right after it. If
+// that is no longer possible a "reset" event is sent first and the client
+// should reload its state. ?author= limits the stream to one author.
+func (h *StreamHandler) StreamBlogs(c *fiber.Ctx) error {
+	author := c.Query("author")
+	cursor := c.Get("Last-Event-ID", c.Query("last_event_id"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	replay, live, resumed := h.feed.Subscribe(ctx, cursor)
+	conn := c.Context().Conn()
+
+	c.Set(fiber.HeaderContentType, "text/event-stream")
+	c.Set(fiber.HeaderCacheControl, "no-cache")
+	c.Set(fiber.HeaderConnection, "keep-alive")
+	c.Set("X-Accel-Buffering", "no") // disable proxy buffering, e.g. nginx
+
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		defer cancel()
+		heartbeat := time.NewTicker(h.heartbeat)
+		defer heartbeat.Stop()
+
+		// The server's write timeout covers the whole response; push the
+		// deadline forward on each write so only a stalled client times out.
+		flush := func() bool {
+			_ = conn.SetWriteDeadline(time.Now().Add(2 * h.heartbeat))
+			return w.Flush() == nil
+		}
+
+		fmt.Fprintf(w, "retry: %d\n\n", streamRetry.Milliseconds())
+		if cursor != "" && !resumed {
+			fmt.Fprint(w, "event: reset\ndata: {}\n\n")
+		}
+		for _, change := range replay {
+			writeChange(w, change, author)
+		}
+		if !flush() {
+			return
+		}
+
+		for {
+			select {
+			case change, ok := <-live:
+				if !ok {
+					return
+				}
+				if writeChange(w, change, author) && !flush() {
+					return
+				}
+			case <-heartbeat.C:
+				fmt.Fprint(w, ": heartbeat\n\n")
+				if !flush() {
+					return
+				}
+			}
+		}
+	})
+	return nil
+}
+
+// writeChange writes change as an SSE message unless it is filtered out by
+// author, and reports whether it was written.
+func writeChange(w *bufio.Writer, change domain.BlogChange, author string) bool {
+	if author != "" && (change.Blog == nil || change.Blog.Author != author) {
+		return false
+	}
+	data, err := json.Marshal(BlogChangeMessage{
+		EventID:    change.Event.ID,
+		Type:       change.Event.Type,
+		BlogID:     change.Event.BlogID,
+		OccurredAt: change.Event.OccurredAt,
+		Blog:       change.Blog,
+	})
+	if err != nil {
+		return false
+	}
+	fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", change.Cursor, change.Event.Type, data)
+	return true
+}
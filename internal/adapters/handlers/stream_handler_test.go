@@ -0,0 +1,84 @@
+package handlers_test
+
+import (
+	"context"
+	"io"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/toffysoft/go-hexagonal-example/internal/adapters/handlers"
+	"github.com/toffysoft/go-hexagonal-example/internal/core/domain"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// closedFeed replays its changes and then ends the live stream, which makes
+// the handler finish the response.
+type closedFeed struct {
+	changes []domain.BlogChange
+	resumed bool
+	cursor  string
+}
+
+func (f *closedFeed) Subscribe(ctx context.Context, cursor string) ([]domain.BlogChange, <-chan domain.BlogChange, bool) {
+	f.cursor = cursor
+	live := make(chan domain.BlogChange)
+	close(live)
+	return f.changes, live, f.resumed
+}
+
+func change(cursor, author string) domain.BlogChange {
+	blog := &domain.Blog{ID: 1, Title: "Test Blog", Author: author}
+	return domain.BlogChange{Cursor: cursor, Event: domain.NewBlogCreatedEvent(blog), Blog: blog}
+}
+
+func stream(t *testing.T, feed *closedFeed, target, lastEventID string) string {
+	app := fiber.New()
+	app.Get("/api/v1/blogs/stream", handlers.NewStreamHandler(feed, time.Minute).StreamBlogs)
+
+	req := httptest.NewRequest("GET", target, nil)
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return string(body)
+}
+
+func TestStreamBlogs(t *testing.T) {
+	t.Run("ReplaysAfterLastEventID", func(t *testing.T) {
+		feed := &closedFeed{changes: []domain.BlogChange{change("e-2", "alice")}, resumed: true}
+
+		body := stream(t, feed, "/api/v1/blogs/stream", "e-1")
+
+		assert.Equal(t, "e-1", feed.cursor)
+		assert.Contains(t, body, "retry: 3000\n\n")
+		assert.Contains(t, body, "id: e-2\nevent: blog.created\ndata: {")
+		assert.Contains(t, body, `"author":"alice"`)
+		assert.NotContains(t, body, "event: reset")
+	})
+
+	t.Run("FiltersByAuthor", func(t *testing.T) {
+		feed := &closedFeed{changes: []domain.BlogChange{change("e-1", "alice"), change("e-2", "bob")}, resumed: true}
+
+		body := stream(t, feed, "/api/v1/blogs/stream?author=bob", "")
+
+		assert.NotContains(t, body, "id: e-1")
+		assert.Contains(t, body, "id: e-2")
+	})
+
+	t.Run("ResetWhenNotResumable", func(t *testing.T) {
+		feed := &closedFeed{resumed: false}
+
+		body := stream(t, feed, "/api/v1/blogs/stream", "stale-9")
+
+		assert.Contains(t, body, "event: reset\n")
+	})
+}
@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"log/slog"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/toffysoft/go-hexagonal-example/internal/adapters/transfer"
+	"github.com/toffysoft/go-hexagonal-example/internal/core/domain"
+	"github.com/toffysoft/go-hexagonal-example/internal/core/ports"
+	"github.com/toffysoft/go-hexagonal-example/pkg/errors"
+	"github.com/toffysoft/go-hexagonal-example/pkg/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// exportWriteTimeout bounds each chunk of an export, replacing the server's
+// write timeout, which would otherwise cut off large exports.
+const exportWriteTimeout = 30 * time.Second
+
+type TransferHandler struct {
+	transferService ports.TransferService
+	maxUnzipped     int64
+}
+
+// NewTransferHandler rejects Markdown archives that unzip to more than
+// maxUnzipped bytes.
+func NewTransferHandler(transferService ports.TransferService, maxUnzipped int64) *TransferHandler {
+	return &TransferHandler{transferService: transferService, maxUnzipped: maxUnzipped}
+}
+
+// ExportBlogs downloads the blogs matching ?author=, ?created_after= and
+// ?created_before= in ?format= (jsonl, csv or markdown; jsonl by default).
+func (h *TransferHandler) ExportBlogs(c *fiber.Ctx) error {
+	format, err := transfer.ParseFormat(c.Query("format", string(transfer.JSONL)))
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, err.Error())
+	}
+	filter := domain.BlogFilter{Author: c.Query("author")}
+	for param, value := range map[string]*time.Time{
+		"created_after":  &filter.CreatedAfter,
+		"created_before": &filter.CreatedBefore,
+	} {
+		if raw := c.Query(param); raw != "" {
+			if *value, err = transfer.ParseTime(raw); err != nil {
+				return utils.SendErrorResponse(c, fiber.StatusBadRequest, fmt.Sprintf("Invalid %s: %v", param, err))
+			}
+		}
+	}
+
+	ctx := c.UserContext()
+	conn := c.Context().Conn()
+	filename := "blogs-" + time.Now().UTC().Format("20060102T150405Z") + format.Extension()
+	c.Set(fiber.HeaderContentType, format.ContentType())
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="%s"`, filename))
+
+	// The status is sent before the first blog is read, so a failure part way
+	// through can only be logged; the client sees a truncated file.
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		encoder := transfer.NewEncoder(w, format)
+		err := h.transferService.ExportBlogs(ctx, filter, func(blogs []*domain.Blog) error {
+			_ = conn.SetWriteDeadline(time.Now().Add(exportWriteTimeout))
+			for _, blog := range blogs {
+				if err := encoder.Encode(blog); err != nil {
+					return err
+				}
+			}
+			return w.Flush()
+		})
+		if err == nil {
+			err = encoder.Close()
+		}
+		if err == nil {
+			err = w.Flush()
+		}
+		if err != nil {
+			slog.ErrorContext(ctx, "Blog export failed", "format", format, "error", err)
+		}
+	})
+	return nil
+}
+
+// ImportBlogs imports the request body, or the multipart "file" field, in
+// ?format= (guessed from the file name of an upload when missing). Options:
+// ?dry_run=true, ?dedupe=none|slug|title_author (title_author by default)
+// and ?upsert=true.
+func (h *TransferHandler) ImportBlogs(c *fiber.Ctx) error {
+	data, name, problem := importFile(c)
+	if problem != "" {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, problem)
+	}
+	formatName := c.Query("format", path.Ext(name))
+	if formatName == "" {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Format is required: use ?format=jsonl, csv or markdown")
+	}
+	format, err := transfer.ParseFormat(formatName)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	records, err := transfer.Decode(data, format, h.maxUnzipped)
+	if appErr, ok := err.(errors.AppError); ok {
+		return sendError(c, appErr, "")
+	}
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, fmt.Sprintf("Unreadable %s file: %v", format, err))
+	}
+
+	opts := domain.ImportOptions{
+		DryRun:   c.QueryBool("dry_run"),
+		DedupeBy: domain.DedupeKey(c.Query("dedupe", string(domain.DedupeTitleAuthor))),
+		Upsert:   c.QueryBool("upsert"),
+	}
+	report, err := h.transferService.ImportBlogs(c.UserContext(), records, opts)
+	if err != nil {
+		return sendError(c, err, "Failed to import blogs")
+	}
+
+	message := "Blogs imported"
+	if opts.DryRun {
+		message = "Dry run finished, nothing was changed"
+	}
+	return utils.SendSuccessResponse(c, fiber.StatusOK, message, report)
+}
+
+// importFile returns the "file" field of a multipart request, or else the
+// body. problem is set when the upload cannot be read.
+func importFile(c *fiber.Ctx) (data []byte, name string, problem string) {
+	if !strings.HasPrefix(c.Get(fiber.HeaderContentType), fiber.MIMEMultipartForm) {
+		return c.Body(), "", ""
+	}
+	header, err := c.FormFile("file")
+	if err != nil {
+		return nil, "", `Multipart upload has no "file" field`
+	}
+	file, err := header.Open()
+	if err != nil {
+		return nil, "", "Unreadable upload"
+	}
+	defer file.Close()
+	if data, err = io.ReadAll(file); err != nil {
+		return nil, "", "Unreadable upload"
+	}
+	return data, header.Filename, ""
+}
@@ -0,0 +1,114 @@
+package handlers_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/toffysoft/go-hexagonal-example/internal/adapters/handlers"
+	"github.com/toffysoft/go-hexagonal-example/internal/adapters/repositories"
+	"github.com/toffysoft/go-hexagonal-example/internal/core/domain"
+	"github.com/toffysoft/go-hexagonal-example/internal/core/services"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type importBody struct {
+	Success bool                `json:"success"`
+	Data    domain.ImportReport `json:"data"`
+}
+
+func transferApp() *fiber.App {
+	store := repositories.NewMemoryStore()
+	transferHandler := handlers.NewTransferHandler(services.NewTransferService(store.Blogs(), store.UnitOfWork(), nil), 1<<20)
+
+	app := fiber.New()
+	app.Get("/api/v1/admin/blogs/export", transferHandler.ExportBlogs)
+	app.Post("/api/v1/admin/blogs/import", transferHandler.ImportBlogs)
+	return app
+}
+
+func importBlogs(t *testing.T, app *fiber.App, query, contentType string, body io.Reader) (int, importBody) {
+	req := httptest.NewRequest("POST", "/api/v1/admin/blogs/import"+query, body)
+	req.Header.Set("Content-Type", contentType)
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+
+	var decoded importBody
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&decoded))
+	return resp.StatusCode, decoded
+}
+
+const importCSV = "title,content,author\nFirst,Body one,alice\nSecond,Body two,bob\nfirst,Body again,alice\n"
+
+func TestImportBlogs(t *testing.T) {
+	app := transferApp()
+
+	status, body := importBlogs(t, app, "?format=csv&dry_run=true", "text/csv", strings.NewReader(importCSV))
+	require.Equal(t, fiber.StatusOK, status)
+	assert.True(t, body.Data.DryRun)
+	assert.Equal(t, 2, body.Data.Created)
+	assert.Equal(t, 1, body.Data.Skipped)
+
+	status, body = importBlogs(t, app, "?format=csv", "text/csv", strings.NewReader(importCSV))
+	require.Equal(t, fiber.StatusOK, status)
+	assert.Equal(t, 2, body.Data.Created)
+	assert.NotZero(t, body.Data.Rows[0].BlogID)
+
+	t.Run("MultipartUpsert", func(t *testing.T) {
+		var buf bytes.Buffer
+		form := multipart.NewWriter(&buf)
+		file, err := form.CreateFormFile("file", "blogs.jsonl")
+		require.NoError(t, err)
+		_, err = file.Write([]byte(`{"title": "Second", "content": "New body", "author": "bob"}` + "\n"))
+		require.NoError(t, err)
+		require.NoError(t, form.Close())
+
+		status, body := importBlogs(t, app, "?upsert=true", form.FormDataContentType(), &buf)
+
+		require.Equal(t, fiber.StatusOK, status)
+		assert.Equal(t, 1, body.Data.Updated)
+	})
+
+	t.Run("Export", func(t *testing.T) {
+		resp, err := app.Test(httptest.NewRequest("GET", "/api/v1/admin/blogs/export?author=bob", nil))
+		require.NoError(t, err)
+
+		data, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		assert.Equal(t, "application/x-ndjson", resp.Header.Get("Content-Type"))
+		assert.Contains(t, resp.Header.Get("Content-Disposition"), ".jsonl")
+		lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+		require.Len(t, lines, 1)
+		assert.Contains(t, lines[0], `"content":"New body"`)
+	})
+
+	t.Run("ArchiveTooLarge", func(t *testing.T) {
+		var buf bytes.Buffer
+		archive := zip.NewWriter(&buf)
+		file, err := archive.Create("large.md")
+		require.NoError(t, err)
+		_, err = file.Write(bytes.Repeat([]byte{'a'}, 2<<20))
+		require.NoError(t, err)
+		require.NoError(t, archive.Close())
+
+		status, body := importBlogs(t, app, "?format=markdown", "application/zip", &buf)
+
+		assert.Equal(t, fiber.StatusRequestEntityTooLarge, status)
+		assert.False(t, body.Success)
+	})
+
+	t.Run("UnknownFormat", func(t *testing.T) {
+		status, body := importBlogs(t, app, "?format=xml", "text/xml", strings.NewReader("<blogs/>"))
+
+		assert.Equal(t, fiber.StatusBadRequest, status)
+		assert.False(t, body.Success)
+	})
+}
@@ -0,0 +1,219 @@
+package handlers
+
+import (
+	"strconv"
+
+	"github.com/toffysoft/go-hexagonal-example/internal/core/domain"
+	"github.com/toffysoft/go-hexagonal-example/internal/core/ports"
+	"github.com/toffysoft/go-hexagonal-example/pkg/errors"
+	"github.com/toffysoft/go-hexagonal-example/pkg/utils"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+)
+
+type WebhookHandler struct {
+	webhookService ports.WebhookService
+	validate       *validator.Validate
+}
+
+func NewWebhookHandler(webhookService ports.WebhookService) *WebhookHandler {
+	return &WebhookHandler{
+		webhookService: webhookService,
+		validate:       validator.New(),
+	}
+}
+
+type CreateWebhookRequest struct {
+	URL    string             `json:"url" validate:"required,url,max=2048"`
+	Secret string             `json:"secret" validate:"omitempty,min=16,max=256"`
+	Events []domain.EventType `json:"events"`
+}
+
+// CreateWebhookResponse is the only response that includes the secret.
+type CreateWebhookResponse struct {
+	*domain.Webhook
+	Secret string `json:"secret"`
+}
+
+func (h *WebhookHandler) CreateWebhook(c *fiber.Ctx) error {
+	var req CreateWebhookRequest
+	if err := utils.ParseBody(c, &req); err != nil {
+		return utils.SendBodyError(c, err)
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, utils.ValidatorErrors(err))
+	}
+
+	webhook := &domain.Webhook{
+		URL:    req.URL,
+		Secret: req.Secret,
+		Events: req.Events,
+	}
+
+	if err := h.webhookService.CreateWebhook(c.UserContext(), webhook); err != nil {
+		return sendError(c, err, "Failed to create webhook")
+	}
+
+	return utils.SendSuccessResponse(c, fiber.StatusCreated, "Webhook created successfully", CreateWebhookResponse{
+		Webhook: webhook,
+		Secret:  webhook.Secret,
+	})
+}
+
+type UpdateWebhookRequest struct {
+	URL     string              `json:"url" validate:"omitempty,url,max=2048"`
+	Secret  string              `json:"secret" validate:"omitempty,min=16,max=256"`
+	Events  *[]domain.EventType `json:"events"`
+	Enabled *bool               `json:"enabled"`
+}
+
+func (h *WebhookHandler) UpdateWebhook(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid webhook ID")
+	}
+
+	var req UpdateWebhookRequest
+	if err := utils.ParseBody(c, &req); err != nil {
+		return utils.SendBodyError(c, err)
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, utils.ValidatorErrors(err))
+	}
+
+	ctx := ports.WithStrongConsistency(c.UserContext())
+	webhook, err := h.webhookService.GetWebhook(ctx, uint(id))
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusNotFound, "Webhook not found")
+	}
+
+	if req.URL != "" {
+		webhook.URL = req.URL
+	}
+	if req.Secret != "" {
+		webhook.Secret = req.Secret
+	}
+	if req.Events != nil {
+		webhook.Events = *req.Events
+	}
+	if req.Enabled != nil {
+		webhook.Enabled = *req.Enabled
+	}
+
+	if err := h.webhookService.UpdateWebhook(ctx, webhook); err != nil {
+		return sendError(c, err, "Failed to update webhook")
+	}
+
+	return utils.SendSuccessResponse(c, fiber.StatusOK, "Webhook updated successfully", webhook)
+}
+
+func (h *WebhookHandler) GetWebhook(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid webhook ID")
+	}
+
+	webhook, err := h.webhookService.GetWebhook(c.UserContext(), uint(id))
+	if err != nil {
+		return sendError(c, err, "Failed to retrieve webhook")
+	}
+
+	return utils.SendSuccessResponse(c, fiber.StatusOK, "Webhook retrieved successfully", webhook)
+}
+
+func (h *WebhookHandler) DeleteWebhook(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid webhook ID")
+	}
+
+	if err := h.webhookService.DeleteWebhook(c.UserContext(), uint(id)); err != nil {
+		return sendError(c, err, "Failed to delete webhook")
+	}
+
+	return utils.SendSuccessResponse(c, fiber.StatusOK, "Webhook deleted successfully", nil)
+}
+
+func (h *WebhookHandler) ListWebhooks(c *fiber.Ctx) error {
+	webhooks, err := h.webhookService.ListWebhooks(c.UserContext())
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to retrieve webhooks")
+	}
+
+	return utils.SendSuccessResponse(c, fiber.StatusOK, "Webhooks retrieved successfully", webhooks)
+}
+
+func (h *WebhookHandler) ListDeliveries(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid webhook ID")
+	}
+
+	deliveries, err := h.webhookService.ListDeliveries(c.UserContext(), uint(id))
+	if err != nil {
+		return sendError(c, err, "Failed to retrieve deliveries")
+	}
+
+	return utils.SendSuccessResponse(c, fiber.StatusOK, "Deliveries retrieved successfully", deliveries)
+}
+
+type DeliveryResponse struct {
+	*domain.WebhookDelivery
+	AttemptLog []*domain.WebhookAttempt `json:"attempt_log"`
+}
+
+func (h *WebhookHandler) GetDelivery(c *fiber.Ctx) error {
+	id, deliveryID, problem := deliveryParams(c)
+	if problem != "" {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, problem)
+	}
+
+	delivery, attempts, err := h.webhookService.GetDelivery(c.UserContext(), id, deliveryID)
+	if err != nil {
+		return sendError(c, err, "Failed to retrieve delivery")
+	}
+
+	return utils.SendSuccessResponse(c, fiber.StatusOK, "Delivery retrieved successfully", DeliveryResponse{
+		WebhookDelivery: delivery,
+		AttemptLog:      attempts,
+	})
+}
+
+func (h *WebhookHandler) Redeliver(c *fiber.Ctx) error {
+	id, deliveryID, problem := deliveryParams(c)
+	if problem != "" {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, problem)
+	}
+
+	delivery, err := h.webhookService.Redeliver(c.UserContext(), id, deliveryID)
+	if err != nil {
+		return sendError(c, err, "Failed to redeliver")
+	}
+
+	return utils.SendSuccessResponse(c, fiber.StatusAccepted, "Redelivery queued", delivery)
+}
+
+// deliveryParams parses the webhook and delivery IDs of the route, or
+// describes which one is invalid.
+func deliveryParams(c *fiber.Ctx) (uint, uint, string) {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return 0, 0, "Invalid webhook ID"
+	}
+	deliveryID, err := strconv.ParseUint(c.Params("deliveryId"), 10, 32)
+	if err != nil {
+		return 0, 0, "Invalid delivery ID"
+	}
+	return uint(id), uint(deliveryID), ""
+}
+
+// sendError responds with the status of an AppError, or 500 with fallback.
+func sendError(c *fiber.Ctx, err error, fallback string) error {
+	if appErr, ok := err.(errors.AppError); ok {
+		return utils.SendErrorResponse(c, appErr.StatusCode(), appErr.Error())
+	}
+	return utils.SendErrorResponse(c, fiber.StatusInternalServerError, fallback)
+}
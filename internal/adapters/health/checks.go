@@ -0,0 +1,47 @@
+package health
+
+import (
+	"context"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// DatabaseCheck pings the database behind db.
+func DatabaseCheck(db *gorm.DB) Check {
+	return func(ctx context.Context) error {
+		sqlDB, err := db.DB()
+		if err != nil {
+			return err
+		}
+		return sqlDB.PingContext(ctx)
+	}
+}
+
+// MigrationsCheck verifies that the table and every column of each model exist,
+// i.e. that AutoMigrate has been applied for the running binary.
+func MigrationsCheck(db *gorm.DB, models ...interface{}) Check {
+	return func(ctx context.Context) error {
+		tx := db.WithContext(ctx)
+		migrator := tx.Migrator()
+
+		for _, model := range models {
+			stmt := &gorm.Statement{DB: tx}
+			if err := stmt.Parse(model); err != nil {
+				return err
+			}
+			if !migrator.HasTable(model) {
+				return fmt.Errorf("table %s is missing", stmt.Schema.Table)
+			}
+			for _, field := range stmt.Schema.Fields {
+				if field.DBName == "" {
+					continue
+				}
+				if !migrator.HasColumn(model, field.DBName) {
+					return fmt.Errorf("column %s.%s is missing", stmt.Schema.Table, field.DBName)
+				}
+			}
+		}
+		return nil
+	}
+}
@@ -0,0 +1,40 @@
+package health
+
+import (
+	"context"
+	"time"
+
+	grpchealth "google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// WatchGRPC runs the registry every interval and mirrors the result onto the
+// gRPC health server for the overall ("") service and each named service.
+// It blocks until ctx is cancelled, after which every service reports NOT_SERVING.
+func (r *Registry) WatchGRPC(ctx context.Context, server *grpchealth.Server, interval time.Duration, services ...string) {
+	services = append([]string{""}, services...)
+
+	update := func() {
+		status := healthpb.HealthCheckResponse_SERVING
+		if !r.Run(ctx).Healthy() {
+			status = healthpb.HealthCheckResponse_NOT_SERVING
+		}
+		for _, service := range services {
+			server.SetServingStatus(service, status)
+		}
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	update()
+	for {
+		select {
+		case <-ctx.Done():
+			server.Shutdown()
+			return
+		case <-ticker.C:
+			update()
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package health_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/toffysoft/go-hexagonal-example/internal/adapters/health"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	grpchealth "google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func ok(context.Context) error { return nil }
+
+func failing(context.Context) error { return errors.New("connection refused") }
+
+func TestRegistryRun(t *testing.T) {
+	t.Run("AllHealthy", func(t *testing.T) {
+		registry := health.NewRegistry(time.Second)
+		registry.Register("database", ok)
+		registry.Register("cache", ok)
+
+		report := registry.Run(context.Background())
+
+		assert.True(t, report.Healthy())
+		assert.Len(t, report.Checks, 2)
+		assert.Equal(t, health.StatusOK, report.Checks["database"].Status)
+	})
+
+	t.Run("OneFailing", func(t *testing.T) {
+		registry := health.NewRegistry(time.Second)
+		registry.Register("database", failing)
+		registry.Register("cache", ok)
+
+		report := registry.Run(context.Background())
+
+		assert.False(t, report.Healthy())
+		assert.Equal(t, health.StatusError, report.Checks["database"].Status)
+		assert.Equal(t, "connection refused", report.Checks["database"].Error)
+		assert.Equal(t, health.StatusOK, report.Checks["cache"].Status)
+	})
+
+	t.Run("Timeout", func(t *testing.T) {
+		registry := health.NewRegistry(time.Second)
+		registry.RegisterWithTimeout("slow", func(ctx context.Context) error {
+			time.Sleep(time.Second)
+			return nil
+		}, 10*time.Millisecond)
+
+		start := time.Now()
+		report := registry.Run(context.Background())
+
+		assert.Less(t, time.Since(start), 500*time.Millisecond)
+		assert.False(t, report.Healthy())
+		assert.Contains(t, report.Checks["slow"].Error, "timed out")
+	})
+
+	t.Run("Panic", func(t *testing.T) {
+		registry := health.NewRegistry(time.Second)
+		registry.Register("broken", func(context.Context) error { panic("boom") })
+
+		report := registry.Run(context.Background())
+
+		assert.False(t, report.Healthy())
+		assert.Contains(t, report.Checks["broken"].Error, "boom")
+	})
+}
+
+func TestHandlers(t *testing.T) {
+	registry := health.NewRegistry(time.Second)
+	registry.Register("database", failing)
+
+	app := fiber.New()
+	app.Get("/healthz", health.LivenessHandler())
+	app.Get("/readyz", health.ReadinessHandler(registry))
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/healthz", nil))
+	assert.NoError(t, err)
+	assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	resp, err = app.Test(httptest.NewRequest("GET", "/readyz", nil))
+	assert.NoError(t, err)
+	assert.Equal(t, fiber.StatusServiceUnavailable, resp.StatusCode)
+
+	var report health.Report
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&report))
+	assert.Equal(t, health.StatusError, report.Status)
+	assert.Equal(t, "connection refused", report.Checks["database"].Error)
+}
+
+func TestWatchGRPC(t *testing.T) {
+	registry := health.NewRegistry(time.Second)
+	registry.Register("database", ok)
+
+	server := grpchealth.NewServer()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		registry.WatchGRPC(ctx, server, time.Hour, "blog.BlogService")
+		close(done)
+	}()
+
+	assert.Eventually(t, func() bool {
+		resp, err := server.Check(context.Background(), &healthpb.HealthCheckRequest{Service: "blog.BlogService"})
+		return err == nil && resp.Status == healthpb.HealthCheckResponse_SERVING
+	}, time.Second, 10*time.Millisecond)
+
+	cancel()
+	<-done
+
+	resp, err := server.Check(context.Background(), &healthpb.HealthCheckRequest{})
+	assert.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, resp.Status)
+}
@@ -0,0 +1,31 @@
+package health
+
+import (
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// LivenessHandler reports that the process is up and serving requests.
+func LivenessHandler() fiber.Handler {
+	started := time.Now()
+	return func(c *fiber.Ctx) error {
+		return c.JSON(fiber.Map{
+			"status": StatusOK,
+			"uptime": time.Since(started).Round(time.Second).String(),
+		})
+	}
+}
+
+// ReadinessHandler runs every registered check and responds 503 if any fails.
+func ReadinessHandler(registry *Registry) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		report := registry.Run(c.UserContext())
+
+		status := fiber.StatusOK
+		if !report.Healthy() {
+			status = fiber.StatusServiceUnavailable
+		}
+		return c.Status(status).JSON(report)
+	}
+}
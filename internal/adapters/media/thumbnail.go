@@ -0,0 +1,124 @@
+package media
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/draw"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+
+	"github.com/toffysoft/go-hexagonal-example/internal/core/domain"
+	"github.com/toffysoft/go-hexagonal-example/internal/core/ports"
+	"github.com/toffysoft/go-hexagonal-example/pkg/errors"
+)
+
+// maxPixels bounds the images that are decoded, since a small file can
+// declare a huge image. A decoded image takes up to 8 bytes a pixel.
+const maxPixels = 16_000_000
+
+// Thumbnailer scales down JPEG, PNG and GIF images with the standard
+// library. JPEGs stay JPEGs; the others become PNGs, to keep transparency.
+type Thumbnailer struct {
+	quality int
+}
+
+var _ ports.Thumbnailer = (*Thumbnailer)(nil)
+
+func NewThumbnailer() *Thumbnailer {
+	return &Thumbnailer{quality: 85}
+}
+
+func (t *Thumbnailer) Thumbnail(ctx context.Context, contentType string, data []byte, size int) (*domain.Thumbnail, error) {
+	var decode func([]byte) (image.Image, error)
+	switch contentType {
+	case "image/jpeg":
+		decode = func(b []byte) (image.Image, error) { return jpeg.Decode(bytes.NewReader(b)) }
+	case "image/png":
+		decode = func(b []byte) (image.Image, error) { return png.Decode(bytes.NewReader(b)) }
+	case "image/gif":
+		decode = func(b []byte) (image.Image, error) { return gif.Decode(bytes.NewReader(b)) }
+	default:
+		return nil, nil
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, errors.NewInvalidInputError("Image cannot be decoded")
+	}
+	if config.Width*config.Height > maxPixels {
+		return nil, errors.NewInvalidInputError("Image has too many pixels")
+	}
+	src, err := decode(data)
+	if err != nil {
+		return nil, errors.NewInvalidInputError("Image cannot be decoded")
+	}
+
+	bounds := src.Bounds()
+	thumb := scale(src, fit(bounds.Dx(), bounds.Dy(), size))
+	var buf bytes.Buffer
+	thumbnail := &domain.Thumbnail{Width: bounds.Dx(), Height: bounds.Dy()}
+	if contentType == "image/jpeg" {
+		err = jpeg.Encode(&buf, thumb, &jpeg.Options{Quality: t.quality})
+		thumbnail.ContentType = "image/jpeg"
+	} else {
+		err = png.Encode(&buf, thumb)
+		thumbnail.ContentType = "image/png"
+	}
+	if err != nil {
+		return nil, err
+	}
+	thumbnail.Data = buf.Bytes()
+	return thumbnail, nil
+}
+
+// fit returns the size of a w by h image scaled down to fit in a size by
+// size square. Smaller images keep their size.
+func fit(w, h, size int) image.Point {
+	if w <= size && h <= size {
+		return image.Pt(w, h)
+	}
+	if w >= h {
+		return image.Pt(size, max(1, h*size/w))
+	}
+	return image.Pt(max(1, w*size/h), size)
+}
+
+// scale resizes src to size by averaging the source pixels that fall in each
+// destination pixel. Only the source rows of one destination row are
+// converted to RGBA at a time, so a large source is not copied whole.
+func scale(src image.Image, size image.Point) *image.RGBA {
+	bounds := src.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, size.X, size.Y))
+	if size.X == bounds.Dx() && size.Y == bounds.Dy() {
+		draw.Draw(dst, dst.Bounds(), src, bounds.Min, draw.Src)
+		return dst
+	}
+
+	sw, sh := bounds.Dx(), bounds.Dy()
+	rgba := image.NewRGBA(image.Rect(0, 0, sw, (sh+size.Y-1)/size.Y))
+	for y := 0; y < size.Y; y++ {
+		y0, y1 := y*sh/size.Y, max((y+1)*sh/size.Y, y*sh/size.Y+1)
+		draw.Draw(rgba, image.Rect(0, 0, sw, y1-y0), src, image.Pt(bounds.Min.X, bounds.Min.Y+y0), draw.Src)
+		for x := 0; x < size.X; x++ {
+			x0, x1 := x*sw/size.X, max((x+1)*sw/size.X, x*sw/size.X+1)
+			var sum [4]int
+			for sy := 0; sy < y1-y0; sy++ {
+				row := rgba.Pix[sy*rgba.Stride+x0*4 : sy*rgba.Stride+x1*4]
+				for i := 0; i < len(row); i += 4 {
+					sum[0] += int(row[i])
+					sum[1] += int(row[i+1])
+					sum[2] += int(row[i+2])
+					sum[3] += int(row[i+3])
+				}
+			}
+			n := (y1 - y0) * (x1 - x0)
+			i := y*dst.Stride + x*4
+			for c := 0; c < 4; c++ {
+				dst.Pix[i+c] = uint8(sum[c] / n)
+			}
+		}
+	}
+	return dst
+}
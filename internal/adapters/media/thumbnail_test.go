@@ -0,0 +1,118 @@
+package media_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"github.com/toffysoft/go-hexagonal-example/internal/adapters/media"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func encodePNG(t *testing.T, w, h int) []byte {
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, color.NRGBA{R: uint8(x), G: uint8(y), B: 200, A: 255})
+		}
+	}
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, img))
+	return buf.Bytes()
+}
+
+func TestThumbnail(t *testing.T) {
+	ctx := context.Background()
+	thumbnailer := media.NewThumbnailer()
+
+	t.Run("ScalesDown", func(t *testing.T) {
+		thumb, err := thumbnailer.Thumbnail(ctx, "image/png", encodePNG(t, 400, 200), 100)
+
+		require.NoError(t, err)
+		require.NotNil(t, thumb)
+		assert.Equal(t, "image/png", thumb.ContentType)
+		assert.Equal(t, 400, thumb.Width)
+		assert.Equal(t, 200, thumb.Height)
+		img, err := png.Decode(bytes.NewReader(thumb.Data))
+		require.NoError(t, err)
+		assert.Equal(t, image.Pt(100, 50), img.Bounds().Size())
+	})
+
+	t.Run("KeepsJPEG", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, jpeg.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 60, 300)), nil))
+
+		thumb, err := thumbnailer.Thumbnail(ctx, "image/jpeg", buf.Bytes(), 100)
+
+		require.NoError(t, err)
+		assert.Equal(t, "image/jpeg", thumb.ContentType)
+		img, err := jpeg.Decode(bytes.NewReader(thumb.Data))
+		require.NoError(t, err)
+		assert.Equal(t, image.Pt(20, 100), img.Bounds().Size())
+	})
+
+	t.Run("SmallImageKeepsItsSize", func(t *testing.T) {
+		thumb, err := thumbnailer.Thumbnail(ctx, "image/png", encodePNG(t, 10, 20), 100)
+
+		require.NoError(t, err)
+		img, err := png.Decode(bytes.NewReader(thumb.Data))
+		require.NoError(t, err)
+		assert.Equal(t, image.Pt(10, 20), img.Bounds().Size())
+	})
+
+	t.Run("AveragesPixels", func(t *testing.T) {
+		img := image.NewNRGBA(image.Rect(0, 0, 4, 2))
+		for x := 0; x < 4; x++ {
+			img.Set(x, 0, color.NRGBA{R: 100, A: 255})
+			img.Set(x, 1, color.NRGBA{R: 200, B: uint8(x * 10), A: 255})
+		}
+		var buf bytes.Buffer
+		require.NoError(t, png.Encode(&buf, img))
+
+		thumb, err := thumbnailer.Thumbnail(ctx, "image/png", buf.Bytes(), 2)
+
+		require.NoError(t, err)
+		scaled, err := png.Decode(bytes.NewReader(thumb.Data))
+		require.NoError(t, err)
+		assert.Equal(t, image.Pt(2, 1), scaled.Bounds().Size())
+		assert.Equal(t, color.RGBA{R: 150, B: 2, A: 255}, color.RGBAModel.Convert(scaled.At(0, 0)))
+		assert.Equal(t, color.RGBA{R: 150, B: 12, A: 255}, color.RGBAModel.Convert(scaled.At(1, 0)))
+	})
+
+	t.Run("TooManyPixels", func(t *testing.T) {
+		// Only the header is needed: the size is checked before decoding.
+		header := make([]byte, 13)
+		binary.BigEndian.PutUint32(header[0:], 5000)
+		binary.BigEndian.PutUint32(header[4:], 4000)
+		header[8], header[9] = 8, 6
+		chunk := append([]byte("IHDR"), header...)
+		data := append([]byte("\x89PNG\r\n\x1a\n\x00\x00\x00\x0d"), chunk...)
+		data = binary.BigEndian.AppendUint32(data, crc32.ChecksumIEEE(chunk))
+
+		_, err := thumbnailer.Thumbnail(ctx, "image/png", data, 100)
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "too many pixels")
+	})
+
+	t.Run("UnsupportedType", func(t *testing.T) {
+		thumb, err := thumbnailer.Thumbnail(ctx, "image/webp", []byte("RIFF"), 100)
+
+		assert.NoError(t, err)
+		assert.Nil(t, thumb)
+	})
+
+	t.Run("Corrupt", func(t *testing.T) {
+		_, err := thumbnailer.Thumbnail(ctx, "image/png", []byte("\x89PNG\r\n\x1a\nbroken"), 100)
+
+		assert.Error(t, err)
+	})
+}
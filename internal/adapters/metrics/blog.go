@@ -0,0 +1,17 @@
+package metrics
+
+import "github.com/toffysoft/go-hexagonal-example/internal/core/ports"
+
+var _ ports.BlogMetrics = (*Metrics)(nil)
+
+func (m *Metrics) BlogCreated() {
+	m.blogChanges.WithLabelValues("created").Inc()
+}
+
+func (m *Metrics) BlogUpdated() {
+	m.blogChanges.WithLabelValues("updated").Inc()
+}
+
+func (m *Metrics) BlogDeleted() {
+	m.blogChanges.WithLabelValues("deleted").Inc()
+}
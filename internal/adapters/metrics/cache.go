@@ -0,0 +1,17 @@
+package metrics
+
+import (
+	"strconv"
+
+	"github.com/toffysoft/go-hexagonal-example/internal/core/ports"
+)
+
+var _ ports.CacheMetrics = (*Metrics)(nil)
+
+func (m *Metrics) CacheHit(cache, tier string, negative bool) {
+	m.cacheHits.WithLabelValues(cache, tier, strconv.FormatBool(negative)).Inc()
+}
+
+func (m *Metrics) CacheMiss(cache string) {
+	m.cacheMisses.WithLabelValues(cache).Inc()
+}
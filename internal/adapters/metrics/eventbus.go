@@ -0,0 +1,20 @@
+package metrics
+
+import (
+	"github.com/toffysoft/go-hexagonal-example/internal/core/domain"
+	"github.com/toffysoft/go-hexagonal-example/internal/core/ports"
+)
+
+var _ ports.EventBusMetrics = (*Metrics)(nil)
+
+func (m *Metrics) QueueDepth(depth int) {
+	m.eventQueueDepth.Set(float64(depth))
+}
+
+func (m *Metrics) HandlerFailed(subscriber string, eventType domain.EventType, panicked bool) {
+	reason := "error"
+	if panicked {
+		reason = "panic"
+	}
+	m.eventHandlerFailures.WithLabelValues(subscriber, string(eventType), reason).Inc()
+}
@@ -0,0 +1,41 @@
+package metrics
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/toffysoft/go-hexagonal-example/pkg/errors"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// FiberMiddleware records a counter and latency histogram for every request,
+// labelled with the matched route pattern rather than the raw path.
+func (m *Metrics) FiberMiddleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		start := time.Now()
+		err := c.Next()
+
+		code := c.Response().StatusCode()
+		if err != nil {
+			code = statusFromError(err)
+		}
+
+		labels := []string{c.Method(), c.Route().Path, strconv.Itoa(code)}
+		m.httpRequests.WithLabelValues(labels...).Inc()
+		m.httpDuration.WithLabelValues(labels...).Observe(time.Since(start).Seconds())
+
+		return err
+	}
+}
+
+func statusFromError(err error) int {
+	switch e := err.(type) {
+	case *fiber.Error:
+		return e.Code
+	case errors.AppError:
+		return e.StatusCode()
+	default:
+		return fiber.StatusInternalServerError
+	}
+}
@@ -0,0 +1,88 @@
+package metrics_test
+
+import (
+	"context"
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/toffysoft/go-hexagonal-example/internal/adapters/metrics"
+	"github.com/toffysoft/go-hexagonal-example/internal/core/domain"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func scrape(t *testing.T, m *metrics.Metrics) string {
+	app := fiber.New()
+	app.Get("/metrics", adaptor.HTTPHandler(m.Handler()))
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/metrics", nil))
+	assert.NoError(t, err)
+
+	body, err := io.ReadAll(resp.Body)
+	assert.NoError(t, err)
+	return string(body)
+}
+
+func TestFiberMiddleware(t *testing.T) {
+	m := metrics.New()
+
+	app := fiber.New()
+	app.Use(m.FiberMiddleware())
+	app.Get("/api/v1/blogs/:id", func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusNotFound)
+	})
+
+	_, err := app.Test(httptest.NewRequest("GET", "/api/v1/blogs/42", nil))
+	assert.NoError(t, err)
+
+	body := scrape(t, m)
+	assert.Contains(t, body, `blog_http_requests_total{method="GET",route="/api/v1/blogs/:id",status="404"} 1`)
+	assert.Contains(t, body, `blog_http_request_duration_seconds_count{method="GET",route="/api/v1/blogs/:id",status="404"} 1`)
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	m := metrics.New()
+	interceptor := m.UnaryServerInterceptor()
+	info := &grpc.UnaryServerInfo{FullMethod: "/blog.BlogService/GetBlog"}
+
+	_, err := interceptor(context.Background(), nil, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, status.Error(codes.NotFound, "not found")
+	})
+	assert.Error(t, err)
+
+	body := scrape(t, m)
+	assert.Contains(t, body, `blog_grpc_requests_total{code="NotFound",method="/blog.BlogService/GetBlog"} 1`)
+}
+
+func TestBlogMetrics(t *testing.T) {
+	m := metrics.New()
+
+	m.BlogCreated()
+	m.BlogCreated()
+	m.BlogUpdated()
+	m.BlogDeleted()
+
+	body := scrape(t, m)
+	assert.Contains(t, body, `blog_service_blog_changes_total{action="created"} 2`)
+	assert.Contains(t, body, `blog_service_blog_changes_total{action="updated"} 1`)
+	assert.Contains(t, body, `blog_service_blog_changes_total{action="deleted"} 1`)
+}
+
+func TestEventBusMetrics(t *testing.T) {
+	m := metrics.New()
+
+	m.QueueDepth(3)
+	m.HandlerFailed("indexer", domain.BlogCreated, false)
+	m.HandlerFailed("indexer", domain.BlogCreated, true)
+
+	body := scrape(t, m)
+	assert.Contains(t, body, `blog_event_bus_queue_depth 3`)
+	assert.Contains(t, body, `blog_event_bus_handler_failures_total{reason="error",subscriber="indexer",type="blog.created"} 1`)
+	assert.Contains(t, body, `blog_event_bus_handler_failures_total{reason="panic",subscriber="indexer",type="blog.created"} 1`)
+}
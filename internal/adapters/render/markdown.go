@@ -0,0 +1,61 @@
+package render
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/toffysoft/go-hexagonal-example/internal/core/domain"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer/html"
+)
+
+// markdown converts CommonMark with the GitHub Flavored Markdown extensions.
+// Table cells are aligned with the align attribute, since Sanitize drops
+// style attributes.
+var markdown = goldmark.New(
+	goldmark.WithExtensions(
+		extension.NewTable(extension.WithTableCellAlignMethod(extension.TableCellAlignAttribute)),
+		extension.Strikethrough,
+		extension.Linkify,
+		extension.TaskList,
+	),
+	goldmark.WithParserOptions(parser.WithAutoHeadingID()),
+	goldmark.WithRendererOptions(html.WithUnsafe()),
+)
+
+// Markdown renders CommonMark with the GitHub Flavored Markdown extensions
+// for tables, task lists, strikethrough and autolinks. Fenced code blocks get
+// a language-* class for syntax highlighters, and headings an id to link to.
+// Raw HTML is passed through, so the output must be sanitised.
+func Markdown(src string) string {
+	var b strings.Builder
+	ctx := parser.NewContext(parser.WithIDs(headingIDs{}))
+	// Writing to a strings.Builder cannot fail.
+	_ = markdown.Convert([]byte(src), &b, parser.WithContext(ctx))
+	return b.String()
+}
+
+// headingIDs gives each heading the slug of its text, numbered from the
+// second heading with the same text on.
+type headingIDs map[string]int
+
+func (ids headingIDs) Generate(value []byte, kind ast.NodeKind) []byte {
+	id := domain.Slugify(string(value))
+	if id == "" {
+		id = "section"
+	}
+	n := ids[id]
+	ids[id] = n + 1
+	if n > 0 {
+		id = fmt.Sprintf("%s-%d", id, n)
+	}
+	return []byte(id)
+}
+
+func (ids headingIDs) Put(value []byte) {
+	ids[string(value)]++
+}
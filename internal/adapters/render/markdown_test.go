@@ -0,0 +1,229 @@
+package render_test
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/toffysoft/go-hexagonal-example/internal/adapters/render"
+	"github.com/toffysoft/go-hexagonal-example/internal/core/domain"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/html"
+)
+
+func TestMarkdown(t *testing.T) {
+	tests := []struct {
+		name, markdown, html string
+	}{
+		{"Paragraphs", "Hello\nworld\n\nAgain", "<p>Hello\nworld</p>\n<p>Again</p>\n"},
+		{"HardBreak", "one  \ntwo\\\nthree", "<p>one<br>\ntwo<br>\nthree</p>\n"},
+		{"Headings", "# Title #\n\nSub\n---\n\n## Title", "<h1 id=\"title\">Title</h1>\n<h2 id=\"sub\">Sub</h2>\n<h2 id=\"title-1\">Title</h2>\n"},
+		{"HeadingWithoutSlug", "# ***\n\n# !", "<h1 id=\"section\">***</h1>\n<h1 id=\"section-1\">!</h1>\n"},
+		{"Emphasis", "*em* **strong** ***both*** _a_b_ ~~gone~~", "<p><em>em</em> <strong>strong</strong> <em><strong>both</strong></em> <em>a_b</em> <del>gone</del></p>\n"},
+		{"UnmatchedEmphasis", "2 * 3 * 4 and **open", "<p>2 * 3 * 4 and **open</p>\n"},
+		{"CodeSpan", "use `` a`b `` and `<b>`", "<p>use <code>a`b</code> and <code>&lt;b&gt;</code></p>\n"},
+		{"Escapes", `\*not em\* & 1 < 2 &copy;`, "<p>*not em* &amp; 1 &lt; 2 ©</p>\n"},
+		{"FencedCode", "```go\nfmt.Println(\"<hi>\")\n```", "<pre><code class=\"language-go\">fmt.Println(&quot;&lt;hi&gt;&quot;)\n</code></pre>\n"},
+		{"IndentedCode", "    line 1\n\n    line 2", "<pre><code>line 1\n\nline 2\n</code></pre>\n"},
+		{"Blockquote", "> quoted\nlazy\n\n> again", "<blockquote>\n<p>quoted\nlazy</p>\n</blockquote>\n<blockquote>\n<p>again</p>\n</blockquote>\n"},
+		{"TightList", "- one\n- two\n  - nested\n- three", "<ul>\n<li>one</li>\n<li>two\n<ul>\n<li>nested</li>\n</ul>\n</li>\n<li>three</li>\n</ul>\n"},
+		{"LooseList", "1. one\n\n2. two", "<ol>\n<li>\n<p>one</p>\n</li>\n<li>\n<p>two</p>\n</li>\n</ol>\n"},
+		{"TaskList", "- [ ] todo\n- [x] done", "<ul>\n<li><input disabled=\"\" type=\"checkbox\"> todo</li>\n<li><input checked=\"\" disabled=\"\" type=\"checkbox\"> done</li>\n</ul>\n"},
+		{"Table", "| a | b |\n|:--|--:|\n| 1 | `x\\|y` |", "<table>\n<thead>\n<tr>\n<th align=\"left\">a</th>\n<th align=\"right\">b</th>\n</tr>\n</thead>\n<tbody>\n<tr>\n<td align=\"left\">1</td>\n<td align=\"right\"><code>x|y</code></td>\n</tr>\n</tbody>\n</table>\n"},
+		{"Links", "[site](https://example.com \"Title\") and ![alt *x*](/a.png)", "<p><a href=\"https://example.com\" title=\"Title\">site</a> and <img src=\"/a.png\" alt=\"alt x\"></p>\n"},
+		{"ReferenceLinks", "[one][x] and [X]\n\n[x]: https://example.com/x", "<p><a href=\"https://example.com/x\">one</a> and <a href=\"https://example.com/x\">X</a></p>\n"},
+		{"Autolinks", "<https://a.example> see www.example.com/path. or https://b.example/(x))", "<p><a href=\"https://a.example\">https://a.example</a> see <a href=\"http://www.example.com/path\">www.example.com/path</a>. or <a href=\"https://b.example/(x)\">https://b.example/(x)</a>)</p>\n"},
+		{"RawHTML", "<div>\nblock\n</div>\n\ninline <span>x</span>", "<div>\nblock\n</div>\n<p>inline <span>x</span></p>\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.html, render.Markdown(tt.markdown))
+		})
+	}
+}
+
+// TestMarkdownSpec checks examples from the CommonMark and GFM specs. The
+// specs write void elements in XHTML syntax, which Markdown does not use.
+func TestMarkdownSpec(t *testing.T) {
+	tests := []struct {
+		name, markdown, html string
+	}{
+		{"Tabs", "\tfoo\tbaz\t\tbim\n", "<pre><code>foo\tbaz\t\tbim\n</code></pre>\n"},
+		{"BackslashEscapes", "\\*not emphasized*\n\\<br/> not a tag\n\\[not a link](/foo)\n", "<p>*not emphasized*\n&lt;br/&gt; not a tag\n[not a link](/foo)</p>\n"},
+		{"EntityReferences", "&nbsp; &amp; &copy; &AElig; &Dcaron;\n", "<p>\u00a0 &amp; © Æ Ď</p>\n"},
+		{"EntitiesInLinks", "[foo](/f&ouml;&ouml; \"f&ouml;&ouml;\")\n", "<p><a href=\"/f%C3%B6%C3%B6\" title=\"föö\">foo</a></p>\n"},
+		{"ThematicBreaks", "***\n---\n___\n", "<hr />\n<hr />\n<hr />\n"},
+		{"IndentedCode", "    a simple\n      indented code block\n", "<pre><code>a simple\n  indented code block\n</code></pre>\n"},
+		{"FencedCode", "```\n<\n >\n```\n", "<pre><code>&lt;\n &gt;\n</code></pre>\n"},
+		{"LinkReferenceDefinition", "[foo]: /url \"title\"\n\n[foo]\n", "<p><a href=\"/url\" title=\"title\">foo</a></p>\n"},
+		{"BlockquoteEndsAtBreak", "> foo\n---\n", "<blockquote>\n<p>foo</p>\n</blockquote>\n<hr />\n"},
+		{"ListMarkerChange", "- foo\n- bar\n+ baz\n", "<ul>\n<li>foo</li>\n<li>bar</li>\n</ul>\n<ul>\n<li>baz</li>\n</ul>\n"},
+		{"OrderedDelimiterChange", "1. foo\n2. bar\n3) baz\n", "<ol>\n<li>foo</li>\n<li>bar</li>\n</ol>\n<ol start=\"3\">\n<li>baz</li>\n</ol>\n"},
+		{"CodeSpanBackticks", "`` foo ` bar ``\n", "<p><code>foo ` bar</code></p>\n"},
+		{"EmphasisNeedsFlanking", "a * foo bar*\n", "<p>a * foo bar*</p>\n"},
+		{"IntrawordEmphasis", "foo*bar*\n", "<p>foo<em>bar</em></p>\n"},
+		{"IntrawordUnderscore", "_foo_bar_\n", "<p><em>foo_bar</em></p>\n"},
+		{"NestedEmphasis", "*foo**bar**baz*\n", "<p><em>foo<strong>bar</strong>baz</em></p>\n"},
+		{"LinkWithTitle", "[link](/uri \"title\")\n", "<p><a href=\"/uri\" title=\"title\">link</a></p>\n"},
+		{"LinkDestinationNoNewline", "[link](<foo\nbar>)\n", "<p>[link](<foo\nbar>)</p>\n"},
+		{"Image", "![foo](/url \"title\")\n", "<p><img src=\"/url\" alt=\"foo\" title=\"title\" /></p>\n"},
+		{"Autolink", "<http://foo.bar.baz>\n", "<p><a href=\"http://foo.bar.baz\">http://foo.bar.baz</a></p>\n"},
+		{"RawInlineHTML", "<a><bab><c2c>\n", "<p><a><bab><c2c></p>\n"},
+		{"HardLineBreak", "foo  \nbaz\n", "<p>foo<br />\nbaz</p>\n"},
+		{"GFMStrikethrough", "~~Hi~~ Hello, ~there~ world!\n", "<p><del>Hi</del> Hello, <del>there</del> world!</p>\n"},
+		{"GFMAutolink", "www.commonmark.org\n", "<p><a href=\"http://www.commonmark.org\">www.commonmark.org</a></p>\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, strings.ReplaceAll(tt.html, " />", ">"), render.Markdown(tt.markdown))
+		})
+	}
+}
+
+func TestSanitize(t *testing.T) {
+	tests := []struct {
+		name, html, safe string
+	}{
+		{"KeepsAllowed", `<p>a <strong>b</strong></p>`, `<p>a <strong>b</strong></p>`},
+		{"DropsScript", `<p>a<script>alert(1)</script></p><style>p{}</style>`, `<p>a</p>`},
+		{"DropsHandlers", `<img src="/x.png" onerror="alert(1)">`, `<img src="/x.png"/>`},
+		{"DropsScriptURLs", `<a href="javascript:alert(1)">x</a><img src="data:image/png;base64,AA">`, `x`},
+		{"LinksAreNofollow", `<a href="https://example.com/?a=1&amp;b=2">x</a>`, `<a href="https://example.com/?a=1&amp;b=2" rel="nofollow">x</a>`},
+		{"UnwrapsUnknown", `<div class="x"><font>text</font></div>`, `<div>text</div>`},
+		{"ClosesOpenTags", `<p><em>open`, `<p><em>open</em></p>`},
+		{"DropsStrayEndTags", `a</em></div>b`, `ab`},
+		{"ChecksClasses", `<code class="language-go">x</code><code class="x" onclick="y">z</code>`, `<code class="language-go">x</code><code>z</code>`},
+		{"ChecksInputs", `<input type="checkbox" checked><input type="text" value="x">`, `<input type="checkbox" checked=""/>`},
+		{"EscapesText", `1 &lt; 2 &amp;&amp; "quotes"`, `1 &lt; 2 &amp;&amp; &#34;quotes&#34;`},
+		{"DropsComments", `a<!-- <script>x</script> -->b`, `ab`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.safe, render.Sanitize(tt.html))
+		})
+	}
+}
+
+// assertSafe fails if fragment, parsed as a browser would, has an element
+// that can run script, an event handler or a URL with another scheme than
+// http, https or mailto.
+func assertSafe(t *testing.T, fragment string) {
+	t.Helper()
+	z := html.NewTokenizer(strings.NewReader(fragment))
+	for tt := z.Next(); tt != html.ErrorToken; tt = z.Next() {
+		if tt != html.StartTagToken && tt != html.SelfClosingTagToken {
+			continue
+		}
+		token := z.Token()
+		switch token.Data {
+		case "script", "style", "svg", "math", "iframe", "object", "embed", "form", "base", "meta", "link":
+			t.Errorf("unsafe <%s> in %q", token.Data, fragment)
+		}
+		for _, attr := range token.Attr {
+			key := strings.ToLower(attr.Key)
+			if strings.HasPrefix(key, "on") || key == "style" || key == "srcdoc" || key == "formaction" {
+				t.Errorf("unsafe %s attribute in %q", key, fragment)
+			}
+			if key == "href" || key == "src" {
+				u, err := url.Parse(attr.Val)
+				if err != nil {
+					t.Errorf("unparseable %s in %q", key, fragment)
+					continue
+				}
+				switch strings.ToLower(u.Scheme) {
+				case "", "http", "https", "mailto":
+				default:
+					t.Errorf("unsafe %s URL %q in %q", key, attr.Val, fragment)
+				}
+			}
+		}
+	}
+}
+
+func TestRenderBlocksXSS(t *testing.T) {
+	tests := []struct {
+		name, content string
+	}{
+		{"ScriptURL", `<a href="javascript:alert(1)">x</a>`},
+		{"MixedCaseScriptURL", `<a href="JaVaScRiPt:alert(1)">x</a>`},
+		{"EntityEncodedScriptURL", `<a href="&#106;&#97;&#118;&#97;&#115;&#99;&#114;&#105;&#112;&#116;&#58;alert(1)">x</a>`},
+		{"HexEntityColon", `<a href="javascript&#x3A;alert(1)">x</a>`},
+		{"NamedEntityColon", `<a href="javascript&colon;alert(1)">x</a>`},
+		{"TabInScheme", "<a href=\"java\tscript:alert(1)\">x</a>"},
+		{"EntityTabInScheme", `<a href="java&#x09;script:alert(1)">x</a>`},
+		{"NewlineInScheme", "<a href=\"java\nscript:alert(1)\">x</a>"},
+		{"LeadingSpace", `<a href="  javascript:alert(1)">x</a>`},
+		{"LeadingControlCharacter", "<a href=\"\x01javascript:alert(1)\">x</a>"},
+		{"VBScriptURL", `<a href="vbscript:msgbox(1)">x</a>`},
+		{"DataURL", `<a href="data:text/html;base64,PHNjcmlwdD5hbGVydCgxKTwvc2NyaXB0Pg==">x</a>`},
+		{"ImageScriptURL", `<img src="javascript:alert(1)">`},
+		{"MarkdownScriptURL", `[x](javascript:alert(1))`},
+		{"MarkdownMixedCaseScriptURL", `[x](JaVaScRiPt:alert(1))`},
+		{"MarkdownEntityScriptURL", `[x](&#106;avascript:alert(1))`},
+		{"MarkdownAngleScriptURL", `[x](<javascript:alert(1)>)`},
+		{"MarkdownReferenceScriptURL", "[x][a]\n\n[a]: javascript:alert(1)"},
+		{"MarkdownImageScriptURL", `![x](javascript:alert(1))`},
+		{"MarkdownAutolinkScriptURL", `<javascript:alert(1)>`},
+		{"SVGOnload", `<svg onload="alert(1)"><circle r="1"/></svg>`},
+		{"SVGScript", `<svg><script>alert(1)</script></svg>`},
+		{"SVGAnimateHref", `<svg><a><animate attributeName="href" values="javascript:alert(1)"/><text>x</text></a></svg>`},
+		{"MathHref", `<math><maction actiontype="statusline" xlink:href="javascript:alert(1)">x</maction></math>`},
+		{"MathStyleMutation", `<math><mtext><table><mglyph><style><img src=x onerror=alert(1)>`},
+		{"NoscriptMutation", `<noscript><p title="</noscript><img src=x onerror=alert(1)>">`},
+		{"UnquotedAttributeBreakout", `<img src=x alt=a onerror=alert(1)>`},
+		{"QuotedAttributeBreakout", `<img src="x" alt="&quot; onerror=&quot;alert(1)">`},
+		{"TitleBreakout", `<a href="/x" title='"><script>alert(1)</script>'>x</a>`},
+		{"BacktickAttribute", "<img src=`x` onerror=`alert(1)`>"},
+		{"SlashSeparatedAttribute", `<img/src="x"/onerror="alert(1)">`},
+		{"MarkdownTitleBreakout", `[x](/a "\"><script>alert(1)</script>")`},
+		{"MarkdownAltBreakout", `![" onerror="alert(1)](/a.png)`},
+		{"FencedLanguageBreakout", "```\" onmouseover=\"alert(1)\nx\n```"},
+		{"Iframe", `<iframe src="https://evil.example" srcdoc="<script>alert(1)</script>"></iframe>`},
+		{"FormAction", `<form><button formaction="javascript:alert(1)">x</button></form>`},
+		{"StyleAttribute", `<p style="background:url(javascript:alert(1))">x</p>`},
+		{"BaseHref", `<base href="javascript:alert(1)//">`},
+		{"MetaRefresh", `<meta http-equiv="refresh" content="0;url=javascript:alert(1)">`},
+		{"CommentBreakout", `<!--><img src=x onerror=alert(1)>-->`},
+		{"UnclosedScript", `<script>alert(1)`},
+		{"NestedScriptTags", `<scr<script>ipt>alert(1)</script>`},
+	}
+	renderer := render.NewRenderer()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, format := range []domain.ContentFormat{domain.ContentMarkdown, domain.ContentHTML} {
+				out, err := renderer.Render(context.Background(), format, tt.content)
+				assert.NoError(t, err)
+				assertSafe(t, out)
+				// Whatever a browser makes of the output must still be safe.
+				assertSafe(t, render.Sanitize(out))
+			}
+		})
+	}
+}
+
+func TestRenderMarkdownIsSanitised(t *testing.T) {
+	out, err := render.NewRenderer().Render(context.Background(), "markdown", "[x](javascript:alert(1)) <img src=x onerror=alert(1)>\n\n<script>alert(1)</script>")
+	assert.NoError(t, err)
+	assert.Equal(t, "<p>x <img src=\"x\"/></p>\n", out)
+}
+
+func TestRenderPlain(t *testing.T) {
+	out, err := render.NewRenderer().Render(context.Background(), "plain", "a <b>\nc\n\n\nd")
+	assert.NoError(t, err)
+	assert.Equal(t, "<p>a &lt;b&gt;<br>\nc</p>\n<p>d</p>\n", out)
+}
+
+func TestAnalyze(t *testing.T) {
+	text, err := render.NewRenderer().Analyze(context.Background(), "markdown",
+		"# Intro\n\nSome *emphasised* [link](https://example.com) text.\n\n```go\nfmt.Println()\n```\n\n- one\n- two\n\n## Intro\n\n<script>hidden words</script>")
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Some emphasised link text.", "one", "two"}, text.Prose)
+	assert.Equal(t, 9, text.Words)
+	assert.Equal(t, []domain.Heading{
+		{Level: 1, Text: "Intro", ID: "intro"},
+		{Level: 2, Text: "Intro", ID: "intro-1"},
+	}, text.Headings)
+}
@@ -0,0 +1,59 @@
+package render
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"strings"
+
+	"github.com/toffysoft/go-hexagonal-example/internal/core/domain"
+	"github.com/toffysoft/go-hexagonal-example/internal/core/ports"
+)
+
+// Renderer renders Markdown and HTML content as sanitised HTML, and plain
+// text as escaped paragraphs. It also analyzes content as it renders it.
+type Renderer struct{}
+
+var (
+	_ ports.ContentRenderer = Renderer{}
+	_ ports.ContentAnalyzer = Renderer{}
+)
+
+func NewRenderer() Renderer {
+	return Renderer{}
+}
+
+func (Renderer) Render(ctx context.Context, format domain.ContentFormat, content string) (string, error) {
+	switch format {
+	case domain.ContentMarkdown:
+		return Sanitize(Markdown(content)), nil
+	case domain.ContentHTML:
+		return Sanitize(content), nil
+	case domain.ContentPlain:
+		return plain(content), nil
+	}
+	return "", fmt.Errorf("unknown content format %q", format)
+}
+
+// Analyze returns the text of the rendered content, so that headings and
+// their ids are those of the rendering.
+func (r Renderer) Analyze(ctx context.Context, format domain.ContentFormat, content string) (domain.ContentText, error) {
+	rendered, err := r.Render(ctx, format, content)
+	if err != nil {
+		return domain.ContentText{}, err
+	}
+	return Text(rendered), nil
+}
+
+// plain renders text as paragraphs separated by blank lines, keeping its
+// line breaks.
+func plain(text string) string {
+	var b strings.Builder
+	for _, para := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n\n") {
+		if para = strings.Trim(para, "\n"); strings.TrimSpace(para) == "" {
+			continue
+		}
+		b.WriteString("<p>" + strings.ReplaceAll(html.EscapeString(para), "\n", "<br>\n") + "</p>\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,45 @@
+package render
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/microcosm-cc/bluemonday"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// policy is bluemonday's policy for user-generated content, which allows
+// http, https and mailto URLs and makes links nofollow. It also keeps the
+// language classes of code blocks and the checkboxes of task lists.
+var policy = func() *bluemonday.Policy {
+	p := bluemonday.UGCPolicy()
+	p.AllowAttrs("class").Matching(regexp.MustCompile(`^language-[A-Za-z0-9_+#.-]+$`)).OnElements("code")
+	p.AllowAttrs("type").Matching(regexp.MustCompile(`^checkbox$`)).OnElements("input")
+	p.AllowAttrs("checked", "disabled").Matching(regexp.MustCompile(`^(|checked|disabled)$`)).OnElements("input")
+	return p
+}()
+
+// Sanitize returns fragment with every element, attribute and URL scheme not
+// on an allowlist removed, so that it cannot run script when embedded in a
+// page. Tags left open are closed and stray end tags dropped first, so that
+// the fragment cannot change the markup around it.
+func Sanitize(fragment string) string {
+	return policy.Sanitize(balance(fragment))
+}
+
+// balance parses fragment as the content of a body element, as a browser
+// would, and serialises the result.
+func balance(fragment string) string {
+	body := &html.Node{Type: html.ElementNode, Data: "body", DataAtom: atom.Body}
+	nodes, err := html.ParseFragment(strings.NewReader(fragment), body)
+	if err != nil {
+		// Reading from a strings.Reader cannot fail.
+		return ""
+	}
+	var b strings.Builder
+	for _, node := range nodes {
+		_ = html.Render(&b, node)
+	}
+	return b.String()
+}
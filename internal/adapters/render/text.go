@@ -0,0 +1,97 @@
+package render
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/toffysoft/go-hexagonal-example/internal/core/domain"
+
+	"golang.org/x/net/html"
+)
+
+// blockElements separate the text of a fragment into blocks.
+var blockElements = map[string]bool{
+	"p": true, "li": true, "blockquote": true, "pre": true, "td": true, "th": true,
+	"dt": true, "dd": true, "summary": true, "hr": true, "table": true, "tr": true,
+	"ul": true, "ol": true, "dl": true, "details": true,
+	"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+}
+
+// Text returns the text of a sanitised HTML fragment, such as the output of
+// Render, with its headings.
+func Text(fragment string) domain.ContentText {
+	var text domain.ContentText
+	var buf strings.Builder
+	var heading *domain.Heading
+	inPre := 0
+
+	flush := func() {
+		block := strings.Join(strings.Fields(buf.String()), " ")
+		buf.Reset()
+		if block == "" {
+			return
+		}
+		text.Words += len(strings.Fields(block))
+		switch {
+		case heading != nil:
+			heading.Text = block
+			text.Headings = append(text.Headings, *heading)
+		case inPre == 0:
+			text.Prose = append(text.Prose, block)
+		}
+	}
+
+	z := html.NewTokenizer(strings.NewReader(fragment))
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			break
+		}
+		token := z.Token()
+		switch tt {
+		case html.TextToken:
+			buf.WriteString(token.Data)
+		case html.StartTagToken, html.SelfClosingTagToken:
+			switch name := token.Data; {
+			case name == "br":
+				buf.WriteByte(' ')
+			case blockElements[name]:
+				flush()
+				heading = nil
+				if level := headingLevel(name); level > 0 {
+					heading = &domain.Heading{Level: level, ID: attribute(token, "id")}
+				}
+				if name == "pre" {
+					inPre++
+				}
+			}
+		case html.EndTagToken:
+			if blockElements[token.Data] {
+				flush()
+				heading = nil
+				if token.Data == "pre" && inPre > 0 {
+					inPre--
+				}
+			}
+		}
+	}
+	flush()
+	return text
+}
+
+func headingLevel(name string) int {
+	if len(name) == 2 && name[0] == 'h' && name[1] >= '1' && name[1] <= '6' {
+		level, _ := strconv.Atoi(name[1:])
+		return level
+	}
+	return 0
+}
+
+func attribute(token html.Token, key string) string {
+	for _, attr := range token.Attr {
+		if attr.Key == key && attr.Namespace == "" {
+			return attr.Val
+		}
+	}
+	return ""
+}
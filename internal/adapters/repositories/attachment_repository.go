@@ -0,0 +1,49 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/toffysoft/go-hexagonal-example/internal/core/domain"
+	"github.com/toffysoft/go-hexagonal-example/internal/core/ports"
+
+	"gorm.io/gorm"
+)
+
+type attachmentRepository struct {
+	db *gorm.DB
+}
+
+func NewAttachmentRepository(db *gorm.DB) ports.AttachmentRepository {
+	return &attachmentRepository{db: db}
+}
+
+func (r *attachmentRepository) Create(ctx context.Context, attachment *domain.Attachment) error {
+	return conn(ctx, r.db).Create(attachment).Error
+}
+
+func (r *attachmentRepository) GetByID(ctx context.Context, id uint) (*domain.Attachment, error) {
+	var attachment domain.Attachment
+	err := conn(ctx, r.db).First(&attachment, id).Error
+	return &attachment, err
+}
+
+func (r *attachmentRepository) Delete(ctx context.Context, id uint) error {
+	return conn(ctx, r.db).Delete(&domain.Attachment{}, id).Error
+}
+
+func (r *attachmentRepository) ListByBlog(ctx context.Context, blogID uint) ([]*domain.Attachment, error) {
+	var attachments []*domain.Attachment
+	err := conn(ctx, r.db).Where("blog_id = ?", blogID).Order("id").Find(&attachments).Error
+	return attachments, err
+}
+
+func (r *attachmentRepository) Orphans(ctx context.Context, limit int) ([]*domain.Attachment, error) {
+	var attachments []*domain.Attachment
+	blogs := conn(ctx, r.db).Model(&domain.Blog{}).Select("1").Where("blogs.id = attachments.blog_id")
+	err := conn(ctx, r.db).
+		Where("NOT EXISTS (?)", blogs).
+		Order("id").
+		Limit(limit).
+		Find(&attachments).Error
+	return attachments, err
+}
@@ -1,12 +1,23 @@
 package repositories
 
 import (
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+
 	"github.com/toffysoft/go-hexagonal-example/internal/core/domain"
 	"github.com/toffysoft/go-hexagonal-example/internal/core/ports"
+	"github.com/toffysoft/go-hexagonal-example/pkg/errors"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/plugin/dbresolver"
 )
 
+// createBatchSize is the number of rows per INSERT statement of CreateBatch.
+const createBatchSize = 100
+
 type blogRepository struct {
 	db *gorm.DB
 }
@@ -15,26 +26,134 @@ func NewBlogRepository(db *gorm.DB) ports.BlogRepository {
 	return &blogRepository{db: db}
 }
 
-func (r *blogRepository) Create(blog *domain.Blog) error {
-	return r.db.Create(blog).Error
+// reader returns a session for queries. Queries go to a replica when one is
+// configured, unless ctx asks for strong consistency or is in a transaction.
+func (r *blogRepository) reader(ctx context.Context) *gorm.DB {
+	db := conn(ctx, r.db)
+	if ports.IsStrongConsistency(ctx) {
+		return db.Clauses(dbresolver.Write)
+	}
+	return db
 }
 
-func (r *blogRepository) GetByID(id uint) (*domain.Blog, error) {
+func (r *blogRepository) Create(ctx context.Context, blog *domain.Blog) error {
+	return conn(ctx, r.db).Create(blog).Error
+}
+
+// GetByID locks the row for the rest of the unit of work it runs in, if
+// any, so that concurrent read-modify-write cycles of a blog are serialised.
+func (r *blogRepository) GetByID(ctx context.Context, id uint) (*domain.Blog, error) {
+	query := r.reader(ctx)
+	if inTransaction(ctx) {
+		query = query.Clauses(clause.Locking{Strength: "UPDATE"})
+	}
 	var blog domain.Blog
-	err := r.db.First(&blog, id).Error
-	return &blog, err
+	err := query.First(&blog, id).Error
+	return &blog, notFound(err, id)
+}
+
+func (r *blogRepository) GetFields(ctx context.Context, id uint, mask domain.FieldMask) (*domain.Blog, error) {
+	if mask.All() {
+		return r.GetByID(ctx, id)
+	}
+	var blog domain.Blog
+	err := r.reader(ctx).Select(mask.Columns()).First(&blog, id).Error
+	return &blog, notFound(err, id)
+}
+
+// notFound turns the error of a read of the blog with the given ID into a
+// NotFound AppError when there is no such blog.
+func notFound(err error, id uint) error {
+	if stderrors.Is(err, gorm.ErrRecordNotFound) {
+		return errors.NewNotFoundError(fmt.Sprintf("Blog with ID %d not found", id))
+	}
+	return err
 }
 
-func (r *blogRepository) Update(blog *domain.Blog) error {
-	return r.db.Save(blog).Error
+// Update writes every field of blog but its creation time. Unlike Save, it
+// never inserts: a blog deleted in the meantime is NotFound.
+func (r *blogRepository) Update(ctx context.Context, blog *domain.Blog) error {
+	// With blog as the model, its ID is the condition and its UpdatedAt is
+	// set to the time written.
+	result := conn(ctx, r.db).Model(blog).Select("*").Omit("created_at").Updates(blog)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.NewNotFoundError(fmt.Sprintf("Blog with ID %d not found", blog.ID))
+	}
+	return nil
 }
 
-func (r *blogRepository) Delete(id uint) error {
-	return r.db.Delete(&domain.Blog{}, id).Error
+func (r *blogRepository) Delete(ctx context.Context, id uint) error {
+	return conn(ctx, r.db).Delete(&domain.Blog{}, id).Error
 }
 
-func (r *blogRepository) List() ([]*domain.Blog, error) {
+func (r *blogRepository) List(ctx context.Context) ([]*domain.Blog, error) {
 	var blogs []*domain.Blog
-	err := r.db.Find(&blogs).Error
+	err := r.reader(ctx).Find(&blogs).Error
 	return blogs, err
 }
+
+func (r *blogRepository) ListFields(ctx context.Context, mask domain.FieldMask) ([]*domain.Blog, error) {
+	if mask.All() {
+		return r.List(ctx)
+	}
+	var blogs []*domain.Blog
+	err := r.reader(ctx).Select(mask.Columns()).Find(&blogs).Error
+	return blogs, err
+}
+
+func (r *blogRepository) ListAfter(ctx context.Context, filter domain.BlogFilter, afterID uint, limit int) ([]*domain.Blog, error) {
+	query := where(r.reader(ctx), filter).Where("id > ?", afterID)
+
+	var blogs []*domain.Blog
+	err := query.Order("id").Limit(limit).Find(&blogs).Error
+	return blogs, err
+}
+
+func (r *blogRepository) ListLatest(ctx context.Context, filter domain.BlogFilter, mask domain.FieldMask, limit int) ([]*domain.Blog, error) {
+	query := where(r.reader(ctx), filter)
+	if !mask.All() {
+		query = query.Select(mask.Columns())
+	}
+
+	var blogs []*domain.Blog
+	err := query.Order("created_at DESC, id DESC").Limit(limit).Find(&blogs).Error
+	return blogs, err
+}
+
+// where adds the conditions of filter to query.
+func where(query *gorm.DB, filter domain.BlogFilter) *gorm.DB {
+	if filter.Author != "" {
+		query = query.Where("author = ?", filter.Author)
+	}
+	if filter.Tag != "" {
+		tags, _ := json.Marshal([]string{filter.Tag})
+		query = query.Where("tags @> ?::jsonb", string(tags))
+	}
+	if !filter.CreatedAfter.IsZero() {
+		query = query.Where("created_at >= ?", filter.CreatedAfter)
+	}
+	if !filter.CreatedBefore.IsZero() {
+		query = query.Where("created_at < ?", filter.CreatedBefore)
+	}
+	if filter.Unsummarized {
+		query = query.Where("word_count = 0 AND content <> ''")
+	}
+	return query
+}
+
+func (r *blogRepository) CreateBatch(ctx context.Context, blogs []*domain.Blog) error {
+	return conn(ctx, r.db).CreateInBatches(blogs, createBatchSize).Error
+}
+
+func (r *blogRepository) GetByIDs(ctx context.Context, ids []uint) ([]*domain.Blog, error) {
+	var blogs []*domain.Blog
+	err := r.reader(ctx).Where("id IN ?", ids).Order("id").Find(&blogs).Error
+	return blogs, err
+}
+
+func (r *blogRepository) DeleteBatch(ctx context.Context, ids []uint) error {
+	return conn(ctx, r.db).Delete(&domain.Blog{}, ids).Error
+}
@@ -0,0 +1,78 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/toffysoft/go-hexagonal-example/internal/core/domain"
+	"github.com/toffysoft/go-hexagonal-example/internal/core/ports"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type idempotencyRepository struct {
+	db *gorm.DB
+}
+
+func NewIdempotencyRepository(db *gorm.DB) ports.IdempotencyStore {
+	return &idempotencyRepository{db: db}
+}
+
+func (r *idempotencyRepository) Reserve(ctx context.Context, record *domain.IdempotencyRecord, now time.Time) (*domain.IdempotencyRecord, error) {
+	for {
+		// The insert replaces an expired record in the same statement, so two
+		// requests racing for an expired key cannot both win it.
+		result := conn(ctx, r.db).Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "key"}},
+			DoUpdates: clause.AssignmentColumns([]string{
+				"token", "fingerprint", "completed", "status_code",
+				"content_type", "body", "created_at", "expires_at",
+			}),
+			Where: clause.Where{Exprs: []clause.Expression{
+				clause.Lte{Column: clause.Column{Table: "idempotency_records", Name: "expires_at"}, Value: now},
+			}},
+		}).Create(record)
+		if result.Error != nil {
+			return nil, result.Error
+		}
+		if result.RowsAffected > 0 {
+			return nil, nil
+		}
+
+		var existing domain.IdempotencyRecord
+		err := conn(ctx, r.db).Where("key = ?", record.Key).Take(&existing).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			// Released or cleaned up since the insert; try again.
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		return &existing, nil
+	}
+}
+
+func (r *idempotencyRepository) Complete(ctx context.Context, record *domain.IdempotencyRecord, response domain.IdempotentResponse, expiresAt time.Time) error {
+	return conn(ctx, r.db).Model(&domain.IdempotencyRecord{}).
+		Where("key = ? AND token = ?", record.Key, record.Token).
+		Updates(map[string]interface{}{
+			"completed":    true,
+			"status_code":  response.StatusCode,
+			"content_type": response.ContentType,
+			"body":         response.Body,
+			"expires_at":   expiresAt,
+		}).Error
+}
+
+func (r *idempotencyRepository) Release(ctx context.Context, record *domain.IdempotencyRecord) error {
+	return conn(ctx, r.db).
+		Where("key = ? AND token = ?", record.Key, record.Token).
+		Delete(&domain.IdempotencyRecord{}).Error
+}
+
+func (r *idempotencyRepository) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	result := conn(ctx, r.db).Where("expires_at <= ?", now).Delete(&domain.IdempotencyRecord{})
+	return result.RowsAffected, result.Error
+}
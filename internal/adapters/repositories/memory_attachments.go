@@ -0,0 +1,88 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/toffysoft/go-hexagonal-example/internal/core/domain"
+	"github.com/toffysoft/go-hexagonal-example/internal/core/ports"
+	"github.com/toffysoft/go-hexagonal-example/pkg/errors"
+)
+
+// MemoryAttachmentRepository keeps attachments in memory, for tests and
+// local runs without a database. Orphans are found by looking their blogs
+// up in blogs.
+type MemoryAttachmentRepository struct {
+	blogs       ports.BlogRepository
+	mu          sync.Mutex
+	attachments map[uint]domain.Attachment
+	nextID      uint
+}
+
+var _ ports.AttachmentRepository = (*MemoryAttachmentRepository)(nil)
+
+func NewMemoryAttachmentRepository(blogs ports.BlogRepository) *MemoryAttachmentRepository {
+	return &MemoryAttachmentRepository{blogs: blogs, attachments: make(map[uint]domain.Attachment)}
+}
+
+func (r *MemoryAttachmentRepository) Create(ctx context.Context, attachment *domain.Attachment) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.nextID++
+	attachment.ID = r.nextID
+	r.attachments[attachment.ID] = *attachment
+	return nil
+}
+
+func (r *MemoryAttachmentRepository) GetByID(ctx context.Context, id uint) (*domain.Attachment, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	attachment, ok := r.attachments[id]
+	if !ok {
+		return nil, errors.NewNotFoundError(fmt.Sprintf("Attachment with ID %d not found", id))
+	}
+	return &attachment, nil
+}
+
+func (r *MemoryAttachmentRepository) Delete(ctx context.Context, id uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.attachments, id)
+	return nil
+}
+
+func (r *MemoryAttachmentRepository) ListByBlog(ctx context.Context, blogID uint) ([]*domain.Attachment, error) {
+	return r.list(func(a domain.Attachment) bool { return a.BlogID == blogID }, 0), nil
+}
+
+func (r *MemoryAttachmentRepository) Orphans(ctx context.Context, limit int) ([]*domain.Attachment, error) {
+	return r.list(func(a domain.Attachment) bool {
+		_, err := r.blogs.GetByID(ctx, a.BlogID)
+		return err != nil
+	}, limit), nil
+}
+
+// list returns up to limit attachments matching keep in ID order, or all of
+// them when limit is zero.
+func (r *MemoryAttachmentRepository) list(keep func(domain.Attachment) bool, limit int) []*domain.Attachment {
+	r.mu.Lock()
+	all := make([]domain.Attachment, 0, len(r.attachments))
+	for _, a := range r.attachments {
+		all = append(all, a)
+	}
+	r.mu.Unlock()
+	sort.Slice(all, func(i, j int) bool { return all[i].ID < all[j].ID })
+
+	var attachments []*domain.Attachment
+	for i := range all {
+		if keep(all[i]) {
+			attachments = append(attachments, &all[i])
+			if len(attachments) == limit {
+				break
+			}
+		}
+	}
+	return attachments
+}
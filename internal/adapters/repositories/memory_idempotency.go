@@ -0,0 +1,69 @@
+package repositories
+
+import (
+	"bytes"
+	"context"
+	"sync"
+	"time"
+
+	"github.com/toffysoft/go-hexagonal-example/internal/core/domain"
+)
+
+// MemoryIdempotencyStore keeps idempotency records in memory, for tests and
+// single-instance runs without a database.
+type MemoryIdempotencyStore struct {
+	mu      sync.Mutex
+	records map[string]domain.IdempotencyRecord
+}
+
+func NewMemoryIdempotencyStore() *MemoryIdempotencyStore {
+	return &MemoryIdempotencyStore{records: make(map[string]domain.IdempotencyRecord)}
+}
+
+func (s *MemoryIdempotencyStore) Reserve(ctx context.Context, record *domain.IdempotencyRecord, now time.Time) (*domain.IdempotencyRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if existing, ok := s.records[record.Key]; ok && existing.ExpiresAt.After(now) {
+		return &existing, nil
+	}
+	s.records[record.Key] = *record
+	return nil, nil
+}
+
+func (s *MemoryIdempotencyStore) Complete(ctx context.Context, record *domain.IdempotencyRecord, response domain.IdempotentResponse, expiresAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stored, ok := s.records[record.Key]
+	if !ok || stored.Token != record.Token {
+		return nil
+	}
+	stored.Completed = true
+	stored.StatusCode = response.StatusCode
+	stored.ContentType = response.ContentType
+	stored.Body = bytes.Clone(response.Body)
+	stored.ExpiresAt = expiresAt
+	s.records[record.Key] = stored
+	return nil
+}
+
+func (s *MemoryIdempotencyStore) Release(ctx context.Context, record *domain.IdempotencyRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if stored, ok := s.records[record.Key]; ok && stored.Token == record.Token {
+		delete(s.records, record.Key)
+	}
+	return nil
+}
+
+func (s *MemoryIdempotencyStore) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var n int64
+	for key, record := range s.records {
+		if !record.ExpiresAt.After(now) {
+			delete(s.records, key)
+			n++
+		}
+	}
+	return n, nil
+}
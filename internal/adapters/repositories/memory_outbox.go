@@ -0,0 +1,108 @@
+package repositories
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/toffysoft/go-hexagonal-example/internal/core/domain"
+	"github.com/toffysoft/go-hexagonal-example/internal/core/ports"
+)
+
+// MemoryOutbox keeps outbox messages in memory, for tests and single-instance
+// runs without a database. Messages are stored as soon as they are added; it
+// does not join units of work.
+type MemoryOutbox struct {
+	mu       sync.Mutex
+	messages []domain.OutboxMessage
+}
+
+var _ ports.OutboxRepository = (*MemoryOutbox)(nil)
+
+func NewMemoryOutbox() *MemoryOutbox {
+	return &MemoryOutbox{}
+}
+
+func (o *MemoryOutbox) Add(ctx context.Context, events ...domain.Event) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	for _, event := range events {
+		message := domain.NewOutboxMessage(event)
+		message.ID = uint(len(o.messages) + 1)
+		message.CreatedAt = time.Now()
+		o.messages = append(o.messages, *message)
+	}
+	return nil
+}
+
+func (o *MemoryOutbox) Pending(ctx context.Context, now time.Time, limit int) ([]*domain.OutboxMessage, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	var pending []*domain.OutboxMessage
+	for _, message := range o.messages {
+		if len(pending) == limit {
+			break
+		}
+		if message.PublishedAt == nil && message.DeadLetteredAt == nil && !message.NextAttemptAt.After(now) {
+			pending = append(pending, &message)
+		}
+	}
+	return pending, nil
+}
+
+func (o *MemoryOutbox) MarkPublished(ctx context.Context, id uint, at time.Time) error {
+	return o.update(id, func(message *domain.OutboxMessage) {
+		message.Attempts++
+		message.LastError = ""
+		message.PublishedAt = &at
+	})
+}
+
+func (o *MemoryOutbox) MarkFailed(ctx context.Context, id uint, reason string, retryAt time.Time) error {
+	return o.update(id, func(message *domain.OutboxMessage) {
+		message.Attempts++
+		message.LastError = reason
+		message.NextAttemptAt = retryAt
+	})
+}
+
+func (o *MemoryOutbox) MarkDeadLettered(ctx context.Context, id uint, reason string, at time.Time) error {
+	return o.update(id, func(message *domain.OutboxMessage) {
+		message.Attempts++
+		message.LastError = reason
+		message.DeadLetteredAt = &at
+	})
+}
+
+func (o *MemoryOutbox) After(ctx context.Context, id uint, limit int) ([]*domain.OutboxMessage, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	// IDs are positions in o.messages plus one.
+	start := min(int(id), len(o.messages))
+	end := min(start+limit, len(o.messages))
+	return copyMessages(o.messages[start:end]), nil
+}
+
+func (o *MemoryOutbox) Latest(ctx context.Context, limit int) ([]*domain.OutboxMessage, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return copyMessages(o.messages[max(len(o.messages)-limit, 0):]), nil
+}
+
+func (o *MemoryOutbox) update(id uint, fn func(*domain.OutboxMessage)) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if id > 0 && int(id) <= len(o.messages) {
+		fn(&o.messages[id-1])
+	}
+	return nil
+}
+
+func copyMessages(messages []domain.OutboxMessage) []*domain.OutboxMessage {
+	copies := make([]*domain.OutboxMessage, len(messages))
+	for i := range messages {
+		message := messages[i]
+		copies[i] = &message
+	}
+	return copies
+}
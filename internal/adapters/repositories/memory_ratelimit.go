@@ -0,0 +1,44 @@
+package repositories
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/toffysoft/go-hexagonal-example/internal/core/domain"
+)
+
+// MemoryRateLimitStore keeps token buckets in memory, so every instance
+// enforces its limits on its own.
+type MemoryRateLimitStore struct {
+	mu      sync.Mutex
+	buckets map[string]*domain.TokenBucket
+}
+
+func NewMemoryRateLimitStore() *MemoryRateLimitStore {
+	return &MemoryRateLimitStore{buckets: make(map[string]*domain.TokenBucket)}
+}
+
+func (s *MemoryRateLimitStore) Take(ctx context.Context, key string, policy domain.RateLimitPolicy, now time.Time) (domain.RateLimitDecision, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	bucket, ok := s.buckets[key]
+	if !ok {
+		bucket = domain.NewTokenBucket(key, policy, now)
+		s.buckets[key] = bucket
+	}
+	return bucket.Take(policy, now), nil
+}
+
+func (s *MemoryRateLimitStore) DeleteFull(ctx context.Context, now time.Time) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var n int64
+	for key, bucket := range s.buckets {
+		if !bucket.FullAt.After(now) {
+			delete(s.buckets, key)
+			n++
+		}
+	}
+	return n, nil
+}
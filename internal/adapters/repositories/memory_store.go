@@ -0,0 +1,334 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/toffysoft/go-hexagonal-example/internal/core/domain"
+	"github.com/toffysoft/go-hexagonal-example/internal/core/ports"
+	"github.com/toffysoft/go-hexagonal-example/pkg/errors"
+)
+
+// MemoryStore keeps blogs in memory. It is meant for tests and local runs
+// without a database, and supports the same transaction semantics as the
+// GORM adapters: work done through its UnitOfWork is only visible to other
+// callers once committed, and nested units of work behave like savepoints.
+type MemoryStore struct {
+	mu     sync.RWMutex
+	blogs  map[uint]domain.Blog
+	nextID uint
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{blogs: make(map[uint]domain.Blog)}
+}
+
+// Blogs returns a blog repository over the store.
+func (s *MemoryStore) Blogs() ports.BlogRepository {
+	return &memoryBlogRepository{store: s}
+}
+
+// UnitOfWork returns a unit of work over the store.
+func (s *MemoryStore) UnitOfWork() ports.UnitOfWork {
+	return &memoryUnitOfWork{store: s}
+}
+
+// memoryTxKey binds the innermost open memoryTx to a context.
+type memoryTxKey struct{}
+
+// memoryTx records the writes of one transaction or savepoint. A nil entry
+// marks a deleted blog. Reads fall through to the parent and then to the store.
+type memoryTx struct {
+	store   *MemoryStore
+	parent  *memoryTx
+	mu      sync.Mutex
+	changes map[uint]*domain.Blog
+}
+
+func (tx *memoryTx) get(id uint) (domain.Blog, bool) {
+	for t := tx; t != nil; t = t.parent {
+		t.mu.Lock()
+		blog, ok := t.changes[id]
+		t.mu.Unlock()
+		if ok {
+			if blog == nil {
+				return domain.Blog{}, false
+			}
+			return *blog, true
+		}
+	}
+	return tx.store.get(id)
+}
+
+func (tx *memoryTx) set(id uint, blog *domain.Blog) {
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+	tx.changes[id] = blog
+}
+
+// commit applies the recorded writes to the parent, or to the store for a
+// top-level transaction.
+func (tx *memoryTx) commit() {
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+	if tx.parent != nil {
+		for id, blog := range tx.changes {
+			tx.parent.set(id, blog)
+		}
+		return
+	}
+
+	tx.store.mu.Lock()
+	defer tx.store.mu.Unlock()
+	for id, blog := range tx.changes {
+		if blog == nil {
+			delete(tx.store.blogs, id)
+		} else {
+			tx.store.blogs[id] = *blog
+		}
+	}
+}
+
+type memoryUnitOfWork struct {
+	store *MemoryStore
+}
+
+// Do discards the recorded writes when fn fails or panics, which is all a
+// rollback needs to do.
+func (u *memoryUnitOfWork) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	return ports.TrackCommit(ctx, func(ctx context.Context) error {
+		parent, _ := ctx.Value(memoryTxKey{}).(*memoryTx)
+		tx := &memoryTx{store: u.store, parent: parent, changes: make(map[uint]*domain.Blog)}
+
+		if err := fn(context.WithValue(ctx, memoryTxKey{}, tx)); err != nil {
+			return err
+		}
+		tx.commit()
+		return nil
+	})
+}
+
+func (s *MemoryStore) get(id uint) (domain.Blog, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	blog, ok := s.blogs[id]
+	return blog, ok
+}
+
+// set writes blog through the transaction bound to ctx, or directly to the
+// store when there is none.
+func (s *MemoryStore) set(ctx context.Context, id uint, blog *domain.Blog) {
+	if tx, ok := ctx.Value(memoryTxKey{}).(*memoryTx); ok {
+		tx.set(id, blog)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if blog == nil {
+		delete(s.blogs, id)
+	} else {
+		s.blogs[id] = *blog
+	}
+}
+
+func (s *MemoryStore) lookup(ctx context.Context, id uint) (domain.Blog, bool) {
+	if tx, ok := ctx.Value(memoryTxKey{}).(*memoryTx); ok {
+		return tx.get(id)
+	}
+	return s.get(id)
+}
+
+// allocateID hands out IDs like a database sequence: IDs used by rolled back
+// transactions are not reused.
+func (s *MemoryStore) allocateID() uint {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.nextID++
+	return s.nextID
+}
+
+type memoryBlogRepository struct {
+	store *MemoryStore
+}
+
+func (r *memoryBlogRepository) Create(ctx context.Context, blog *domain.Blog) error {
+	if blog.ID == 0 {
+		blog.ID = r.store.allocateID()
+	} else if _, exists := r.store.lookup(ctx, blog.ID); exists {
+		return errors.NewInvalidInputError(fmt.Sprintf("Blog with ID %d already exists", blog.ID))
+	}
+	now := time.Now()
+	if blog.CreatedAt.IsZero() {
+		blog.CreatedAt = now
+	}
+	blog.UpdatedAt = now
+
+	stored := *blog
+	r.store.set(ctx, blog.ID, &stored)
+	return nil
+}
+
+func (r *memoryBlogRepository) GetByID(ctx context.Context, id uint) (*domain.Blog, error) {
+	blog, ok := r.store.lookup(ctx, id)
+	if !ok {
+		return &domain.Blog{}, errors.NewNotFoundError(fmt.Sprintf("Blog with ID %d not found", id))
+	}
+	return &blog, nil
+}
+
+func (r *memoryBlogRepository) GetFields(ctx context.Context, id uint, mask domain.FieldMask) (*domain.Blog, error) {
+	blog, err := r.GetByID(ctx, id)
+	if err != nil {
+		return blog, err
+	}
+	return mask.Apply(blog), nil
+}
+
+func (r *memoryBlogRepository) Update(ctx context.Context, blog *domain.Blog) error {
+	if _, ok := r.store.lookup(ctx, blog.ID); !ok {
+		return errors.NewNotFoundError(fmt.Sprintf("Blog with ID %d not found", blog.ID))
+	}
+	blog.UpdatedAt = time.Now()
+	stored := *blog
+	r.store.set(ctx, blog.ID, &stored)
+	return nil
+}
+
+func (r *memoryBlogRepository) Delete(ctx context.Context, id uint) error {
+	r.store.set(ctx, id, nil)
+	return nil
+}
+
+func (r *memoryBlogRepository) List(ctx context.Context) ([]*domain.Blog, error) {
+	r.store.mu.RLock()
+	ids := make([]uint, 0, len(r.store.blogs))
+	for id := range r.store.blogs {
+		ids = append(ids, id)
+	}
+	r.store.mu.RUnlock()
+
+	if tx, ok := ctx.Value(memoryTxKey{}).(*memoryTx); ok {
+		for t := tx; t != nil; t = t.parent {
+			t.mu.Lock()
+			for id := range t.changes {
+				ids = append(ids, id)
+			}
+			t.mu.Unlock()
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	blogs := make([]*domain.Blog, 0, len(ids))
+	for i, id := range ids {
+		if i > 0 && ids[i-1] == id {
+			continue
+		}
+		if blog, ok := r.store.lookup(ctx, id); ok {
+			blogs = append(blogs, &blog)
+		}
+	}
+	return blogs, nil
+}
+
+func (r *memoryBlogRepository) ListFields(ctx context.Context, mask domain.FieldMask) ([]*domain.Blog, error) {
+	blogs, err := r.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i, blog := range blogs {
+		blogs[i] = mask.Apply(blog)
+	}
+	return blogs, nil
+}
+
+func (r *memoryBlogRepository) ListAfter(ctx context.Context, filter domain.BlogFilter, afterID uint, limit int) ([]*domain.Blog, error) {
+	blogs, err := r.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	page := make([]*domain.Blog, 0, limit)
+	for _, blog := range blogs {
+		if blog.ID > afterID && matches(filter, blog) && len(page) < limit {
+			page = append(page, blog)
+		}
+	}
+	return page, nil
+}
+
+func (r *memoryBlogRepository) ListLatest(ctx context.Context, filter domain.BlogFilter, mask domain.FieldMask, limit int) ([]*domain.Blog, error) {
+	blogs, err := r.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var latest []*domain.Blog
+	for _, blog := range blogs {
+		if matches(filter, blog) {
+			latest = append(latest, mask.Apply(blog))
+		}
+	}
+	sort.Slice(latest, func(i, j int) bool {
+		if !latest[i].CreatedAt.Equal(latest[j].CreatedAt) {
+			return latest[i].CreatedAt.After(latest[j].CreatedAt)
+		}
+		return latest[i].ID > latest[j].ID
+	})
+	if len(latest) > limit {
+		latest = latest[:limit]
+	}
+	return latest, nil
+}
+
+func matches(filter domain.BlogFilter, blog *domain.Blog) bool {
+	if filter.Author != "" && blog.Author != filter.Author {
+		return false
+	}
+	if filter.Tag != "" && !blog.HasTag(filter.Tag) {
+		return false
+	}
+	if !filter.CreatedAfter.IsZero() && blog.CreatedAt.Before(filter.CreatedAfter) {
+		return false
+	}
+	if !filter.CreatedBefore.IsZero() && !blog.CreatedAt.Before(filter.CreatedBefore) {
+		return false
+	}
+	if filter.Unsummarized && (blog.WordCount != 0 || blog.Content == "") {
+		return false
+	}
+	return true
+}
+
+func (r *memoryBlogRepository) CreateBatch(ctx context.Context, blogs []*domain.Blog) error {
+	for _, blog := range blogs {
+		if err := r.Create(ctx, blog); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *memoryBlogRepository) GetByIDs(ctx context.Context, ids []uint) ([]*domain.Blog, error) {
+	sorted := append([]uint(nil), ids...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	blogs := make([]*domain.Blog, 0, len(sorted))
+	for i, id := range sorted {
+		if i > 0 && sorted[i-1] == id {
+			continue
+		}
+		if blog, ok := r.store.lookup(ctx, id); ok {
+			blogs = append(blogs, &blog)
+		}
+	}
+	return blogs, nil
+}
+
+func (r *memoryBlogRepository) DeleteBatch(ctx context.Context, ids []uint) error {
+	for _, id := range ids {
+		r.store.set(ctx, id, nil)
+	}
+	return nil
+}
@@ -0,0 +1,186 @@
+package repositories_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/toffysoft/go-hexagonal-example/internal/adapters/repositories"
+	"github.com/toffysoft/go-hexagonal-example/internal/core/domain"
+	"github.com/toffysoft/go-hexagonal-example/internal/core/ports"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var errAbort = errors.New("abort")
+
+func TestMemoryStoreCRUD(t *testing.T) {
+	ctx := context.Background()
+	repo := repositories.NewMemoryStore().Blogs()
+
+	blog := &domain.Blog{Title: "Title", Content: "Content", Author: "Author"}
+	require.NoError(t, repo.Create(ctx, blog))
+	assert.Equal(t, uint(1), blog.ID)
+	assert.False(t, blog.CreatedAt.IsZero())
+
+	got, err := repo.GetByID(ctx, blog.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Title", got.Title)
+
+	got.Title = "Changed"
+	require.NoError(t, repo.Update(ctx, got))
+	got, err = repo.GetByID(ctx, blog.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Changed", got.Title)
+
+	blogs, err := repo.List(ctx)
+	require.NoError(t, err)
+	assert.Len(t, blogs, 1)
+
+	require.NoError(t, repo.Delete(ctx, blog.ID))
+	_, err = repo.GetByID(ctx, blog.ID)
+	assert.Error(t, err)
+
+	// An update does not bring a deleted blog back.
+	assert.Error(t, repo.Update(ctx, got))
+	_, err = repo.GetByID(ctx, blog.ID)
+	assert.Error(t, err)
+}
+
+func TestMemoryListLatest(t *testing.T) {
+	ctx := context.Background()
+	repo := repositories.NewMemoryStore().Blogs()
+	for _, blog := range []*domain.Blog{
+		{Title: "One", Content: "Content", Author: "alice", Tags: []string{"go"}},
+		{Title: "Two", Content: "Content", Author: "bob", Tags: []string{"go", "news"}},
+		{Title: "Three", Content: "Content", Author: "alice"},
+	} {
+		require.NoError(t, repo.Create(ctx, blog))
+	}
+	titles := func(blogs []*domain.Blog) []string {
+		var titles []string
+		for _, blog := range blogs {
+			titles = append(titles, blog.Title)
+		}
+		return titles
+	}
+
+	blogs, err := repo.ListLatest(ctx, domain.BlogFilter{}, domain.FieldMask{domain.FieldTitle}, 2)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Three", "Two"}, titles(blogs))
+	assert.Empty(t, blogs[0].Content)
+
+	blogs, err = repo.ListLatest(ctx, domain.BlogFilter{Tag: "go"}, nil, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Two", "One"}, titles(blogs))
+
+	blogs, err = repo.ListLatest(ctx, domain.BlogFilter{Author: "alice", Tag: "go"}, nil, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"One"}, titles(blogs))
+}
+
+func TestMemoryUnitOfWork(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Commit", func(t *testing.T) {
+		store := repositories.NewMemoryStore()
+		repo, uow := store.Blogs(), store.UnitOfWork()
+
+		err := uow.Do(ctx, func(ctx context.Context) error {
+			require.NoError(t, repo.Create(ctx, &domain.Blog{Title: "A"}))
+			require.NoError(t, repo.Create(ctx, &domain.Blog{Title: "B"}))
+
+			outside, err := repo.List(context.Background())
+			require.NoError(t, err)
+			assert.Empty(t, outside, "uncommitted writes must not be visible outside the transaction")
+
+			inside, err := repo.List(ctx)
+			require.NoError(t, err)
+			assert.Len(t, inside, 2)
+			return nil
+		})
+
+		require.NoError(t, err)
+		blogs, err := repo.List(ctx)
+		require.NoError(t, err)
+		assert.Len(t, blogs, 2)
+	})
+
+	t.Run("Rollback", func(t *testing.T) {
+		store := repositories.NewMemoryStore()
+		repo, uow := store.Blogs(), store.UnitOfWork()
+		existing := &domain.Blog{Title: "Existing"}
+		require.NoError(t, repo.Create(ctx, existing))
+
+		err := uow.Do(ctx, func(ctx context.Context) error {
+			require.NoError(t, repo.Delete(ctx, existing.ID))
+			require.NoError(t, repo.Create(ctx, &domain.Blog{Title: "New"}))
+			return errAbort
+		})
+
+		assert.ErrorIs(t, err, errAbort)
+		blogs, err := repo.List(ctx)
+		require.NoError(t, err)
+		require.Len(t, blogs, 1)
+		assert.Equal(t, "Existing", blogs[0].Title)
+	})
+
+	t.Run("SavepointRollback", func(t *testing.T) {
+		store := repositories.NewMemoryStore()
+		repo, uow := store.Blogs(), store.UnitOfWork()
+
+		err := uow.Do(ctx, func(ctx context.Context) error {
+			require.NoError(t, repo.Create(ctx, &domain.Blog{Title: "Outer"}))
+
+			inner := uow.Do(ctx, func(ctx context.Context) error {
+				require.NoError(t, repo.Create(ctx, &domain.Blog{Title: "Inner"}))
+				return errAbort
+			})
+			assert.ErrorIs(t, inner, errAbort)
+
+			return uow.Do(ctx, func(ctx context.Context) error {
+				return repo.Create(ctx, &domain.Blog{Title: "Kept"})
+			})
+		})
+
+		require.NoError(t, err)
+		blogs, err := repo.List(ctx)
+		require.NoError(t, err)
+		require.Len(t, blogs, 2)
+		assert.Equal(t, "Outer", blogs[0].Title)
+		assert.Equal(t, "Kept", blogs[1].Title)
+	})
+}
+
+func TestAfterCommit(t *testing.T) {
+	ctx := context.Background()
+	uow := repositories.NewMemoryStore().UnitOfWork()
+	var ran []string
+
+	ports.AfterCommit(ctx, func() { ran = append(ran, "outside") })
+	assert.Equal(t, []string{"outside"}, ran)
+
+	err := uow.Do(ctx, func(ctx context.Context) error {
+		assert.True(t, ports.InUnitOfWork(ctx))
+		ports.AfterCommit(ctx, func() { ran = append(ran, "outer") })
+		_ = uow.Do(ctx, func(ctx context.Context) error {
+			ports.AfterCommit(ctx, func() { ran = append(ran, "rolled back savepoint") })
+			return errAbort
+		})
+		require.NoError(t, uow.Do(ctx, func(ctx context.Context) error {
+			ports.AfterCommit(ctx, func() { ran = append(ran, "savepoint") })
+			return nil
+		}))
+		assert.Equal(t, []string{"outside"}, ran)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"outside", "outer", "savepoint"}, ran)
+
+	_ = uow.Do(ctx, func(ctx context.Context) error {
+		ports.AfterCommit(ctx, func() { ran = append(ran, "rolled back") })
+		return errAbort
+	})
+	assert.Len(t, ran, 3)
+}
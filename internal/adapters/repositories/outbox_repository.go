@@ -0,0 +1,86 @@
+package repositories
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/toffysoft/go-hexagonal-example/internal/core/domain"
+	"github.com/toffysoft/go-hexagonal-example/internal/core/ports"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type outboxRepository struct {
+	db *gorm.DB
+}
+
+func NewOutboxRepository(db *gorm.DB) ports.OutboxRepository {
+	return &outboxRepository{db: db}
+}
+
+func (r *outboxRepository) Add(ctx context.Context, events ...domain.Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+	messages := make([]*domain.OutboxMessage, len(events))
+	for i, event := range events {
+		messages[i] = domain.NewOutboxMessage(event)
+	}
+	return conn(ctx, r.db).Create(&messages).Error
+}
+
+func (r *outboxRepository) Pending(ctx context.Context, now time.Time, limit int) ([]*domain.OutboxMessage, error) {
+	var messages []*domain.OutboxMessage
+	err := conn(ctx, r.db).
+		Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+		Where("published_at IS NULL AND dead_lettered_at IS NULL AND next_attempt_at <= ?", now).
+		Order("id").
+		Limit(limit).
+		Find(&messages).Error
+	return messages, err
+}
+
+func (r *outboxRepository) After(ctx context.Context, id uint, limit int) ([]*domain.OutboxMessage, error) {
+	var messages []*domain.OutboxMessage
+	err := conn(ctx, r.db).Where("id > ?", id).Order("id").Limit(limit).Find(&messages).Error
+	return messages, err
+}
+
+func (r *outboxRepository) Latest(ctx context.Context, limit int) ([]*domain.OutboxMessage, error) {
+	var messages []*domain.OutboxMessage
+	err := conn(ctx, r.db).Order("id DESC").Limit(limit).Find(&messages).Error
+	slices.Reverse(messages)
+	return messages, err
+}
+
+func (r *outboxRepository) MarkPublished(ctx context.Context, id uint, at time.Time) error {
+	return conn(ctx, r.db).Model(&domain.OutboxMessage{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"published_at": at,
+			"attempts":     gorm.Expr("attempts + 1"),
+			"last_error":   "",
+		}).Error
+}
+
+func (r *outboxRepository) MarkFailed(ctx context.Context, id uint, reason string, retryAt time.Time) error {
+	return conn(ctx, r.db).Model(&domain.OutboxMessage{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"attempts":        gorm.Expr("attempts + 1"),
+			"last_error":      reason,
+			"next_attempt_at": retryAt,
+		}).Error
+}
+
+func (r *outboxRepository) MarkDeadLettered(ctx context.Context, id uint, reason string, at time.Time) error {
+	return conn(ctx, r.db).Model(&domain.OutboxMessage{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"attempts":         gorm.Expr("attempts + 1"),
+			"last_error":       reason,
+			"dead_lettered_at": at,
+		}).Error
+}
@@ -0,0 +1,45 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/toffysoft/go-hexagonal-example/internal/core/domain"
+	"github.com/toffysoft/go-hexagonal-example/internal/core/ports"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type rateLimitRepository struct {
+	db *gorm.DB
+}
+
+// NewRateLimitRepository returns a store that shares buckets between every
+// instance using the database.
+func NewRateLimitRepository(db *gorm.DB) ports.RateLimitStore {
+	return &rateLimitRepository{db: db}
+}
+
+func (r *rateLimitRepository) Take(ctx context.Context, key string, policy domain.RateLimitPolicy, now time.Time) (domain.RateLimitDecision, error) {
+	var decision domain.RateLimitDecision
+	err := conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).
+			Create(domain.NewTokenBucket(key, policy, now)).Error; err != nil {
+			return err
+		}
+		var bucket domain.TokenBucket
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("key = ?", key).Take(&bucket).Error; err != nil {
+			return err
+		}
+		decision = bucket.Take(policy, now)
+		return tx.Save(&bucket).Error
+	})
+	return decision, err
+}
+
+func (r *rateLimitRepository) DeleteFull(ctx context.Context, now time.Time) (int64, error) {
+	result := conn(ctx, r.db).Where("full_at <= ?", now).Delete(&domain.TokenBucket{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,46 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/toffysoft/go-hexagonal-example/internal/core/ports"
+
+	"gorm.io/gorm"
+)
+
+type txKey struct{}
+
+type unitOfWork struct {
+	db *gorm.DB
+}
+
+// NewUnitOfWork returns a unit of work backed by GORM transactions. Nested
+// calls use savepoints.
+func NewUnitOfWork(db *gorm.DB) ports.UnitOfWork {
+	return &unitOfWork{db: db}
+}
+
+func (u *unitOfWork) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	return ports.TrackCommit(ctx, func(ctx context.Context) error {
+		// Transaction on a session that is already in a transaction creates a
+		// savepoint instead of a new transaction.
+		return conn(ctx, u.db).Transaction(func(tx *gorm.DB) error {
+			return fn(context.WithValue(ctx, txKey{}, tx))
+		})
+	})
+}
+
+// conn returns the transaction bound to ctx by a unit of work, or db if there
+// is none.
+func conn(ctx context.Context, db *gorm.DB) *gorm.DB {
+	if tx, ok := ctx.Value(txKey{}).(*gorm.DB); ok {
+		return tx.WithContext(ctx)
+	}
+	return db.WithContext(ctx)
+}
+
+// inTransaction reports whether ctx is bound to a unit of work.
+func inTransaction(ctx context.Context) bool {
+	_, ok := ctx.Value(txKey{}).(*gorm.DB)
+	return ok
+}
@@ -0,0 +1,110 @@
+package repositories
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"time"
+
+	"github.com/toffysoft/go-hexagonal-example/internal/core/domain"
+	"github.com/toffysoft/go-hexagonal-example/internal/core/ports"
+	"github.com/toffysoft/go-hexagonal-example/pkg/errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type webhookRepository struct {
+	db *gorm.DB
+}
+
+func NewWebhookRepository(db *gorm.DB) ports.WebhookRepository {
+	return &webhookRepository{db: db}
+}
+
+func (r *webhookRepository) Create(ctx context.Context, webhook *domain.Webhook) error {
+	return conn(ctx, r.db).Create(webhook).Error
+}
+
+// GetByID locks the webhook until the end of the unit of work it is called
+// in, if any.
+func (r *webhookRepository) GetByID(ctx context.Context, id uint) (*domain.Webhook, error) {
+	query := conn(ctx, r.db)
+	if inTransaction(ctx) {
+		query = query.Clauses(clause.Locking{Strength: "UPDATE"})
+	}
+	var webhook domain.Webhook
+	err := query.First(&webhook, id).Error
+	if stderrors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errors.NewNotFoundError(fmt.Sprintf("Webhook with ID %d not found", id))
+	}
+	return &webhook, err
+}
+
+func (r *webhookRepository) Update(ctx context.Context, webhook *domain.Webhook) error {
+	return conn(ctx, r.db).Save(webhook).Error
+}
+
+// Delete removes the webhook together with its delivery log.
+func (r *webhookRepository) Delete(ctx context.Context, id uint) error {
+	db := conn(ctx, r.db)
+	deliveries := db.Model(&domain.WebhookDelivery{}).Select("id").Where("webhook_id = ?", id)
+	if err := db.Where("delivery_id IN (?)", deliveries).Delete(&domain.WebhookAttempt{}).Error; err != nil {
+		return err
+	}
+	if err := db.Where("webhook_id = ?", id).Delete(&domain.WebhookDelivery{}).Error; err != nil {
+		return err
+	}
+	return db.Delete(&domain.Webhook{}, id).Error
+}
+
+func (r *webhookRepository) List(ctx context.Context) ([]*domain.Webhook, error) {
+	var webhooks []*domain.Webhook
+	err := conn(ctx, r.db).Order("id").Find(&webhooks).Error
+	return webhooks, err
+}
+
+func (r *webhookRepository) CreateDeliveries(ctx context.Context, deliveries []*domain.WebhookDelivery) error {
+	return conn(ctx, r.db).Create(&deliveries).Error
+}
+
+func (r *webhookRepository) GetDelivery(ctx context.Context, id uint) (*domain.WebhookDelivery, error) {
+	var delivery domain.WebhookDelivery
+	err := conn(ctx, r.db).First(&delivery, id).Error
+	return &delivery, err
+}
+
+func (r *webhookRepository) UpdateDelivery(ctx context.Context, delivery *domain.WebhookDelivery) error {
+	return conn(ctx, r.db).Save(delivery).Error
+}
+
+func (r *webhookRepository) ListDeliveries(ctx context.Context, webhookID uint, limit int) ([]*domain.WebhookDelivery, error) {
+	var deliveries []*domain.WebhookDelivery
+	err := conn(ctx, r.db).
+		Where("webhook_id = ?", webhookID).
+		Order("id DESC").
+		Limit(limit).
+		Find(&deliveries).Error
+	return deliveries, err
+}
+
+func (r *webhookRepository) DueDeliveries(ctx context.Context, now time.Time, limit int) ([]*domain.WebhookDelivery, error) {
+	var deliveries []*domain.WebhookDelivery
+	err := conn(ctx, r.db).
+		Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+		Where("status = ? AND next_attempt_at <= ?", domain.DeliveryPending, now).
+		Order("next_attempt_at, id").
+		Limit(limit).
+		Find(&deliveries).Error
+	return deliveries, err
+}
+
+func (r *webhookRepository) AddAttempt(ctx context.Context, attempt *domain.WebhookAttempt) error {
+	return conn(ctx, r.db).Create(attempt).Error
+}
+
+func (r *webhookRepository) ListAttempts(ctx context.Context, deliveryID uint) ([]*domain.WebhookAttempt, error) {
+	var attempts []*domain.WebhookAttempt
+	err := conn(ctx, r.db).Where("delivery_id = ?", deliveryID).Order("attempt").Find(&attempts).Error
+	return attempts, err
+}
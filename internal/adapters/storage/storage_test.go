@@ -0,0 +1,54 @@
+package storage_test
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/toffysoft/go-hexagonal-example/internal/adapters/storage"
+	"github.com/toffysoft/go-hexagonal-example/internal/core/ports"
+	"github.com/toffysoft/go-hexagonal-example/pkg/errors"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestObjectStores(t *testing.T) {
+	local, err := storage.NewLocalStore(t.TempDir())
+	require.NoError(t, err)
+	stores := map[string]ports.ObjectStore{
+		"Local":  local,
+		"Memory": storage.NewMemoryStore(),
+	}
+
+	for name, store := range stores {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+
+			require.NoError(t, store.Put(ctx, "blogs/1/a", strings.NewReader("first"), "text/plain"))
+			require.NoError(t, store.Put(ctx, "blogs/1/a", strings.NewReader("second"), "text/plain"))
+			r, err := store.Get(ctx, "blogs/1/a")
+			require.NoError(t, err)
+			data, err := io.ReadAll(r)
+			require.NoError(t, r.Close())
+			require.NoError(t, err)
+			assert.Equal(t, "second", string(data))
+
+			require.NoError(t, store.Delete(ctx, "blogs/1/a"))
+			require.NoError(t, store.Delete(ctx, "blogs/1/a"))
+			_, err = store.Get(ctx, "blogs/1/a")
+			require.Error(t, err)
+			assert.Equal(t, errors.NotFound, err.(errors.AppError).Type)
+		})
+	}
+}
+
+func TestLocalStoreKeys(t *testing.T) {
+	store, err := storage.NewLocalStore(t.TempDir())
+	require.NoError(t, err)
+
+	for _, key := range []string{"", "/etc/passwd", "../outside", "a/../../outside", "a//b"} {
+		assert.Error(t, store.Put(context.Background(), key, strings.NewReader("x"), "text/plain"), key)
+	}
+}
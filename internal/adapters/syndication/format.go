@@ -0,0 +1,116 @@
+// Package syndication writes feeds of blogs in the formats read by feed
+// readers: RSS 2.0, Atom (RFC 4287) and JSON Feed 1.1.
+package syndication
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/toffysoft/go-hexagonal-example/internal/core/domain"
+)
+
+type Format string
+
+const (
+	RSS      Format = "rss"
+	Atom     Format = "atom"
+	JSONFeed Format = "json"
+)
+
+func (f Format) ContentType() string {
+	switch f {
+	case RSS:
+		return "application/rss+xml; charset=utf-8"
+	case Atom:
+		return "application/atom+xml; charset=utf-8"
+	default:
+		return "application/feed+json; charset=utf-8"
+	}
+}
+
+// Feed is a list of blogs, newest first. Links are absolute URLs: Link is
+// the site the feed belongs to and FeedURL the feed itself.
+type Feed struct {
+	Title       string
+	Description string
+	Link        string
+	FeedURL     string
+	Items       []Item
+}
+
+// Item is a blog in a feed. Link is its permanent URL, which also identifies
+// it. ContentHTML may be empty, in which case the summary stands in. The tags
+// of the blog are its categories.
+type Item struct {
+	Link        string
+	Title       string
+	Author      string
+	Categories  []string
+	Summary     string
+	ContentHTML string
+	Published   time.Time
+	Updated     time.Time
+}
+
+// NewItem makes the item of a blog, linked at the URL of pattern resolved
+// against base. The pattern names the blog with {id} and {slug}.
+func NewItem(blog *domain.Blog, base, pattern string) Item {
+	return Item{
+		Link:        ItemLink(blog, base, pattern),
+		Title:       blog.Title,
+		Author:      blog.Author,
+		Categories:  blog.Tags,
+		Summary:     blog.Excerpt,
+		ContentHTML: blog.RenderedHTML,
+		Published:   blog.CreatedAt,
+		Updated:     blog.UpdatedAt,
+	}
+}
+
+// ItemLink resolves the URL of a blog, as in NewItem.
+func ItemLink(blog *domain.Blog, base, pattern string) string {
+	link := strings.NewReplacer(
+		"{id}", strconv.FormatUint(uint64(blog.ID), 10),
+		"{slug}", url.PathEscape(domain.Slugify(blog.Title)),
+	).Replace(pattern)
+	return Resolve(base, link)
+}
+
+// Resolve returns ref relative to the absolute URL base.
+func Resolve(base, ref string) string {
+	baseURL, err := url.Parse(base)
+	if err != nil {
+		return ref
+	}
+	refURL, err := url.Parse(ref)
+	if err != nil {
+		return ref
+	}
+	return baseURL.ResolveReference(refURL).String()
+}
+
+// Updated is the time of the latest change to an item, or the zero time for
+// a feed without items.
+func (f *Feed) Updated() time.Time {
+	var updated time.Time
+	for _, item := range f.Items {
+		if item.Updated.After(updated) {
+			updated = item.Updated
+		}
+	}
+	return updated
+}
+
+// Encode writes the feed in the given format.
+func Encode(feed *Feed, format Format) ([]byte, error) {
+	switch format {
+	case RSS:
+		return encodeRSS(feed)
+	case Atom:
+		return encodeAtom(feed)
+	default:
+		return encodeJSONFeed(feed)
+	}
+}
@@ -0,0 +1,106 @@
+package syndication
+
+import (
+	"bytes"
+	"encoding/xml"
+	"time"
+)
+
+// RSS 2.0 names the author of an item by email address only, so authors are
+// written as Dublin Core creators instead. The full content of an item goes
+// in content:encoded, leaving the description to its summary.
+const (
+	namespaceAtom    = "http://www.w3.org/2005/Atom"
+	namespaceContent = "http://purl.org/rss/1.0/modules/content/"
+	namespaceDC      = "http://purl.org/dc/elements/1.1/"
+)
+
+type rssDocument struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	Atom    string     `xml:"xmlns:atom,attr"`
+	Content string     `xml:"xmlns:content,attr"`
+	DC      string     `xml:"xmlns:dc,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string      `xml:"title"`
+	Link          string      `xml:"link"`
+	Description   string      `xml:"description"`
+	Self          rssAtomLink `xml:"atom:link"`
+	LastBuildDate string      `xml:"lastBuildDate,omitempty"`
+	Items         []rssItem   `xml:"item"`
+}
+
+// rssAtomLink is the link of a feed to itself, recommended by the RSS
+// Advisory Board.
+type rssAtomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr"`
+	Type string `xml:"type,attr"`
+}
+
+type rssItem struct {
+	Title       string   `xml:"title"`
+	Link        string   `xml:"link"`
+	Description string   `xml:"description,omitempty"`
+	Content     string   `xml:"content:encoded,omitempty"`
+	Creator     string   `xml:"dc:creator,omitempty"`
+	Categories  []string `xml:"category"`
+	GUID        rssGUID  `xml:"guid"`
+	PubDate     string   `xml:"pubDate"`
+}
+
+type rssGUID struct {
+	Value       string `xml:",chardata"`
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+}
+
+// rssDate formats a time as RFC 822 requires, with a four-digit year.
+func rssDate(t time.Time) string {
+	return t.UTC().Format(time.RFC1123Z)
+}
+
+func encodeRSS(feed *Feed) ([]byte, error) {
+	channel := rssChannel{
+		Title:       feed.Title,
+		Link:        feed.Link,
+		Description: feed.Description,
+		Self:        rssAtomLink{Href: feed.FeedURL, Rel: "self", Type: "application/rss+xml"},
+	}
+	if updated := feed.Updated(); !updated.IsZero() {
+		channel.LastBuildDate = rssDate(updated)
+	}
+	for _, item := range feed.Items {
+		channel.Items = append(channel.Items, rssItem{
+			Title:       item.Title,
+			Link:        item.Link,
+			Description: item.Summary,
+			Content:     item.ContentHTML,
+			Creator:     item.Author,
+			Categories:  item.Categories,
+			GUID:        rssGUID{Value: item.Link, IsPermaLink: true},
+			PubDate:     rssDate(item.Published),
+		})
+	}
+	return marshalXML(rssDocument{
+		Version: "2.0",
+		Atom:    namespaceAtom,
+		Content: namespaceContent,
+		DC:      namespaceDC,
+		Channel: channel,
+	})
+}
+
+func marshalXML(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	encoder := xml.NewEncoder(&buf)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(v); err != nil {
+		return nil, err
+	}
+	buf.WriteByte('\n')
+	return buf.Bytes(), nil
+}
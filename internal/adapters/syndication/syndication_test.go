@@ -0,0 +1,259 @@
+package syndication_test
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"testing"
+	"time"
+
+	"github.com/toffysoft/go-hexagonal-example/internal/adapters/syndication"
+	"github.com/toffysoft/go-hexagonal-example/internal/core/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	published = time.Date(2024, 5, 1, 9, 30, 0, 0, time.FixedZone("ICT", 7*60*60))
+	updated   = time.Date(2024, 5, 2, 10, 0, 0, 0, time.UTC)
+)
+
+func testFeed() *syndication.Feed {
+	return &syndication.Feed{
+		Title:       "Blog",
+		Description: "The latest posts",
+		Link:        "https://example.com/",
+		FeedURL:     "https://example.com/feeds/rss.xml",
+		Items: []syndication.Item{
+			{
+				Link:        "https://example.com/blogs/2",
+				Title:       "Tips & tricks",
+				Author:      "alice",
+				Categories:  []string{"go", "testing"},
+				Summary:     "Some <tips>",
+				ContentHTML: "<p>Some &lt;tips&gt;</p>",
+				Published:   published,
+				Updated:     updated,
+			},
+			{
+				Link:      "https://example.com/blogs/1",
+				Title:     "Hello",
+				Author:    "bob",
+				Summary:   "Hello world",
+				Published: published.Add(-time.Hour),
+				Updated:   published.Add(-time.Hour),
+			},
+		},
+	}
+}
+
+func TestItemLink(t *testing.T) {
+	blog := &domain.Blog{ID: 7, Title: "Hello, World!"}
+
+	assert.Equal(t, "https://example.com/blogs/7/hello-world",
+		syndication.ItemLink(blog, "https://example.com", "/blogs/{id}/{slug}"))
+	assert.Equal(t, "https://blog.example.com/p/7",
+		syndication.ItemLink(blog, "https://example.com", "https://blog.example.com/p/{id}"))
+}
+
+// rss holds what the RSS 2.0 specification requires of a feed, with the
+// elements of extension modules matched by namespace.
+type rss struct {
+	XMLName xml.Name `xml:"rss"`
+	Version string   `xml:"version,attr"`
+	Channel struct {
+		Title         string `xml:"title"`
+		Description   string `xml:"description"`
+		LastBuildDate string `xml:"lastBuildDate"`
+		// Links are the RSS link and the Atom link to the feed itself.
+		Links []struct {
+			XMLName xml.Name
+			Value   string `xml:",chardata"`
+			Href    string `xml:"href,attr"`
+			Rel     string `xml:"rel,attr"`
+		} `xml:"link"`
+		Items []struct {
+			Title       string   `xml:"title"`
+			Link        string   `xml:"link"`
+			Description string   `xml:"description"`
+			Content     string   `xml:"http://purl.org/rss/1.0/modules/content/ encoded"`
+			Creator     string   `xml:"http://purl.org/dc/elements/1.1/ creator"`
+			Categories  []string `xml:"category"`
+			GUID        struct {
+				Value       string `xml:",chardata"`
+				IsPermaLink string `xml:"isPermaLink,attr"`
+			} `xml:"guid"`
+			PubDate string `xml:"pubDate"`
+		} `xml:"item"`
+	} `xml:"channel"`
+}
+
+func TestRSS(t *testing.T) {
+	data, err := syndication.Encode(testFeed(), syndication.RSS)
+	require.NoError(t, err)
+
+	var doc rss
+	require.NoError(t, xml.Unmarshal(data, &doc))
+	assert.Equal(t, "2.0", doc.Version)
+	channel := doc.Channel
+	assert.Equal(t, "Blog", channel.Title)
+	assert.Equal(t, "The latest posts", channel.Description)
+	require.Len(t, channel.Links, 2)
+	assert.Equal(t, "", channel.Links[0].XMLName.Space)
+	assert.Equal(t, "https://example.com/", channel.Links[0].Value)
+	assert.Equal(t, "http://www.w3.org/2005/Atom", channel.Links[1].XMLName.Space)
+	assert.Equal(t, "https://example.com/feeds/rss.xml", channel.Links[1].Href)
+	assert.Equal(t, "self", channel.Links[1].Rel)
+	// Dates are RFC 822 dates with four-digit years, as the specification
+	// requires.
+	assert.Equal(t, "Thu, 02 May 2024 10:00:00 +0000", channel.LastBuildDate)
+
+	require.Len(t, channel.Items, 2)
+	item := channel.Items[0]
+	assert.Equal(t, "Tips & tricks", item.Title)
+	assert.Equal(t, "https://example.com/blogs/2", item.Link)
+	assert.Equal(t, "Some <tips>", item.Description)
+	assert.Equal(t, "<p>Some &lt;tips&gt;</p>", item.Content)
+	assert.Equal(t, "alice", item.Creator)
+	assert.Equal(t, []string{"go", "testing"}, item.Categories)
+	assert.Equal(t, "https://example.com/blogs/2", item.GUID.Value)
+	assert.Equal(t, "true", item.GUID.IsPermaLink)
+	pubDate, err := time.Parse(time.RFC1123Z, item.PubDate)
+	require.NoError(t, err)
+	assert.True(t, published.Equal(pubDate))
+	assert.Empty(t, channel.Items[1].Content)
+}
+
+// atom holds what RFC 4287 requires of a feed.
+type atom struct {
+	XMLName xml.Name `xml:"http://www.w3.org/2005/Atom feed"`
+	ID      string   `xml:"id"`
+	Title   string   `xml:"title"`
+	Updated string   `xml:"updated"`
+	Links   []struct {
+		Href string `xml:"href,attr"`
+		Rel  string `xml:"rel,attr"`
+	} `xml:"link"`
+	Entries []struct {
+		ID        string `xml:"id"`
+		Title     string `xml:"title"`
+		Updated   string `xml:"updated"`
+		Published string `xml:"published"`
+		Author    struct {
+			Name string `xml:"name"`
+		} `xml:"author"`
+		Link struct {
+			Href string `xml:"href,attr"`
+			Rel  string `xml:"rel,attr"`
+		} `xml:"link"`
+		Categories []struct {
+			Term string `xml:"term,attr"`
+		} `xml:"category"`
+		Summary *struct {
+			Type  string `xml:"type,attr"`
+			Value string `xml:",chardata"`
+		} `xml:"summary"`
+		Content *struct {
+			Type  string `xml:"type,attr"`
+			Value string `xml:",chardata"`
+		} `xml:"content"`
+	} `xml:"entry"`
+}
+
+func TestAtom(t *testing.T) {
+	data, err := syndication.Encode(testFeed(), syndication.Atom)
+	require.NoError(t, err)
+
+	var doc atom
+	require.NoError(t, xml.Unmarshal(data, &doc))
+	assert.Equal(t, "https://example.com/feeds/rss.xml", doc.ID)
+	assert.Equal(t, "Blog", doc.Title)
+	assert.Equal(t, "2024-05-02T10:00:00Z", doc.Updated)
+	require.Len(t, doc.Links, 2)
+	assert.Equal(t, "self", doc.Links[0].Rel)
+	assert.Equal(t, "alternate", doc.Links[1].Rel)
+
+	require.Len(t, doc.Entries, 2)
+	entry := doc.Entries[0]
+	assert.Equal(t, "https://example.com/blogs/2", entry.ID)
+	assert.Equal(t, "Tips & tricks", entry.Title)
+	assert.Equal(t, "2024-05-02T10:00:00Z", entry.Updated)
+	assert.Equal(t, "2024-05-01T02:30:00Z", entry.Published)
+	assert.Equal(t, "alice", entry.Author.Name)
+	assert.Equal(t, "alternate", entry.Link.Rel)
+	require.Len(t, entry.Categories, 2)
+	assert.Equal(t, "go", entry.Categories[0].Term)
+	require.NotNil(t, entry.Summary)
+	assert.Equal(t, "text", entry.Summary.Type)
+	require.NotNil(t, entry.Content)
+	assert.Equal(t, "html", entry.Content.Type)
+	assert.Equal(t, "<p>Some &lt;tips&gt;</p>", entry.Content.Value)
+	assert.Nil(t, doc.Entries[1].Content)
+}
+
+func TestAtomWithoutEntries(t *testing.T) {
+	feed := testFeed()
+	feed.Items = nil
+
+	data, err := syndication.Encode(feed, syndication.Atom)
+	require.NoError(t, err)
+
+	var doc atom
+	require.NoError(t, xml.Unmarshal(data, &doc))
+	assert.Equal(t, "1970-01-01T00:00:00Z", doc.Updated)
+	assert.Empty(t, doc.Entries)
+}
+
+func TestJSONFeed(t *testing.T) {
+	data, err := syndication.Encode(testFeed(), syndication.JSONFeed)
+	require.NoError(t, err)
+
+	var doc struct {
+		Version     string `json:"version"`
+		Title       string `json:"title"`
+		HomePageURL string `json:"home_page_url"`
+		FeedURL     string `json:"feed_url"`
+		Items       []struct {
+			ID            string `json:"id"`
+			URL           string `json:"url"`
+			Title         string `json:"title"`
+			ContentHTML   string `json:"content_html"`
+			ContentText   string `json:"content_text"`
+			DatePublished string `json:"date_published"`
+			DateModified  string `json:"date_modified"`
+			Authors       []struct {
+				Name string `json:"name"`
+			} `json:"authors"`
+			Tags []string `json:"tags"`
+		} `json:"items"`
+	}
+	require.NoError(t, json.Unmarshal(data, &doc))
+	assert.Equal(t, "https://jsonfeed.org/version/1.1", doc.Version)
+	assert.Equal(t, "Blog", doc.Title)
+	assert.Equal(t, "https://example.com/", doc.HomePageURL)
+	assert.Equal(t, "https://example.com/feeds/rss.xml", doc.FeedURL)
+
+	require.Len(t, doc.Items, 2)
+	item := doc.Items[0]
+	assert.Equal(t, "https://example.com/blogs/2", item.ID)
+	assert.Equal(t, "https://example.com/blogs/2", item.URL)
+	assert.Equal(t, "<p>Some &lt;tips&gt;</p>", item.ContentHTML)
+	assert.Empty(t, item.ContentText)
+	assert.Equal(t, "2024-05-01T02:30:00Z", item.DatePublished)
+	assert.Equal(t, "2024-05-02T10:00:00Z", item.DateModified)
+	require.Len(t, item.Authors, 1)
+	assert.Equal(t, "alice", item.Authors[0].Name)
+	assert.Equal(t, []string{"go", "testing"}, item.Tags)
+	// Every item needs content, so the summary stands in for it.
+	assert.Equal(t, "Hello world", doc.Items[1].ContentText)
+}
+
+func TestJSONFeedWithoutItems(t *testing.T) {
+	feed := testFeed()
+	feed.Items = nil
+
+	data, err := syndication.Encode(feed, syndication.JSONFeed)
+	require.NoError(t, err)
+
+	assert.Contains(t, string(data), `"items": []`)
+}
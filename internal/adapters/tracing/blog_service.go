@@ -0,0 +1,178 @@
+package tracing
+
+import (
+	"context"
+
+	"github.com/toffysoft/go-hexagonal-example/internal/core/domain"
+	"github.com/toffysoft/go-hexagonal-example/internal/core/ports"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type blogService struct {
+	next ports.BlogService
+}
+
+// NewBlogService wraps a BlogService so that every call runs in its own span.
+func NewBlogService(next ports.BlogService) ports.BlogService {
+	return &blogService{next: next}
+}
+
+func (s *blogService) CreateBlog(ctx context.Context, blog *domain.Blog) error {
+	ctx, span := tracer().Start(ctx, "BlogService.CreateBlog")
+	defer span.End()
+
+	err := s.next.CreateBlog(ctx, blog)
+	span.SetAttributes(attribute.Int64("blog.id", int64(blog.ID)))
+	return finish(span, err)
+}
+
+func (s *blogService) GetBlog(ctx context.Context, id uint) (*domain.Blog, error) {
+	ctx, span := tracer().Start(ctx, "BlogService.GetBlog", trace.WithAttributes(attribute.Int64("blog.id", int64(id))))
+	defer span.End()
+
+	blog, err := s.next.GetBlog(ctx, id)
+	return blog, finish(span, err)
+}
+
+func (s *blogService) GetBlogFields(ctx context.Context, id uint, mask domain.FieldMask) (*domain.Blog, error) {
+	ctx, span := tracer().Start(ctx, "BlogService.GetBlogFields", trace.WithAttributes(
+		attribute.Int64("blog.id", int64(id)),
+		attribute.StringSlice("blog.fields", mask),
+	))
+	defer span.End()
+
+	blog, err := s.next.GetBlogFields(ctx, id, mask)
+	return blog, finish(span, err)
+}
+
+func (s *blogService) UpdateBlog(ctx context.Context, blog *domain.Blog) error {
+	ctx, span := tracer().Start(ctx, "BlogService.UpdateBlog", trace.WithAttributes(attribute.Int64("blog.id", int64(blog.ID))))
+	defer span.End()
+
+	return finish(span, s.next.UpdateBlog(ctx, blog))
+}
+
+func (s *blogService) PatchBlog(ctx context.Context, id uint, patch domain.BlogPatch) (*domain.Blog, error) {
+	ctx, span := tracer().Start(ctx, "BlogService.PatchBlog", trace.WithAttributes(attribute.Int64("blog.id", int64(id))))
+	defer span.End()
+
+	blog, err := s.next.PatchBlog(ctx, id, patch)
+	return blog, finish(span, err)
+}
+
+func (s *blogService) DeleteBlog(ctx context.Context, id uint) error {
+	ctx, span := tracer().Start(ctx, "BlogService.DeleteBlog", trace.WithAttributes(attribute.Int64("blog.id", int64(id))))
+	defer span.End()
+
+	return finish(span, s.next.DeleteBlog(ctx, id))
+}
+
+func (s *blogService) ListBlogs(ctx context.Context) ([]*domain.Blog, error) {
+	ctx, span := tracer().Start(ctx, "BlogService.ListBlogs")
+	defer span.End()
+
+	blogs, err := s.next.ListBlogs(ctx)
+	span.SetAttributes(attribute.Int("blog.count", len(blogs)))
+	return blogs, finish(span, err)
+}
+
+func (s *blogService) ListBlogFields(ctx context.Context, mask domain.FieldMask) ([]*domain.Blog, error) {
+	ctx, span := tracer().Start(ctx, "BlogService.ListBlogFields", trace.WithAttributes(attribute.StringSlice("blog.fields", mask)))
+	defer span.End()
+
+	blogs, err := s.next.ListBlogFields(ctx, mask)
+	span.SetAttributes(attribute.Int("blog.count", len(blogs)))
+	return blogs, finish(span, err)
+}
+
+func (s *blogService) ListLatestBlogs(ctx context.Context, filter domain.BlogFilter, mask domain.FieldMask, limit int) ([]*domain.Blog, error) {
+	ctx, span := tracer().Start(ctx, "BlogService.ListLatestBlogs", trace.WithAttributes(
+		attribute.String("blog.author", filter.Author),
+		attribute.String("blog.tag", filter.Tag),
+		attribute.StringSlice("blog.fields", mask),
+		attribute.Int("blog.limit", limit),
+	))
+	defer span.End()
+
+	blogs, err := s.next.ListLatestBlogs(ctx, filter, mask, limit)
+	span.SetAttributes(attribute.Int("blog.count", len(blogs)))
+	return blogs, finish(span, err)
+}
+
+func (s *blogService) RenderBlogs(ctx context.Context, blogs ...*domain.Blog) error {
+	ctx, span := tracer().Start(ctx, "BlogService.RenderBlogs", trace.WithAttributes(attribute.Int("blog.count", len(blogs))))
+	defer span.End()
+
+	return finish(span, s.next.RenderBlogs(ctx, blogs...))
+}
+
+func (s *blogService) StreamBlogs(ctx context.Context, afterID uint, chunkSize int, fn func(blogs []*domain.Blog) error) error {
+	ctx, span := tracer().Start(ctx, "BlogService.StreamBlogs", trace.WithAttributes(
+		attribute.Int64("blog.after_id", int64(afterID)),
+		attribute.Int("blog.chunk_size", chunkSize),
+	))
+	defer span.End()
+
+	count := 0
+	err := s.next.StreamBlogs(ctx, afterID, chunkSize, func(blogs []*domain.Blog) error {
+		count += len(blogs)
+		return fn(blogs)
+	})
+	span.SetAttributes(attribute.Int("blog.count", count))
+	return finish(span, err)
+}
+
+func (s *blogService) BatchCreateBlogs(ctx context.Context, blogs []*domain.Blog, mode domain.BatchMode) ([]domain.BatchResult, error) {
+	ctx, span := startBatch(ctx, "BlogService.BatchCreateBlogs", len(blogs), mode)
+	defer span.End()
+
+	results, err := s.next.BatchCreateBlogs(ctx, blogs, mode)
+	return results, finishBatch(span, results, err)
+}
+
+func (s *blogService) BatchUpdateBlogs(ctx context.Context, blogs []*domain.Blog, mode domain.BatchMode) ([]domain.BatchResult, error) {
+	ctx, span := startBatch(ctx, "BlogService.BatchUpdateBlogs", len(blogs), mode)
+	defer span.End()
+
+	results, err := s.next.BatchUpdateBlogs(ctx, blogs, mode)
+	return results, finishBatch(span, results, err)
+}
+
+func (s *blogService) BatchDeleteBlogs(ctx context.Context, ids []uint, mode domain.BatchMode) ([]domain.BatchResult, error) {
+	ctx, span := startBatch(ctx, "BlogService.BatchDeleteBlogs", len(ids), mode)
+	defer span.End()
+
+	results, err := s.next.BatchDeleteBlogs(ctx, ids, mode)
+	return results, finishBatch(span, results, err)
+}
+
+func startBatch(ctx context.Context, name string, size int, mode domain.BatchMode) (context.Context, trace.Span) {
+	return tracer().Start(ctx, name, trace.WithAttributes(
+		attribute.Int("batch.size", size),
+		attribute.String("batch.mode", string(mode)),
+	))
+}
+
+// finishBatch records how many items failed, which can happen without the
+// operation as a whole returning an error.
+func finishBatch(span trace.Span, results []domain.BatchResult, err error) error {
+	failed := 0
+	for _, result := range results {
+		if result.Err != nil {
+			failed++
+		}
+	}
+	span.SetAttributes(attribute.Int("batch.failed", failed))
+	return finish(span, err)
+}
+
+func finish(span trace.Span, err error) error {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	return err
+}
@@ -0,0 +1,14 @@
+package tracing
+
+import (
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/toffysoft/go-hexagonal-example"
+
+// tracer resolves the global provider on every call so adapters pick up the
+// provider installed by telemetry.InitTracer regardless of construction order.
+func tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}
@@ -0,0 +1,159 @@
+package tracing_test
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/toffysoft/go-hexagonal-example/internal/adapters/tracing"
+	"github.com/toffysoft/go-hexagonal-example/internal/core/domain"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+type MockBlogService struct {
+	mock.Mock
+}
+
+func (m *MockBlogService) CreateBlog(ctx context.Context, blog *domain.Blog) error {
+	args := m.Called(ctx, blog)
+	return args.Error(0)
+}
+
+func (m *MockBlogService) GetBlog(ctx context.Context, id uint) (*domain.Blog, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(*domain.Blog), args.Error(1)
+}
+
+func (m *MockBlogService) UpdateBlog(ctx context.Context, blog *domain.Blog) error {
+	args := m.Called(ctx, blog)
+	return args.Error(0)
+}
+
+func (m *MockBlogService) DeleteBlog(ctx context.Context, id uint) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockBlogService) ListBlogs(ctx context.Context) ([]*domain.Blog, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]*domain.Blog), args.Error(1)
+}
+
+func (m *MockBlogService) RenderBlogs(ctx context.Context, blogs ...*domain.Blog) error {
+	args := m.Called(ctx, blogs)
+	return args.Error(0)
+}
+
+func (m *MockBlogService) GetBlogFields(ctx context.Context, id uint, mask domain.FieldMask) (*domain.Blog, error) {
+	args := m.Called(ctx, id, mask)
+	return args.Get(0).(*domain.Blog), args.Error(1)
+}
+
+func (m *MockBlogService) ListBlogFields(ctx context.Context, mask domain.FieldMask) ([]*domain.Blog, error) {
+	args := m.Called(ctx, mask)
+	return args.Get(0).([]*domain.Blog), args.Error(1)
+}
+
+func (m *MockBlogService) ListLatestBlogs(ctx context.Context, filter domain.BlogFilter, mask domain.FieldMask, limit int) ([]*domain.Blog, error) {
+	args := m.Called(ctx, filter, mask, limit)
+	return args.Get(0).([]*domain.Blog), args.Error(1)
+}
+
+func (m *MockBlogService) StreamBlogs(ctx context.Context, afterID uint, chunkSize int, fn func(blogs []*domain.Blog) error) error {
+	args := m.Called(ctx, afterID, chunkSize)
+	for _, chunk := range args.Get(0).([][]*domain.Blog) {
+		if err := fn(chunk); err != nil {
+			return err
+		}
+	}
+	return args.Error(1)
+}
+
+func (m *MockBlogService) PatchBlog(ctx context.Context, id uint, patch domain.BlogPatch) (*domain.Blog, error) {
+	args := m.Called(ctx, id, patch)
+	blog, _ := args.Get(0).(*domain.Blog)
+	return blog, args.Error(1)
+}
+
+func (m *MockBlogService) BatchCreateBlogs(ctx context.Context, blogs []*domain.Blog, mode domain.BatchMode) ([]domain.BatchResult, error) {
+	args := m.Called(ctx, blogs, mode)
+	results, _ := args.Get(0).([]domain.BatchResult)
+	return results, args.Error(1)
+}
+
+func (m *MockBlogService) BatchUpdateBlogs(ctx context.Context, blogs []*domain.Blog, mode domain.BatchMode) ([]domain.BatchResult, error) {
+	args := m.Called(ctx, blogs, mode)
+	results, _ := args.Get(0).([]domain.BatchResult)
+	return results, args.Error(1)
+}
+
+func (m *MockBlogService) BatchDeleteBlogs(ctx context.Context, ids []uint, mode domain.BatchMode) ([]domain.BatchResult, error) {
+	args := m.Called(ctx, ids, mode)
+	results, _ := args.Get(0).([]domain.BatchResult)
+	return results, args.Error(1)
+}
+
+func setupRecorder(t *testing.T) *tracetest.SpanRecorder {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() { _ = provider.Shutdown(context.Background()) })
+	return recorder
+}
+
+func TestFiberMiddlewarePropagatesTraceContext(t *testing.T) {
+	recorder := setupRecorder(t)
+
+	mockService := new(MockBlogService)
+	mockService.On("GetBlog", mock.Anything, uint(1)).Return(&domain.Blog{ID: 1}, nil)
+	blogService := tracing.NewBlogService(mockService)
+
+	app := fiber.New()
+	app.Use(tracing.FiberMiddleware())
+	app.Get("/api/v1/blogs/:id", func(c *fiber.Ctx) error {
+		_, err := blogService.GetBlog(c.UserContext(), 1)
+		return err
+	})
+
+	req := httptest.NewRequest("GET", "/api/v1/blogs/1", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+
+	_, err := app.Test(req)
+	assert.NoError(t, err)
+
+	spans := recorder.Ended()
+	assert.Len(t, spans, 2)
+
+	serviceSpan, serverSpan := spans[0], spans[1]
+	assert.Equal(t, "BlogService.GetBlog", serviceSpan.Name())
+	assert.Equal(t, "GET /api/v1/blogs/:id", serverSpan.Name())
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", serverSpan.SpanContext().TraceID().String())
+	assert.Equal(t, "00f067aa0ba902b7", serverSpan.Parent().SpanID().String())
+	assert.Equal(t, serverSpan.SpanContext().SpanID(), serviceSpan.Parent().SpanID())
+	mockService.AssertExpectations(t)
+}
+
+func TestBlogServiceRecordsErrors(t *testing.T) {
+	recorder := setupRecorder(t)
+
+	mockService := new(MockBlogService)
+	mockService.On("DeleteBlog", mock.Anything, uint(7)).Return(assert.AnError)
+	blogService := tracing.NewBlogService(mockService)
+
+	err := blogService.DeleteBlog(context.Background(), 7)
+	assert.ErrorIs(t, err, assert.AnError)
+
+	spans := recorder.Ended()
+	assert.Len(t, spans, 1)
+	assert.Equal(t, "BlogService.DeleteBlog", spans[0].Name())
+	assert.Equal(t, "Error", spans[0].Status().Code.String())
+	mockService.AssertExpectations(t)
+}
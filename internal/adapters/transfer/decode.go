@@ -0,0 +1,218 @@
+package transfer
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/toffysoft/go-hexagonal-example/internal/core/domain"
+	"github.com/toffysoft/go-hexagonal-example/pkg/errors"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Decode parses an import file. Rows that cannot be parsed are returned with
+// an error so that they show up in the import report; an error is only
+// returned when the file as a whole is unreadable. A Markdown archive whose
+// files unzip to more than maxUnzipped bytes fails with a TooLarge error.
+func Decode(data []byte, format Format, maxUnzipped int64) ([]domain.ImportRecord, error) {
+	switch format {
+	case JSONL:
+		return decodeJSONL(data)
+	case CSV:
+		return decodeCSV(data)
+	default:
+		return decodeMarkdown(data, maxUnzipped)
+	}
+}
+
+func decodeJSONL(data []byte) ([]domain.ImportRecord, error) {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), len(data)+1)
+
+	var records []domain.ImportRecord
+	for line := 1; scanner.Scan(); line++ {
+		text := bytes.TrimSpace(scanner.Bytes())
+		if len(text) == 0 {
+			continue
+		}
+		imported := domain.ImportRecord{Source: fmt.Sprintf("line %d", line)}
+		var r record
+		if err := json.Unmarshal(text, &r); err != nil {
+			imported.Err = fmt.Errorf("invalid JSON: %w", err)
+		} else {
+			imported.Blog = r.blog()
+		}
+		records = append(records, imported)
+	}
+	return records, scanner.Err()
+}
+
+func decodeCSV(data []byte) ([]domain.ImportRecord, error) {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid CSV header: %w", err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, required := range []string{"title", "content", "author"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("CSV header has no %q column", required)
+		}
+	}
+
+	var records []domain.ImportRecord
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			return records, nil
+		}
+		var parseErr *csv.ParseError
+		if stderrors.As(err, &parseErr) {
+			records = append(records, domain.ImportRecord{Source: fmt.Sprintf("line %d", parseErr.StartLine), Err: err})
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		line, _ := reader.FieldPos(0)
+		imported := domain.ImportRecord{Source: fmt.Sprintf("line %d", line)}
+		imported.Blog, imported.Err = csvBlog(columns, row)
+		records = append(records, imported)
+	}
+}
+
+func csvBlog(columns map[string]int, row []string) (*domain.Blog, error) {
+	field := func(name string) string {
+		if i, ok := columns[name]; ok && i < len(row) {
+			return row[i]
+		}
+		return ""
+	}
+
+	r := record{Title: field("title"), Author: field("author"), Content: field("content"), ContentFormat: field("content_format")}
+	var err error
+	if id := field("id"); id != "" {
+		var parsed uint64
+		if parsed, err = strconv.ParseUint(id, 10, 32); err != nil {
+			return nil, fmt.Errorf("invalid id %q", id)
+		}
+		r.ID = uint(parsed)
+	}
+	if r.CreatedAt, err = parseTime(field("created_at")); err != nil {
+		return nil, fmt.Errorf("invalid created_at: %w", err)
+	}
+	if r.UpdatedAt, err = parseTime(field("updated_at")); err != nil {
+		return nil, fmt.Errorf("invalid updated_at: %w", err)
+	}
+	return r.blog(), nil
+}
+
+func parseTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.RFC3339Nano, value)
+}
+
+// decodeMarkdown reads every .md file of a zip. Other files, and the
+// metadata folders some archivers add, are ignored. The sizes the archive
+// declares must add up to at most maxUnzipped, and no file is read past its
+// declared size, so a small archive cannot expand into a large import.
+func decodeMarkdown(data []byte, maxUnzipped int64) ([]domain.ImportRecord, error) {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("invalid zip archive: %w", err)
+	}
+
+	var files []*zip.File
+	var total uint64
+	for _, file := range archive.File {
+		name := file.Name
+		if file.FileInfo().IsDir() || path.Ext(name) != ".md" ||
+			strings.HasPrefix(name, "__MACOSX/") || strings.HasPrefix(path.Base(name), ".") {
+			continue
+		}
+		// Each size is checked before it is added, so total cannot overflow.
+		if file.UncompressedSize64 > uint64(maxUnzipped) || total+file.UncompressedSize64 > uint64(maxUnzipped) {
+			return nil, errors.NewTooLargeError(fmt.Sprintf("The archive unzips to more than %d bytes", maxUnzipped))
+		}
+		total += file.UncompressedSize64
+		files = append(files, file)
+	}
+
+	records := make([]domain.ImportRecord, len(files))
+	for i, file := range files {
+		records[i] = domain.ImportRecord{Source: file.Name}
+		records[i].Blog, records[i].Err = readMarkdown(file)
+	}
+	return records, nil
+}
+
+func readMarkdown(file *zip.File) (*domain.Blog, error) {
+	rc, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	data, err := io.ReadAll(io.LimitReader(rc, int64(file.UncompressedSize64)+1))
+	if err != nil {
+		return nil, err
+	}
+	if uint64(len(data)) > file.UncompressedSize64 {
+		return nil, fmt.Errorf("larger than the %d bytes declared in the archive", file.UncompressedSize64)
+	}
+	r, err := unmarshalMarkdown(data)
+	if err != nil {
+		return nil, err
+	}
+	return r.blog(), nil
+}
+
+// unmarshalMarkdown is the inverse of marshalMarkdown. The blank line after
+// the front matter is optional.
+func unmarshalMarkdown(data []byte) (record, error) {
+	lines := strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n")
+	if len(lines) == 0 || lines[0] != "---" {
+		return record{}, stderrors.New("missing front matter")
+	}
+	end := -1
+	for i := 1; i < len(lines); i++ {
+		if lines[i] == "---" {
+			end = i
+			break
+		}
+	}
+	if end < 0 {
+		return record{}, stderrors.New("unterminated front matter")
+	}
+
+	var r record
+	if err := yaml.Unmarshal([]byte(strings.Join(lines[1:end], "\n")), &r); err != nil {
+		return record{}, fmt.Errorf("invalid front matter: %w", err)
+	}
+	content := lines[end+1:]
+	if len(content) > 0 && content[0] == "" {
+		content = content[1:]
+	}
+	r.Content = strings.Join(content, "\n")
+	return r, nil
+}
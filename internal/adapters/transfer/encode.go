@@ -0,0 +1,141 @@
+package transfer
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/toffysoft/go-hexagonal-example/internal/core/domain"
+
+	"gopkg.in/yaml.v3"
+)
+
+// csvHeader lists the columns of CSV exports. Imports find columns by name,
+// so they may come in any order and only title, content and author are
+// required.
+var csvHeader = []string{"id", "title", "slug", "author", "content", "content_format", "created_at", "updated_at"}
+
+// Encoder writes blogs in one format. Close completes the output and must be
+// called after the last blog.
+type Encoder interface {
+	Encode(blog *domain.Blog) error
+	Close() error
+}
+
+func NewEncoder(w io.Writer, format Format) Encoder {
+	switch format {
+	case JSONL:
+		encoder := json.NewEncoder(w)
+		encoder.SetEscapeHTML(false)
+		return &jsonlEncoder{encoder: encoder}
+	case CSV:
+		return &csvEncoder{writer: csv.NewWriter(w)}
+	default:
+		return &markdownEncoder{zip: zip.NewWriter(w)}
+	}
+}
+
+type jsonlEncoder struct {
+	encoder *json.Encoder
+}
+
+func (e *jsonlEncoder) Encode(blog *domain.Blog) error {
+	return e.encoder.Encode(newRecord(blog))
+}
+
+func (e *jsonlEncoder) Close() error { return nil }
+
+type csvEncoder struct {
+	writer      *csv.Writer
+	wroteHeader bool
+}
+
+func (e *csvEncoder) Encode(blog *domain.Blog) error {
+	if err := e.writeHeader(); err != nil {
+		return err
+	}
+	r := newRecord(blog)
+	return e.writer.Write([]string{
+		strconv.FormatUint(uint64(r.ID), 10),
+		r.Title,
+		r.Slug,
+		r.Author,
+		r.Content,
+		r.ContentFormat,
+		formatTime(r.CreatedAt),
+		formatTime(r.UpdatedAt),
+	})
+}
+
+func (e *csvEncoder) writeHeader() error {
+	if e.wroteHeader {
+		return nil
+	}
+	e.wroteHeader = true
+	return e.writer.Write(csvHeader)
+}
+
+// Close writes the header of an empty export, so that it can be imported.
+func (e *csvEncoder) Close() error {
+	if err := e.writeHeader(); err != nil {
+		return err
+	}
+	e.writer.Flush()
+	return e.writer.Error()
+}
+
+type markdownEncoder struct {
+	zip *zip.Writer
+}
+
+func (e *markdownEncoder) Encode(blog *domain.Blog) error {
+	r := newRecord(blog)
+	file, err := e.zip.Create(markdownName(r))
+	if err != nil {
+		return err
+	}
+	data, err := marshalMarkdown(r)
+	if err != nil {
+		return err
+	}
+	_, err = file.Write(data)
+	return err
+}
+
+func (e *markdownEncoder) Close() error {
+	return e.zip.Close()
+}
+
+func markdownName(r record) string {
+	slug := r.Slug
+	if slug == "" {
+		slug = "untitled"
+	}
+	return fmt.Sprintf("%d-%s.md", r.ID, slug)
+}
+
+// marshalMarkdown writes the front matter, a blank line and the content.
+func marshalMarkdown(r record) ([]byte, error) {
+	front, err := yaml.Marshal(r)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	buf.WriteString("---\n")
+	buf.Write(front)
+	buf.WriteString("---\n\n")
+	buf.WriteString(r.Content)
+	return buf.Bytes(), nil
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339Nano)
+}
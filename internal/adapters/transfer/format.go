@@ -0,0 +1,69 @@
+// Package transfer reads and writes blogs in the file formats used to move
+// them between environments.
+package transfer
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+type Format string
+
+const (
+	// JSONL is one JSON object per line.
+	JSONL Format = "jsonl"
+	// CSV has a header row naming the columns.
+	CSV Format = "csv"
+	// Markdown is a zip of Markdown files with YAML front matter, one per blog.
+	Markdown Format = "markdown"
+)
+
+// ParseFormat accepts a format name or a file extension.
+func ParseFormat(name string) (Format, error) {
+	switch strings.ToLower(strings.TrimPrefix(name, ".")) {
+	case "jsonl", "ndjson":
+		return JSONL, nil
+	case "csv":
+		return CSV, nil
+	case "markdown", "md", "zip":
+		return Markdown, nil
+	default:
+		return "", fmt.Errorf("unknown format %q: use jsonl, csv or markdown", name)
+	}
+}
+
+func (f Format) ContentType() string {
+	switch f {
+	case JSONL:
+		return "application/x-ndjson"
+	case CSV:
+		return "text/csv; charset=utf-8"
+	default:
+		return "application/zip"
+	}
+}
+
+// Extension is the file extension of an export, including the dot.
+func (f Format) Extension() string {
+	switch f {
+	case JSONL:
+		return ".jsonl"
+	case CSV:
+		return ".csv"
+	default:
+		return ".zip"
+	}
+}
+
+// ParseTime accepts an RFC 3339 timestamp or a date, which means midnight UTC.
+func ParseTime(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time %q: use RFC 3339 or YYYY-MM-DD", value)
+	}
+	return t, nil
+}
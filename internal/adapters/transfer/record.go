@@ -0,0 +1,45 @@
+package transfer
+
+import (
+	"time"
+
+	"github.com/toffysoft/go-hexagonal-example/internal/core/domain"
+)
+
+// record is a blog as written to files. The slug is informational: imports
+// derive it from the title again.
+type record struct {
+	ID            uint      `json:"id" yaml:"id,omitempty"`
+	Title         string    `json:"title" yaml:"title"`
+	Slug          string    `json:"slug" yaml:"slug,omitempty"`
+	Author        string    `json:"author" yaml:"author"`
+	Content       string    `json:"content" yaml:"-"`
+	ContentFormat string    `json:"content_format,omitempty" yaml:"content_format,omitempty"`
+	CreatedAt     time.Time `json:"created_at" yaml:"created_at,omitempty"`
+	UpdatedAt     time.Time `json:"updated_at" yaml:"updated_at,omitempty"`
+}
+
+func newRecord(blog *domain.Blog) record {
+	return record{
+		ID:            blog.ID,
+		Title:         blog.Title,
+		Slug:          domain.Slugify(blog.Title),
+		Author:        blog.Author,
+		Content:       blog.Content,
+		ContentFormat: string(blog.ContentFormat),
+		CreatedAt:     blog.CreatedAt,
+		UpdatedAt:     blog.UpdatedAt,
+	}
+}
+
+func (r record) blog() *domain.Blog {
+	return &domain.Blog{
+		ID:            r.ID,
+		Title:         r.Title,
+		Author:        r.Author,
+		Content:       r.Content,
+		ContentFormat: domain.ContentFormat(r.ContentFormat),
+		CreatedAt:     r.CreatedAt,
+		UpdatedAt:     r.UpdatedAt,
+	}
+}
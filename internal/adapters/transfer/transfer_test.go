@@ -0,0 +1,167 @@
+package transfer_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/toffysoft/go-hexagonal-example/internal/adapters/transfer"
+	"github.com/toffysoft/go-hexagonal-example/internal/core/domain"
+	"github.com/toffysoft/go-hexagonal-example/pkg/errors"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const maxUnzipped = 1 << 20
+
+func export(t *testing.T, format transfer.Format, blogs ...*domain.Blog) []byte {
+	var buf bytes.Buffer
+	encoder := transfer.NewEncoder(&buf, format)
+	for _, blog := range blogs {
+		require.NoError(t, encoder.Encode(blog))
+	}
+	require.NoError(t, encoder.Close())
+	return buf.Bytes()
+}
+
+func TestRoundTrip(t *testing.T) {
+	created := time.Date(2024, 5, 1, 9, 30, 0, 0, time.UTC)
+	blogs := []*domain.Blog{
+		{ID: 1, Title: "Hello: World", Content: "Line one\n\nLine \"two\", with a comma\n", Author: "alice", CreatedAt: created, UpdatedAt: created},
+		{ID: 7, Title: "สวัสดี", Content: "---\nnot front matter", Author: "bob", CreatedAt: created, UpdatedAt: created},
+	}
+
+	for _, format := range []transfer.Format{transfer.JSONL, transfer.CSV, transfer.Markdown} {
+		t.Run(string(format), func(t *testing.T) {
+			records, err := transfer.Decode(export(t, format, blogs...), format, maxUnzipped)
+
+			require.NoError(t, err)
+			require.Len(t, records, 2)
+			for i, record := range records {
+				require.NoError(t, record.Err)
+				assert.Equal(t, blogs[i], record.Blog)
+			}
+		})
+	}
+}
+
+func TestDecodeCSV(t *testing.T) {
+	t.Run("ColumnsByName", func(t *testing.T) {
+		data := "author,content,title\nalice,Some content,A title\nbob,Other content\n"
+
+		records, err := transfer.Decode([]byte(data), transfer.CSV, maxUnzipped)
+
+		require.NoError(t, err)
+		require.Len(t, records, 2)
+		assert.Equal(t, &domain.Blog{Title: "A title", Content: "Some content", Author: "alice"}, records[0].Blog)
+		assert.Equal(t, "line 3", records[1].Source)
+		assert.Equal(t, "", records[1].Blog.Title)
+	})
+
+	t.Run("MissingColumn", func(t *testing.T) {
+		_, err := transfer.Decode([]byte("title,content\nA,B\n"), transfer.CSV, maxUnzipped)
+
+		assert.ErrorContains(t, err, "author")
+	})
+
+	t.Run("BadRow", func(t *testing.T) {
+		records, err := transfer.Decode([]byte("id,title,content,author\nx,A,B,C\n"), transfer.CSV, maxUnzipped)
+
+		require.NoError(t, err)
+		assert.ErrorContains(t, records[0].Err, "invalid id")
+	})
+}
+
+func TestDecodeJSONL(t *testing.T) {
+	data := `{"title": "A", "content": "B", "author": "C"}
+
+not json
+`
+	records, err := transfer.Decode([]byte(data), transfer.JSONL, maxUnzipped)
+
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	assert.Equal(t, "A", records[0].Blog.Title)
+	assert.Equal(t, "line 3", records[1].Source)
+	assert.Error(t, records[1].Err)
+}
+
+func TestDecodeMarkdown(t *testing.T) {
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	files := map[string]string{
+		"posts/hello.md":          "---\ntitle: Hello\nauthor: alice\n---\nBody",
+		"posts/broken.md":         "no front matter",
+		"posts/image.png":         "binary",
+		"__MACOSX/posts/hello.md": "metadata",
+	}
+	for _, name := range []string{"posts/hello.md", "posts/broken.md", "posts/image.png", "__MACOSX/posts/hello.md"} {
+		file, err := archive.Create(name)
+		require.NoError(t, err)
+		_, err = file.Write([]byte(files[name]))
+		require.NoError(t, err)
+	}
+	require.NoError(t, archive.Close())
+
+	records, err := transfer.Decode(buf.Bytes(), transfer.Markdown, maxUnzipped)
+
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	assert.Equal(t, &domain.Blog{Title: "Hello", Author: "alice", Content: "Body"}, records[0].Blog)
+	assert.Equal(t, "posts/broken.md", records[1].Source)
+	assert.Error(t, records[1].Err)
+
+	_, err = transfer.Decode([]byte("not a zip"), transfer.Markdown, maxUnzipped)
+	assert.Error(t, err)
+}
+
+func TestDecodeMarkdownTooLarge(t *testing.T) {
+	zipped := func(sizes ...int) []byte {
+		var buf bytes.Buffer
+		archive := zip.NewWriter(&buf)
+		for i, size := range sizes {
+			file, err := archive.Create(fmt.Sprintf("post-%d.md", i))
+			require.NoError(t, err)
+			_, err = file.Write(bytes.Repeat([]byte{'a'}, size))
+			require.NoError(t, err)
+		}
+		require.NoError(t, archive.Close())
+		return buf.Bytes()
+	}
+
+	t.Run("OneLargeFile", func(t *testing.T) {
+		data := zipped(maxUnzipped + 1)
+		require.Less(t, len(data), 16<<10)
+
+		_, err := transfer.Decode(data, transfer.Markdown, maxUnzipped)
+
+		require.Error(t, err)
+		assert.Equal(t, errors.TooLarge, err.(errors.AppError).Type)
+	})
+
+	t.Run("ManyFiles", func(t *testing.T) {
+		_, err := transfer.Decode(zipped(maxUnzipped/2, maxUnzipped/2, 1), transfer.Markdown, maxUnzipped)
+
+		require.Error(t, err)
+		assert.Equal(t, errors.TooLarge, err.(errors.AppError).Type)
+	})
+
+	t.Run("AtTheLimit", func(t *testing.T) {
+		records, err := transfer.Decode(zipped(maxUnzipped/2, maxUnzipped/2), transfer.Markdown, maxUnzipped)
+
+		require.NoError(t, err)
+		assert.Len(t, records, 2)
+	})
+}
+
+func TestParseFormat(t *testing.T) {
+	format, err := transfer.ParseFormat(".md")
+	require.NoError(t, err)
+	assert.Equal(t, transfer.Markdown, format)
+
+	_, err = transfer.ParseFormat("xml")
+	assert.Error(t, err)
+}
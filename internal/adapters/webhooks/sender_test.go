@@ -0,0 +1,98 @@
+package webhooks_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/toffysoft/go-hexagonal-example/internal/adapters/webhooks"
+	"github.com/toffysoft/go-hexagonal-example/internal/core/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const secret = "0123456789abcdef"
+
+func TestHTTPSender(t *testing.T) {
+	var received *http.Request
+	var body []byte
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r
+		body, _ = io.ReadAll(r.Body)
+		if err := webhooks.Verify(secret, r.Header, body, 5*time.Minute, time.Now()); err != nil {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer receiver.Close()
+
+	sender := webhooks.NewHTTPSender(receiver.Client())
+	delivery := &domain.WebhookDelivery{
+		ID:        42,
+		EventID:   "event-1",
+		EventType: domain.BlogCreated,
+		Payload:   []byte(`{"id":"event-1","type":"blog.created"}`),
+	}
+
+	t.Run("SignsRequest", func(t *testing.T) {
+		status, err := sender.Send(context.Background(), &domain.Webhook{URL: receiver.URL, Secret: secret}, delivery)
+
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusNoContent, status)
+		assert.Equal(t, "application/json", received.Header.Get("Content-Type"))
+		assert.Equal(t, "42", received.Header.Get(webhooks.HeaderDeliveryID))
+		assert.Equal(t, "blog.created", received.Header.Get(webhooks.HeaderEvent))
+		assert.Equal(t, "event-1", received.Header.Get(webhooks.HeaderEventID))
+		assert.Equal(t, delivery.Payload, body)
+	})
+
+	t.Run("WrongSecretIsRejected", func(t *testing.T) {
+		status, err := sender.Send(context.Background(), &domain.Webhook{URL: receiver.URL, Secret: "another-secret-value"}, delivery)
+
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusUnauthorized, status)
+	})
+
+	t.Run("UnreachableEndpoint", func(t *testing.T) {
+		_, err := sender.Send(context.Background(), &domain.Webhook{URL: "http://127.0.0.1:1", Secret: secret}, delivery)
+
+		assert.Error(t, err)
+	})
+}
+
+func TestNewClientRefusesInternalAddresses(t *testing.T) {
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer receiver.Close()
+	u, err := url.Parse(receiver.URL)
+	require.NoError(t, err)
+
+	sender := webhooks.NewHTTPSender(webhooks.NewClient(time.Second))
+	delivery := &domain.WebhookDelivery{ID: 1, EventID: "event-1", EventType: domain.BlogCreated, Payload: []byte(`{}`)}
+
+	// A name is checked once it is resolved, as it would be after DNS rebinding.
+	for _, target := range []string{receiver.URL, "http://localhost:" + u.Port()} {
+		_, err := sender.Send(context.Background(), &domain.Webhook{URL: target, Secret: secret}, delivery)
+
+		assert.ErrorIs(t, err, webhooks.ErrForbiddenAddress, target)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	body := []byte(`{}`)
+	now := time.Unix(1700000000, 0)
+	header := http.Header{}
+	header.Set(webhooks.HeaderTimestamp, "1700000000")
+	header.Set(webhooks.HeaderSignature, webhooks.Sign(secret, now.Unix(), body))
+
+	assert.NoError(t, webhooks.Verify(secret, header, body, time.Minute, now))
+	assert.ErrorIs(t, webhooks.Verify(secret, header, []byte(`{"x":1}`), time.Minute, now), webhooks.ErrInvalidSignature)
+	assert.ErrorIs(t, webhooks.Verify(secret, header, body, time.Minute, now.Add(2*time.Minute)), webhooks.ErrExpiredTimestamp)
+}
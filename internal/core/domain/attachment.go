@@ -0,0 +1,42 @@
+package domain
+
+import (
+	"strings"
+	"time"
+)
+
+// Attachment is a file uploaded to a blog, such as an image. Its bytes are
+// kept in object storage under Key, and those of its thumbnail, for images
+// that could be decoded, under ThumbnailKey.
+type Attachment struct {
+	ID            uint      `json:"id" gorm:"primaryKey"`
+	BlogID        uint      `json:"blog_id" gorm:"index;not null"`
+	Filename      string    `json:"filename" gorm:"not null"`
+	ContentType   string    `json:"content_type" gorm:"not null"`
+	Size          int64     `json:"size" gorm:"not null"`
+	Key           string    `json:"-" gorm:"uniqueIndex;not null"`
+	ThumbnailKey  string    `json:"-" gorm:"not null;default:''"`
+	ThumbnailType string    `json:"-" gorm:"not null;default:''"`
+	Width         int       `json:"width,omitempty" gorm:"not null;default:0"`
+	Height        int       `json:"height,omitempty" gorm:"not null;default:0"`
+	CreatedAt     time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// IsImage reports whether the attachment is an image.
+func (a *Attachment) IsImage() bool {
+	return strings.HasPrefix(a.ContentType, "image/")
+}
+
+// HasThumbnail reports whether a thumbnail was made of the attachment.
+func (a *Attachment) HasThumbnail() bool {
+	return a.ThumbnailKey != ""
+}
+
+// Thumbnail is a scaled down copy of an image. Width and Height are those of
+// the original image.
+type Thumbnail struct {
+	Data        []byte
+	ContentType string
+	Width       int
+	Height      int
+}
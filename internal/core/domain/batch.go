@@ -0,0 +1,31 @@
+package domain
+
+// BatchMode selects how a batch operation treats items that fail.
+type BatchMode string
+
+const (
+	// BatchAtomic applies every item of the batch or none of them.
+	BatchAtomic BatchMode = "atomic"
+	// BatchBestEffort applies the items that succeed and reports the others.
+	BatchBestEffort BatchMode = "best_effort"
+)
+
+// Valid reports whether m is a known batch mode.
+func (m BatchMode) Valid() bool {
+	return m == BatchAtomic || m == BatchBestEffort
+}
+
+// BatchResult is the outcome of one item of a batch operation.
+type BatchResult struct {
+	// Index is the position of the item in the request.
+	Index int
+	// Blog is the created, updated or deleted blog. It is nil when the item
+	// failed, or was skipped because another item of an atomic batch failed.
+	Blog *Blog
+	Err  error
+}
+
+// Skipped reports whether the item was valid but not applied.
+func (r BatchResult) Skipped() bool {
+	return r.Err == nil && r.Blog == nil
+}
@@ -3,10 +3,24 @@ package domain
 import "time"
 
 type Blog struct {
-	ID        uint      `json:"id" gorm:"primaryKey"`
-	Title     string    `json:"title" gorm:"not null"`
-	Content   string    `json:"content" gorm:"not null"`
-	Author    string    `json:"author" gorm:"not null"`
-	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
-	UpdatedAt time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+	ID            uint          `json:"id" gorm:"primaryKey"`
+	Title         string        `json:"title" gorm:"not null"`
+	Content       string        `json:"content" gorm:"not null"`
+	ContentFormat ContentFormat `json:"content_format" gorm:"not null;default:markdown"`
+	Author        string        `json:"author" gorm:"not null"`
+	// Tags are slugs, in the order they were given.
+	Tags []string `json:"tags" gorm:"type:jsonb;serializer:json;not null;default:'[]'"`
+	// Excerpt, WordCount, ReadingMinutes and TOC are derived from the
+	// content whenever it is written.
+	Excerpt        string    `json:"excerpt" gorm:"not null;default:''"`
+	WordCount      int       `json:"word_count" gorm:"not null;default:0"`
+	ReadingMinutes int       `json:"reading_minutes" gorm:"not null;default:0"`
+	TOC            []Heading `json:"toc" gorm:"serializer:json"`
+	// CoverImageID is the image attachment shown as the cover of the blog.
+	CoverImageID *uint     `json:"cover_image_id" gorm:"index"`
+	CreatedAt    time.Time `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt    time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+	// RenderedHTML is the content as sanitised HTML. It is only set when
+	// asked for, and never stored.
+	RenderedHTML string `json:"rendered_html,omitempty" gorm:"-"`
 }
@@ -0,0 +1,9 @@
+package domain
+
+// BlogChange is a blog event as delivered by the change feed. Cursor
+// identifies its position in the feed and can be used to resume after it.
+type BlogChange struct {
+	Cursor string
+	Event  Event
+	Blog   *Blog
+}
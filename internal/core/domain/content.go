@@ -0,0 +1,96 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// ContentFormat tells how the content of a blog is written.
+type ContentFormat string
+
+const (
+	ContentMarkdown ContentFormat = "markdown"
+	ContentHTML     ContentFormat = "html"
+	ContentPlain    ContentFormat = "plain"
+)
+
+// DefaultContentFormat is the format of blogs that do not name one.
+const DefaultContentFormat = ContentMarkdown
+
+func (f ContentFormat) Valid() bool {
+	switch f {
+	case ContentMarkdown, ContentHTML, ContentPlain:
+		return true
+	}
+	return false
+}
+
+// ParseContentFormat returns the format with the given name, or the default
+// format for an empty name.
+func ParseContentFormat(name string) (ContentFormat, error) {
+	if name == "" {
+		return DefaultContentFormat, nil
+	}
+	format := ContentFormat(name)
+	if !format.Valid() {
+		return "", fmt.Errorf("content format must be %s, %s or %s", ContentMarkdown, ContentHTML, ContentPlain)
+	}
+	return format, nil
+}
+
+// Heading is an entry of the table of contents of a blog. ID is the id of
+// the heading in the rendered content, if it has one.
+type Heading struct {
+	Level int    `json:"level"`
+	Text  string `json:"text"`
+	ID    string `json:"id,omitempty"`
+}
+
+// ContentText is the text of content without its markup.
+type ContentText struct {
+	// Prose holds the text of paragraphs, list items, quotes and table
+	// cells, one block per entry. Headings and code are left out.
+	Prose []string
+	// Words counts the words of all the text, headings and code included.
+	Words    int
+	Headings []Heading
+}
+
+// Summarize sets the excerpt, word count, reading time and table of contents
+// of blog from the text of its content. The excerpt is the start of the
+// prose, cut at a word boundary to at most excerptLength characters, and the
+// reading time is rounded up to whole minutes.
+func (b *Blog) Summarize(text ContentText, excerptLength, wordsPerMinute int) {
+	b.Excerpt = excerpt(strings.Join(text.Prose, " "), excerptLength)
+	b.WordCount = text.Words
+	b.ReadingMinutes = 0
+	if wordsPerMinute > 0 {
+		b.ReadingMinutes = (text.Words + wordsPerMinute - 1) / wordsPerMinute
+	}
+	b.TOC = text.Headings
+}
+
+// excerpt returns text cut at a word boundary to at most length characters,
+// an ellipsis included.
+func excerpt(text string, length int) string {
+	text = strings.Join(strings.Fields(text), " ")
+	if utf8.RuneCountInString(text) <= length {
+		return text
+	}
+	runes := []rune(text)[:length]
+	cut := len(runes) - 1 // leave room for the ellipsis
+	if space := lastSpace(runes[:cut+1]); space > 0 {
+		cut = space
+	}
+	return strings.TrimRight(string(runes[:cut]), " ") + "…"
+}
+
+func lastSpace(runes []rune) int {
+	for i := len(runes) - 1; i >= 0; i-- {
+		if runes[i] == ' ' {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,69 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type EventType string
+
+const (
+	BlogCreated EventType = "blog.created"
+	BlogUpdated EventType = "blog.updated"
+	BlogDeleted EventType = "blog.deleted"
+)
+
+// EventVersion is the version of the event payload schema. Bump it when the
+// payload changes in a way consumers must know about.
+const EventVersion = 1
+
+// Event is a domain event describing a change to a blog. Payload holds the
+// JSON encoded BlogEventPayload.
+type Event struct {
+	ID         string          `json:"id"`
+	Type       EventType       `json:"type"`
+	BlogID     uint            `json:"blog_id"`
+	Version    int             `json:"version"`
+	OccurredAt time.Time       `json:"occurred_at"`
+	Payload    json.RawMessage `json:"payload"`
+}
+
+// BlogEventPayload is the payload of blog events. Blog is the state after the
+// change, or the last state for BlogDeleted.
+type BlogEventPayload struct {
+	Blog *Blog `json:"blog,omitempty"`
+}
+
+func NewBlogCreatedEvent(blog *Blog) Event {
+	return newBlogEvent(BlogCreated, blog.ID, blog)
+}
+
+func NewBlogUpdatedEvent(blog *Blog) Event {
+	return newBlogEvent(BlogUpdated, blog.ID, blog)
+}
+
+func NewBlogDeletedEvent(blog *Blog) Event {
+	return newBlogEvent(BlogDeleted, blog.ID, blog)
+}
+
+func newBlogEvent(eventType EventType, id uint, blog *Blog) Event {
+	// Marshalling a Blog cannot fail.
+	payload, _ := json.Marshal(BlogEventPayload{Blog: blog})
+	return Event{
+		ID:         uuid.NewString(),
+		Type:       eventType,
+		BlogID:     id,
+		Version:    EventVersion,
+		OccurredAt: time.Now().UTC(),
+		Payload:    payload,
+	}
+}
+
+// BlogPayload decodes the payload of a blog event.
+func (e Event) BlogPayload() (BlogEventPayload, error) {
+	var payload BlogEventPayload
+	err := json.Unmarshal(e.Payload, &payload)
+	return payload, err
+}
@@ -0,0 +1,172 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Names of the fields of a blog, in the order they are encoded. Stored
+// fields are read from the column of the same name.
+const (
+	FieldID             = "id"
+	FieldTitle          = "title"
+	FieldContent        = "content"
+	FieldContentFormat  = "content_format"
+	FieldAuthor         = "author"
+	FieldTags           = "tags"
+	FieldExcerpt        = "excerpt"
+	FieldWordCount      = "word_count"
+	FieldReadingMinutes = "reading_minutes"
+	FieldTOC            = "toc"
+	FieldCoverImageID   = "cover_image_id"
+	FieldCreatedAt      = "created_at"
+	FieldUpdatedAt      = "updated_at"
+	// FieldRenderedHTML is not stored, but rendered from the content.
+	FieldRenderedHTML = "rendered_html"
+)
+
+var blogFields = []string{
+	FieldID, FieldTitle, FieldContent, FieldContentFormat, FieldAuthor, FieldTags,
+	FieldExcerpt, FieldWordCount, FieldReadingMinutes, FieldTOC,
+	FieldCoverImageID, FieldCreatedAt, FieldUpdatedAt, FieldRenderedHTML,
+}
+
+// FieldMask selects fields of a blog by name, in the order of blogFields.
+// The nil mask selects every stored field.
+type FieldMask []string
+
+// ListingFields is the default mask of listings: every stored field but the
+// content, which may be large and is summed up by the excerpt.
+var ListingFields = FieldMask{
+	FieldID, FieldTitle, FieldContentFormat, FieldAuthor, FieldTags,
+	FieldExcerpt, FieldWordCount, FieldReadingMinutes, FieldTOC,
+	FieldCoverImageID, FieldCreatedAt, FieldUpdatedAt,
+}
+
+// ParseFieldMask returns the mask of the named fields. It fails on an
+// unknown name, and on no names at all.
+func ParseFieldMask(names []string) (FieldMask, error) {
+	selected := make(map[string]bool, len(names))
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if !isBlogField(name) {
+			return nil, fmt.Errorf("unknown field %q; fields are %s", name, strings.Join(blogFields, ", "))
+		}
+		selected[name] = true
+	}
+	if len(selected) == 0 {
+		return nil, fmt.Errorf("no fields selected")
+	}
+	var mask FieldMask
+	for _, name := range blogFields {
+		if selected[name] {
+			mask = append(mask, name)
+		}
+	}
+	return mask, nil
+}
+
+func isBlogField(name string) bool {
+	for _, field := range blogFields {
+		if field == name {
+			return true
+		}
+	}
+	return false
+}
+
+// All reports whether the mask selects every stored field.
+func (m FieldMask) All() bool {
+	return m == nil
+}
+
+func (m FieldMask) Has(name string) bool {
+	if m.All() {
+		return name != FieldRenderedHTML
+	}
+	for _, field := range m {
+		if field == name {
+			return true
+		}
+	}
+	return false
+}
+
+// With returns the mask with the named fields added.
+func (m FieldMask) With(names ...string) FieldMask {
+	if m.All() {
+		m = blogFields[:len(blogFields)-1]
+	}
+	selected := make(map[string]bool, len(m)+len(names))
+	for _, name := range append(append([]string(nil), m...), names...) {
+		selected[name] = true
+	}
+	var mask FieldMask
+	for _, name := range blogFields {
+		if selected[name] {
+			mask = append(mask, name)
+		}
+	}
+	return mask
+}
+
+// Columns returns the columns to read for the mask. The ID and UpdatedAt
+// are always read, since they identify the version of a blog, and rendering
+// needs the content and its format.
+func (m FieldMask) Columns() []string {
+	need := m.With(FieldID, FieldUpdatedAt)
+	if m.Has(FieldRenderedHTML) {
+		need = need.With(FieldContent, FieldContentFormat)
+	}
+	columns := make([]string, 0, len(need))
+	for _, name := range need {
+		if name != FieldRenderedHTML {
+			columns = append(columns, name)
+		}
+	}
+	return columns
+}
+
+// Apply returns a copy of blog with only the fields of Columns set.
+func (m FieldMask) Apply(blog *Blog) *Blog {
+	if m.All() {
+		copied := *blog
+		return &copied
+	}
+	columns := FieldMask(m.Columns())
+	projected := &Blog{ID: blog.ID, UpdatedAt: blog.UpdatedAt}
+	if columns.Has(FieldTitle) {
+		projected.Title = blog.Title
+	}
+	if columns.Has(FieldContent) {
+		projected.Content = blog.Content
+	}
+	if columns.Has(FieldContentFormat) {
+		projected.ContentFormat = blog.ContentFormat
+	}
+	if columns.Has(FieldAuthor) {
+		projected.Author = blog.Author
+	}
+	if columns.Has(FieldTags) {
+		projected.Tags = blog.Tags
+	}
+	if columns.Has(FieldExcerpt) {
+		projected.Excerpt = blog.Excerpt
+	}
+	if columns.Has(FieldWordCount) {
+		projected.WordCount = blog.WordCount
+	}
+	if columns.Has(FieldReadingMinutes) {
+		projected.ReadingMinutes = blog.ReadingMinutes
+	}
+	if columns.Has(FieldTOC) {
+		projected.TOC = blog.TOC
+	}
+	if columns.Has(FieldCoverImageID) {
+		projected.CoverImageID = blog.CoverImageID
+	}
+	if columns.Has(FieldCreatedAt) {
+		projected.CreatedAt = blog.CreatedAt
+	}
+	return projected
+}
@@ -0,0 +1,40 @@
+package domain
+
+import "time"
+
+// IdempotencyRecord remembers a request made with an idempotency key. It is
+// reserved before the request is processed and completed with the response,
+// which is then replayed for repeats of the request until ExpiresAt.
+type IdempotencyRecord struct {
+	// Key is the client key, prefixed with the scope of the caller.
+	Key string `gorm:"primaryKey;size:320"`
+	// Token identifies the reservation, so that a request whose reservation
+	// expired cannot complete or release the one that replaced it.
+	Token string `gorm:"size:36;not null"`
+	// Fingerprint is a hash of the request; repeats must match it.
+	Fingerprint string `gorm:"size:64;not null"`
+	Completed   bool   `gorm:"not null"`
+	StatusCode  int    `gorm:"not null"`
+	ContentType string
+	Body        []byte
+	CreatedAt   time.Time `gorm:"not null"`
+	// ExpiresAt ends the reservation of an in-progress request, or the
+	// retention of a completed one.
+	ExpiresAt time.Time `gorm:"index;not null"`
+}
+
+// IdempotentResponse is the response stored for replay.
+type IdempotentResponse struct {
+	StatusCode  int
+	ContentType string
+	Body        []byte
+}
+
+// Response returns the stored response of a completed record.
+func (r *IdempotencyRecord) Response() IdempotentResponse {
+	return IdempotentResponse{
+		StatusCode:  r.StatusCode,
+		ContentType: r.ContentType,
+		Body:        r.Body,
+	}
+}
@@ -0,0 +1,45 @@
+package domain
+
+import "time"
+
+// OutboxMessage is an event stored in the outbox table, in the same
+// transaction as the change it describes, until it has been published.
+type OutboxMessage struct {
+	ID             uint      `gorm:"primaryKey"`
+	EventID        string    `gorm:"uniqueIndex;not null"`
+	Type           EventType `gorm:"not null"`
+	BlogID         uint      `gorm:"not null"`
+	Version        int       `gorm:"not null"`
+	Payload        []byte    `gorm:"not null"`
+	OccurredAt     time.Time `gorm:"not null"`
+	Attempts       int       `gorm:"not null;default:0"`
+	NextAttemptAt  time.Time `gorm:"index;not null"`
+	LastError      string
+	PublishedAt    *time.Time `gorm:"index"`
+	DeadLetteredAt *time.Time `gorm:"index"`
+	CreatedAt      time.Time  `gorm:"autoCreateTime"`
+}
+
+func NewOutboxMessage(event Event) *OutboxMessage {
+	return &OutboxMessage{
+		EventID:       event.ID,
+		Type:          event.Type,
+		BlogID:        event.BlogID,
+		Version:       event.Version,
+		Payload:       event.Payload,
+		OccurredAt:    event.OccurredAt,
+		NextAttemptAt: event.OccurredAt,
+	}
+}
+
+// Event returns the event the message carries.
+func (m *OutboxMessage) Event() Event {
+	return Event{
+		ID:         m.EventID,
+		Type:       m.Type,
+		BlogID:     m.BlogID,
+		Version:    m.Version,
+		OccurredAt: m.OccurredAt,
+		Payload:    m.Payload,
+	}
+}
@@ -0,0 +1,198 @@
+package domain
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/toffysoft/go-hexagonal-example/pkg/errors"
+)
+
+// BlogPatch changes some fields of a blog. Apply leaves blog unchanged when
+// it fails.
+type BlogPatch interface {
+	Apply(blog *Blog) error
+}
+
+// FieldPatch sets the fields that are not nil. An empty string clears the
+// field, and so does an empty list of tags. It is what merge patches and
+// field masks translate to.
+type FieldPatch struct {
+	Title         *string
+	Content       *string
+	ContentFormat *string
+	Author        *string
+	Tags          *[]string
+}
+
+func (p FieldPatch) Apply(blog *Blog) error {
+	if p.Title != nil {
+		blog.Title = *p.Title
+	}
+	if p.Content != nil {
+		blog.Content = *p.Content
+	}
+	if p.ContentFormat != nil {
+		blog.ContentFormat = ContentFormat(*p.ContentFormat)
+	}
+	if p.Author != nil {
+		blog.Author = *p.Author
+	}
+	if p.Tags != nil {
+		blog.Tags = *p.Tags
+	}
+	return nil
+}
+
+// Set sets the field named by its JSON name. It reports false for fields
+// that cannot be patched.
+func (p *FieldPatch) Set(field, value string) bool {
+	switch field {
+	case "title":
+		p.Title = &value
+	case "content":
+		p.Content = &value
+	case "content_format":
+		p.ContentFormat = &value
+	case "author":
+		p.Author = &value
+	default:
+		return false
+	}
+	return true
+}
+
+// Empty reports whether the patch changes nothing.
+func (p FieldPatch) Empty() bool {
+	return p.Title == nil && p.Content == nil && p.ContentFormat == nil && p.Author == nil && p.Tags == nil
+}
+
+// SetTags replaces the tags of the blog; nil clears them.
+func (p *FieldPatch) SetTags(tags []string) {
+	if tags == nil {
+		tags = []string{}
+	}
+	p.Tags = &tags
+}
+
+// CoverPatch sets the cover image of a blog to an attachment, or clears it
+// when AttachmentID is zero.
+type CoverPatch struct {
+	AttachmentID uint
+}
+
+func (p CoverPatch) Apply(blog *Blog) error {
+	if p.AttachmentID == 0 {
+		blog.CoverImageID = nil
+		return nil
+	}
+	id := p.AttachmentID
+	blog.CoverImageID = &id
+	return nil
+}
+
+// PatchOperation is one operation of an RFC 6902 JSON Patch.
+type PatchOperation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	From  string          `json:"from,omitempty"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// JSONPatch is an RFC 6902 JSON Patch over the patchable fields of a blog,
+// /title, /content, /content_format and /author. Since those always exist,
+// "add" works like "replace" and "remove" clears the field. A failed "test" is a conflict.
+type JSONPatch []PatchOperation
+
+func (p JSONPatch) Apply(blog *Blog) error {
+	patched := *blog
+	for i, op := range p {
+		if err := op.apply(&patched); err != nil {
+			return errors.NewAppError(errorType(err), fmt.Sprintf("Operation %d (%s %s): %v", i, op.Op, op.Path, err))
+		}
+	}
+	*blog = patched
+	return nil
+}
+
+// Written returns the fields that p writes, set to their values in blog.
+func (p JSONPatch) Written(blog *Blog) FieldPatch {
+	var written FieldPatch
+	for _, op := range p {
+		if op.Op == "test" {
+			continue
+		}
+		if target, err := blogField(blog, op.Path); err == nil {
+			written.Set(op.Path[1:], *target)
+		}
+	}
+	return written
+}
+
+func (op PatchOperation) apply(blog *Blog) error {
+	target, err := blogField(blog, op.Path)
+	if err != nil {
+		return err
+	}
+
+	switch op.Op {
+	case "add", "replace":
+		value, err := stringValue(op.Value)
+		if err != nil {
+			return err
+		}
+		*target = value
+	case "remove":
+		*target = ""
+	case "test":
+		value, err := stringValue(op.Value)
+		if err != nil {
+			return err
+		}
+		if *target != value {
+			return errors.NewConflictError("test failed")
+		}
+	case "copy", "move":
+		source, err := blogField(blog, op.From)
+		if err != nil {
+			return err
+		}
+		value := *source
+		if op.Op == "move" {
+			*source = ""
+		}
+		*target = value
+	default:
+		return errors.NewInvalidInputError("unknown op")
+	}
+	return nil
+}
+
+func blogField(blog *Blog, path string) (*string, error) {
+	switch path {
+	case "/title":
+		return &blog.Title, nil
+	case "/content":
+		return &blog.Content, nil
+	case "/content_format":
+		return (*string)(&blog.ContentFormat), nil
+	case "/author":
+		return &blog.Author, nil
+	default:
+		return nil, errors.NewInvalidInputError(fmt.Sprintf("path %q cannot be patched", path))
+	}
+}
+
+func stringValue(raw json.RawMessage) (string, error) {
+	var value string
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return "", errors.NewInvalidInputError("value must be a string")
+	}
+	return value, nil
+}
+
+func errorType(err error) errors.ErrorType {
+	if appErr, ok := err.(errors.AppError); ok {
+		return appErr.Type
+	}
+	return errors.InvalidInput
+}
@@ -0,0 +1,173 @@
+package domain
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RateLimitIdentity selects whose requests share a bucket.
+type RateLimitIdentity string
+
+const (
+	IdentityIP     RateLimitIdentity = "ip"
+	IdentityAPIKey RateLimitIdentity = "api_key"
+	IdentityUser   RateLimitIdentity = "user"
+)
+
+func (i RateLimitIdentity) Valid() bool {
+	switch i {
+	case IdentityIP, IdentityAPIKey, IdentityUser:
+		return true
+	}
+	return false
+}
+
+// RateLimitPolicy is a token bucket that holds up to Burst tokens and refills
+// at Limit tokens per Window. Every request takes one token.
+type RateLimitPolicy struct {
+	// Name keys the buckets of the policy.
+	Name string
+	// Method and Prefix select the requests the policy applies to. An empty
+	// Method matches any method; gRPC calls have none and match by full
+	// method name.
+	Method   string
+	Prefix   string
+	Limit    int
+	Window   time.Duration
+	Burst    int
+	Identity RateLimitIdentity
+}
+
+// ParseRateLimitPolicy parses a policy written as
+//
+//	[METHOD] PREFIX LIMIT/WINDOW [burst=N] [by=ip|api_key|user]
+//
+// e.g. "POST /api/v1/blogs 10/1m burst=20 by=api_key". Burst defaults to
+// Limit and the identity to the API key.
+func ParseRateLimitPolicy(spec string) (RateLimitPolicy, error) {
+	fields := strings.Fields(spec)
+	policy := RateLimitPolicy{Identity: IdentityAPIKey}
+
+	at := slices.IndexFunc(fields, isRate)
+	if at < 0 {
+		return RateLimitPolicy{}, fmt.Errorf("rate limit policy %q has no rate", spec)
+	}
+	route, fields := fields[:at], fields[at:]
+	switch len(route) {
+	case 1:
+		policy.Prefix = route[0]
+	case 2:
+		policy.Method, policy.Prefix = strings.ToUpper(route[0]), route[1]
+	default:
+		return RateLimitPolicy{}, fmt.Errorf("rate limit policy %q must start with an optional method and a route prefix", spec)
+	}
+	policy.Name = strings.Join(route, " ")
+
+	limit, window, _ := strings.Cut(fields[0], "/")
+	var err error
+	if policy.Limit, err = strconv.Atoi(limit); err != nil || policy.Limit <= 0 {
+		return RateLimitPolicy{}, fmt.Errorf("rate limit policy %q has an invalid limit %q", spec, limit)
+	}
+	if policy.Window, err = time.ParseDuration(window); err != nil || policy.Window <= 0 {
+		return RateLimitPolicy{}, fmt.Errorf("rate limit policy %q has an invalid window %q", spec, window)
+	}
+	policy.Burst = policy.Limit
+
+	for _, option := range fields[1:] {
+		name, value, _ := strings.Cut(option, "=")
+		switch name {
+		case "burst":
+			if policy.Burst, err = strconv.Atoi(value); err != nil || policy.Burst <= 0 {
+				return RateLimitPolicy{}, fmt.Errorf("rate limit policy %q has an invalid burst %q", spec, value)
+			}
+		case "by":
+			policy.Identity = RateLimitIdentity(value)
+			if !policy.Identity.Valid() {
+				return RateLimitPolicy{}, fmt.Errorf("rate limit policy %q has an unknown identity %q", spec, value)
+			}
+		default:
+			return RateLimitPolicy{}, fmt.Errorf("rate limit policy %q has an unknown option %q", spec, option)
+		}
+	}
+	return policy, nil
+}
+
+// isRate reports whether field has the LIMIT/WINDOW form of a rate.
+func isRate(field string) bool {
+	limit, _, ok := strings.Cut(field, "/")
+	_, err := strconv.Atoi(limit)
+	return ok && err == nil
+}
+
+// Matches reports whether the policy applies to a request.
+func (p RateLimitPolicy) Matches(method, route string) bool {
+	return (p.Method == "" || p.Method == method) && strings.HasPrefix(route, p.Prefix)
+}
+
+// Header describes the policy in the form of the RateLimit-Policy header.
+func (p RateLimitPolicy) Header() string {
+	return fmt.Sprintf("%d;w=%d;burst=%d", p.Limit, int(p.Window.Seconds()), p.Burst)
+}
+
+// rate returns the refill rate in tokens per second.
+func (p RateLimitPolicy) rate() float64 {
+	return float64(p.Limit) / p.Window.Seconds()
+}
+
+// RateLimitDecision is the outcome of taking a token.
+type RateLimitDecision struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// Reset is the time until the bucket is full again.
+	Reset time.Duration
+	// RetryAfter is the time until the next token, for a denied request.
+	RetryAfter time.Duration
+}
+
+// TokenBucket is the state of one caller's bucket under one policy.
+type TokenBucket struct {
+	Key        string    `gorm:"primaryKey;size:320"`
+	Tokens     float64   `gorm:"not null"`
+	RefilledAt time.Time `gorm:"not null"`
+	// FullAt is when the bucket will be full, after which it is no different
+	// from a missing bucket and can be deleted.
+	FullAt time.Time `gorm:"index;not null"`
+}
+
+// NewTokenBucket returns a full bucket.
+func NewTokenBucket(key string, policy RateLimitPolicy, now time.Time) *TokenBucket {
+	return &TokenBucket{Key: key, Tokens: float64(policy.Burst), RefilledAt: now, FullAt: now}
+}
+
+// Take refills the bucket up to now and takes a token if one is available.
+func (b *TokenBucket) Take(policy RateLimitPolicy, now time.Time) RateLimitDecision {
+	rate := policy.rate()
+	burst := float64(policy.Burst)
+	if elapsed := now.Sub(b.RefilledAt).Seconds(); elapsed > 0 {
+		b.Tokens += elapsed * rate
+		b.RefilledAt = now
+	}
+	// Capping here also covers a burst lowered since the bucket was last used.
+	b.Tokens = math.Min(burst, b.Tokens)
+
+	decision := RateLimitDecision{Limit: policy.Burst}
+	if b.Tokens >= 1 {
+		b.Tokens--
+		decision.Allowed = true
+	} else {
+		decision.RetryAfter = seconds((1 - b.Tokens) / rate)
+	}
+	decision.Remaining = int(b.Tokens)
+	decision.Reset = seconds((burst - b.Tokens) / rate)
+	b.FullAt = now.Add(decision.Reset)
+	return decision
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
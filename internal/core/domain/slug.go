@@ -0,0 +1,25 @@
+package domain
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Slugify turns a title into a URL-friendly slug: lower-case letters and
+// digits separated by single hyphens. Letters outside ASCII are kept.
+func Slugify(title string) string {
+	var b strings.Builder
+	pendingHyphen := false
+	for _, r := range strings.ToLower(title) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.Is(unicode.Mn, r) {
+			if pendingHyphen && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			pendingHyphen = false
+			b.WriteRune(r)
+			continue
+		}
+		pendingHyphen = true
+	}
+	return b.String()
+}
@@ -0,0 +1,35 @@
+package domain
+
+import "fmt"
+
+// MaxTags is the number of tags a blog can have.
+const MaxTags = 10
+
+// NormalizeTags returns tags as slugs, without empty or repeated ones, in
+// their original order. It fails on more than MaxTags tags.
+func NormalizeTags(tags []string) ([]string, error) {
+	normalized := make([]string, 0, len(tags))
+	seen := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		tag = Slugify(tag)
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+	if len(normalized) > MaxTags {
+		return nil, fmt.Errorf("a blog can have at most %d tags", MaxTags)
+	}
+	return normalized, nil
+}
+
+// HasTag reports whether the blog is tagged with tag.
+func (b *Blog) HasTag(tag string) bool {
+	for _, t := range b.Tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
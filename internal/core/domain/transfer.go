@@ -0,0 +1,93 @@
+package domain
+
+import (
+	"strings"
+	"time"
+)
+
+// BlogFilter selects blogs. Zero fields match every blog.
+type BlogFilter struct {
+	Author        string
+	Tag           string
+	CreatedAfter  time.Time
+	CreatedBefore time.Time
+	// Unsummarized selects blogs with content but no word count, written
+	// before their metadata was derived.
+	Unsummarized bool
+}
+
+// DedupeKey selects how imported rows are matched with existing blogs and
+// with each other.
+type DedupeKey string
+
+const (
+	// DedupeNone imports every row as a new blog.
+	DedupeNone DedupeKey = "none"
+	// DedupeSlug matches blogs whose titles have the same slug.
+	DedupeSlug DedupeKey = "slug"
+	// DedupeTitleAuthor matches blogs with the same title and author.
+	DedupeTitleAuthor DedupeKey = "title_author"
+)
+
+// Valid reports whether k is a known dedupe key.
+func (k DedupeKey) Valid() bool {
+	return k == DedupeNone || k == DedupeSlug || k == DedupeTitleAuthor
+}
+
+// Of returns the value blog is matched by, or "" when k matches nothing.
+func (k DedupeKey) Of(blog *Blog) string {
+	switch k {
+	case DedupeSlug:
+		return Slugify(blog.Title)
+	case DedupeTitleAuthor:
+		return strings.ToLower(strings.TrimSpace(blog.Title)) + "\x00" + strings.ToLower(strings.TrimSpace(blog.Author))
+	default:
+		return ""
+	}
+}
+
+type ImportOptions struct {
+	// DryRun reports what an import would do without changing anything.
+	DryRun   bool
+	DedupeBy DedupeKey
+	// Upsert overwrites the matching blog instead of skipping the row.
+	Upsert bool
+}
+
+// ImportRecord is one row of an import file. Err is set when the row could
+// not be parsed.
+type ImportRecord struct {
+	// Source locates the row in the file, e.g. "line 3".
+	Source string
+	Blog   *Blog
+	Err    error
+}
+
+type ImportAction string
+
+const (
+	ImportCreated ImportAction = "created"
+	ImportUpdated ImportAction = "updated"
+	ImportSkipped ImportAction = "skipped"
+	ImportFailed  ImportAction = "failed"
+)
+
+// ImportRow reports what happened to one row of an import.
+type ImportRow struct {
+	Source string       `json:"source"`
+	Action ImportAction `json:"action"`
+	// BlogID is the created, updated or matching blog. It is 0 for rows a
+	// dry run would create.
+	BlogID uint `json:"blog_id,omitempty"`
+	// Reason explains skipped and failed rows.
+	Reason string `json:"reason,omitempty"`
+}
+
+type ImportReport struct {
+	DryRun  bool        `json:"dry_run"`
+	Created int         `json:"created"`
+	Updated int         `json:"updated"`
+	Skipped int         `json:"skipped"`
+	Failed  int         `json:"failed"`
+	Rows    []ImportRow `json:"rows"`
+}
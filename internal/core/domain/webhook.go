@@ -0,0 +1,76 @@
+package domain
+
+import (
+	"net/netip"
+	"time"
+)
+
+// Webhook is a partner endpoint that receives blog events over HTTP. An empty
+// Events list subscribes to every event type.
+type Webhook struct {
+	ID                  uint        `json:"id" gorm:"primaryKey"`
+	URL                 string      `json:"url" gorm:"not null"`
+	Secret              string      `json:"-" gorm:"not null"`
+	Events              []EventType `json:"events" gorm:"serializer:json"`
+	Enabled             bool        `json:"enabled" gorm:"not null;default:true"`
+	ConsecutiveFailures int         `json:"consecutive_failures" gorm:"not null;default:0"`
+	DisabledAt          *time.Time  `json:"disabled_at,omitempty"`
+	DisabledReason      string      `json:"disabled_reason,omitempty"`
+	CreatedAt           time.Time   `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt           time.Time   `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// Subscribed reports whether the webhook wants events of the given type.
+func (w *Webhook) Subscribed(eventType EventType) bool {
+	if len(w.Events) == 0 {
+		return true
+	}
+	for _, t := range w.Events {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// PublicWebhookAddr reports whether webhooks may be sent to addr. Loopback,
+// private, link-local, multicast and unspecified addresses are refused, so
+// that a webhook cannot reach the service's own network.
+func PublicWebhookAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	return addr.IsValid() && addr.IsGlobalUnicast() && !addr.IsPrivate()
+}
+
+type DeliveryStatus string
+
+const (
+	DeliveryPending   DeliveryStatus = "pending"
+	DeliverySucceeded DeliveryStatus = "succeeded"
+	DeliveryFailed    DeliveryStatus = "failed"
+)
+
+// WebhookDelivery is one event to be sent to one webhook. It stays pending
+// while attempts are left and ends up succeeded or failed.
+type WebhookDelivery struct {
+	ID            uint           `json:"id" gorm:"primaryKey"`
+	WebhookID     uint           `json:"webhook_id" gorm:"index;not null"`
+	EventID       string         `json:"event_id" gorm:"index;not null"`
+	EventType     EventType      `json:"event_type" gorm:"not null"`
+	Payload       []byte         `json:"-" gorm:"not null"`
+	Status        DeliveryStatus `json:"status" gorm:"index;not null"`
+	Attempts      int            `json:"attempts" gorm:"not null;default:0"`
+	NextAttemptAt time.Time      `json:"next_attempt_at" gorm:"index;not null"`
+	CreatedAt     time.Time      `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt     time.Time      `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// WebhookAttempt records the outcome of one HTTP request of a delivery.
+type WebhookAttempt struct {
+	ID         uint          `json:"id" gorm:"primaryKey"`
+	DeliveryID uint          `json:"delivery_id" gorm:"index;not null"`
+	Attempt    int           `json:"attempt" gorm:"not null"`
+	StatusCode int           `json:"status_code"`
+	Error      string        `json:"error,omitempty"`
+	Duration   time.Duration `json:"duration"`
+	CreatedAt  time.Time     `json:"created_at" gorm:"autoCreateTime"`
+}
@@ -0,0 +1,55 @@
+package ports
+
+import (
+	"context"
+	"io"
+
+	"github.com/toffysoft/go-hexagonal-example/internal/core/domain"
+)
+
+type AttachmentRepository interface {
+	Create(ctx context.Context, attachment *domain.Attachment) error
+	GetByID(ctx context.Context, id uint) (*domain.Attachment, error)
+	Delete(ctx context.Context, id uint) error
+	// ListByBlog returns the attachments of a blog in ascending ID order.
+	ListByBlog(ctx context.Context, blogID uint) ([]*domain.Attachment, error)
+	// Orphans returns up to limit attachments whose blog no longer exists.
+	Orphans(ctx context.Context, limit int) ([]*domain.Attachment, error)
+}
+
+// ObjectStore keeps the bytes of attachments by key, in a filesystem or an
+// S3-compatible bucket.
+type ObjectStore interface {
+	Put(ctx context.Context, key string, data io.Reader, contentType string) error
+	// Get fails with a NotFound AppError when there is no object with key.
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete succeeds when there is no object with key.
+	Delete(ctx context.Context, key string) error
+}
+
+// Thumbnailer scales down images.
+type Thumbnailer interface {
+	// Thumbnail returns a copy of an image of the given content type that
+	// fits in a size by size square, or nil if it cannot decode the type.
+	Thumbnail(ctx context.Context, contentType string, data []byte, size int) (*domain.Thumbnail, error)
+}
+
+type AttachmentService interface {
+	// Upload stores content as an attachment of a blog. Its type is sniffed
+	// from the content, not taken from the client.
+	Upload(ctx context.Context, blogID uint, filename string, content io.Reader) (*domain.Attachment, error)
+	GetAttachment(ctx context.Context, id uint) (*domain.Attachment, error)
+	ListAttachments(ctx context.Context, blogID uint) ([]*domain.Attachment, error)
+	// OpenAttachment returns the attachment with a reader of its bytes, or of
+	// those of its thumbnail. The caller closes the reader.
+	OpenAttachment(ctx context.Context, id uint, thumbnail bool) (*domain.Attachment, io.ReadCloser, error)
+	// DeleteAttachment deletes an attachment, and clears the cover image of
+	// its blog if it was that.
+	DeleteAttachment(ctx context.Context, id uint) error
+	// SetCover makes an image attachment of a blog its cover image, or clears
+	// the cover image when attachmentID is zero.
+	SetCover(ctx context.Context, blogID, attachmentID uint) (*domain.Blog, error)
+	// CleanupOrphans deletes the attachments of blogs that no longer exist
+	// and returns how many were deleted.
+	CleanupOrphans(ctx context.Context) (int, error)
+}
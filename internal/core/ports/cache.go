@@ -0,0 +1,15 @@
+package ports
+
+import (
+	"context"
+	"time"
+)
+
+// Cache is a byte cache shared by every instance, such as Redis or
+// memcached. Callers treat it as best effort: an error is a miss.
+type Cache interface {
+	// Get returns the value at key, or false if there is none.
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	Delete(ctx context.Context, keys ...string) error
+}
@@ -0,0 +1,18 @@
+package ports
+
+import "context"
+
+type strongConsistencyKey struct{}
+
+// WithStrongConsistency marks ctx so that repository reads made with it observe
+// every committed write, e.g. by reading from the primary instead of a replica.
+// Use it for read-before-write and read-after-write paths.
+func WithStrongConsistency(ctx context.Context) context.Context {
+	return context.WithValue(ctx, strongConsistencyKey{}, true)
+}
+
+// IsStrongConsistency reports whether ctx was marked by WithStrongConsistency.
+func IsStrongConsistency(ctx context.Context) bool {
+	strong, _ := ctx.Value(strongConsistencyKey{}).(bool)
+	return strong
+}
@@ -0,0 +1,60 @@
+package ports
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/toffysoft/go-hexagonal-example/internal/core/domain"
+)
+
+// EventBus delivers domain events to the handlers subscribed to them. Publish
+// may be called more than once for the same event, so handlers must tolerate
+// duplicates, e.g. by deduplicating on the event ID.
+type EventBus interface {
+	Publish(ctx context.Context, event domain.Event) error
+	// Subscribe registers handler under name for events of the given types,
+	// or for every event when no type is given. The name identifies the
+	// handler in logs and metrics.
+	Subscribe(name string, handler EventHandler, types ...domain.EventType)
+}
+
+// EventHandler reacts to a domain event.
+type EventHandler func(ctx context.Context, event domain.Event) error
+
+// BlogEventHandler reacts to a blog event with its decoded payload.
+type BlogEventHandler func(ctx context.Context, event domain.Event, payload domain.BlogEventPayload) error
+
+// HandleBlogEvents adapts fn to an EventHandler that decodes the blog payload
+// first.
+func HandleBlogEvents(fn BlogEventHandler) EventHandler {
+	return func(ctx context.Context, event domain.Event) error {
+		payload, err := event.BlogPayload()
+		if err != nil {
+			return fmt.Errorf("decode %s event %s: %w", event.Type, event.ID, err)
+		}
+		return fn(ctx, event, payload)
+	}
+}
+
+// OutboxRepository stores events until they are published. Add must join the
+// unit of work bound to ctx so the events commit or roll back with the change.
+type OutboxRepository interface {
+	Add(ctx context.Context, events ...domain.Event) error
+	// Pending returns up to limit messages due for delivery at now, oldest
+	// first. Inside a unit of work the messages stay claimed until it ends,
+	// so concurrent relays do not pick the same messages.
+	Pending(ctx context.Context, now time.Time, limit int) ([]*domain.OutboxMessage, error)
+	MarkPublished(ctx context.Context, id uint, at time.Time) error
+	// MarkFailed records a failed attempt and schedules the next one at retryAt.
+	MarkFailed(ctx context.Context, id uint, reason string, retryAt time.Time) error
+	// MarkDeadLettered records a final failed attempt; the message is kept for
+	// inspection but never retried.
+	MarkDeadLettered(ctx context.Context, id uint, reason string, at time.Time) error
+	// After returns up to limit messages with an ID above id in ID order,
+	// whether published or not. Messages are never removed, so the outbox
+	// can be read as a log of every change.
+	After(ctx context.Context, id uint, limit int) ([]*domain.OutboxMessage, error)
+	// Latest returns the last limit messages in ID order.
+	Latest(ctx context.Context, limit int) ([]*domain.OutboxMessage, error)
+}
@@ -0,0 +1,19 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/toffysoft/go-hexagonal-example/internal/core/domain"
+)
+
+// ChangeFeed streams blog changes to live subscribers and keeps the most
+// recent ones so that subscribers can resume after a disconnect.
+type ChangeFeed interface {
+	// Subscribe returns the buffered changes after cursor and a channel of
+	// the changes that follow. The channel is closed when ctx ends or when
+	// the subscriber falls too far behind; it should then resume from the
+	// last cursor it received. An empty cursor subscribes to new changes only.
+	// resumed is false if cursor is unknown or its successors have already
+	// been dropped from the buffer, meaning changes may have been missed.
+	Subscribe(ctx context.Context, cursor string) (replay []domain.BlogChange, live <-chan domain.BlogChange, resumed bool)
+}
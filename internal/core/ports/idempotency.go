@@ -0,0 +1,37 @@
+package ports
+
+import (
+	"context"
+	"time"
+
+	"github.com/toffysoft/go-hexagonal-example/internal/core/domain"
+)
+
+// IdempotencyStore keeps the records of requests made with an idempotency key.
+type IdempotencyStore interface {
+	// Reserve stores record unless a record with the same key that has not
+	// expired at now exists, in which case it stores nothing and returns that
+	// record instead. Expired records are replaced.
+	Reserve(ctx context.Context, record *domain.IdempotencyRecord, now time.Time) (*domain.IdempotencyRecord, error)
+	// Complete stores the response of the reservation made with record's key
+	// and token, and keeps it until expiresAt.
+	Complete(ctx context.Context, record *domain.IdempotencyRecord, response domain.IdempotentResponse, expiresAt time.Time) error
+	// Release deletes the reservation made with record's key and token.
+	Release(ctx context.Context, record *domain.IdempotencyRecord) error
+	// DeleteExpired deletes the records expired at now and returns how many.
+	DeleteExpired(ctx context.Context, now time.Time) (int64, error)
+}
+
+// IdempotencyService makes requests carrying an idempotency key safe to retry.
+type IdempotencyService interface {
+	// Begin claims key within scope for a request with the given fingerprint.
+	// The returned record is either a new reservation, which the caller must
+	// Complete or Release, or a completed record whose response the caller
+	// replays. Reusing a key with a different fingerprint is Unprocessable;
+	// a key whose first request is still in progress is a Conflict.
+	Begin(ctx context.Context, scope, key, fingerprint string) (*domain.IdempotencyRecord, error)
+	// Complete stores the response of a reserved request for replay.
+	Complete(ctx context.Context, record *domain.IdempotencyRecord, response domain.IdempotentResponse) error
+	// Release gives up a reservation, so that the request can be retried.
+	Release(ctx context.Context, record *domain.IdempotencyRecord) error
+}
@@ -0,0 +1,5 @@
+package ports
+
+// MaintenanceState reports whether maintenance mode is on and the message to
+// return to rejected callers. Both the HTTP and gRPC servers consult it.
+type MaintenanceState func() (enabled bool, message string)
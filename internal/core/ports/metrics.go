@@ -0,0 +1,29 @@
+package ports
+
+import "github.com/toffysoft/go-hexagonal-example/internal/core/domain"
+
+// BlogMetrics records service-level blog events. The core only depends on this
+// interface; the Prometheus implementation lives in the metrics adapter.
+type BlogMetrics interface {
+	BlogCreated()
+	BlogUpdated()
+	BlogDeleted()
+}
+
+// EventBusMetrics records the health of the in-process event bus.
+type EventBusMetrics interface {
+	// QueueDepth reports the number of deliveries waiting for a worker.
+	QueueDepth(depth int)
+	// HandlerFailed counts a handler that returned an error or, when
+	// panicked is set, panicked.
+	HandlerFailed(subscriber string, eventType domain.EventType, panicked bool)
+}
+
+// CacheMetrics records how reads are served by a cache.
+type CacheMetrics interface {
+	// CacheHit counts a read of cache served by tier ("local" or "remote");
+	// a negative hit served a cached not-found.
+	CacheHit(cache, tier string, negative bool)
+	// CacheMiss counts a read of cache that went to the underlying store.
+	CacheMiss(cache string)
+}
@@ -0,0 +1,27 @@
+package ports
+
+import (
+	"context"
+	"time"
+
+	"github.com/toffysoft/go-hexagonal-example/internal/core/domain"
+)
+
+// RateLimitStore keeps token buckets. Stores shared by several instances
+// enforce the limits across all of them.
+type RateLimitStore interface {
+	// Take takes a token from the bucket at key under policy, creating a full
+	// bucket if there is none. It must be atomic with concurrent Takes.
+	Take(ctx context.Context, key string, policy domain.RateLimitPolicy, now time.Time) (domain.RateLimitDecision, error)
+	// DeleteFull deletes the buckets that are full at now and returns how many.
+	DeleteFull(ctx context.Context, now time.Time) (int64, error)
+}
+
+// RateLimiter decides whether a caller may make a request.
+type RateLimiter interface {
+	// Policy returns the policy applying to a request, or false when requests
+	// are not limited.
+	Policy(method, route string) (domain.RateLimitPolicy, bool)
+	// Allow takes a token from the caller's bucket under policy.
+	Allow(ctx context.Context, policy domain.RateLimitPolicy, identity string) (domain.RateLimitDecision, error)
+}
@@ -0,0 +1,19 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/toffysoft/go-hexagonal-example/internal/core/domain"
+)
+
+// ContentRenderer turns the content of a blog into HTML that is safe to embed
+// in a page.
+type ContentRenderer interface {
+	Render(ctx context.Context, format domain.ContentFormat, content string) (string, error)
+}
+
+// ContentAnalyzer extracts the text of the content of a blog, from which its
+// excerpt, word count, reading time and table of contents are derived.
+type ContentAnalyzer interface {
+	Analyze(ctx context.Context, format domain.ContentFormat, content string) (domain.ContentText, error)
+}
@@ -1,11 +1,33 @@
 package ports
 
-import "github.com/toffysoft/go-hexagonal-example/internal/core/domain"
+import (
+	"context"
+
+	"github.com/toffysoft/go-hexagonal-example/internal/core/domain"
+)
 
 type BlogRepository interface {
-	Create(blog *domain.Blog) error
-	GetByID(id uint) (*domain.Blog, error)
-	Update(blog *domain.Blog) error
-	Delete(id uint) error
-	List() ([]*domain.Blog, error)
+	Create(ctx context.Context, blog *domain.Blog) error
+	GetByID(ctx context.Context, id uint) (*domain.Blog, error)
+	// GetFields is GetByID reading only the columns of mask.Columns().
+	GetFields(ctx context.Context, id uint, mask domain.FieldMask) (*domain.Blog, error)
+	Update(ctx context.Context, blog *domain.Blog) error
+	Delete(ctx context.Context, id uint) error
+	List(ctx context.Context) ([]*domain.Blog, error)
+	// ListFields is List reading only the columns of mask.Columns().
+	ListFields(ctx context.Context, mask domain.FieldMask) ([]*domain.Blog, error)
+	// ListAfter returns up to limit blogs matching filter with an ID greater
+	// than afterID, in ascending ID order.
+	ListAfter(ctx context.Context, filter domain.BlogFilter, afterID uint, limit int) ([]*domain.Blog, error)
+	// ListLatest returns up to limit blogs matching filter, newest first,
+	// reading only the columns of mask.Columns().
+	ListLatest(ctx context.Context, filter domain.BlogFilter, mask domain.FieldMask, limit int) ([]*domain.Blog, error)
+	// CreateBatch inserts blogs with as few round trips as possible. It is
+	// all or nothing only when ctx is in a unit of work.
+	CreateBatch(ctx context.Context, blogs []*domain.Blog) error
+	// GetByIDs returns the blogs with the given IDs that exist, in ascending
+	// ID order.
+	GetByIDs(ctx context.Context, ids []uint) ([]*domain.Blog, error)
+	// DeleteBatch deletes the blogs with the given IDs in one statement.
+	DeleteBatch(ctx context.Context, ids []uint) error
 }
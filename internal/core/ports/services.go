@@ -1,11 +1,45 @@
 package ports
 
-import "github.com/toffysoft/go-hexagonal-example/internal/core/domain"
+import (
+	"context"
+
+	"github.com/toffysoft/go-hexagonal-example/internal/core/domain"
+)
 
 type BlogService interface {
-	CreateBlog(blog *domain.Blog) error
-	GetBlog(id uint) (*domain.Blog, error)
-	UpdateBlog(blog *domain.Blog) error
-	DeleteBlog(id uint) error
-	ListBlogs() ([]*domain.Blog, error)
+	CreateBlog(ctx context.Context, blog *domain.Blog) error
+	GetBlog(ctx context.Context, id uint) (*domain.Blog, error)
+	// GetBlogFields is GetBlog reading only the fields of mask. Fields
+	// outside of it may be set too. With rendered_html in the mask, the blog
+	// is rendered.
+	GetBlogFields(ctx context.Context, id uint, mask domain.FieldMask) (*domain.Blog, error)
+	UpdateBlog(ctx context.Context, blog *domain.Blog) error
+	// PatchBlog applies patch to the stored blog with the given ID and
+	// returns the result. Unlike UpdateBlog it can clear fields.
+	PatchBlog(ctx context.Context, id uint, patch domain.BlogPatch) (*domain.Blog, error)
+	DeleteBlog(ctx context.Context, id uint) error
+	ListBlogs(ctx context.Context) ([]*domain.Blog, error)
+	// ListBlogFields is ListBlogs reading only the fields of mask, like
+	// GetBlogFields.
+	ListBlogFields(ctx context.Context, mask domain.FieldMask) ([]*domain.Blog, error)
+	// ListLatestBlogs returns up to limit blogs matching filter, newest
+	// first, reading only the fields of mask like GetBlogFields.
+	ListLatestBlogs(ctx context.Context, filter domain.BlogFilter, mask domain.FieldMask, limit int) ([]*domain.Blog, error)
+	// RenderBlogs sets the RenderedHTML of each blog from its content and
+	// content format.
+	RenderBlogs(ctx context.Context, blogs ...*domain.Blog) error
+	// StreamBlogs calls fn with successive chunks of at most chunkSize blogs
+	// with an ID greater than afterID, in ascending ID order. It stops at the
+	// first error from fn or when ctx ends.
+	StreamBlogs(ctx context.Context, afterID uint, chunkSize int, fn func(blogs []*domain.Blog) error) error
+	// BatchCreateBlogs creates blogs and reports the outcome of each one. In
+	// atomic mode a failing item aborts the batch with an error, and the
+	// results tell which items were at fault.
+	BatchCreateBlogs(ctx context.Context, blogs []*domain.Blog, mode domain.BatchMode) ([]domain.BatchResult, error)
+	// BatchUpdateBlogs applies the non-empty fields of each blog to the
+	// stored blog with the same ID. Modes work as in BatchCreateBlogs.
+	BatchUpdateBlogs(ctx context.Context, blogs []*domain.Blog, mode domain.BatchMode) ([]domain.BatchResult, error)
+	// BatchDeleteBlogs deletes the blogs with the given IDs. Modes work as in
+	// BatchCreateBlogs.
+	BatchDeleteBlogs(ctx context.Context, ids []uint, mode domain.BatchMode) ([]domain.BatchResult, error)
 }
@@ -0,0 +1,71 @@
+package ports
+
+import (
+	"context"
+	"sync"
+)
+
+// UnitOfWork runs a group of repository calls atomically.
+//
+// Do calls fn with a context bound to a new transaction; repository calls
+// made with that context take part in it. The transaction is committed when fn
+// returns nil and rolled back when it returns an error or panics. Calling Do
+// again with the transactional context opens a savepoint, so a failing inner
+// fn only undoes its own changes.
+//
+// Implementations run Do through TrackCommit, so that AfterCommit works.
+type UnitOfWork interface {
+	Do(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+type commitHooksKey struct{}
+
+// commitHooks collects the callbacks registered in one transaction or savepoint.
+type commitHooks struct {
+	mu  sync.Mutex
+	fns []func()
+}
+
+func (h *commitHooks) add(fns ...func()) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.fns = append(h.fns, fns...)
+}
+
+// AfterCommit runs fn once the unit of work bound to ctx has committed, or
+// right away when ctx has none. fn is dropped if the unit of work, or the
+// savepoint it was registered in, rolls back. Use it for side effects that
+// must not be seen before the change, such as cache invalidation.
+func AfterCommit(ctx context.Context, fn func()) {
+	if hooks, ok := ctx.Value(commitHooksKey{}).(*commitHooks); ok {
+		hooks.add(fn)
+		return
+	}
+	fn()
+}
+
+// InUnitOfWork reports whether ctx is bound to a unit of work.
+func InUnitOfWork(ctx context.Context) bool {
+	_, ok := ctx.Value(commitHooksKey{}).(*commitHooks)
+	return ok
+}
+
+// TrackCommit is for UnitOfWork implementations. It calls do, which must open
+// the transaction or savepoint and run fn in it, with a context that collects
+// AfterCommit callbacks. Once do succeeds, the callbacks of a transaction run
+// and those of a savepoint are handed to the enclosing transaction.
+func TrackCommit(ctx context.Context, do func(ctx context.Context) error) error {
+	parent, _ := ctx.Value(commitHooksKey{}).(*commitHooks)
+	hooks := &commitHooks{}
+	if err := do(context.WithValue(ctx, commitHooksKey{}, hooks)); err != nil {
+		return err
+	}
+	if parent != nil {
+		parent.add(hooks.fns...)
+		return nil
+	}
+	for _, fn := range hooks.fns {
+		fn()
+	}
+	return nil
+}
@@ -0,0 +1,19 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/toffysoft/go-hexagonal-example/internal/core/domain"
+)
+
+// TransferService moves blogs between environments in bulk. File formats
+// are left to adapters; the service works on blogs and parsed rows.
+type TransferService interface {
+	// ExportBlogs calls fn with successive chunks of the blogs matching
+	// filter, in ascending ID order.
+	ExportBlogs(ctx context.Context, filter domain.BlogFilter, fn func(blogs []*domain.Blog) error) error
+	// ImportBlogs creates, updates or skips a blog for every record and
+	// reports what it did. Rows that fail do not stop the import, but the
+	// writes of an import are applied all together or not at all.
+	ImportBlogs(ctx context.Context, records []domain.ImportRecord, opts domain.ImportOptions) (*domain.ImportReport, error)
+}
@@ -0,0 +1,48 @@
+package ports
+
+import (
+	"context"
+	"time"
+
+	"github.com/toffysoft/go-hexagonal-example/internal/core/domain"
+)
+
+type WebhookRepository interface {
+	Create(ctx context.Context, webhook *domain.Webhook) error
+	// GetByID fails with a NotFound AppError when there is no webhook with id.
+	GetByID(ctx context.Context, id uint) (*domain.Webhook, error)
+	Update(ctx context.Context, webhook *domain.Webhook) error
+	Delete(ctx context.Context, id uint) error
+	List(ctx context.Context) ([]*domain.Webhook, error)
+
+	CreateDeliveries(ctx context.Context, deliveries []*domain.WebhookDelivery) error
+	GetDelivery(ctx context.Context, id uint) (*domain.WebhookDelivery, error)
+	UpdateDelivery(ctx context.Context, delivery *domain.WebhookDelivery) error
+	// ListDeliveries returns the deliveries of a webhook, newest first.
+	ListDeliveries(ctx context.Context, webhookID uint, limit int) ([]*domain.WebhookDelivery, error)
+	// DueDeliveries returns up to limit pending deliveries due at now, locked
+	// until the surrounding unit of work ends. Other callers skip locked
+	// deliveries.
+	DueDeliveries(ctx context.Context, now time.Time, limit int) ([]*domain.WebhookDelivery, error)
+	AddAttempt(ctx context.Context, attempt *domain.WebhookAttempt) error
+	ListAttempts(ctx context.Context, deliveryID uint) ([]*domain.WebhookAttempt, error)
+}
+
+// WebhookSender makes the HTTP request of one delivery attempt. It returns the
+// response status code, or an error if no response was received.
+type WebhookSender interface {
+	Send(ctx context.Context, webhook *domain.Webhook, delivery *domain.WebhookDelivery) (int, error)
+}
+
+type WebhookService interface {
+	CreateWebhook(ctx context.Context, webhook *domain.Webhook) error
+	GetWebhook(ctx context.Context, id uint) (*domain.Webhook, error)
+	UpdateWebhook(ctx context.Context, webhook *domain.Webhook) error
+	DeleteWebhook(ctx context.Context, id uint) error
+	ListWebhooks(ctx context.Context) ([]*domain.Webhook, error)
+
+	ListDeliveries(ctx context.Context, webhookID uint) ([]*domain.WebhookDelivery, error)
+	GetDelivery(ctx context.Context, webhookID, deliveryID uint) (*domain.WebhookDelivery, []*domain.WebhookAttempt, error)
+	// Redeliver queues a new delivery of the same event to the webhook.
+	Redeliver(ctx context.Context, webhookID, deliveryID uint) (*domain.WebhookDelivery, error)
+}
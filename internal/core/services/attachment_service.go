@@ -0,0 +1,327 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/toffysoft/go-hexagonal-example/internal/core/domain"
+	"github.com/toffysoft/go-hexagonal-example/internal/core/ports"
+	"github.com/toffysoft/go-hexagonal-example/pkg/errors"
+
+	"github.com/google/uuid"
+)
+
+// Defaults of AttachmentConfig.
+const (
+	DefaultMaxAttachmentSize = 3 << 20
+	DefaultThumbnailSize     = 320
+	DefaultCleanupInterval   = time.Hour
+)
+
+// DefaultAttachmentTypes are the types of attachments accepted by default.
+// SVG is left out, since it can carry scripts.
+var DefaultAttachmentTypes = []string{"image/jpeg", "image/png", "image/gif", "image/webp", "application/pdf"}
+
+// orphanBatchSize is the number of orphaned attachments deleted at a time.
+const orphanBatchSize = 100
+
+// AttachmentConfig limits uploads to MaxSize bytes of one of AllowedTypes.
+// Images get thumbnails that fit in a ThumbnailSize pixel square, and Run
+// looks for orphaned attachments every CleanupInterval. Zero fields take
+// their defaults.
+type AttachmentConfig struct {
+	MaxSize         int64
+	AllowedTypes    []string
+	ThumbnailSize   int
+	CleanupInterval time.Duration
+}
+
+func (c AttachmentConfig) withDefaults() AttachmentConfig {
+	if c.MaxSize <= 0 {
+		c.MaxSize = DefaultMaxAttachmentSize
+	}
+	if len(c.AllowedTypes) == 0 {
+		c.AllowedTypes = DefaultAttachmentTypes
+	}
+	if c.ThumbnailSize <= 0 {
+		c.ThumbnailSize = DefaultThumbnailSize
+	}
+	if c.CleanupInterval <= 0 {
+		c.CleanupInterval = DefaultCleanupInterval
+	}
+	return c
+}
+
+// AttachmentService keeps the files uploaded to blogs: their metadata in a
+// repository, and their bytes and thumbnails in object storage. Attachments
+// of deleted blogs are removed by HandleEvent and, for blogs deleted while
+// events were not delivered, by Run.
+type AttachmentService struct {
+	repo        ports.AttachmentRepository
+	store       ports.ObjectStore
+	blogs       ports.BlogService
+	thumbnailer ports.Thumbnailer
+	cfg         AttachmentConfig
+}
+
+var _ ports.AttachmentService = (*AttachmentService)(nil)
+
+func NewAttachmentService(repo ports.AttachmentRepository, store ports.ObjectStore, blogs ports.BlogService, thumbnailer ports.Thumbnailer, cfg AttachmentConfig) *AttachmentService {
+	return &AttachmentService{
+		repo:        repo,
+		store:       store,
+		blogs:       blogs,
+		thumbnailer: thumbnailer,
+		cfg:         cfg.withDefaults(),
+	}
+}
+
+func (s *AttachmentService) Upload(ctx context.Context, blogID uint, filename string, content io.Reader) (*domain.Attachment, error) {
+	if _, err := s.blogs.GetBlog(ctx, blogID); err != nil {
+		return nil, err
+	}
+	data, err := io.ReadAll(io.LimitReader(content, s.cfg.MaxSize+1))
+	if err != nil {
+		return nil, errors.NewInvalidInputError(fmt.Sprintf("Failed to read attachment: %v", err))
+	}
+	if int64(len(data)) > s.cfg.MaxSize {
+		return nil, errors.NewTooLargeError(fmt.Sprintf("Attachment exceeds %d bytes", s.cfg.MaxSize))
+	}
+	if len(data) == 0 {
+		return nil, errors.NewInvalidInputError("Attachment is empty")
+	}
+	contentType := sniff(data)
+	if !s.allowed(contentType) {
+		return nil, errors.NewUnsupportedError(fmt.Sprintf("Attachment type %s is not allowed; allowed types are %s",
+			contentType, strings.Join(s.cfg.AllowedTypes, ", ")))
+	}
+
+	attachment := &domain.Attachment{
+		BlogID:      blogID,
+		Filename:    cleanFilename(filename),
+		ContentType: contentType,
+		Size:        int64(len(data)),
+		Key:         fmt.Sprintf("blogs/%d/%s", blogID, uuid.NewString()),
+	}
+	var thumbnail *domain.Thumbnail
+	if attachment.IsImage() {
+		if thumbnail, err = s.thumbnailer.Thumbnail(ctx, contentType, data, s.cfg.ThumbnailSize); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.store.Put(ctx, attachment.Key, bytes.NewReader(data), contentType); err != nil {
+		return nil, errors.NewInternalServerError(fmt.Sprintf("Failed to store attachment: %v", err))
+	}
+	if thumbnail != nil {
+		attachment.ThumbnailKey = attachment.Key + ".thumbnail"
+		attachment.ThumbnailType = thumbnail.ContentType
+		attachment.Width, attachment.Height = thumbnail.Width, thumbnail.Height
+		if err := s.store.Put(ctx, attachment.ThumbnailKey, bytes.NewReader(thumbnail.Data), thumbnail.ContentType); err != nil {
+			s.deleteObjects(ctx, attachment)
+			return nil, errors.NewInternalServerError(fmt.Sprintf("Failed to store thumbnail: %v", err))
+		}
+	}
+	if err := s.repo.Create(ctx, attachment); err != nil {
+		s.deleteObjects(ctx, attachment)
+		return nil, err
+	}
+	return attachment, nil
+}
+
+func (s *AttachmentService) allowed(contentType string) bool {
+	for _, t := range s.cfg.AllowedTypes {
+		if t == contentType {
+			return true
+		}
+	}
+	return false
+}
+
+// sniff returns the media type of data by its content, without parameters.
+func sniff(data []byte) string {
+	mediaType, _, err := mime.ParseMediaType(http.DetectContentType(data))
+	if err != nil {
+		return "application/octet-stream"
+	}
+	return mediaType
+}
+
+// cleanFilename keeps the last element of a client path, up to 255 bytes.
+func cleanFilename(name string) string {
+	name = path.Base(strings.ReplaceAll(name, "\\", "/"))
+	name = strings.TrimSpace(strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7f {
+			return -1
+		}
+		return r
+	}, name))
+	for len(name) > 255 {
+		_, size := utf8.DecodeLastRuneInString(name)
+		name = name[:len(name)-size]
+	}
+	if name == "" || name == "." || name == "/" {
+		return "attachment"
+	}
+	return name
+}
+
+func (s *AttachmentService) GetAttachment(ctx context.Context, id uint) (*domain.Attachment, error) {
+	attachment, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, errors.NewNotFoundError(fmt.Sprintf("Attachment with ID %d not found", id))
+	}
+	return attachment, nil
+}
+
+func (s *AttachmentService) ListAttachments(ctx context.Context, blogID uint) ([]*domain.Attachment, error) {
+	if _, err := s.blogs.GetBlog(ctx, blogID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListByBlog(ctx, blogID)
+}
+
+func (s *AttachmentService) OpenAttachment(ctx context.Context, id uint, thumbnail bool) (*domain.Attachment, io.ReadCloser, error) {
+	attachment, err := s.GetAttachment(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	key := attachment.Key
+	if thumbnail {
+		if !attachment.HasThumbnail() {
+			return nil, nil, errors.NewNotFoundError(fmt.Sprintf("Attachment with ID %d has no thumbnail", id))
+		}
+		key = attachment.ThumbnailKey
+	}
+	r, err := s.store.Get(ctx, key)
+	if err != nil {
+		if _, ok := err.(errors.AppError); ok {
+			return nil, nil, err
+		}
+		return nil, nil, errors.NewInternalServerError(fmt.Sprintf("Failed to read attachment: %v", err))
+	}
+	return attachment, r, nil
+}
+
+func (s *AttachmentService) DeleteAttachment(ctx context.Context, id uint) error {
+	attachment, err := s.GetAttachment(ctx, id)
+	if err != nil {
+		return err
+	}
+	blog, err := s.blogs.GetBlog(ports.WithStrongConsistency(ctx), attachment.BlogID)
+	if err == nil && blog.CoverImageID != nil && *blog.CoverImageID == id {
+		if _, err := s.blogs.PatchBlog(ctx, blog.ID, domain.CoverPatch{}); err != nil {
+			return err
+		}
+	}
+	return s.delete(ctx, attachment)
+}
+
+func (s *AttachmentService) SetCover(ctx context.Context, blogID, attachmentID uint) (*domain.Blog, error) {
+	if attachmentID != 0 {
+		attachment, err := s.GetAttachment(ctx, attachmentID)
+		if err != nil {
+			return nil, err
+		}
+		if attachment.BlogID != blogID {
+			return nil, errors.NewInvalidInputError(fmt.Sprintf("Attachment %d does not belong to blog %d", attachmentID, blogID))
+		}
+		if !attachment.IsImage() {
+			return nil, errors.NewInvalidInputError("Cover image must be an image")
+		}
+	}
+	return s.blogs.PatchBlog(ctx, blogID, domain.CoverPatch{AttachmentID: attachmentID})
+}
+
+func (s *AttachmentService) CleanupOrphans(ctx context.Context) (int, error) {
+	var deleted int
+	for {
+		orphans, err := s.repo.Orphans(ctx, orphanBatchSize)
+		if err != nil {
+			return deleted, err
+		}
+		for _, attachment := range orphans {
+			if err := s.delete(ctx, attachment); err != nil {
+				return deleted, err
+			}
+			deleted++
+		}
+		if len(orphans) < orphanBatchSize {
+			return deleted, nil
+		}
+	}
+}
+
+// HandleEvent deletes the attachments of a deleted blog. It is meant to be
+// subscribed to the event bus for BlogDeleted events.
+func (s *AttachmentService) HandleEvent(ctx context.Context, event domain.Event) error {
+	if event.Type != domain.BlogDeleted {
+		return nil
+	}
+	attachments, err := s.repo.ListByBlog(ctx, event.BlogID)
+	if err != nil {
+		return err
+	}
+	for _, attachment := range attachments {
+		if err := s.delete(ctx, attachment); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Run deletes orphaned attachments every CleanupInterval until ctx is
+// cancelled.
+func (s *AttachmentService) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.cfg.CleanupInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		n, err := s.CleanupOrphans(ctx)
+		if err != nil {
+			slog.Error("attachment cleanup failed", "error", err)
+		}
+		if n > 0 {
+			slog.Info("deleted orphaned attachments", "count", n)
+		}
+	}
+}
+
+// delete removes the objects of an attachment before its record, so that a
+// failure leaves the record to be deleted again.
+func (s *AttachmentService) delete(ctx context.Context, attachment *domain.Attachment) error {
+	for _, key := range []string{attachment.Key, attachment.ThumbnailKey} {
+		if key == "" {
+			continue
+		}
+		if err := s.store.Delete(ctx, key); err != nil {
+			return errors.NewInternalServerError(fmt.Sprintf("Failed to delete attachment: %v", err))
+		}
+	}
+	return s.repo.Delete(ctx, attachment.ID)
+}
+
+// deleteObjects removes what was stored of an upload that failed.
+func (s *AttachmentService) deleteObjects(ctx context.Context, attachment *domain.Attachment) {
+	for _, key := range []string{attachment.Key, attachment.ThumbnailKey} {
+		if key == "" {
+			continue
+		}
+		if err := s.store.Delete(ctx, key); err != nil {
+			slog.Warn("failed to delete object of a failed upload", "key", key, "error", err)
+		}
+	}
+}
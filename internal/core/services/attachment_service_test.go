@@ -0,0 +1,190 @@
+package services_test
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/png"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/toffysoft/go-hexagonal-example/internal/adapters/media"
+	"github.com/toffysoft/go-hexagonal-example/internal/adapters/repositories"
+	"github.com/toffysoft/go-hexagonal-example/internal/adapters/storage"
+	"github.com/toffysoft/go-hexagonal-example/internal/core/domain"
+	"github.com/toffysoft/go-hexagonal-example/internal/core/ports"
+	"github.com/toffysoft/go-hexagonal-example/internal/core/services"
+	"github.com/toffysoft/go-hexagonal-example/pkg/errors"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type attachmentFixture struct {
+	blogs       ports.BlogService
+	objects     *storage.MemoryStore
+	attachments *services.AttachmentService
+	blog        *domain.Blog
+}
+
+func newAttachmentFixture(t *testing.T, cfg services.AttachmentConfig) *attachmentFixture {
+	store := repositories.NewMemoryStore()
+	blogs := services.NewBlogService(store.Blogs(), services.WithUnitOfWork(store.UnitOfWork()))
+	objects := storage.NewMemoryStore()
+	blog := &domain.Blog{Title: "Title", Content: "Content", Author: "alice"}
+	require.NoError(t, blogs.CreateBlog(context.Background(), blog))
+	return &attachmentFixture{
+		blogs:       blogs,
+		objects:     objects,
+		attachments: services.NewAttachmentService(repositories.NewMemoryAttachmentRepository(store.Blogs()), objects, blogs, media.NewThumbnailer(), cfg),
+		blog:        blog,
+	}
+}
+
+func pngImage(t *testing.T, w, h int) []byte {
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, image.NewGray(image.Rect(0, 0, w, h))))
+	return buf.Bytes()
+}
+
+func errorType(err error) errors.ErrorType {
+	if appErr, ok := err.(errors.AppError); ok {
+		return appErr.Type
+	}
+	return ""
+}
+
+func TestUploadAttachment(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Image", func(t *testing.T) {
+		f := newAttachmentFixture(t, services.AttachmentConfig{ThumbnailSize: 50})
+
+		attachment, err := f.attachments.Upload(ctx, f.blog.ID, `C:\photos\cat.png`, bytes.NewReader(pngImage(t, 200, 100)))
+
+		require.NoError(t, err)
+		assert.Equal(t, "cat.png", attachment.Filename)
+		assert.Equal(t, "image/png", attachment.ContentType)
+		assert.Equal(t, 200, attachment.Width)
+		assert.Equal(t, 100, attachment.Height)
+		assert.True(t, attachment.HasThumbnail())
+		assert.Len(t, f.objects.Keys(), 2)
+
+		_, r, err := f.attachments.OpenAttachment(ctx, attachment.ID, true)
+		require.NoError(t, err)
+		defer r.Close()
+		thumb, err := png.Decode(r)
+		require.NoError(t, err)
+		assert.Equal(t, image.Pt(50, 25), thumb.Bounds().Size())
+	})
+
+	t.Run("SniffsTheType", func(t *testing.T) {
+		f := newAttachmentFixture(t, services.AttachmentConfig{})
+
+		attachment, err := f.attachments.Upload(ctx, f.blog.ID, "report.pdf", strings.NewReader("%PDF-1.4\n..."))
+
+		require.NoError(t, err)
+		assert.Equal(t, "application/pdf", attachment.ContentType)
+		assert.False(t, attachment.HasThumbnail())
+	})
+
+	t.Run("RejectsDisallowedType", func(t *testing.T) {
+		f := newAttachmentFixture(t, services.AttachmentConfig{})
+
+		_, err := f.attachments.Upload(ctx, f.blog.ID, "image.png", strings.NewReader("<svg><script>alert(1)</script></svg>"))
+
+		assert.Equal(t, errors.Unsupported, errorType(err))
+		assert.Empty(t, f.objects.Keys())
+	})
+
+	t.Run("RejectsTooLarge", func(t *testing.T) {
+		f := newAttachmentFixture(t, services.AttachmentConfig{MaxSize: 10})
+
+		_, err := f.attachments.Upload(ctx, f.blog.ID, "big.pdf", strings.NewReader("%PDF-1.4 and more"))
+
+		assert.Equal(t, errors.TooLarge, errorType(err))
+	})
+
+	t.Run("RejectsCorruptImage", func(t *testing.T) {
+		f := newAttachmentFixture(t, services.AttachmentConfig{})
+
+		_, err := f.attachments.Upload(ctx, f.blog.ID, "broken.png", strings.NewReader("\x89PNG\r\n\x1a\nbroken"))
+
+		assert.Equal(t, errors.InvalidInput, errorType(err))
+		assert.Empty(t, f.objects.Keys())
+	})
+
+	t.Run("UnknownBlog", func(t *testing.T) {
+		f := newAttachmentFixture(t, services.AttachmentConfig{})
+
+		_, err := f.attachments.Upload(ctx, 999, "report.pdf", strings.NewReader("%PDF-1.4"))
+
+		assert.Equal(t, errors.NotFound, errorType(err))
+	})
+}
+
+func TestCoverImage(t *testing.T) {
+	ctx := context.Background()
+	f := newAttachmentFixture(t, services.AttachmentConfig{})
+	cover, err := f.attachments.Upload(ctx, f.blog.ID, "cover.png", bytes.NewReader(pngImage(t, 10, 10)))
+	require.NoError(t, err)
+	pdf, err := f.attachments.Upload(ctx, f.blog.ID, "report.pdf", strings.NewReader("%PDF-1.4"))
+	require.NoError(t, err)
+
+	_, err = f.attachments.SetCover(ctx, f.blog.ID, pdf.ID)
+	assert.Equal(t, errors.InvalidInput, errorType(err))
+
+	blog, err := f.attachments.SetCover(ctx, f.blog.ID, cover.ID)
+	require.NoError(t, err)
+	require.NotNil(t, blog.CoverImageID)
+	assert.Equal(t, cover.ID, *blog.CoverImageID)
+
+	// Deleting the cover image clears it.
+	require.NoError(t, f.attachments.DeleteAttachment(ctx, cover.ID))
+	blog, err = f.blogs.GetBlog(ctx, f.blog.ID)
+	require.NoError(t, err)
+	assert.Nil(t, blog.CoverImageID)
+	_, _, err = f.attachments.OpenAttachment(ctx, cover.ID, false)
+	assert.Equal(t, errors.NotFound, errorType(err))
+}
+
+func TestOrphanedAttachments(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("HandleEvent", func(t *testing.T) {
+		f := newAttachmentFixture(t, services.AttachmentConfig{})
+		_, err := f.attachments.Upload(ctx, f.blog.ID, "cover.png", bytes.NewReader(pngImage(t, 10, 10)))
+		require.NoError(t, err)
+
+		require.NoError(t, f.attachments.HandleEvent(ctx, domain.NewBlogDeletedEvent(f.blog)))
+
+		assert.Empty(t, f.objects.Keys())
+	})
+
+	t.Run("CleanupOrphans", func(t *testing.T) {
+		f := newAttachmentFixture(t, services.AttachmentConfig{})
+		_, err := f.attachments.Upload(ctx, f.blog.ID, "report.pdf", strings.NewReader("%PDF-1.4"))
+		require.NoError(t, err)
+		other := &domain.Blog{Title: "Other", Content: "Content", Author: "bob"}
+		require.NoError(t, f.blogs.CreateBlog(ctx, other))
+		kept, err := f.attachments.Upload(ctx, other.ID, "report.pdf", strings.NewReader("%PDF-1.4"))
+		require.NoError(t, err)
+
+		n, err := f.attachments.CleanupOrphans(ctx)
+		require.NoError(t, err)
+		assert.Zero(t, n)
+
+		require.NoError(t, f.blogs.DeleteBlog(ctx, f.blog.ID))
+		n, err = f.attachments.CleanupOrphans(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 1, n)
+
+		_, r, err := f.attachments.OpenAttachment(ctx, kept.ID, false)
+		require.NoError(t, err)
+		data, _ := io.ReadAll(r)
+		r.Close()
+		assert.Equal(t, "%PDF-1.4", string(data))
+		assert.Len(t, f.objects.Keys(), 1)
+	})
+}
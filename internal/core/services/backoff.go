@@ -0,0 +1,17 @@
+package services
+
+import "time"
+
+// backoff returns the delay before the retry that follows the given number of
+// failed attempts: initial after the first failure, doubling after each
+// further one, capped at max.
+func backoff(initial, max time.Duration, attempts int) time.Duration {
+	delay := initial
+	for i := 1; i < attempts && delay < max; i++ {
+		delay *= 2
+	}
+	if delay > max {
+		delay = max
+	}
+	return delay
+}
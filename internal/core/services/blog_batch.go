@@ -0,0 +1,301 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/toffysoft/go-hexagonal-example/internal/core/domain"
+	"github.com/toffysoft/go-hexagonal-example/internal/core/ports"
+	"github.com/toffysoft/go-hexagonal-example/pkg/errors"
+)
+
+// DefaultMaxBatchSize is the number of items a batch operation accepts
+// unless WithMaxBatchSize says otherwise.
+const DefaultMaxBatchSize = 500
+
+func (s *blogService) BatchCreateBlogs(ctx context.Context, blogs []*domain.Blog, mode domain.BatchMode) ([]domain.BatchResult, error) {
+	if err := s.checkBatch(len(blogs), mode); err != nil {
+		return nil, err
+	}
+
+	results := newBatchResults(len(blogs))
+	var valid []int
+	for i, blog := range blogs {
+		if err := validateBlog(blog); err != nil {
+			results[i].Err = err
+			continue
+		}
+		if err := s.summary.summarize(ctx, blog); err != nil {
+			results[i].Err = err
+			continue
+		}
+		valid = append(valid, i)
+	}
+	if mode == domain.BatchAtomic && len(valid) < len(blogs) {
+		return results, rejectBatch(results)
+	}
+	if len(valid) == 0 {
+		return results, nil
+	}
+
+	batch := make([]*domain.Blog, len(valid))
+	ids := make([]uint, len(valid))
+	for j, i := range valid {
+		batch[j] = blogs[i]
+		ids[j] = blogs[i].ID
+	}
+	if err := s.createBlogs(ctx, batch); err != nil {
+		if mode == domain.BatchAtomic {
+			return nil, err
+		}
+		// One bad row fails the whole bulk insert. Insert the items one by
+		// one so that only the offending ones fail.
+		for j, i := range valid {
+			blogs[i].ID = ids[j]
+			results[i].Err = s.createBlogs(ctx, blogs[i:i+1])
+		}
+	}
+
+	for _, i := range valid {
+		if results[i].Err == nil {
+			results[i].Blog = blogs[i]
+			s.metrics.BlogCreated()
+		}
+	}
+	return results, nil
+}
+
+func (s *blogService) createBlogs(ctx context.Context, blogs []*domain.Blog) error {
+	return s.uow.Do(ctx, func(ctx context.Context) error {
+		if err := s.repo.CreateBatch(ctx, blogs); err != nil {
+			return err
+		}
+		events := make([]domain.Event, len(blogs))
+		for i, blog := range blogs {
+			events[i] = domain.NewBlogCreatedEvent(blog)
+		}
+		return s.outbox.Add(ctx, events...)
+	})
+}
+
+func (s *blogService) BatchUpdateBlogs(ctx context.Context, blogs []*domain.Blog, mode domain.BatchMode) ([]domain.BatchResult, error) {
+	if err := s.checkBatch(len(blogs), mode); err != nil {
+		return nil, err
+	}
+
+	results := newBatchResults(len(blogs))
+	ids := make([]uint, 0, len(blogs))
+	for i, blog := range blogs {
+		if blog.ID == 0 {
+			results[i].Err = errors.NewInvalidInputError("Blog ID is required")
+			continue
+		}
+		if blog.ContentFormat != "" {
+			if err := checkContentFormat(blog); err != nil {
+				results[i].Err = err
+				continue
+			}
+		}
+		if err := normalizeTags(blog); err != nil {
+			results[i].Err = err
+			continue
+		}
+		ids = append(ids, blog.ID)
+	}
+
+	var rejected bool
+	err := s.uow.Do(ports.WithStrongConsistency(ctx), func(ctx context.Context) error {
+		stored, err := s.repo.GetByIDs(ctx, ids)
+		if err != nil {
+			return err
+		}
+		byID := make(map[uint]*domain.Blog, len(stored))
+		for _, blog := range stored {
+			byID[blog.ID] = blog
+		}
+		for i, blog := range blogs {
+			if results[i].Err == nil && byID[blog.ID] == nil {
+				results[i].Err = errors.NewNotFoundError(fmt.Sprintf("Blog with ID %d not found", blog.ID))
+			}
+		}
+		if mode == domain.BatchAtomic && failedItems(results) > 0 {
+			rejected = true
+			return rejectBatch(results)
+		}
+
+		var events []domain.Event
+		for i, patch := range blogs {
+			if results[i].Err != nil {
+				continue
+			}
+			blog := byID[patch.ID]
+			mergeBlog(blog, patch)
+			if err := s.summary.summarize(ctx, blog); err != nil {
+				if mode == domain.BatchAtomic {
+					return err
+				}
+				results[i].Err = err
+				continue
+			}
+
+			if mode == domain.BatchAtomic {
+				if err := s.repo.Update(ctx, blog); err != nil {
+					return err
+				}
+				events = append(events, domain.NewBlogUpdatedEvent(blog))
+			} else {
+				// A savepoint per item keeps one failed update from
+				// aborting the others.
+				err := s.uow.Do(ctx, func(ctx context.Context) error {
+					if err := s.repo.Update(ctx, blog); err != nil {
+						return err
+					}
+					return s.outbox.Add(ctx, domain.NewBlogUpdatedEvent(blog))
+				})
+				if err != nil {
+					results[i].Err = err
+					continue
+				}
+			}
+			results[i].Blog = blog
+		}
+		if len(events) == 0 {
+			return nil
+		}
+		return s.outbox.Add(ctx, events...)
+	})
+	if err != nil {
+		if rejected {
+			return results, err
+		}
+		return nil, err
+	}
+
+	for _, result := range results {
+		if result.Blog != nil {
+			s.metrics.BlogUpdated()
+		}
+	}
+	return results, nil
+}
+
+func (s *blogService) BatchDeleteBlogs(ctx context.Context, ids []uint, mode domain.BatchMode) ([]domain.BatchResult, error) {
+	if err := s.checkBatch(len(ids), mode); err != nil {
+		return nil, err
+	}
+
+	results := newBatchResults(len(ids))
+	var rejected bool
+	var deleted int
+	err := s.uow.Do(ports.WithStrongConsistency(ctx), func(ctx context.Context) error {
+		stored, err := s.repo.GetByIDs(ctx, ids)
+		if err != nil {
+			return err
+		}
+		byID := make(map[uint]*domain.Blog, len(stored))
+		for _, blog := range stored {
+			byID[blog.ID] = blog
+		}
+		for i, id := range ids {
+			if byID[id] == nil {
+				results[i].Err = errors.NewNotFoundError(fmt.Sprintf("Blog with ID %d not found", id))
+			}
+		}
+		if mode == domain.BatchAtomic && failedItems(results) > 0 {
+			rejected = true
+			return rejectBatch(results)
+		}
+		if len(stored) == 0 {
+			return nil
+		}
+
+		for i, id := range ids {
+			results[i].Blog = byID[id]
+		}
+		found := make([]uint, len(stored))
+		events := make([]domain.Event, len(stored))
+		for i, blog := range stored {
+			found[i] = blog.ID
+			events[i] = domain.NewBlogDeletedEvent(blog)
+		}
+		if err := s.repo.DeleteBatch(ctx, found); err != nil {
+			return err
+		}
+		deleted = len(found)
+		return s.outbox.Add(ctx, events...)
+	})
+	if err != nil {
+		if rejected {
+			return results, err
+		}
+		return nil, err
+	}
+
+	for i := 0; i < deleted; i++ {
+		s.metrics.BlogDeleted()
+	}
+	return results, nil
+}
+
+func (s *blogService) checkBatch(size int, mode domain.BatchMode) error {
+	if !mode.Valid() {
+		return errors.NewInvalidInputError(fmt.Sprintf("Unknown batch mode %q", mode))
+	}
+	if size == 0 {
+		return errors.NewInvalidInputError("Batch must contain at least one item")
+	}
+	if size > s.maxBatchSize {
+		return errors.NewInvalidInputError(fmt.Sprintf("Batch must not contain more than %d items", s.maxBatchSize))
+	}
+	return nil
+}
+
+func newBatchResults(size int) []domain.BatchResult {
+	results := make([]domain.BatchResult, size)
+	for i := range results {
+		results[i].Index = i
+	}
+	return results
+}
+
+func failedItems(results []domain.BatchResult) int {
+	failed := 0
+	for _, result := range results {
+		if result.Err != nil {
+			failed++
+		}
+	}
+	return failed
+}
+
+// rejectBatch returns the error of an atomic batch with failing items. It
+// has the type of the first failure, so a batch failing only on missing
+// blogs is reported as not found.
+func rejectBatch(results []domain.BatchResult) error {
+	message := fmt.Sprintf("Batch rejected: %d of %d items failed", failedItems(results), len(results))
+	for _, result := range results {
+		if appErr, ok := result.Err.(errors.AppError); ok {
+			return errors.NewAppError(appErr.Type, message)
+		}
+	}
+	return errors.NewInvalidInputError(message)
+}
+
+// mergeBlog copies the non-empty fields of patch onto blog.
+func mergeBlog(blog, patch *domain.Blog) {
+	if patch.Title != "" {
+		blog.Title = patch.Title
+	}
+	if patch.Content != "" {
+		blog.Content = patch.Content
+	}
+	if patch.Author != "" {
+		blog.Author = patch.Author
+	}
+	if patch.ContentFormat != "" {
+		blog.ContentFormat = patch.ContentFormat
+	}
+	if len(patch.Tags) > 0 {
+		blog.Tags = patch.Tags
+	}
+}
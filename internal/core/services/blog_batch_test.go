@@ -0,0 +1,194 @@
+package services_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/toffysoft/go-hexagonal-example/internal/core/domain"
+	"github.com/toffysoft/go-hexagonal-example/internal/core/services"
+	"github.com/toffysoft/go-hexagonal-example/pkg/errors"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBatchCreateBlogs(t *testing.T) {
+	ctx := context.Background()
+	newBlogs := func() []*domain.Blog {
+		return []*domain.Blog{
+			{Title: "One", Content: "Content", Author: "alice"},
+			{Title: "Two", Content: "", Author: "bob"},
+			{Title: "Three", Content: "Content", Author: "carol"},
+		}
+	}
+
+	t.Run("AtomicRejectsInvalidItems", func(t *testing.T) {
+		mockRepo := new(MockBlogRepository)
+		blogService := services.NewBlogService(mockRepo)
+
+		results, err := blogService.BatchCreateBlogs(ctx, newBlogs(), domain.BatchAtomic)
+
+		require.Error(t, err)
+		assert.Equal(t, errors.InvalidInput, err.(errors.AppError).Type)
+		require.Len(t, results, 3)
+		assert.True(t, results[0].Skipped())
+		assert.Error(t, results[1].Err)
+		mockRepo.AssertNotCalled(t, "CreateBatch", mock.Anything, mock.Anything)
+	})
+
+	t.Run("AtomicInsertsInOneBatch", func(t *testing.T) {
+		mockRepo := new(MockBlogRepository)
+		mockOutbox := new(MockOutbox)
+		blogService := services.NewBlogService(mockRepo, services.WithOutbox(mockOutbox))
+		blogs := newBlogs()[:1]
+
+		mockRepo.On("CreateBatch", ctx, blogs).Return(nil).Once()
+		mockOutbox.On("Add", ctx, eventsOfType(domain.BlogCreated)).Return(nil).Once()
+
+		results, err := blogService.BatchCreateBlogs(ctx, blogs, domain.BatchAtomic)
+
+		require.NoError(t, err)
+		assert.Same(t, blogs[0], results[0].Blog)
+		mockRepo.AssertExpectations(t)
+		mockOutbox.AssertExpectations(t)
+	})
+
+	t.Run("BestEffortRetriesFailedBulkInsert", func(t *testing.T) {
+		mockRepo := new(MockBlogRepository)
+		blogService := services.NewBlogService(mockRepo)
+		blogs := newBlogs()
+		dbErr := errors.NewInternalServerError("duplicate key")
+
+		mockRepo.On("CreateBatch", ctx, []*domain.Blog{blogs[0], blogs[2]}).Return(dbErr).Once()
+		mockRepo.On("CreateBatch", ctx, []*domain.Blog{blogs[0]}).Return(nil).Once()
+		mockRepo.On("CreateBatch", ctx, []*domain.Blog{blogs[2]}).Return(dbErr).Once()
+
+		results, err := blogService.BatchCreateBlogs(ctx, blogs, domain.BatchBestEffort)
+
+		require.NoError(t, err)
+		assert.Same(t, blogs[0], results[0].Blog)
+		assert.Equal(t, errors.InvalidInput, results[1].Err.(errors.AppError).Type)
+		assert.Equal(t, dbErr, results[2].Err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("RejectsOversizedBatch", func(t *testing.T) {
+		blogService := services.NewBlogService(new(MockBlogRepository), services.WithMaxBatchSize(2))
+
+		results, err := blogService.BatchCreateBlogs(ctx, newBlogs(), domain.BatchBestEffort)
+
+		assert.Error(t, err)
+		assert.Nil(t, results)
+	})
+}
+
+func TestBatchUpdateBlogs(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("MergesNonEmptyFields", func(t *testing.T) {
+		mockRepo := new(MockBlogRepository)
+		blogService := services.NewBlogService(mockRepo)
+		stored := &domain.Blog{ID: 1, Title: "Old", Content: "Old content", Author: "alice"}
+
+		mockRepo.On("GetByIDs", primary, []uint{1}).Return([]*domain.Blog{stored}, nil).Once()
+		mockRepo.On("Update", primary, stored).Return(nil).Once()
+
+		results, err := blogService.BatchUpdateBlogs(ctx, []*domain.Blog{{ID: 1, Title: "New"}}, domain.BatchAtomic)
+
+		require.NoError(t, err)
+		assert.Equal(t, "New", results[0].Blog.Title)
+		assert.Equal(t, "Old content", results[0].Blog.Content)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("MergesTags", func(t *testing.T) {
+		mockRepo := new(MockBlogRepository)
+		blogService := services.NewBlogService(mockRepo)
+		stored := &domain.Blog{ID: 1, Title: "Old", Content: "Old content", Author: "alice", Tags: []string{"old"}}
+		untagged := &domain.Blog{ID: 2, Title: "Old", Content: "Old content", Author: "bob", Tags: []string{"kept"}}
+
+		mockRepo.On("GetByIDs", primary, []uint{1, 2}).Return([]*domain.Blog{stored, untagged}, nil).Once()
+		mockRepo.On("Update", primary, mock.Anything).Return(nil).Twice()
+
+		patches := []*domain.Blog{{ID: 1, Tags: []string{"Go", "Web Dev"}}, {ID: 2, Title: "New"}}
+		results, err := blogService.BatchUpdateBlogs(ctx, patches, domain.BatchAtomic)
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"go", "web-dev"}, results[0].Blog.Tags)
+		assert.Equal(t, []string{"kept"}, results[1].Blog.Tags)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("RejectsInvalidTags", func(t *testing.T) {
+		mockRepo := new(MockBlogRepository)
+		blogService := services.NewBlogService(mockRepo)
+		tags := make([]string, domain.MaxTags+1)
+		for i := range tags {
+			tags[i] = fmt.Sprintf("tag-%d", i)
+		}
+
+		mockRepo.On("GetByIDs", primary, []uint{}).Return([]*domain.Blog{}, nil).Once()
+
+		results, err := blogService.BatchUpdateBlogs(ctx, []*domain.Blog{{ID: 1, Tags: tags}}, domain.BatchAtomic)
+
+		require.Error(t, err)
+		assert.Equal(t, errors.InvalidInput, results[0].Err.(errors.AppError).Type)
+		mockRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	})
+
+	t.Run("AtomicReportsMissingBlogs", func(t *testing.T) {
+		mockRepo := new(MockBlogRepository)
+		blogService := services.NewBlogService(mockRepo)
+
+		mockRepo.On("GetByIDs", primary, []uint{1, 2}).Return([]*domain.Blog{{ID: 1}}, nil).Once()
+
+		results, err := blogService.BatchUpdateBlogs(ctx, []*domain.Blog{{ID: 1, Title: "New"}, {ID: 2, Title: "New"}}, domain.BatchAtomic)
+
+		require.Error(t, err)
+		assert.Equal(t, errors.NotFound, err.(errors.AppError).Type)
+		assert.True(t, results[0].Skipped())
+		assert.Error(t, results[1].Err)
+		mockRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	})
+}
+
+func TestBatchDeleteBlogs(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("BestEffortDeletesFoundBlogsAtOnce", func(t *testing.T) {
+		mockRepo := new(MockBlogRepository)
+		mockOutbox := new(MockOutbox)
+		blogService := services.NewBlogService(mockRepo, services.WithOutbox(mockOutbox))
+		found := []*domain.Blog{{ID: 1}, {ID: 3}}
+
+		mockRepo.On("GetByIDs", primary, []uint{1, 2, 3}).Return(found, nil).Once()
+		mockRepo.On("DeleteBatch", primary, []uint{1, 3}).Return(nil).Once()
+		mockOutbox.On("Add", primary, mock.MatchedBy(func(events []domain.Event) bool {
+			return len(events) == 2 && events[0].Type == domain.BlogDeleted
+		})).Return(nil).Once()
+
+		results, err := blogService.BatchDeleteBlogs(ctx, []uint{1, 2, 3}, domain.BatchBestEffort)
+
+		require.NoError(t, err)
+		assert.Equal(t, uint(1), results[0].Blog.ID)
+		assert.Equal(t, errors.NotFound, results[1].Err.(errors.AppError).Type)
+		assert.Equal(t, uint(3), results[2].Blog.ID)
+		mockRepo.AssertExpectations(t)
+		mockOutbox.AssertExpectations(t)
+	})
+
+	t.Run("AtomicDeletesNothingWhenOneIsMissing", func(t *testing.T) {
+		mockRepo := new(MockBlogRepository)
+		blogService := services.NewBlogService(mockRepo)
+
+		mockRepo.On("GetByIDs", primary, []uint{1, 2}).Return([]*domain.Blog{{ID: 1}}, nil).Once()
+
+		results, err := blogService.BatchDeleteBlogs(ctx, []uint{1, 2}, domain.BatchAtomic)
+
+		require.Error(t, err)
+		assert.True(t, results[0].Skipped())
+		mockRepo.AssertNotCalled(t, "DeleteBatch", mock.Anything, mock.Anything)
+	})
+}
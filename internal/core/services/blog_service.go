@@ -1,6 +1,7 @@
 package services
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/toffysoft/go-hexagonal-example/internal/core/domain"
@@ -9,47 +10,282 @@ import (
 )
 
 type blogService struct {
-	repo ports.BlogRepository
+	repo     ports.BlogRepository
+	uow      ports.UnitOfWork
+	outbox   ports.OutboxRepository
+	metrics  ports.BlogMetrics
+	renderer ports.ContentRenderer
+	summary  SummaryConfig
+
+	maxBatchSize int
+}
+
+func NewBlogService(repo ports.BlogRepository, opts ...Option) ports.BlogService {
+	s := &blogService{
+		repo:     repo,
+		uow:      nopUnitOfWork{},
+		outbox:   nopOutbox{},
+		metrics:  nopBlogMetrics{},
+		renderer: nopRenderer{},
+		summary:  SummaryConfig{}.withDefaults(),
+
+		maxBatchSize: DefaultMaxBatchSize,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
-func NewBlogService(repo ports.BlogRepository) ports.BlogService {
-	return &blogService{repo: repo}
+func (s *blogService) CreateBlog(ctx context.Context, blog *domain.Blog) error {
+	if err := validateBlog(blog); err != nil {
+		return err
+	}
+	if err := s.summary.summarize(ctx, blog); err != nil {
+		return err
+	}
+	err := s.uow.Do(ctx, func(ctx context.Context) error {
+		if err := s.repo.Create(ctx, blog); err != nil {
+			return err
+		}
+		return s.outbox.Add(ctx, domain.NewBlogCreatedEvent(blog))
+	})
+	if err != nil {
+		return err
+	}
+	s.metrics.BlogCreated()
+	return nil
 }
 
-func (s *blogService) CreateBlog(blog *domain.Blog) error {
+func validateBlog(blog *domain.Blog) error {
 	if blog.Title == "" || blog.Content == "" || blog.Author == "" {
 		return errors.NewInvalidInputError("All fields are required")
 	}
-	return s.repo.Create(blog)
+	if err := normalizeTags(blog); err != nil {
+		return err
+	}
+	return checkContentFormat(blog)
+}
+
+func normalizeTags(blog *domain.Blog) error {
+	tags, err := domain.NormalizeTags(blog.Tags)
+	if err != nil {
+		return errors.NewInvalidInputError(err.Error())
+	}
+	blog.Tags = tags
+	return nil
+}
+
+// checkContentFormat gives a blog without a content format the default one,
+// and rejects unknown formats.
+func checkContentFormat(blog *domain.Blog) error {
+	format, err := domain.ParseContentFormat(string(blog.ContentFormat))
+	if err != nil {
+		return errors.NewInvalidInputError(err.Error())
+	}
+	blog.ContentFormat = format
+	return nil
+}
+
+func (s *blogService) GetBlog(ctx context.Context, id uint) (*domain.Blog, error) {
+	blog, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, errors.NewNotFoundError(fmt.Sprintf("Blog with ID %d not found", id))
+	}
+	return blog, nil
 }
 
-func (s *blogService) GetBlog(id uint) (*domain.Blog, error) {
-	blog, err := s.repo.GetByID(id)
+func (s *blogService) GetBlogFields(ctx context.Context, id uint, mask domain.FieldMask) (*domain.Blog, error) {
+	blog, err := s.repo.GetFields(ctx, id, mask)
 	if err != nil {
 		return nil, errors.NewNotFoundError(fmt.Sprintf("Blog with ID %d not found", id))
 	}
+	if err := s.renderFields(ctx, mask, blog); err != nil {
+		return nil, err
+	}
 	return blog, nil
 }
 
-func (s *blogService) UpdateBlog(blog *domain.Blog) error {
+func (s *blogService) UpdateBlog(ctx context.Context, blog *domain.Blog) error {
 	if blog.ID == 0 {
 		return errors.NewInvalidInputError("Blog ID is required")
 	}
-	_, err := s.GetBlog(blog.ID)
+	if blog.ContentFormat != "" {
+		if err := checkContentFormat(blog); err != nil {
+			return err
+		}
+	}
+	if err := normalizeTags(blog); err != nil {
+		return err
+	}
+	err := s.uow.Do(ports.WithStrongConsistency(ctx), func(ctx context.Context) error {
+		stored, err := s.GetBlog(ctx, blog.ID)
+		if err != nil {
+			return err
+		}
+		// An update that does not name a format keeps the stored one.
+		if blog.ContentFormat == "" {
+			blog.ContentFormat = stored.ContentFormat
+		}
+		if err := s.summary.summarize(ctx, blog); err != nil {
+			return err
+		}
+		if err := s.repo.Update(ctx, blog); err != nil {
+			return err
+		}
+		return s.outbox.Add(ctx, domain.NewBlogUpdatedEvent(blog))
+	})
+	if err != nil {
+		return err
+	}
+	s.metrics.BlogUpdated()
+	return nil
+}
+
+func (s *blogService) PatchBlog(ctx context.Context, id uint, patch domain.BlogPatch) (*domain.Blog, error) {
+	var blog *domain.Blog
+	err := s.uow.Do(ports.WithStrongConsistency(ctx), func(ctx context.Context) error {
+		var err error
+		if blog, err = s.GetBlog(ctx, id); err != nil {
+			return err
+		}
+		if err := patch.Apply(blog); err != nil {
+			return err
+		}
+		// The patch is well-formed, but leaves a blog that cannot be stored.
+		if blog.Title == "" || blog.Content == "" || blog.Author == "" {
+			return errors.NewUnprocessableError("Title, content and author cannot be cleared")
+		}
+		if err := validateBlog(blog); err != nil {
+			return err
+		}
+		if err := s.summary.summarize(ctx, blog); err != nil {
+			return err
+		}
+		if err := s.repo.Update(ctx, blog); err != nil {
+			return err
+		}
+		return s.outbox.Add(ctx, domain.NewBlogUpdatedEvent(blog))
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.metrics.BlogUpdated()
+	return blog, nil
+}
+
+func (s *blogService) DeleteBlog(ctx context.Context, id uint) error {
+	err := s.uow.Do(ports.WithStrongConsistency(ctx), func(ctx context.Context) error {
+		blog, err := s.GetBlog(ctx, id)
+		if err != nil {
+			return err
+		}
+		if err := s.repo.Delete(ctx, id); err != nil {
+			return err
+		}
+		return s.outbox.Add(ctx, domain.NewBlogDeletedEvent(blog))
+	})
 	if err != nil {
 		return err
 	}
-	return s.repo.Update(blog)
+	s.metrics.BlogDeleted()
+	return nil
+}
+
+func (s *blogService) ListBlogs(ctx context.Context) ([]*domain.Blog, error) {
+	return s.repo.List(ctx)
+}
+
+func (s *blogService) ListBlogFields(ctx context.Context, mask domain.FieldMask) ([]*domain.Blog, error) {
+	blogs, err := s.repo.ListFields(ctx, mask)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.renderFields(ctx, mask, blogs...); err != nil {
+		return nil, err
+	}
+	return blogs, nil
 }
 
-func (s *blogService) DeleteBlog(id uint) error {
-	_, err := s.GetBlog(id)
+func (s *blogService) ListLatestBlogs(ctx context.Context, filter domain.BlogFilter, mask domain.FieldMask, limit int) ([]*domain.Blog, error) {
+	if limit <= 0 {
+		return nil, errors.NewInvalidInputError("Limit must be greater than zero")
+	}
+	blogs, err := s.repo.ListLatest(ctx, filter, mask, limit)
 	if err != nil {
+		return nil, err
+	}
+	if err := s.renderFields(ctx, mask, blogs...); err != nil {
+		return nil, err
+	}
+	return blogs, nil
+}
+
+// renderFields renders blogs read with mask when it asks for rendered_html,
+// then clears the content and format read only to render them.
+func (s *blogService) renderFields(ctx context.Context, mask domain.FieldMask, blogs ...*domain.Blog) error {
+	if !mask.Has(domain.FieldRenderedHTML) {
+		return nil
+	}
+	if err := s.RenderBlogs(ctx, blogs...); err != nil {
 		return err
 	}
-	return s.repo.Delete(id)
+	for _, blog := range blogs {
+		if !mask.Has(domain.FieldContent) {
+			blog.Content = ""
+		}
+		if !mask.Has(domain.FieldContentFormat) {
+			blog.ContentFormat = ""
+		}
+	}
+	return nil
+}
+
+func (s *blogService) RenderBlogs(ctx context.Context, blogs ...*domain.Blog) error {
+	for _, blog := range blogs {
+		format := blog.ContentFormat
+		if format == "" {
+			format = domain.DefaultContentFormat
+		}
+		rendered, err := s.renderer.Render(ctx, format, blog.Content)
+		if err != nil {
+			if _, ok := err.(errors.AppError); ok {
+				return err
+			}
+			return errors.NewInternalServerError(fmt.Sprintf("Failed to render blog %d: %v", blog.ID, err))
+		}
+		blog.RenderedHTML = rendered
+	}
+	return nil
 }
 
-func (s *blogService) ListBlogs() ([]*domain.Blog, error) {
-	return s.repo.List()
+func (s *blogService) StreamBlogs(ctx context.Context, afterID uint, chunkSize int, fn func(blogs []*domain.Blog) error) error {
+	if chunkSize <= 0 {
+		return errors.NewInvalidInputError("Chunk size must be greater than zero")
+	}
+	return eachChunk(ctx, s.repo, domain.BlogFilter{}, afterID, chunkSize, fn)
+}
+
+// eachChunk pages through the blogs matching filter by ID and calls fn with
+// every page.
+func eachChunk(ctx context.Context, repo ports.BlogRepository, filter domain.BlogFilter, afterID uint, chunkSize int, fn func(blogs []*domain.Blog) error) error {
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		blogs, err := repo.ListAfter(ctx, filter, afterID, chunkSize)
+		if err != nil {
+			return err
+		}
+		if len(blogs) == 0 {
+			return nil
+		}
+		if err := fn(blogs); err != nil {
+			return err
+		}
+		if len(blogs) < chunkSize {
+			return nil
+		}
+		afterID = blogs[len(blogs)-1].ID
+	}
 }
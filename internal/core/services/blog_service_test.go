@@ -1,14 +1,18 @@
 package services_test
 
 import (
+	"context"
+	"fmt"
 	"testing"
 
 	"github.com/toffysoft/go-hexagonal-example/internal/core/domain"
+	"github.com/toffysoft/go-hexagonal-example/internal/core/ports"
 	"github.com/toffysoft/go-hexagonal-example/internal/core/services"
 	"github.com/toffysoft/go-hexagonal-example/pkg/errors"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 // MockBlogRepository is a mock type for the BlogRepository
@@ -16,40 +20,93 @@ type MockBlogRepository struct {
 	mock.Mock
 }
 
-func (m *MockBlogRepository) Create(blog *domain.Blog) error {
-	args := m.Called(blog)
+func (m *MockBlogRepository) Create(ctx context.Context, blog *domain.Blog) error {
+	args := m.Called(ctx, blog)
 	return args.Error(0)
 }
 
-func (m *MockBlogRepository) GetByID(id uint) (*domain.Blog, error) {
-	args := m.Called(id)
+func (m *MockBlogRepository) GetByID(ctx context.Context, id uint) (*domain.Blog, error) {
+	args := m.Called(ctx, id)
 	return args.Get(0).(*domain.Blog), args.Error(1)
 }
 
-func (m *MockBlogRepository) Update(blog *domain.Blog) error {
-	args := m.Called(blog)
+func (m *MockBlogRepository) GetFields(ctx context.Context, id uint, mask domain.FieldMask) (*domain.Blog, error) {
+	args := m.Called(ctx, id, mask)
+	return args.Get(0).(*domain.Blog), args.Error(1)
+}
+
+func (m *MockBlogRepository) ListFields(ctx context.Context, mask domain.FieldMask) ([]*domain.Blog, error) {
+	args := m.Called(ctx, mask)
+	return args.Get(0).([]*domain.Blog), args.Error(1)
+}
+
+func (m *MockBlogRepository) ListLatest(ctx context.Context, filter domain.BlogFilter, mask domain.FieldMask, limit int) ([]*domain.Blog, error) {
+	args := m.Called(ctx, filter, mask, limit)
+	return args.Get(0).([]*domain.Blog), args.Error(1)
+}
+
+func (m *MockBlogRepository) Update(ctx context.Context, blog *domain.Blog) error {
+	args := m.Called(ctx, blog)
+	return args.Error(0)
+}
+
+func (m *MockBlogRepository) Delete(ctx context.Context, id uint) error {
+	args := m.Called(ctx, id)
 	return args.Error(0)
 }
 
-func (m *MockBlogRepository) Delete(id uint) error {
-	args := m.Called(id)
+func (m *MockBlogRepository) List(ctx context.Context) ([]*domain.Blog, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]*domain.Blog), args.Error(1)
+}
+
+func (m *MockBlogRepository) ListAfter(ctx context.Context, filter domain.BlogFilter, afterID uint, limit int) ([]*domain.Blog, error) {
+	args := m.Called(ctx, filter, afterID, limit)
+	return args.Get(0).([]*domain.Blog), args.Error(1)
+}
+
+func (m *MockBlogRepository) CreateBatch(ctx context.Context, blogs []*domain.Blog) error {
+	args := m.Called(ctx, blogs)
 	return args.Error(0)
 }
 
-func (m *MockBlogRepository) List() ([]*domain.Blog, error) {
-	args := m.Called()
+func (m *MockBlogRepository) GetByIDs(ctx context.Context, ids []uint) ([]*domain.Blog, error) {
+	args := m.Called(ctx, ids)
 	return args.Get(0).([]*domain.Blog), args.Error(1)
 }
 
+func (m *MockBlogRepository) DeleteBatch(ctx context.Context, ids []uint) error {
+	args := m.Called(ctx, ids)
+	return args.Error(0)
+}
+
+// MockBlogMetrics is a mock type for the BlogMetrics port
+type MockBlogMetrics struct {
+	mock.Mock
+}
+
+func (m *MockBlogMetrics) BlogCreated() {
+	m.Called()
+}
+
+func (m *MockBlogMetrics) BlogUpdated() {
+	m.Called()
+}
+
+func (m *MockBlogMetrics) BlogDeleted() {
+	m.Called()
+}
+
 func TestCreateBlog(t *testing.T) {
+	ctx := context.Background()
 	mockRepo := new(MockBlogRepository)
 	blogService := services.NewBlogService(mockRepo)
 
 	t.Run("Success", func(t *testing.T) {
 		blog := &domain.Blog{Title: "Test Blog", Content: "Test Content", Author: "Test Author"}
-		mockRepo.On("Create", blog).Return(nil).Once()
+		mockRepo.On("Create", ctx, blog).Return(nil).Once()
 
-		err := blogService.CreateBlog(blog)
+		err := blogService.CreateBlog(ctx, blog)
 
 		assert.NoError(t, err)
 		mockRepo.AssertExpectations(t)
@@ -58,18 +115,40 @@ func TestCreateBlog(t *testing.T) {
 	t.Run("EmptyTitle", func(t *testing.T) {
 		blog := &domain.Blog{Content: "Test Content", Author: "Test Author"}
 
-		err := blogService.CreateBlog(blog)
+		err := blogService.CreateBlog(ctx, blog)
 
 		assert.Error(t, err)
 		assert.IsType(t, errors.AppError{}, err)
 		assert.Equal(t, errors.InvalidInput, err.(errors.AppError).Type)
 	})
 
+	t.Run("NormalizesTags", func(t *testing.T) {
+		blog := &domain.Blog{Title: "Test Blog", Content: "Test Content", Author: "Test Author", Tags: []string{"Go", " go ", "Web Dev", ""}}
+		mockRepo.On("Create", ctx, blog).Return(nil).Once()
+
+		err := blogService.CreateBlog(ctx, blog)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"go", "web-dev"}, blog.Tags)
+	})
+
+	t.Run("TooManyTags", func(t *testing.T) {
+		blog := &domain.Blog{Title: "Test Blog", Content: "Test Content", Author: "Test Author"}
+		for i := 0; i <= domain.MaxTags; i++ {
+			blog.Tags = append(blog.Tags, fmt.Sprintf("tag-%d", i))
+		}
+
+		err := blogService.CreateBlog(ctx, blog)
+
+		assert.Error(t, err)
+		assert.Equal(t, errors.InvalidInput, err.(errors.AppError).Type)
+	})
+
 	t.Run("RepositoryError", func(t *testing.T) {
 		blog := &domain.Blog{Title: "Test Blog", Content: "Test Content", Author: "Test Author"}
-		mockRepo.On("Create", blog).Return(errors.NewInternalServerError("Database error")).Once()
+		mockRepo.On("Create", ctx, blog).Return(errors.NewInternalServerError("Database error")).Once()
 
-		err := blogService.CreateBlog(blog)
+		err := blogService.CreateBlog(ctx, blog)
 
 		assert.Error(t, err)
 		assert.IsType(t, errors.AppError{}, err)
@@ -79,14 +158,15 @@ func TestCreateBlog(t *testing.T) {
 }
 
 func TestGetBlog(t *testing.T) {
+	ctx := context.Background()
 	mockRepo := new(MockBlogRepository)
 	blogService := services.NewBlogService(mockRepo)
 
 	t.Run("Success", func(t *testing.T) {
 		blog := &domain.Blog{ID: 1, Title: "Test Blog", Content: "Test Content", Author: "Test Author"}
-		mockRepo.On("GetByID", uint(1)).Return(blog, nil).Once()
+		mockRepo.On("GetByID", ctx, uint(1)).Return(blog, nil).Once()
 
-		result, err := blogService.GetBlog(1)
+		result, err := blogService.GetBlog(ctx, 1)
 
 		assert.NoError(t, err)
 		assert.Equal(t, blog, result)
@@ -94,9 +174,9 @@ func TestGetBlog(t *testing.T) {
 	})
 
 	t.Run("NotFound", func(t *testing.T) {
-		mockRepo.On("GetByID", uint(999)).Return((*domain.Blog)(nil), errors.NewNotFoundError("Blog not found")).Once()
+		mockRepo.On("GetByID", ctx, uint(999)).Return((*domain.Blog)(nil), errors.NewNotFoundError("Blog not found")).Once()
 
-		result, err := blogService.GetBlog(999)
+		result, err := blogService.GetBlog(ctx, 999)
 
 		assert.Error(t, err)
 		assert.Nil(t, result)
@@ -106,16 +186,20 @@ func TestGetBlog(t *testing.T) {
 	})
 }
 
+// primary matches contexts that route reads to the primary database.
+var primary = mock.MatchedBy(ports.IsStrongConsistency)
+
 func TestUpdateBlog(t *testing.T) {
+	ctx := context.Background()
 	mockRepo := new(MockBlogRepository)
 	blogService := services.NewBlogService(mockRepo)
 
 	t.Run("Success", func(t *testing.T) {
 		blog := &domain.Blog{ID: 1, Title: "Updated Blog", Content: "Updated Content", Author: "Updated Author"}
-		mockRepo.On("GetByID", uint(1)).Return(blog, nil).Once()
-		mockRepo.On("Update", blog).Return(nil).Once()
+		mockRepo.On("GetByID", primary, uint(1)).Return(blog, nil).Once()
+		mockRepo.On("Update", primary, blog).Return(nil).Once()
 
-		err := blogService.UpdateBlog(blog)
+		err := blogService.UpdateBlog(ctx, blog)
 
 		assert.NoError(t, err)
 		mockRepo.AssertExpectations(t)
@@ -123,9 +207,9 @@ func TestUpdateBlog(t *testing.T) {
 
 	t.Run("NotFound", func(t *testing.T) {
 		blog := &domain.Blog{ID: 999, Title: "Non-existent Blog"}
-		mockRepo.On("GetByID", uint(999)).Return((*domain.Blog)(nil), errors.NewNotFoundError("Blog not found")).Once()
+		mockRepo.On("GetByID", primary, uint(999)).Return((*domain.Blog)(nil), errors.NewNotFoundError("Blog not found")).Once()
 
-		err := blogService.UpdateBlog(blog)
+		err := blogService.UpdateBlog(ctx, blog)
 
 		assert.Error(t, err)
 		assert.IsType(t, errors.AppError{}, err)
@@ -136,7 +220,7 @@ func TestUpdateBlog(t *testing.T) {
 	t.Run("InvalidInput", func(t *testing.T) {
 		blog := &domain.Blog{ID: 0, Title: "Invalid Blog"}
 
-		err := blogService.UpdateBlog(blog)
+		err := blogService.UpdateBlog(ctx, blog)
 
 		assert.Error(t, err)
 		assert.IsType(t, errors.AppError{}, err)
@@ -144,24 +228,69 @@ func TestUpdateBlog(t *testing.T) {
 	})
 }
 
+func TestPatchBlog(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockBlogRepository)
+	blogService := services.NewBlogService(mockRepo)
+
+	t.Run("Success", func(t *testing.T) {
+		blog := &domain.Blog{ID: 1, Title: "Title", Content: "Content", ContentFormat: domain.ContentPlain, Author: "alice"}
+		cleared := ""
+		mockRepo.On("GetByID", primary, uint(1)).Return(blog, nil).Once()
+		mockRepo.On("Update", primary, blog).Return(nil).Once()
+
+		result, err := blogService.PatchBlog(ctx, 1, domain.FieldPatch{ContentFormat: &cleared})
+
+		assert.NoError(t, err)
+		assert.Equal(t, domain.DefaultContentFormat, result.ContentFormat)
+		assert.Equal(t, "Title", result.Title)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("ClearingRequiredFieldIsUnprocessable", func(t *testing.T) {
+		blog := &domain.Blog{ID: 3, Title: "Title", Content: "Content", Author: "alice"}
+		cleared := ""
+		mockRepo.On("GetByID", primary, uint(3)).Return(blog, nil).Once()
+
+		_, err := blogService.PatchBlog(ctx, 3, domain.FieldPatch{Title: &cleared})
+
+		require.Error(t, err)
+		assert.Equal(t, errors.Unprocessable, err.(errors.AppError).Type)
+		mockRepo.AssertNotCalled(t, "Update", mock.Anything, blog)
+	})
+
+	t.Run("FailedPatchIsNotSaved", func(t *testing.T) {
+		blog := &domain.Blog{ID: 2, Author: "alice"}
+		mockRepo.On("GetByID", primary, uint(2)).Return(blog, nil).Once()
+
+		_, err := blogService.PatchBlog(ctx, 2, domain.JSONPatch{
+			{Op: "test", Path: "/author", Value: []byte(`"bob"`)},
+		})
+
+		assert.Equal(t, errors.Conflict, err.(errors.AppError).Type)
+		mockRepo.AssertNotCalled(t, "Update", mock.Anything, blog)
+	})
+}
+
 func TestDeleteBlog(t *testing.T) {
+	ctx := context.Background()
 	mockRepo := new(MockBlogRepository)
 	blogService := services.NewBlogService(mockRepo)
 
 	t.Run("Success", func(t *testing.T) {
-		mockRepo.On("GetByID", uint(1)).Return(&domain.Blog{ID: 1}, nil).Once()
-		mockRepo.On("Delete", uint(1)).Return(nil).Once()
+		mockRepo.On("GetByID", primary, uint(1)).Return(&domain.Blog{ID: 1}, nil).Once()
+		mockRepo.On("Delete", primary, uint(1)).Return(nil).Once()
 
-		err := blogService.DeleteBlog(1)
+		err := blogService.DeleteBlog(ctx, 1)
 
 		assert.NoError(t, err)
 		mockRepo.AssertExpectations(t)
 	})
 
 	t.Run("NotFound", func(t *testing.T) {
-		mockRepo.On("GetByID", uint(999)).Return((*domain.Blog)(nil), errors.NewNotFoundError("Blog not found")).Once()
+		mockRepo.On("GetByID", primary, uint(999)).Return((*domain.Blog)(nil), errors.NewNotFoundError("Blog not found")).Once()
 
-		err := blogService.DeleteBlog(999)
+		err := blogService.DeleteBlog(ctx, 999)
 
 		assert.Error(t, err)
 		assert.IsType(t, errors.AppError{}, err)
@@ -171,6 +300,7 @@ func TestDeleteBlog(t *testing.T) {
 }
 
 func TestListBlogs(t *testing.T) {
+	ctx := context.Background()
 	mockRepo := new(MockBlogRepository)
 	blogService := services.NewBlogService(mockRepo)
 
@@ -179,9 +309,9 @@ func TestListBlogs(t *testing.T) {
 			{ID: 1, Title: "Blog 1"},
 			{ID: 2, Title: "Blog 2"},
 		}
-		mockRepo.On("List").Return(blogs, nil).Once()
+		mockRepo.On("List", ctx).Return(blogs, nil).Once()
 
-		result, err := blogService.ListBlogs()
+		result, err := blogService.ListBlogs(ctx)
 
 		assert.NoError(t, err)
 		assert.Equal(t, blogs, result)
@@ -189,9 +319,9 @@ func TestListBlogs(t *testing.T) {
 	})
 
 	t.Run("EmptyList", func(t *testing.T) {
-		mockRepo.On("List").Return([]*domain.Blog{}, nil).Once()
+		mockRepo.On("List", ctx).Return([]*domain.Blog{}, nil).Once()
 
-		result, err := blogService.ListBlogs()
+		result, err := blogService.ListBlogs(ctx)
 
 		assert.NoError(t, err)
 		assert.Empty(t, result)
@@ -199,9 +329,9 @@ func TestListBlogs(t *testing.T) {
 	})
 
 	t.Run("RepositoryError", func(t *testing.T) {
-		mockRepo.On("List").Return(([]*domain.Blog)(nil), errors.NewInternalServerError("Database error")).Once()
+		mockRepo.On("List", ctx).Return(([]*domain.Blog)(nil), errors.NewInternalServerError("Database error")).Once()
 
-		result, err := blogService.ListBlogs()
+		result, err := blogService.ListBlogs(ctx)
 
 		assert.Error(t, err)
 		assert.Nil(t, result)
@@ -210,3 +340,279 @@ func TestListBlogs(t *testing.T) {
 		mockRepo.AssertExpectations(t)
 	})
 }
+
+func TestStreamBlogs(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("ChunksByCursor", func(t *testing.T) {
+		mockRepo := new(MockBlogRepository)
+		blogService := services.NewBlogService(mockRepo)
+
+		mockRepo.On("ListAfter", ctx, domain.BlogFilter{}, uint(0), 2).Return([]*domain.Blog{{ID: 1}, {ID: 2}}, nil).Once()
+		mockRepo.On("ListAfter", ctx, domain.BlogFilter{}, uint(2), 2).Return([]*domain.Blog{{ID: 5}}, nil).Once()
+
+		var chunks [][]*domain.Blog
+		err := blogService.StreamBlogs(ctx, 0, 2, func(blogs []*domain.Blog) error {
+			chunks = append(chunks, blogs)
+			return nil
+		})
+
+		assert.NoError(t, err)
+		assert.Len(t, chunks, 2)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("StopsWhenCancelled", func(t *testing.T) {
+		mockRepo := new(MockBlogRepository)
+		blogService := services.NewBlogService(mockRepo)
+		cancelled, cancel := context.WithCancel(ctx)
+
+		mockRepo.On("ListAfter", cancelled, domain.BlogFilter{}, uint(0), 1).Return([]*domain.Blog{{ID: 1}}, nil).Once()
+
+		err := blogService.StreamBlogs(cancelled, 0, 1, func(blogs []*domain.Blog) error {
+			cancel()
+			return nil
+		})
+
+		assert.ErrorIs(t, err, context.Canceled)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+type MockUnitOfWork struct {
+	mock.Mock
+}
+
+func (m *MockUnitOfWork) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	args := m.Called(ctx)
+	if err := args.Error(0); err != nil {
+		return err
+	}
+	return fn(ctx)
+}
+
+func TestUnitOfWork(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("UpdateRunsInUnitOfWork", func(t *testing.T) {
+		mockRepo := new(MockBlogRepository)
+		mockUOW := new(MockUnitOfWork)
+		blogService := services.NewBlogService(mockRepo, services.WithUnitOfWork(mockUOW))
+
+		blog := &domain.Blog{ID: 1, Title: "Updated Blog", Content: "Updated Content", Author: "Updated Author"}
+		mockUOW.On("Do", primary).Return(nil).Once()
+		mockRepo.On("GetByID", primary, uint(1)).Return(blog, nil).Once()
+		mockRepo.On("Update", primary, blog).Return(nil).Once()
+
+		assert.NoError(t, blogService.UpdateBlog(ctx, blog))
+
+		mockUOW.AssertExpectations(t)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("DeleteFailsWhenTransactionFails", func(t *testing.T) {
+		mockRepo := new(MockBlogRepository)
+		mockUOW := new(MockUnitOfWork)
+		mockMetrics := new(MockBlogMetrics)
+		blogService := services.NewBlogService(mockRepo, services.WithUnitOfWork(mockUOW), services.WithMetrics(mockMetrics))
+
+		mockUOW.On("Do", primary).Return(errors.NewInternalServerError("Database error")).Once()
+
+		assert.Error(t, blogService.DeleteBlog(ctx, 1))
+
+		mockUOW.AssertExpectations(t)
+		mockRepo.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+		mockMetrics.AssertNotCalled(t, "BlogDeleted")
+	})
+}
+
+func TestBlogMetrics(t *testing.T) {
+	ctx := context.Background()
+	t.Run("RecordsSuccessfulChanges", func(t *testing.T) {
+		mockRepo := new(MockBlogRepository)
+		mockMetrics := new(MockBlogMetrics)
+		blogService := services.NewBlogService(mockRepo, services.WithMetrics(mockMetrics))
+
+		blog := &domain.Blog{ID: 1, Title: "Test Blog", Content: "Test Content", Author: "Test Author"}
+		mockRepo.On("Create", ctx, blog).Return(nil).Once()
+		mockRepo.On("GetByID", primary, uint(1)).Return(blog, nil).Twice()
+		mockRepo.On("Update", primary, blog).Return(nil).Once()
+		mockRepo.On("Delete", primary, uint(1)).Return(nil).Once()
+		mockMetrics.On("BlogCreated").Once()
+		mockMetrics.On("BlogUpdated").Once()
+		mockMetrics.On("BlogDeleted").Once()
+
+		assert.NoError(t, blogService.CreateBlog(ctx, blog))
+		assert.NoError(t, blogService.UpdateBlog(ctx, blog))
+		assert.NoError(t, blogService.DeleteBlog(ctx, 1))
+
+		mockRepo.AssertExpectations(t)
+		mockMetrics.AssertExpectations(t)
+	})
+
+	t.Run("SkipsFailedChanges", func(t *testing.T) {
+		mockRepo := new(MockBlogRepository)
+		mockMetrics := new(MockBlogMetrics)
+		blogService := services.NewBlogService(mockRepo, services.WithMetrics(mockMetrics))
+
+		blog := &domain.Blog{Title: "Test Blog", Content: "Test Content", Author: "Test Author"}
+		mockRepo.On("Create", ctx, blog).Return(errors.NewInternalServerError("Database error")).Once()
+
+		assert.Error(t, blogService.CreateBlog(ctx, blog))
+
+		mockRepo.AssertExpectations(t)
+		mockMetrics.AssertNotCalled(t, "BlogCreated")
+	})
+}
+
+func TestContentFormat(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("DefaultsOnCreate", func(t *testing.T) {
+		mockRepo := new(MockBlogRepository)
+		blogService := services.NewBlogService(mockRepo)
+		blog := &domain.Blog{Title: "Test Blog", Content: "Test Content", Author: "Test Author"}
+		mockRepo.On("Create", ctx, blog).Return(nil).Once()
+
+		assert.NoError(t, blogService.CreateBlog(ctx, blog))
+		assert.Equal(t, domain.ContentMarkdown, blog.ContentFormat)
+	})
+
+	t.Run("RejectsUnknown", func(t *testing.T) {
+		blogService := services.NewBlogService(new(MockBlogRepository))
+		blog := &domain.Blog{Title: "Test Blog", Content: "Test Content", Author: "Test Author", ContentFormat: "rst"}
+
+		err := blogService.CreateBlog(ctx, blog)
+
+		assert.Equal(t, errors.InvalidInput, err.(errors.AppError).Type)
+	})
+
+	t.Run("UpdateKeepsStoredFormat", func(t *testing.T) {
+		mockRepo := new(MockBlogRepository)
+		blogService := services.NewBlogService(mockRepo)
+		stored := &domain.Blog{ID: 1, Title: "Test Blog", Content: "Test Content", ContentFormat: domain.ContentHTML}
+		blog := &domain.Blog{ID: 1, Title: "Updated Blog", Content: "Updated Content"}
+		mockRepo.On("GetByID", primary, uint(1)).Return(stored, nil).Once()
+		mockRepo.On("Update", primary, blog).Return(nil).Once()
+
+		assert.NoError(t, blogService.UpdateBlog(ctx, blog))
+		assert.Equal(t, domain.ContentHTML, blog.ContentFormat)
+	})
+
+	t.Run("PatchRejectsUnknown", func(t *testing.T) {
+		mockRepo := new(MockBlogRepository)
+		blogService := services.NewBlogService(mockRepo)
+		blog := &domain.Blog{ID: 1, Title: "Test Blog", Content: "Test Content", Author: "alice", ContentFormat: domain.ContentPlain}
+		format := "rst"
+		mockRepo.On("GetByID", primary, uint(1)).Return(blog, nil).Once()
+
+		_, err := blogService.PatchBlog(ctx, 1, domain.FieldPatch{ContentFormat: &format})
+
+		assert.Equal(t, errors.InvalidInput, err.(errors.AppError).Type)
+		mockRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	})
+}
+
+type fakeRenderer struct{}
+
+func (fakeRenderer) Render(ctx context.Context, format domain.ContentFormat, content string) (string, error) {
+	return string(format) + ":" + content, nil
+}
+
+func TestRenderBlogs(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("SetsRenderedHTML", func(t *testing.T) {
+		blogService := services.NewBlogService(new(MockBlogRepository), services.WithRenderer(fakeRenderer{}))
+		blogs := []*domain.Blog{
+			{ID: 1, Content: "a", ContentFormat: domain.ContentPlain},
+			{ID: 2, Content: "b"},
+		}
+
+		assert.NoError(t, blogService.RenderBlogs(ctx, blogs...))
+		assert.Equal(t, "plain:a", blogs[0].RenderedHTML)
+		assert.Equal(t, "markdown:b", blogs[1].RenderedHTML)
+	})
+
+	t.Run("FailsWithoutRenderer", func(t *testing.T) {
+		blogService := services.NewBlogService(new(MockBlogRepository))
+
+		err := blogService.RenderBlogs(ctx, &domain.Blog{ID: 1, Content: "a"})
+
+		assert.Equal(t, errors.InternalServer, err.(errors.AppError).Type)
+	})
+}
+
+func TestBlogFields(t *testing.T) {
+	ctx := context.Background()
+	mask := domain.FieldMask{domain.FieldTitle, domain.FieldRenderedHTML}
+
+	t.Run("RendersAndClearsContent", func(t *testing.T) {
+		mockRepo := new(MockBlogRepository)
+		blogService := services.NewBlogService(mockRepo, services.WithRenderer(fakeRenderer{}))
+		mockRepo.On("GetFields", ctx, uint(1), mask).Return(&domain.Blog{ID: 1, Title: "Title", Content: "a", ContentFormat: domain.ContentPlain}, nil)
+
+		blog, err := blogService.GetBlogFields(ctx, 1, mask)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "plain:a", blog.RenderedHTML)
+		assert.Empty(t, blog.Content)
+		assert.Empty(t, blog.ContentFormat)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		mockRepo := new(MockBlogRepository)
+		blogService := services.NewBlogService(mockRepo)
+		mockRepo.On("GetFields", ctx, uint(1), domain.FieldMask(nil)).Return((*domain.Blog)(nil), errors.NewNotFoundError("Blog not found"))
+
+		_, err := blogService.GetBlogFields(ctx, 1, nil)
+
+		assert.Equal(t, errors.NotFound, err.(errors.AppError).Type)
+	})
+}
+
+type fakeAnalyzer struct{}
+
+func (fakeAnalyzer) Analyze(ctx context.Context, format domain.ContentFormat, content string) (domain.ContentText, error) {
+	return domain.ContentText{
+		Prose:    []string{"The first paragraph of the post.", "The second one."},
+		Words:    450,
+		Headings: []domain.Heading{{Level: 1, Text: "Title", ID: "title"}},
+	}, nil
+}
+
+func TestSummary(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("DerivedOnCreate", func(t *testing.T) {
+		mockRepo := new(MockBlogRepository)
+		blogService := services.NewBlogService(mockRepo, services.WithSummary(services.SummaryConfig{
+			Analyzer: fakeAnalyzer{}, ExcerptLength: 30, WordsPerMinute: 200,
+		}))
+		blog := &domain.Blog{Title: "Test Blog", Content: "Test Content", Author: "Test Author"}
+		mockRepo.On("Create", ctx, blog).Return(nil).Once()
+
+		assert.NoError(t, blogService.CreateBlog(ctx, blog))
+		assert.Equal(t, "The first paragraph of the…", blog.Excerpt)
+		assert.Equal(t, 450, blog.WordCount)
+		assert.Equal(t, 3, blog.ReadingMinutes)
+		assert.Equal(t, []domain.Heading{{Level: 1, Text: "Title", ID: "title"}}, blog.TOC)
+	})
+
+	t.Run("DerivedOnPatch", func(t *testing.T) {
+		mockRepo := new(MockBlogRepository)
+		blogService := services.NewBlogService(mockRepo)
+		blog := &domain.Blog{ID: 1, Title: "Test Blog", Content: "Old", Author: "alice", Excerpt: "Old", WordCount: 1, ReadingMinutes: 1}
+		content := "New words\n\nin two paragraphs"
+		mockRepo.On("GetByID", primary, uint(1)).Return(blog, nil).Once()
+		mockRepo.On("Update", primary, blog).Return(nil).Once()
+
+		result, err := blogService.PatchBlog(ctx, 1, domain.FieldPatch{Content: &content})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "New words in two paragraphs", result.Excerpt)
+		assert.Equal(t, 5, result.WordCount)
+		assert.Equal(t, 1, result.ReadingMinutes)
+	})
+}
@@ -0,0 +1,114 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/toffysoft/go-hexagonal-example/internal/core/domain"
+	"github.com/toffysoft/go-hexagonal-example/internal/core/ports"
+	"github.com/toffysoft/go-hexagonal-example/pkg/errors"
+)
+
+// MaxIdempotencyKeyLength is the longest idempotency key accepted.
+const MaxIdempotencyKeyLength = 255
+
+// IdempotencyConfig tunes the idempotency service.
+type IdempotencyConfig struct {
+	// TTL is how long the response of a completed request is replayed.
+	TTL time.Duration
+	// LockTimeout is how long a request may stay in progress before its key
+	// can be claimed again, e.g. after the instance handling it crashed.
+	LockTimeout time.Duration
+	// Wait is how long a repeat waits for the first request to complete
+	// before it is rejected as a conflict.
+	Wait time.Duration
+	// PollInterval is how often a waiting repeat checks the first request.
+	PollInterval time.Duration
+	// CleanupInterval is how often expired records are deleted.
+	CleanupInterval time.Duration
+}
+
+type IdempotencyService struct {
+	store ports.IdempotencyStore
+	cfg   IdempotencyConfig
+	now   func() time.Time
+}
+
+func NewIdempotencyService(store ports.IdempotencyStore, cfg IdempotencyConfig) *IdempotencyService {
+	return &IdempotencyService{
+		store: store,
+		cfg:   cfg,
+		now:   time.Now,
+	}
+}
+
+func (s *IdempotencyService) Begin(ctx context.Context, scope, key, fingerprint string) (*domain.IdempotencyRecord, error) {
+	if key == "" || len(key) > MaxIdempotencyKeyLength {
+		return nil, errors.NewInvalidInputError("Idempotency key must be between 1 and 255 characters")
+	}
+
+	wait := time.NewTimer(s.cfg.Wait)
+	defer wait.Stop()
+	for {
+		now := s.now()
+		record := &domain.IdempotencyRecord{
+			Key:         scope + ":" + key,
+			Token:       uuid.NewString(),
+			Fingerprint: fingerprint,
+			CreatedAt:   now,
+			ExpiresAt:   now.Add(s.cfg.LockTimeout),
+		}
+		existing, err := s.store.Reserve(ctx, record, now)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case existing == nil:
+			return record, nil
+		case existing.Fingerprint != fingerprint:
+			return nil, errors.NewUnprocessableError("Idempotency key was already used for a different request")
+		case existing.Completed:
+			return existing, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-wait.C:
+			return nil, errors.NewConflictError("A request with this idempotency key is still in progress")
+		case <-time.After(s.cfg.PollInterval):
+		}
+	}
+}
+
+func (s *IdempotencyService) Complete(ctx context.Context, record *domain.IdempotencyRecord, response domain.IdempotentResponse) error {
+	return s.store.Complete(ctx, record, response, s.now().Add(s.cfg.TTL))
+}
+
+func (s *IdempotencyService) Release(ctx context.Context, record *domain.IdempotencyRecord) error {
+	return s.store.Release(ctx, record)
+}
+
+// Run deletes expired records every CleanupInterval until ctx is cancelled.
+func (s *IdempotencyService) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.cfg.CleanupInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		n, err := s.store.DeleteExpired(ctx, s.now())
+		if err != nil {
+			slog.Error("idempotency cleanup failed", "error", err)
+			continue
+		}
+		if n > 0 {
+			slog.Debug("deleted expired idempotency records", "count", n)
+		}
+	}
+}
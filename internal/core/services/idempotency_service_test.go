@@ -0,0 +1,156 @@
+package services_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/toffysoft/go-hexagonal-example/internal/adapters/repositories"
+	"github.com/toffysoft/go-hexagonal-example/internal/core/domain"
+	"github.com/toffysoft/go-hexagonal-example/internal/core/services"
+	"github.com/toffysoft/go-hexagonal-example/pkg/errors"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newIdempotencyService(cfg services.IdempotencyConfig) *services.IdempotencyService {
+	defaults := services.IdempotencyConfig{
+		TTL:             time.Hour,
+		LockTimeout:     time.Minute,
+		PollInterval:    time.Millisecond,
+		CleanupInterval: time.Hour,
+	}
+	if cfg.TTL != 0 {
+		defaults.TTL = cfg.TTL
+	}
+	if cfg.LockTimeout != 0 {
+		defaults.LockTimeout = cfg.LockTimeout
+	}
+	defaults.Wait = cfg.Wait
+	return services.NewIdempotencyService(repositories.NewMemoryIdempotencyStore(), defaults)
+}
+
+func TestIdempotencyService(t *testing.T) {
+	ctx := context.Background()
+	created := domain.IdempotentResponse{StatusCode: 201, ContentType: "application/json", Body: []byte(`{"id":1}`)}
+
+	t.Run("ReplaysCompletedRequest", func(t *testing.T) {
+		service := newIdempotencyService(services.IdempotencyConfig{})
+
+		record, err := service.Begin(ctx, "caller", "key-1", "fp")
+		require.NoError(t, err)
+		assert.False(t, record.Completed)
+		require.NoError(t, service.Complete(ctx, record, created))
+
+		replayed, err := service.Begin(ctx, "caller", "key-1", "fp")
+		require.NoError(t, err)
+		assert.True(t, replayed.Completed)
+		assert.Equal(t, created, replayed.Response())
+	})
+
+	t.Run("ScopesKeys", func(t *testing.T) {
+		service := newIdempotencyService(services.IdempotencyConfig{})
+
+		record, err := service.Begin(ctx, "alice", "key-1", "fp")
+		require.NoError(t, err)
+		require.NoError(t, service.Complete(ctx, record, created))
+
+		other, err := service.Begin(ctx, "bob", "key-1", "fp")
+		require.NoError(t, err)
+		assert.False(t, other.Completed)
+	})
+
+	t.Run("RejectsDifferentRequest", func(t *testing.T) {
+		service := newIdempotencyService(services.IdempotencyConfig{})
+
+		record, err := service.Begin(ctx, "caller", "key-1", "fp")
+		require.NoError(t, err)
+		require.NoError(t, service.Complete(ctx, record, created))
+
+		_, err = service.Begin(ctx, "caller", "key-1", "other")
+		assert.Equal(t, errors.Unprocessable, err.(errors.AppError).Type)
+	})
+
+	t.Run("RejectsInvalidKey", func(t *testing.T) {
+		service := newIdempotencyService(services.IdempotencyConfig{})
+
+		_, err := service.Begin(ctx, "caller", string(make([]byte, services.MaxIdempotencyKeyLength+1)), "fp")
+		assert.Equal(t, errors.InvalidInput, err.(errors.AppError).Type)
+	})
+
+	t.Run("RejectsRequestInProgress", func(t *testing.T) {
+		service := newIdempotencyService(services.IdempotencyConfig{})
+
+		_, err := service.Begin(ctx, "caller", "key-1", "fp")
+		require.NoError(t, err)
+
+		_, err = service.Begin(ctx, "caller", "key-1", "fp")
+		assert.Equal(t, errors.Conflict, err.(errors.AppError).Type)
+	})
+
+	t.Run("WaitsForRequestInProgress", func(t *testing.T) {
+		service := newIdempotencyService(services.IdempotencyConfig{Wait: 5 * time.Second})
+
+		record, err := service.Begin(ctx, "caller", "key-1", "fp")
+		require.NoError(t, err)
+
+		var wg sync.WaitGroup
+		var replayed *domain.IdempotencyRecord
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			replayed, err = service.Begin(ctx, "caller", "key-1", "fp")
+		}()
+		time.Sleep(10 * time.Millisecond)
+		require.NoError(t, service.Complete(ctx, record, created))
+		wg.Wait()
+
+		require.NoError(t, err)
+		assert.True(t, replayed.Completed)
+		assert.Equal(t, created, replayed.Response())
+	})
+
+	t.Run("ReleasedKeyCanBeRetried", func(t *testing.T) {
+		service := newIdempotencyService(services.IdempotencyConfig{})
+
+		record, err := service.Begin(ctx, "caller", "key-1", "fp")
+		require.NoError(t, err)
+		require.NoError(t, service.Release(ctx, record))
+
+		retry, err := service.Begin(ctx, "caller", "key-1", "fp")
+		require.NoError(t, err)
+		assert.False(t, retry.Completed)
+	})
+
+	t.Run("ExpiredReservationIsReclaimed", func(t *testing.T) {
+		service := newIdempotencyService(services.IdempotencyConfig{LockTimeout: time.Millisecond})
+
+		lost, err := service.Begin(ctx, "caller", "key-1", "fp")
+		require.NoError(t, err)
+		time.Sleep(5 * time.Millisecond)
+
+		retry, err := service.Begin(ctx, "caller", "key-1", "fp")
+		require.NoError(t, err)
+		assert.False(t, retry.Completed)
+
+		// The lost request no longer owns the key.
+		require.NoError(t, service.Complete(ctx, lost, created))
+		_, err = service.Begin(ctx, "caller", "key-1", "fp")
+		assert.Equal(t, errors.Conflict, err.(errors.AppError).Type)
+	})
+
+	t.Run("ExpiredResponseIsForgotten", func(t *testing.T) {
+		service := newIdempotencyService(services.IdempotencyConfig{TTL: time.Millisecond})
+
+		record, err := service.Begin(ctx, "caller", "key-1", "fp")
+		require.NoError(t, err)
+		require.NoError(t, service.Complete(ctx, record, created))
+		time.Sleep(5 * time.Millisecond)
+
+		again, err := service.Begin(ctx, "caller", "key-1", "other")
+		require.NoError(t, err)
+		assert.False(t, again.Completed)
+	})
+}
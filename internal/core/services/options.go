@@ -0,0 +1,90 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/toffysoft/go-hexagonal-example/internal/core/domain"
+	"github.com/toffysoft/go-hexagonal-example/internal/core/ports"
+	"github.com/toffysoft/go-hexagonal-example/pkg/errors"
+)
+
+// Option configures optional collaborators of the blog service.
+type Option func(*blogService)
+
+// WithMetrics records blog changes on the given metrics port.
+func WithMetrics(metrics ports.BlogMetrics) Option {
+	return func(s *blogService) {
+		s.metrics = metrics
+	}
+}
+
+// WithUnitOfWork runs multi-step operations, such as the existence check and
+// write of an update, atomically. Without it each step runs on its own.
+func WithUnitOfWork(uow ports.UnitOfWork) Option {
+	return func(s *blogService) {
+		s.uow = uow
+	}
+}
+
+// WithOutbox records a domain event for every blog change in the outbox, in
+// the same unit of work as the change.
+func WithOutbox(outbox ports.OutboxRepository) Option {
+	return func(s *blogService) {
+		s.outbox = outbox
+	}
+}
+
+// WithMaxBatchSize limits the number of items of a batch operation.
+func WithMaxBatchSize(size int) Option {
+	return func(s *blogService) {
+		s.maxBatchSize = size
+	}
+}
+
+// WithRenderer renders the content of blogs for RenderBlogs. Without it
+// RenderBlogs fails.
+func WithRenderer(renderer ports.ContentRenderer) Option {
+	return func(s *blogService) {
+		s.renderer = renderer
+	}
+}
+
+// WithSummary sets how the metadata of blogs is derived from their content.
+func WithSummary(cfg SummaryConfig) Option {
+	return func(s *blogService) {
+		s.summary = cfg.withDefaults()
+	}
+}
+
+type nopBlogMetrics struct{}
+
+func (nopBlogMetrics) BlogCreated() {}
+func (nopBlogMetrics) BlogUpdated() {}
+func (nopBlogMetrics) BlogDeleted() {}
+
+type nopUnitOfWork struct{}
+
+func (nopUnitOfWork) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+type nopOutbox struct{}
+
+func (nopOutbox) Add(context.Context, ...domain.Event) error { return nil }
+func (nopOutbox) Pending(context.Context, time.Time, int) ([]*domain.OutboxMessage, error) {
+	return nil, nil
+}
+func (nopOutbox) MarkPublished(context.Context, uint, time.Time) error            { return nil }
+func (nopOutbox) MarkFailed(context.Context, uint, string, time.Time) error       { return nil }
+func (nopOutbox) MarkDeadLettered(context.Context, uint, string, time.Time) error { return nil }
+func (nopOutbox) After(context.Context, uint, int) ([]*domain.OutboxMessage, error) {
+	return nil, nil
+}
+func (nopOutbox) Latest(context.Context, int) ([]*domain.OutboxMessage, error) { return nil, nil }
+
+type nopRenderer struct{}
+
+func (nopRenderer) Render(context.Context, domain.ContentFormat, string) (string, error) {
+	return "", errors.NewInternalServerError("Rendering is not configured")
+}
@@ -0,0 +1,113 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/toffysoft/go-hexagonal-example/internal/core/domain"
+	"github.com/toffysoft/go-hexagonal-example/internal/core/ports"
+)
+
+// RelayConfig tunes the outbox relay.
+type RelayConfig struct {
+	// PollInterval is how long the relay waits after draining the outbox.
+	PollInterval time.Duration
+	// BatchSize is the number of messages claimed per transaction.
+	BatchSize int
+	// MaxAttempts is the number of deliveries tried before a message is
+	// dead-lettered.
+	MaxAttempts int
+	// RetryBackoff is the delay after the first failure; it doubles with every
+	// further failure up to MaxRetryBackoff.
+	RetryBackoff    time.Duration
+	MaxRetryBackoff time.Duration
+}
+
+// OutboxRelay publishes outbox messages to an event bus. A message is marked
+// published only after the bus accepted it, so delivery is at least once.
+type OutboxRelay struct {
+	outbox ports.OutboxRepository
+	bus    ports.EventBus
+	uow    ports.UnitOfWork
+	cfg    RelayConfig
+	now    func() time.Time
+}
+
+func NewOutboxRelay(outbox ports.OutboxRepository, bus ports.EventBus, uow ports.UnitOfWork, cfg RelayConfig) *OutboxRelay {
+	return &OutboxRelay{
+		outbox: outbox,
+		bus:    bus,
+		uow:    uow,
+		cfg:    cfg,
+		now:    time.Now,
+	}
+}
+
+// Run relays messages until ctx is cancelled.
+func (r *OutboxRelay) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.cfg.PollInterval)
+	defer ticker.Stop()
+	for {
+		r.drain(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// drain relays batches until no message is due.
+func (r *OutboxRelay) drain(ctx context.Context) {
+	for ctx.Err() == nil {
+		n, err := r.RelayOnce(ctx)
+		if err != nil {
+			slog.Error("outbox relay failed", "error", err)
+			return
+		}
+		if n < r.cfg.BatchSize {
+			return
+		}
+	}
+}
+
+// RelayOnce claims one batch of due messages, tries to publish each and
+// records the outcome. It returns the number of messages processed.
+func (r *OutboxRelay) RelayOnce(ctx context.Context) (int, error) {
+	var processed int
+	err := r.uow.Do(ctx, func(ctx context.Context) error {
+		messages, err := r.outbox.Pending(ctx, r.now(), r.cfg.BatchSize)
+		if err != nil {
+			return err
+		}
+		for _, message := range messages {
+			if err := r.relay(ctx, message); err != nil {
+				return err
+			}
+			processed++
+		}
+		return nil
+	})
+	return processed, err
+}
+
+func (r *OutboxRelay) relay(ctx context.Context, message *domain.OutboxMessage) error {
+	now := r.now()
+	publishErr := r.bus.Publish(ctx, message.Event())
+	if publishErr == nil {
+		return r.outbox.MarkPublished(ctx, message.ID, now)
+	}
+
+	attempts := message.Attempts + 1
+	if attempts >= r.cfg.MaxAttempts {
+		slog.Error("outbox message dead-lettered",
+			"event_id", message.EventID, "type", message.Type, "attempts", attempts, "error", publishErr)
+		return r.outbox.MarkDeadLettered(ctx, message.ID, publishErr.Error(), now)
+	}
+
+	retryAt := now.Add(backoff(r.cfg.RetryBackoff, r.cfg.MaxRetryBackoff, attempts))
+	slog.Warn("outbox message publish failed",
+		"event_id", message.EventID, "type", message.Type, "attempts", attempts, "retry_at", retryAt, "error", publishErr)
+	return r.outbox.MarkFailed(ctx, message.ID, publishErr.Error(), retryAt)
+}
@@ -0,0 +1,204 @@
+package services_test
+
+import (
+	"context"
+	stderrors "errors"
+	"testing"
+	"time"
+
+	"github.com/toffysoft/go-hexagonal-example/internal/core/domain"
+	"github.com/toffysoft/go-hexagonal-example/internal/core/ports"
+	"github.com/toffysoft/go-hexagonal-example/internal/core/services"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockOutbox struct {
+	mock.Mock
+}
+
+func (m *MockOutbox) Add(ctx context.Context, events ...domain.Event) error {
+	args := m.Called(ctx, events)
+	return args.Error(0)
+}
+
+func (m *MockOutbox) Pending(ctx context.Context, now time.Time, limit int) ([]*domain.OutboxMessage, error) {
+	args := m.Called(ctx, now, limit)
+	return args.Get(0).([]*domain.OutboxMessage), args.Error(1)
+}
+
+func (m *MockOutbox) MarkPublished(ctx context.Context, id uint, at time.Time) error {
+	args := m.Called(ctx, id, at)
+	return args.Error(0)
+}
+
+func (m *MockOutbox) MarkFailed(ctx context.Context, id uint, reason string, retryAt time.Time) error {
+	args := m.Called(ctx, id, reason, retryAt)
+	return args.Error(0)
+}
+
+func (m *MockOutbox) MarkDeadLettered(ctx context.Context, id uint, reason string, at time.Time) error {
+	args := m.Called(ctx, id, reason, at)
+	return args.Error(0)
+}
+
+func (m *MockOutbox) After(ctx context.Context, id uint, limit int) ([]*domain.OutboxMessage, error) {
+	args := m.Called(ctx, id, limit)
+	return args.Get(0).([]*domain.OutboxMessage), args.Error(1)
+}
+
+func (m *MockOutbox) Latest(ctx context.Context, limit int) ([]*domain.OutboxMessage, error) {
+	args := m.Called(ctx, limit)
+	return args.Get(0).([]*domain.OutboxMessage), args.Error(1)
+}
+
+type MockEventBus struct {
+	mock.Mock
+}
+
+func (m *MockEventBus) Publish(ctx context.Context, event domain.Event) error {
+	args := m.Called(ctx, event)
+	return args.Error(0)
+}
+
+func (m *MockEventBus) Subscribe(name string, handler ports.EventHandler, types ...domain.EventType) {
+	m.Called(name, handler, types)
+}
+
+// eventsOfType matches an Add call recording exactly one event of the given type.
+func eventsOfType(eventType domain.EventType) interface{} {
+	return mock.MatchedBy(func(events []domain.Event) bool {
+		return len(events) == 1 && events[0].Type == eventType
+	})
+}
+
+func TestOutboxEvents(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockBlogRepository)
+	mockOutbox := new(MockOutbox)
+	blogService := services.NewBlogService(mockRepo, services.WithOutbox(mockOutbox))
+
+	t.Run("RecordsChanges", func(t *testing.T) {
+		blog := &domain.Blog{ID: 1, Title: "Test Blog", Content: "Test Content", Author: "Test Author"}
+		mockRepo.On("Create", ctx, blog).Return(nil).Once()
+		mockRepo.On("GetByID", primary, uint(1)).Return(blog, nil).Twice()
+		mockRepo.On("Update", primary, blog).Return(nil).Once()
+		mockRepo.On("Delete", primary, uint(1)).Return(nil).Once()
+		mockOutbox.On("Add", ctx, eventsOfType(domain.BlogCreated)).Return(nil).Once()
+		mockOutbox.On("Add", primary, eventsOfType(domain.BlogUpdated)).Return(nil).Once()
+		mockOutbox.On("Add", primary, eventsOfType(domain.BlogDeleted)).Return(nil).Once()
+
+		assert.NoError(t, blogService.CreateBlog(ctx, blog))
+		assert.NoError(t, blogService.UpdateBlog(ctx, blog))
+		assert.NoError(t, blogService.DeleteBlog(ctx, 1))
+
+		mockRepo.AssertExpectations(t)
+		mockOutbox.AssertExpectations(t)
+	})
+
+	t.Run("FailsWhenOutboxFails", func(t *testing.T) {
+		blog := &domain.Blog{Title: "Test Blog", Content: "Test Content", Author: "Test Author"}
+		mockRepo.On("Create", ctx, blog).Return(nil).Once()
+		mockOutbox.On("Add", ctx, eventsOfType(domain.BlogCreated)).Return(stderrors.New("outbox unavailable")).Once()
+
+		assert.Error(t, blogService.CreateBlog(ctx, blog))
+
+		mockOutbox.AssertExpectations(t)
+	})
+}
+
+func TestBlogEventPayload(t *testing.T) {
+	blog := &domain.Blog{ID: 7, Title: "Test Blog", Content: "Test Content", Author: "Test Author"}
+
+	event := domain.NewBlogUpdatedEvent(blog)
+	payload, err := event.BlogPayload()
+
+	assert.NoError(t, err)
+	assert.NotEmpty(t, event.ID)
+	assert.Equal(t, uint(7), event.BlogID)
+	assert.Equal(t, domain.EventVersion, event.Version)
+	assert.Equal(t, blog.Title, payload.Blog.Title)
+
+	payload, err = domain.NewBlogDeletedEvent(blog).BlogPayload()
+	assert.NoError(t, err)
+	assert.Equal(t, blog.Author, payload.Blog.Author)
+}
+
+func TestOutboxRelay(t *testing.T) {
+	ctx := context.Background()
+	cfg := services.RelayConfig{
+		PollInterval:    time.Second,
+		BatchSize:       10,
+		MaxAttempts:     3,
+		RetryBackoff:    time.Second,
+		MaxRetryBackoff: time.Minute,
+	}
+	newMessage := func(id uint, attempts int) *domain.OutboxMessage {
+		message := domain.NewOutboxMessage(domain.NewBlogCreatedEvent(&domain.Blog{ID: id}))
+		message.ID = id
+		message.Attempts = attempts
+		return message
+	}
+
+	t.Run("PublishesPendingMessages", func(t *testing.T) {
+		mockOutbox := new(MockOutbox)
+		mockBus := new(MockEventBus)
+		relay := services.NewOutboxRelay(mockOutbox, mockBus, new(nopUOW), cfg)
+
+		message := newMessage(1, 0)
+		mockOutbox.On("Pending", ctx, mock.Anything, 10).Return([]*domain.OutboxMessage{message}, nil).Once()
+		mockBus.On("Publish", ctx, message.Event()).Return(nil).Once()
+		mockOutbox.On("MarkPublished", ctx, uint(1), mock.Anything).Return(nil).Once()
+
+		n, err := relay.RelayOnce(ctx)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, n)
+		mockOutbox.AssertExpectations(t)
+		mockBus.AssertExpectations(t)
+	})
+
+	t.Run("SchedulesRetryWithBackoff", func(t *testing.T) {
+		mockOutbox := new(MockOutbox)
+		mockBus := new(MockEventBus)
+		relay := services.NewOutboxRelay(mockOutbox, mockBus, new(nopUOW), cfg)
+
+		message := newMessage(1, 1)
+		start := time.Now()
+		mockOutbox.On("Pending", ctx, mock.Anything, 10).Return([]*domain.OutboxMessage{message}, nil).Once()
+		mockBus.On("Publish", ctx, mock.Anything).Return(stderrors.New("bus down")).Once()
+		mockOutbox.On("MarkFailed", ctx, uint(1), "bus down", mock.MatchedBy(func(retryAt time.Time) bool {
+			// second failure: twice the initial backoff
+			return !retryAt.Before(start.Add(2*time.Second)) && retryAt.Before(start.Add(3*time.Second))
+		})).Return(nil).Once()
+
+		_, err := relay.RelayOnce(ctx)
+
+		assert.NoError(t, err)
+		mockOutbox.AssertExpectations(t)
+	})
+
+	t.Run("DeadLettersAfterMaxAttempts", func(t *testing.T) {
+		mockOutbox := new(MockOutbox)
+		mockBus := new(MockEventBus)
+		relay := services.NewOutboxRelay(mockOutbox, mockBus, new(nopUOW), cfg)
+
+		message := newMessage(1, 2)
+		mockOutbox.On("Pending", ctx, mock.Anything, 10).Return([]*domain.OutboxMessage{message}, nil).Once()
+		mockBus.On("Publish", ctx, mock.Anything).Return(stderrors.New("bus down")).Once()
+		mockOutbox.On("MarkDeadLettered", ctx, uint(1), "bus down", mock.Anything).Return(nil).Once()
+
+		_, err := relay.RelayOnce(ctx)
+
+		assert.NoError(t, err)
+		mockOutbox.AssertExpectations(t)
+		mockOutbox.AssertNotCalled(t, "MarkFailed", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+type nopUOW struct{}
+
+func (*nopUOW) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
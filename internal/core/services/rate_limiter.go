@@ -0,0 +1,62 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+	"sync/atomic"
+	"time"
+
+	"github.com/toffysoft/go-hexagonal-example/internal/core/domain"
+	"github.com/toffysoft/go-hexagonal-example/internal/core/ports"
+)
+
+// RateLimiter applies token bucket policies to requests. The first policy
+// matching a request applies; requests matching none are not limited.
+// Policies can be replaced while serving, e.g. after a config reload.
+type RateLimiter struct {
+	store    ports.RateLimitStore
+	policies atomic.Pointer[[]domain.RateLimitPolicy]
+	now      func() time.Time
+}
+
+// NewRateLimiter returns a limiter that allows every request until it is
+// configured.
+func NewRateLimiter(store ports.RateLimitStore, policies ...domain.RateLimitPolicy) *RateLimiter {
+	l := &RateLimiter{store: store, now: time.Now}
+	l.Configure(policies...)
+	return l
+}
+
+// Configure replaces the policies; none disables limiting.
+func (l *RateLimiter) Configure(policies ...domain.RateLimitPolicy) {
+	l.policies.Store(&policies)
+}
+
+func (l *RateLimiter) Policy(method, route string) (domain.RateLimitPolicy, bool) {
+	for _, policy := range *l.policies.Load() {
+		if policy.Matches(method, route) {
+			return policy, true
+		}
+	}
+	return domain.RateLimitPolicy{}, false
+}
+
+func (l *RateLimiter) Allow(ctx context.Context, policy domain.RateLimitPolicy, identity string) (domain.RateLimitDecision, error) {
+	return l.store.Take(ctx, policy.Name+"|"+identity, policy, l.now())
+}
+
+// Run deletes full buckets every interval until ctx is cancelled.
+func (l *RateLimiter) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if _, err := l.store.DeleteFull(ctx, l.now()); err != nil {
+			slog.Error("rate limit cleanup failed", "error", err)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/toffysoft/go-hexagonal-example/internal/adapters/repositories"
+	"github.com/toffysoft/go-hexagonal-example/internal/core/domain"
+	"github.com/toffysoft/go-hexagonal-example/internal/core/services"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func mustParsePolicy(t *testing.T, spec string) domain.RateLimitPolicy {
+	policy, err := domain.ParseRateLimitPolicy(spec)
+	require.NoError(t, err)
+	return policy
+}
+
+func TestParseRateLimitPolicy(t *testing.T) {
+	policy := mustParsePolicy(t, "post /api/v1/blogs 10/1m burst=20 by=ip")
+	assert.Equal(t, domain.RateLimitPolicy{
+		Name:     "post /api/v1/blogs",
+		Method:   "POST",
+		Prefix:   "/api/v1/blogs",
+		Limit:    10,
+		Window:   time.Minute,
+		Burst:    20,
+		Identity: domain.IdentityIP,
+	}, policy)
+	assert.Equal(t, "10;w=60;burst=20", policy.Header())
+
+	policy = mustParsePolicy(t, "/blog.BlogService/ 5/1s")
+	assert.Empty(t, policy.Method)
+	assert.Equal(t, 5, policy.Burst)
+	assert.Equal(t, domain.IdentityAPIKey, policy.Identity)
+
+	for _, spec := range []string{
+		"",
+		"10/1m",
+		"/api 0/1m",
+		"/api 10/forever",
+		"/api 10/1m burst=0",
+		"/api 10/1m by=session",
+		"/api 10/1m fast",
+		"GET POST /api 10/1m",
+	} {
+		_, err := domain.ParseRateLimitPolicy(spec)
+		assert.Error(t, err, spec)
+	}
+}
+
+func TestRateLimiter(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("FirstMatchingPolicyApplies", func(t *testing.T) {
+		limiter := services.NewRateLimiter(repositories.NewMemoryRateLimitStore(),
+			mustParsePolicy(t, "POST /api/v1/blogs 1/1m"),
+			mustParsePolicy(t, "/ 100/1m"),
+		)
+
+		policy, ok := limiter.Policy("POST", "/api/v1/blogs")
+		require.True(t, ok)
+		assert.Equal(t, 1, policy.Limit)
+		policy, ok = limiter.Policy("GET", "/api/v1/blogs")
+		require.True(t, ok)
+		assert.Equal(t, 100, policy.Limit)
+		_, ok = limiter.Policy("", "/blog.BlogService/GetBlog")
+		assert.True(t, ok)
+	})
+
+	t.Run("DisabledWithoutPolicies", func(t *testing.T) {
+		limiter := services.NewRateLimiter(repositories.NewMemoryRateLimitStore())
+
+		_, ok := limiter.Policy("GET", "/api/v1/blogs")
+		assert.False(t, ok)
+
+		limiter.Configure(mustParsePolicy(t, "/ 1/1m"))
+		_, ok = limiter.Policy("GET", "/api/v1/blogs")
+		assert.True(t, ok)
+	})
+
+	t.Run("EmptiesBucketPerIdentity", func(t *testing.T) {
+		policy := mustParsePolicy(t, "/ 2/1m")
+		limiter := services.NewRateLimiter(repositories.NewMemoryRateLimitStore(), policy)
+
+		for remaining := 1; remaining >= 0; remaining-- {
+			decision, err := limiter.Allow(ctx, policy, "ip:10.0.0.1")
+			require.NoError(t, err)
+			assert.True(t, decision.Allowed)
+			assert.Equal(t, remaining, decision.Remaining)
+		}
+
+		decision, err := limiter.Allow(ctx, policy, "ip:10.0.0.1")
+		require.NoError(t, err)
+		assert.False(t, decision.Allowed)
+		assert.Equal(t, 2, decision.Limit)
+		assert.InDelta(t, 30*time.Second, decision.RetryAfter, float64(time.Second))
+		assert.InDelta(t, time.Minute, decision.Reset, float64(time.Second))
+
+		decision, err = limiter.Allow(ctx, policy, "ip:10.0.0.2")
+		require.NoError(t, err)
+		assert.True(t, decision.Allowed)
+	})
+}
+
+func TestTokenBucketRefills(t *testing.T) {
+	policy := mustParsePolicy(t, "/ 10/10s burst=5")
+	now := time.Now()
+	bucket := domain.NewTokenBucket("key", policy, now)
+
+	for i := 0; i < 5; i++ {
+		assert.True(t, bucket.Take(policy, now).Allowed)
+	}
+	assert.False(t, bucket.Take(policy, now).Allowed)
+
+	// One token per second, never more than the burst.
+	assert.True(t, bucket.Take(policy, now.Add(time.Second)).Allowed)
+	assert.False(t, bucket.Take(policy, now.Add(time.Second)).Allowed)
+	decision := bucket.Take(policy, now.Add(time.Hour))
+	assert.True(t, decision.Allowed)
+	assert.Equal(t, 4, decision.Remaining)
+	assert.Equal(t, now.Add(time.Hour+time.Second), bucket.FullAt)
+}
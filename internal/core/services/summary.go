@@ -0,0 +1,122 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/toffysoft/go-hexagonal-example/internal/core/domain"
+	"github.com/toffysoft/go-hexagonal-example/internal/core/ports"
+	"github.com/toffysoft/go-hexagonal-example/pkg/errors"
+)
+
+// Defaults of SummaryConfig.
+const (
+	DefaultExcerptLength  = 200
+	DefaultWordsPerMinute = 200
+)
+
+// SummaryConfig tells how the excerpt, word count, reading time and table
+// of contents of a blog are derived from its content. Zero fields take their
+// defaults; without an Analyzer content is read as plain text.
+type SummaryConfig struct {
+	Analyzer       ports.ContentAnalyzer
+	ExcerptLength  int
+	WordsPerMinute int
+}
+
+func (c SummaryConfig) withDefaults() SummaryConfig {
+	if c.Analyzer == nil {
+		c.Analyzer = plainAnalyzer{}
+	}
+	if c.ExcerptLength <= 0 {
+		c.ExcerptLength = DefaultExcerptLength
+	}
+	if c.WordsPerMinute <= 0 {
+		c.WordsPerMinute = DefaultWordsPerMinute
+	}
+	return c
+}
+
+// summarize derives the metadata of blog from its content.
+func (c SummaryConfig) summarize(ctx context.Context, blog *domain.Blog) error {
+	text, err := c.Analyzer.Analyze(ctx, blog.ContentFormat, blog.Content)
+	if err != nil {
+		if _, ok := err.(errors.AppError); ok {
+			return err
+		}
+		return errors.NewInternalServerError(fmt.Sprintf("Failed to analyze content: %v", err))
+	}
+	blog.Summarize(text, c.ExcerptLength, c.WordsPerMinute)
+	return nil
+}
+
+// SummaryBackfill derives the metadata of the blogs written before it was
+// stored with them.
+type SummaryBackfill struct {
+	repo    ports.BlogRepository
+	uow     ports.UnitOfWork
+	summary SummaryConfig
+}
+
+func NewSummaryBackfill(repo ports.BlogRepository, uow ports.UnitOfWork, summary SummaryConfig) *SummaryBackfill {
+	return &SummaryBackfill{repo: repo, uow: uow, summary: summary.withDefaults()}
+}
+
+// Run summarizes the blogs with content but no word count, chunkSize at a
+// time and each chunk in a unit of work, and returns how many it updated.
+// Blogs written meanwhile are left alone, so it is safe to run while the
+// service is up, and to run again after a failure.
+func (b *SummaryBackfill) Run(ctx context.Context, chunkSize int) (int, error) {
+	if chunkSize <= 0 {
+		return 0, errors.NewInvalidInputError("Chunk size must be greater than zero")
+	}
+	updated := 0
+	err := eachChunk(ctx, b.repo, domain.BlogFilter{Unsummarized: true}, 0, chunkSize, func(blogs []*domain.Blog) error {
+		n := 0
+		err := b.uow.Do(ports.WithStrongConsistency(ctx), func(ctx context.Context) error {
+			for _, listed := range blogs {
+				blog, err := b.repo.GetByID(ctx, listed.ID)
+				if isNotFound(err) {
+					continue
+				}
+				if err != nil {
+					return err
+				}
+				if blog.WordCount != 0 || blog.Content == "" {
+					// Written since it was listed.
+					continue
+				}
+				if err := b.summary.summarize(ctx, blog); err != nil {
+					return err
+				}
+				if err := b.repo.Update(ctx, blog); err != nil {
+					return err
+				}
+				n++
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		updated += n
+		return nil
+	})
+	return updated, err
+}
+
+// plainAnalyzer reads content as plain text, with paragraphs separated by
+// blank lines.
+type plainAnalyzer struct{}
+
+func (plainAnalyzer) Analyze(ctx context.Context, format domain.ContentFormat, content string) (domain.ContentText, error) {
+	var text domain.ContentText
+	for _, para := range strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n\n") {
+		if words := strings.Fields(para); len(words) > 0 {
+			text.Prose = append(text.Prose, strings.Join(words, " "))
+			text.Words += len(words)
+		}
+	}
+	return text, nil
+}
@@ -0,0 +1,58 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/toffysoft/go-hexagonal-example/internal/adapters/repositories"
+	"github.com/toffysoft/go-hexagonal-example/internal/core/domain"
+	"github.com/toffysoft/go-hexagonal-example/internal/core/services"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSummaryBackfill(t *testing.T) {
+	ctx := context.Background()
+	store := repositories.NewMemoryStore()
+	repo := store.Blogs()
+	// Written straight to the repository, as before summaries were stored.
+	blogs := []*domain.Blog{
+		{Title: "One", Content: "The first post", Author: "alice"},
+		{Title: "Two", Content: "Already summed up", Author: "alice", Excerpt: "Kept", WordCount: 3, ReadingMinutes: 1},
+		{Title: "Three", Content: "The third and last post", Author: "bob"},
+		{Title: "Four", Author: "bob"},
+	}
+	for _, blog := range blogs {
+		require.NoError(t, repo.Create(ctx, blog))
+	}
+	backfill := services.NewSummaryBackfill(repo, store.UnitOfWork(), services.SummaryConfig{})
+
+	updated, err := backfill.Run(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, 2, updated)
+
+	for _, want := range []struct {
+		id        uint
+		excerpt   string
+		wordCount int
+	}{
+		{1, "The first post", 3},
+		{2, "Kept", 3},
+		{3, "The third and last post", 5},
+		{4, "", 0},
+	} {
+		blog, err := repo.GetByID(ctx, want.id)
+		require.NoError(t, err)
+		assert.Equal(t, want.excerpt, blog.Excerpt, blog.Title)
+		assert.Equal(t, want.wordCount, blog.WordCount, blog.Title)
+	}
+
+	// Nothing is left to do on a second run.
+	updated, err = backfill.Run(ctx, 10)
+	require.NoError(t, err)
+	assert.Zero(t, updated)
+
+	_, err = backfill.Run(ctx, 0)
+	assert.Error(t, err)
+}
@@ -0,0 +1,212 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/toffysoft/go-hexagonal-example/internal/core/domain"
+	"github.com/toffysoft/go-hexagonal-example/internal/core/ports"
+	"github.com/toffysoft/go-hexagonal-example/pkg/errors"
+)
+
+// transferChunkSize is the number of blogs read per query by exports and by
+// the duplicate index of imports.
+const transferChunkSize = 500
+
+type transferService struct {
+	repo    ports.BlogRepository
+	uow     ports.UnitOfWork
+	outbox  ports.OutboxRepository
+	summary SummaryConfig
+}
+
+// TransferOption configures optional behaviour of the TransferService.
+type TransferOption func(*transferService)
+
+// WithImportSummary sets how the metadata of imported blogs is derived from
+// their content.
+func WithImportSummary(cfg SummaryConfig) TransferOption {
+	return func(s *transferService) {
+		s.summary = cfg.withDefaults()
+	}
+}
+
+// NewTransferService returns a TransferService. Imports record their events
+// in outbox, unless it is nil.
+func NewTransferService(repo ports.BlogRepository, uow ports.UnitOfWork, outbox ports.OutboxRepository, opts ...TransferOption) ports.TransferService {
+	if outbox == nil {
+		outbox = nopOutbox{}
+	}
+	s := &transferService{repo: repo, uow: uow, outbox: outbox, summary: SummaryConfig{}.withDefaults()}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+func (s *transferService) ExportBlogs(ctx context.Context, filter domain.BlogFilter, fn func(blogs []*domain.Blog) error) error {
+	return eachChunk(ctx, s.repo, filter, 0, transferChunkSize, fn)
+}
+
+// pendingUpdate is an imported row that overwrites an existing blog.
+type pendingUpdate struct {
+	row   int
+	id    uint
+	patch *domain.Blog
+}
+
+func (s *transferService) ImportBlogs(ctx context.Context, records []domain.ImportRecord, opts domain.ImportOptions) (*domain.ImportReport, error) {
+	if opts.DedupeBy == "" {
+		opts.DedupeBy = domain.DedupeNone
+	}
+	if !opts.DedupeBy.Valid() {
+		return nil, errors.NewInvalidInputError(fmt.Sprintf("Unknown dedupe key %q", opts.DedupeBy))
+	}
+	if opts.Upsert && opts.DedupeBy == domain.DedupeNone {
+		return nil, errors.NewInvalidInputError("Upsert needs a dedupe key to match existing blogs")
+	}
+
+	existing, err := s.index(ctx, opts.DedupeBy)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &domain.ImportReport{DryRun: opts.DryRun, Rows: make([]domain.ImportRow, len(records))}
+	var creates []int
+	var updates []pendingUpdate
+	seen := make(map[string]int)
+	for i, record := range records {
+		row := &report.Rows[i]
+		row.Source = record.Source
+		if record.Err != nil {
+			row.Action, row.Reason = domain.ImportFailed, record.Err.Error()
+			continue
+		}
+		if err := validateBlog(record.Blog); err != nil {
+			row.Action, row.Reason = domain.ImportFailed, err.Error()
+			continue
+		}
+
+		if key := opts.DedupeBy.Of(record.Blog); key != "" {
+			if first, dup := seen[key]; dup {
+				row.Action, row.Reason = domain.ImportSkipped, "duplicate of "+records[first].Source
+				continue
+			}
+			seen[key] = i
+			if id, ok := existing[key]; ok {
+				row.BlogID = id
+				if opts.Upsert {
+					row.Action = domain.ImportUpdated
+					updates = append(updates, pendingUpdate{row: i, id: id, patch: record.Blog})
+				} else {
+					row.Action, row.Reason = domain.ImportSkipped, fmt.Sprintf("matches blog %d", id)
+				}
+				continue
+			}
+		}
+		if err := s.summary.summarize(ctx, record.Blog); err != nil {
+			row.Action, row.Reason = domain.ImportFailed, err.Error()
+			continue
+		}
+		row.Action = domain.ImportCreated
+		creates = append(creates, i)
+	}
+
+	if !opts.DryRun {
+		if err := s.apply(ctx, records, creates, updates, report); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, row := range report.Rows {
+		switch row.Action {
+		case domain.ImportCreated:
+			report.Created++
+		case domain.ImportUpdated:
+			report.Updated++
+		case domain.ImportSkipped:
+			report.Skipped++
+		case domain.ImportFailed:
+			report.Failed++
+		}
+	}
+	return report, nil
+}
+
+// index maps the dedupe key of every stored blog to its ID. The oldest blog
+// wins when several share a key.
+func (s *transferService) index(ctx context.Context, dedupeBy domain.DedupeKey) (map[string]uint, error) {
+	index := make(map[string]uint)
+	if dedupeBy == domain.DedupeNone {
+		return index, nil
+	}
+	err := eachChunk(ctx, s.repo, domain.BlogFilter{}, 0, transferChunkSize, func(blogs []*domain.Blog) error {
+		for _, blog := range blogs {
+			key := dedupeBy.Of(blog)
+			if _, ok := index[key]; !ok {
+				index[key] = blog.ID
+			}
+		}
+		return nil
+	})
+	return index, err
+}
+
+func (s *transferService) apply(ctx context.Context, records []domain.ImportRecord, creates []int, updates []pendingUpdate, report *domain.ImportReport) error {
+	return s.uow.Do(ports.WithStrongConsistency(ctx), func(ctx context.Context) error {
+		var events []domain.Event
+
+		if len(creates) > 0 {
+			blogs := make([]*domain.Blog, len(creates))
+			for j, i := range creates {
+				// IDs belong to the environment the file came from.
+				blog := records[i].Blog
+				blog.ID = 0
+				blogs[j] = blog
+			}
+			if err := s.repo.CreateBatch(ctx, blogs); err != nil {
+				return err
+			}
+			for j, i := range creates {
+				report.Rows[i].BlogID = blogs[j].ID
+				events = append(events, domain.NewBlogCreatedEvent(blogs[j]))
+			}
+		}
+
+		if len(updates) > 0 {
+			ids := make([]uint, len(updates))
+			for j, update := range updates {
+				ids[j] = update.id
+			}
+			stored, err := s.repo.GetByIDs(ctx, ids)
+			if err != nil {
+				return err
+			}
+			byID := make(map[uint]*domain.Blog, len(stored))
+			for _, blog := range stored {
+				byID[blog.ID] = blog
+			}
+			for _, update := range updates {
+				blog, ok := byID[update.id]
+				if !ok {
+					row := &report.Rows[update.row]
+					row.Action, row.Reason = domain.ImportFailed, fmt.Sprintf("Blog with ID %d not found", update.id)
+					continue
+				}
+				mergeBlog(blog, update.patch)
+				if err := s.summary.summarize(ctx, blog); err != nil {
+					return err
+				}
+				if err := s.repo.Update(ctx, blog); err != nil {
+					return err
+				}
+				events = append(events, domain.NewBlogUpdatedEvent(blog))
+			}
+		}
+
+		if len(events) == 0 {
+			return nil
+		}
+		return s.outbox.Add(ctx, events...)
+	})
+}
@@ -0,0 +1,109 @@
+package services_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/toffysoft/go-hexagonal-example/internal/core/domain"
+	"github.com/toffysoft/go-hexagonal-example/internal/core/services"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func importRecords(blogs ...*domain.Blog) []domain.ImportRecord {
+	records := make([]domain.ImportRecord, len(blogs))
+	for i, blog := range blogs {
+		records[i] = domain.ImportRecord{Source: fmt.Sprintf("line %d", i+1), Blog: blog}
+	}
+	return records
+}
+
+func TestImportBlogs(t *testing.T) {
+	ctx := context.Background()
+	stored := []*domain.Blog{{ID: 4, Title: "Hello, World!", Content: "Old", Author: "alice"}}
+
+	t.Run("DryRunWritesNothing", func(t *testing.T) {
+		mockRepo := new(MockBlogRepository)
+		transferService := services.NewTransferService(mockRepo, new(nopUOW), new(MockOutbox))
+		mockRepo.On("ListAfter", ctx, domain.BlogFilter{}, uint(0), mock.Anything).Return(stored, nil).Once()
+
+		records := importRecords(
+			&domain.Blog{Title: "hello world", Content: "New", Author: "bob"},
+			&domain.Blog{Title: "Fresh", Content: "New", Author: "bob"},
+			&domain.Blog{Title: "fresh!", Content: "Again", Author: "carol"},
+			&domain.Blog{Title: "No content", Author: "bob"},
+		)
+		records = append(records, domain.ImportRecord{Source: "line 5", Err: fmt.Errorf("invalid JSON")})
+
+		report, err := transferService.ImportBlogs(ctx, records, domain.ImportOptions{DryRun: true, DedupeBy: domain.DedupeSlug})
+
+		require.NoError(t, err)
+		assert.True(t, report.DryRun)
+		assert.Equal(t, 1, report.Created)
+		assert.Equal(t, 2, report.Skipped)
+		assert.Equal(t, 2, report.Failed)
+		assert.Equal(t, uint(4), report.Rows[0].BlogID)
+		assert.Equal(t, "duplicate of line 2", report.Rows[2].Reason)
+		mockRepo.AssertNotCalled(t, "CreateBatch", mock.Anything, mock.Anything)
+	})
+
+	t.Run("UpsertUpdatesMatchesAndCreatesTheRest", func(t *testing.T) {
+		mockRepo := new(MockBlogRepository)
+		mockOutbox := new(MockOutbox)
+		transferService := services.NewTransferService(mockRepo, new(nopUOW), mockOutbox)
+		current := &domain.Blog{ID: 4, Title: "Hello, World!", Content: "Old", Author: "alice"}
+
+		mockRepo.On("ListAfter", ctx, domain.BlogFilter{}, uint(0), mock.Anything).Return(stored, nil).Once()
+		mockRepo.On("CreateBatch", primary, mock.Anything).Run(func(args mock.Arguments) {
+			args.Get(1).([]*domain.Blog)[0].ID = 9
+		}).Return(nil).Once()
+		mockRepo.On("GetByIDs", primary, []uint{4}).Return([]*domain.Blog{current}, nil).Once()
+		mockRepo.On("Update", primary, current).Return(nil).Once()
+		mockOutbox.On("Add", primary, mock.Anything).Return(nil).Once()
+
+		records := importRecords(
+			&domain.Blog{ID: 4, Title: "Hello, World!", Content: "New", Author: "alice", Tags: []string{"Greetings"}},
+			&domain.Blog{ID: 4, Title: "Another", Content: "New", Author: "alice"},
+		)
+		report, err := transferService.ImportBlogs(ctx, records, domain.ImportOptions{DedupeBy: domain.DedupeTitleAuthor, Upsert: true})
+
+		require.NoError(t, err)
+		assert.Equal(t, 1, report.Created)
+		assert.Equal(t, 1, report.Updated)
+		assert.Equal(t, "New", current.Content)
+		assert.Equal(t, []string{"greetings"}, current.Tags)
+		assert.Equal(t, uint(9), report.Rows[1].BlogID)
+		mockRepo.AssertExpectations(t)
+		mockOutbox.AssertExpectations(t)
+	})
+
+	t.Run("UpsertNeedsDedupeKey", func(t *testing.T) {
+		transferService := services.NewTransferService(new(MockBlogRepository), new(nopUOW), new(MockOutbox))
+
+		_, err := transferService.ImportBlogs(ctx, nil, domain.ImportOptions{Upsert: true})
+
+		assert.Error(t, err)
+	})
+}
+
+func TestExportBlogs(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockBlogRepository)
+	transferService := services.NewTransferService(mockRepo, new(nopUOW), new(MockOutbox))
+	filter := domain.BlogFilter{Author: "alice"}
+
+	mockRepo.On("ListAfter", ctx, filter, uint(0), mock.Anything).Return([]*domain.Blog{{ID: 2, Author: "alice"}}, nil).Once()
+
+	var exported []*domain.Blog
+	err := transferService.ExportBlogs(ctx, filter, func(blogs []*domain.Blog) error {
+		exported = append(exported, blogs...)
+		return nil
+	})
+
+	require.NoError(t, err)
+	assert.Len(t, exported, 1)
+	mockRepo.AssertExpectations(t)
+}
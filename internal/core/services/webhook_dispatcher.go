@@ -0,0 +1,237 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/toffysoft/go-hexagonal-example/internal/core/domain"
+	"github.com/toffysoft/go-hexagonal-example/internal/core/ports"
+	"github.com/toffysoft/go-hexagonal-example/pkg/errors"
+)
+
+// DispatcherConfig tunes webhook delivery.
+type DispatcherConfig struct {
+	PollInterval time.Duration
+	BatchSize    int
+	// MaxAttempts is the number of requests made for a delivery before it is
+	// marked failed.
+	MaxAttempts     int
+	RetryBackoff    time.Duration
+	MaxRetryBackoff time.Duration
+	// DisableAfter disables a webhook after that many consecutive failed
+	// requests. Zero never disables.
+	DisableAfter int
+	// Lease is how long a batch of deliveries stays claimed. A dispatcher
+	// that stops before recording the result of a delivery leaves it to be
+	// sent again once the lease runs out.
+	Lease time.Duration
+}
+
+// WebhookDispatcher turns domain events into webhook deliveries and sends them.
+type WebhookDispatcher struct {
+	repo   ports.WebhookRepository
+	sender ports.WebhookSender
+	uow    ports.UnitOfWork
+	cfg    DispatcherConfig
+	now    func() time.Time
+}
+
+func NewWebhookDispatcher(repo ports.WebhookRepository, sender ports.WebhookSender, uow ports.UnitOfWork, cfg DispatcherConfig) *WebhookDispatcher {
+	return &WebhookDispatcher{
+		repo:   repo,
+		sender: sender,
+		uow:    uow,
+		cfg:    cfg,
+		now:    time.Now,
+	}
+}
+
+// HandleEvent queues a delivery of event for every enabled webhook subscribed
+// to its type. It is meant to be subscribed to the event bus.
+func (d *WebhookDispatcher) HandleEvent(ctx context.Context, event domain.Event) error {
+	webhooks, err := d.repo.List(ctx)
+	if err != nil {
+		return err
+	}
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	var deliveries []*domain.WebhookDelivery
+	for _, webhook := range webhooks {
+		if webhook.Enabled && webhook.Subscribed(event.Type) {
+			deliveries = append(deliveries, newDelivery(webhook.ID, event.ID, event.Type, payload, d.now()))
+		}
+	}
+	if len(deliveries) == 0 {
+		return nil
+	}
+	return d.repo.CreateDeliveries(ctx, deliveries)
+}
+
+// Run sends due deliveries until ctx is cancelled.
+func (d *WebhookDispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.cfg.PollInterval)
+	defer ticker.Stop()
+	for {
+		for ctx.Err() == nil {
+			n, err := d.DispatchOnce(ctx)
+			if err != nil {
+				slog.Error("webhook dispatch failed", "error", err)
+				break
+			}
+			if n < d.cfg.BatchSize {
+				break
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// DispatchOnce claims one batch of due deliveries and makes one attempt for
+// each. It returns the number of deliveries attempted.
+//
+// No transaction is open while requests are sent: the batch is claimed by
+// moving its next attempt past the lease, and the result of each attempt is
+// recorded on its own.
+func (d *WebhookDispatcher) DispatchOnce(ctx context.Context) (int, error) {
+	deliveries, err := d.claim(ctx)
+	if err != nil {
+		return 0, err
+	}
+	var errs []error
+	for _, delivery := range deliveries {
+		if err := d.attempt(ctx, delivery); err != nil {
+			errs = append(errs, fmt.Errorf("delivery %d: %w", delivery.ID, err))
+		}
+	}
+	return len(deliveries), stderrors.Join(errs...)
+}
+
+// claim leases the due deliveries to this dispatcher.
+func (d *WebhookDispatcher) claim(ctx context.Context) ([]*domain.WebhookDelivery, error) {
+	var deliveries []*domain.WebhookDelivery
+	err := d.uow.Do(ctx, func(ctx context.Context) error {
+		now := d.now()
+		due, err := d.repo.DueDeliveries(ctx, now, d.cfg.BatchSize)
+		if err != nil {
+			return err
+		}
+		for _, delivery := range due {
+			delivery.NextAttemptAt = now.Add(d.cfg.Lease)
+			if err := d.repo.UpdateDelivery(ctx, delivery); err != nil {
+				return err
+			}
+		}
+		deliveries = due
+		return nil
+	})
+	return deliveries, err
+}
+
+func (d *WebhookDispatcher) attempt(ctx context.Context, delivery *domain.WebhookDelivery) error {
+	webhook, err := d.repo.GetByID(ctx, delivery.WebhookID)
+	if err != nil && !isNotFound(err) {
+		// The delivery is sent again once its lease runs out.
+		return err
+	}
+	if err != nil || !webhook.Enabled {
+		// The webhook was deleted or disabled after the delivery was queued.
+		delivery.Status = domain.DeliveryFailed
+		return d.repo.UpdateDelivery(ctx, delivery)
+	}
+
+	start := d.now()
+	statusCode, sendErr := d.sender.Send(ctx, webhook, delivery)
+	delivery.Attempts++
+	attempt := &domain.WebhookAttempt{
+		DeliveryID: delivery.ID,
+		Attempt:    delivery.Attempts,
+		StatusCode: statusCode,
+		Duration:   d.now().Sub(start),
+	}
+	if sendErr == nil && (statusCode < 200 || statusCode > 299) {
+		sendErr = fmt.Errorf("unexpected status code %d", statusCode)
+	}
+	if sendErr == nil {
+		delivery.Status = domain.DeliverySucceeded
+	} else {
+		attempt.Error = sendErr.Error()
+		if delivery.Attempts >= d.cfg.MaxAttempts {
+			delivery.Status = domain.DeliveryFailed
+		} else {
+			delivery.NextAttemptAt = d.now().Add(backoff(d.cfg.RetryBackoff, d.cfg.MaxRetryBackoff, delivery.Attempts))
+		}
+	}
+
+	return d.uow.Do(ctx, func(ctx context.Context) error {
+		if err := d.repo.AddAttempt(ctx, attempt); err != nil {
+			return err
+		}
+		if err := d.repo.UpdateDelivery(ctx, delivery); err != nil {
+			return err
+		}
+		return d.recordResult(ctx, webhook, sendErr)
+	})
+}
+
+// recordResult updates the failure count of webhook after a request, reading
+// it again so that concurrent dispatchers count every failure.
+func (d *WebhookDispatcher) recordResult(ctx context.Context, webhook *domain.Webhook, sendErr error) error {
+	current, err := d.repo.GetByID(ctx, webhook.ID)
+	if isNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	*webhook = *current
+	if sendErr != nil {
+		return d.recordFailure(ctx, webhook, sendErr)
+	}
+	if webhook.ConsecutiveFailures == 0 {
+		return nil
+	}
+	webhook.ConsecutiveFailures = 0
+	return d.repo.Update(ctx, webhook)
+}
+
+// recordFailure counts a failed request against webhook and disables it once
+// it reaches the configured limit.
+func (d *WebhookDispatcher) recordFailure(ctx context.Context, webhook *domain.Webhook, cause error) error {
+	webhook.ConsecutiveFailures++
+	if d.cfg.DisableAfter > 0 && webhook.ConsecutiveFailures >= d.cfg.DisableAfter {
+		now := d.now()
+		webhook.Enabled = false
+		webhook.DisabledAt = &now
+		webhook.DisabledReason = fmt.Sprintf("%d consecutive failed deliveries, last: %v", webhook.ConsecutiveFailures, cause)
+		slog.Warn("webhook disabled", "webhook_id", webhook.ID, "url", webhook.URL, "reason", webhook.DisabledReason)
+	}
+	return d.repo.Update(ctx, webhook)
+}
+
+// newDelivery returns a pending delivery, due at now.
+func newDelivery(webhookID uint, eventID string, eventType domain.EventType, payload []byte, now time.Time) *domain.WebhookDelivery {
+	return &domain.WebhookDelivery{
+		WebhookID:     webhookID,
+		EventID:       eventID,
+		EventType:     eventType,
+		Payload:       payload,
+		Status:        domain.DeliveryPending,
+		NextAttemptAt: now,
+	}
+}
+
+func isNotFound(err error) bool {
+	var appErr errors.AppError
+	return stderrors.As(err, &appErr) && appErr.Type == errors.NotFound
+}
@@ -0,0 +1,164 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/netip"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/toffysoft/go-hexagonal-example/internal/core/domain"
+	"github.com/toffysoft/go-hexagonal-example/internal/core/ports"
+	"github.com/toffysoft/go-hexagonal-example/pkg/errors"
+)
+
+// deliveryHistory is the number of deliveries listed per webhook.
+const deliveryHistory = 100
+
+var eventTypes = map[domain.EventType]bool{
+	domain.BlogCreated: true,
+	domain.BlogUpdated: true,
+	domain.BlogDeleted: true,
+}
+
+type webhookService struct {
+	repo ports.WebhookRepository
+	uow  ports.UnitOfWork
+}
+
+func NewWebhookService(repo ports.WebhookRepository, uow ports.UnitOfWork) ports.WebhookService {
+	return &webhookService{repo: repo, uow: uow}
+}
+
+// CreateWebhook registers an enabled webhook. A secret is generated when none
+// is given; it is left on webhook so it can be shown to the caller once.
+func (s *webhookService) CreateWebhook(ctx context.Context, webhook *domain.Webhook) error {
+	if webhook.Secret == "" {
+		secret, err := generateSecret()
+		if err != nil {
+			return errors.NewInternalServerError("Failed to generate webhook secret")
+		}
+		webhook.Secret = secret
+	}
+	if err := validateWebhook(webhook); err != nil {
+		return err
+	}
+	webhook.Enabled = true
+	return s.repo.Create(ctx, webhook)
+}
+
+func (s *webhookService) GetWebhook(ctx context.Context, id uint) (*domain.Webhook, error) {
+	webhook, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, errors.NewNotFoundError(fmt.Sprintf("Webhook with ID %d not found", id))
+	}
+	return webhook, nil
+}
+
+// UpdateWebhook saves webhook. Enabling a webhook clears its failure count and
+// the reason it was disabled.
+func (s *webhookService) UpdateWebhook(ctx context.Context, webhook *domain.Webhook) error {
+	if webhook.ID == 0 {
+		return errors.NewInvalidInputError("Webhook ID is required")
+	}
+	if err := validateWebhook(webhook); err != nil {
+		return err
+	}
+	return s.uow.Do(ports.WithStrongConsistency(ctx), func(ctx context.Context) error {
+		current, err := s.GetWebhook(ctx, webhook.ID)
+		if err != nil {
+			return err
+		}
+		if webhook.Enabled && !current.Enabled {
+			webhook.ConsecutiveFailures = 0
+			webhook.DisabledAt = nil
+			webhook.DisabledReason = ""
+		}
+		return s.repo.Update(ctx, webhook)
+	})
+}
+
+func (s *webhookService) DeleteWebhook(ctx context.Context, id uint) error {
+	return s.uow.Do(ports.WithStrongConsistency(ctx), func(ctx context.Context) error {
+		if _, err := s.GetWebhook(ctx, id); err != nil {
+			return err
+		}
+		return s.repo.Delete(ctx, id)
+	})
+}
+
+func (s *webhookService) ListWebhooks(ctx context.Context) ([]*domain.Webhook, error) {
+	return s.repo.List(ctx)
+}
+
+func (s *webhookService) ListDeliveries(ctx context.Context, webhookID uint) ([]*domain.WebhookDelivery, error) {
+	if _, err := s.GetWebhook(ctx, webhookID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListDeliveries(ctx, webhookID, deliveryHistory)
+}
+
+func (s *webhookService) GetDelivery(ctx context.Context, webhookID, deliveryID uint) (*domain.WebhookDelivery, []*domain.WebhookAttempt, error) {
+	delivery, err := s.getDelivery(ctx, webhookID, deliveryID)
+	if err != nil {
+		return nil, nil, err
+	}
+	attempts, err := s.repo.ListAttempts(ctx, deliveryID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return delivery, attempts, nil
+}
+
+func (s *webhookService) Redeliver(ctx context.Context, webhookID, deliveryID uint) (*domain.WebhookDelivery, error) {
+	delivery, err := s.getDelivery(ctx, webhookID, deliveryID)
+	if err != nil {
+		return nil, err
+	}
+	redelivery := newDelivery(webhookID, delivery.EventID, delivery.EventType, delivery.Payload, time.Now())
+	if err := s.repo.CreateDeliveries(ctx, []*domain.WebhookDelivery{redelivery}); err != nil {
+		return nil, err
+	}
+	return redelivery, nil
+}
+
+func (s *webhookService) getDelivery(ctx context.Context, webhookID, deliveryID uint) (*domain.WebhookDelivery, error) {
+	delivery, err := s.repo.GetDelivery(ctx, deliveryID)
+	if err != nil || delivery.WebhookID != webhookID {
+		return nil, errors.NewNotFoundError(fmt.Sprintf("Delivery with ID %d not found", deliveryID))
+	}
+	return delivery, nil
+}
+
+func validateWebhook(webhook *domain.Webhook) error {
+	u, err := url.Parse(webhook.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.NewInvalidInputError("Webhook URL must be an absolute http or https URL")
+	}
+	// Names are checked again when the sender dials, once they are resolved.
+	host := strings.ToLower(strings.TrimSuffix(u.Hostname(), "."))
+	addr, err := netip.ParseAddr(host)
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") || (err == nil && !domain.PublicWebhookAddr(addr)) {
+		return errors.NewInvalidInputError("Webhook URL must not point to a loopback, private or link-local address")
+	}
+	if len(webhook.Secret) < 16 {
+		return errors.NewInvalidInputError("Webhook secret must be at least 16 characters long")
+	}
+	for _, t := range webhook.Events {
+		if !eventTypes[t] {
+			return errors.NewInvalidInputError(fmt.Sprintf("Unknown event type %q", t))
+		}
+	}
+	return nil
+}
+
+func generateSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
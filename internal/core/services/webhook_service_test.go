@@ -0,0 +1,366 @@
+package services_test
+
+import (
+	"context"
+	stderrors "errors"
+	"testing"
+	"time"
+
+	"github.com/toffysoft/go-hexagonal-example/internal/core/domain"
+	"github.com/toffysoft/go-hexagonal-example/internal/core/services"
+	"github.com/toffysoft/go-hexagonal-example/pkg/errors"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockWebhookRepository struct {
+	mock.Mock
+}
+
+func (m *MockWebhookRepository) Create(ctx context.Context, webhook *domain.Webhook) error {
+	args := m.Called(ctx, webhook)
+	return args.Error(0)
+}
+
+func (m *MockWebhookRepository) GetByID(ctx context.Context, id uint) (*domain.Webhook, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(*domain.Webhook), args.Error(1)
+}
+
+func (m *MockWebhookRepository) Update(ctx context.Context, webhook *domain.Webhook) error {
+	args := m.Called(ctx, webhook)
+	return args.Error(0)
+}
+
+func (m *MockWebhookRepository) Delete(ctx context.Context, id uint) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockWebhookRepository) List(ctx context.Context) ([]*domain.Webhook, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]*domain.Webhook), args.Error(1)
+}
+
+func (m *MockWebhookRepository) CreateDeliveries(ctx context.Context, deliveries []*domain.WebhookDelivery) error {
+	args := m.Called(ctx, deliveries)
+	return args.Error(0)
+}
+
+func (m *MockWebhookRepository) GetDelivery(ctx context.Context, id uint) (*domain.WebhookDelivery, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(*domain.WebhookDelivery), args.Error(1)
+}
+
+func (m *MockWebhookRepository) UpdateDelivery(ctx context.Context, delivery *domain.WebhookDelivery) error {
+	args := m.Called(ctx, delivery)
+	return args.Error(0)
+}
+
+func (m *MockWebhookRepository) ListDeliveries(ctx context.Context, webhookID uint, limit int) ([]*domain.WebhookDelivery, error) {
+	args := m.Called(ctx, webhookID, limit)
+	return args.Get(0).([]*domain.WebhookDelivery), args.Error(1)
+}
+
+func (m *MockWebhookRepository) DueDeliveries(ctx context.Context, now time.Time, limit int) ([]*domain.WebhookDelivery, error) {
+	args := m.Called(ctx, now, limit)
+	return args.Get(0).([]*domain.WebhookDelivery), args.Error(1)
+}
+
+func (m *MockWebhookRepository) AddAttempt(ctx context.Context, attempt *domain.WebhookAttempt) error {
+	args := m.Called(ctx, attempt)
+	return args.Error(0)
+}
+
+func (m *MockWebhookRepository) ListAttempts(ctx context.Context, deliveryID uint) ([]*domain.WebhookAttempt, error) {
+	args := m.Called(ctx, deliveryID)
+	return args.Get(0).([]*domain.WebhookAttempt), args.Error(1)
+}
+
+type MockWebhookSender struct {
+	mock.Mock
+}
+
+func (m *MockWebhookSender) Send(ctx context.Context, webhook *domain.Webhook, delivery *domain.WebhookDelivery) (int, error) {
+	args := m.Called(ctx, webhook, delivery)
+	return args.Int(0), args.Error(1)
+}
+
+func TestCreateWebhook(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockWebhookRepository)
+	webhookService := services.NewWebhookService(mockRepo, new(nopUOW))
+
+	t.Run("GeneratesSecret", func(t *testing.T) {
+		webhook := &domain.Webhook{URL: "https://partner.example.com/hook", Events: []domain.EventType{domain.BlogCreated}}
+		mockRepo.On("Create", ctx, webhook).Return(nil).Once()
+
+		err := webhookService.CreateWebhook(ctx, webhook)
+
+		assert.NoError(t, err)
+		assert.Len(t, webhook.Secret, 64)
+		assert.True(t, webhook.Enabled)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("InvalidInput", func(t *testing.T) {
+		for _, webhook := range []*domain.Webhook{
+			{URL: "ftp://partner.example.com/hook"},
+			{URL: "http://169.254.169.254/latest/meta-data"},
+			{URL: "http://localhost:8080/hook"},
+			{URL: "http://127.0.0.1/hook"},
+			{URL: "http://10.1.2.3/hook"},
+			{URL: "http://[::1]/hook"},
+			{URL: "http://[::ffff:192.168.0.1]/hook"},
+			{URL: "http://0.0.0.0/hook"},
+			{URL: "https://partner.example.com/hook", Secret: "short"},
+			{URL: "https://partner.example.com/hook", Events: []domain.EventType{"blog.renamed"}},
+		} {
+			err := webhookService.CreateWebhook(ctx, webhook)
+
+			assert.Error(t, err)
+			assert.Equal(t, errors.InvalidInput, err.(errors.AppError).Type)
+		}
+	})
+}
+
+func TestUpdateWebhookReenables(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockWebhookRepository)
+	webhookService := services.NewWebhookService(mockRepo, new(nopUOW))
+
+	disabledAt := time.Now()
+	mockRepo.On("GetByID", primary, uint(1)).Return(&domain.Webhook{ID: 1, Enabled: false}, nil).Once()
+	mockRepo.On("Update", primary, mock.Anything).Return(nil).Once()
+
+	webhook := &domain.Webhook{
+		ID: 1, URL: "https://partner.example.com/hook", Secret: "0123456789abcdef", Enabled: true,
+		ConsecutiveFailures: 50, DisabledAt: &disabledAt, DisabledReason: "50 consecutive failed deliveries",
+	}
+	err := webhookService.UpdateWebhook(ctx, webhook)
+
+	assert.NoError(t, err)
+	assert.Zero(t, webhook.ConsecutiveFailures)
+	assert.Nil(t, webhook.DisabledAt)
+	assert.Empty(t, webhook.DisabledReason)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestRedeliver(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockWebhookRepository)
+	webhookService := services.NewWebhookService(mockRepo, new(nopUOW))
+
+	t.Run("QueuesNewDelivery", func(t *testing.T) {
+		original := &domain.WebhookDelivery{ID: 5, WebhookID: 1, EventID: "event-1", EventType: domain.BlogCreated, Payload: []byte(`{}`), Status: domain.DeliveryFailed, Attempts: 8}
+		mockRepo.On("GetDelivery", ctx, uint(5)).Return(original, nil).Once()
+		mockRepo.On("CreateDeliveries", ctx, mock.MatchedBy(func(deliveries []*domain.WebhookDelivery) bool {
+			d := deliveries[0]
+			return len(deliveries) == 1 && d.EventID == "event-1" && d.Status == domain.DeliveryPending && d.Attempts == 0
+		})).Return(nil).Once()
+
+		delivery, err := webhookService.Redeliver(ctx, 1, 5)
+
+		assert.NoError(t, err)
+		assert.Equal(t, uint(1), delivery.WebhookID)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("OtherWebhook", func(t *testing.T) {
+		mockRepo.On("GetDelivery", ctx, uint(6)).Return(&domain.WebhookDelivery{ID: 6, WebhookID: 2}, nil).Once()
+
+		_, err := webhookService.Redeliver(ctx, 1, 6)
+
+		assert.Error(t, err)
+		assert.Equal(t, errors.NotFound, err.(errors.AppError).Type)
+	})
+}
+
+func TestWebhookDispatcher(t *testing.T) {
+	ctx := context.Background()
+	cfg := services.DispatcherConfig{
+		PollInterval:    time.Second,
+		BatchSize:       10,
+		MaxAttempts:     3,
+		RetryBackoff:    time.Second,
+		MaxRetryBackoff: time.Minute,
+		DisableAfter:    5,
+		Lease:           time.Minute,
+	}
+	newWebhook := func(failures int) *domain.Webhook {
+		return &domain.Webhook{ID: 1, URL: "https://partner.example.com/hook", Secret: "0123456789abcdef", Enabled: true, ConsecutiveFailures: failures}
+	}
+	newPending := func(attempts int) *domain.WebhookDelivery {
+		return &domain.WebhookDelivery{ID: 7, WebhookID: 1, EventType: domain.BlogCreated, Status: domain.DeliveryPending, Attempts: attempts}
+	}
+
+	t.Run("HandleEventFansOutToSubscribedWebhooks", func(t *testing.T) {
+		mockRepo := new(MockWebhookRepository)
+		dispatcher := services.NewWebhookDispatcher(mockRepo, new(MockWebhookSender), new(nopUOW), cfg)
+
+		mockRepo.On("List", ctx).Return([]*domain.Webhook{
+			{ID: 1, Enabled: true},
+			{ID: 2, Enabled: true, Events: []domain.EventType{domain.BlogDeleted}},
+			{ID: 3, Enabled: false},
+			{ID: 4, Enabled: true, Events: []domain.EventType{domain.BlogCreated}},
+		}, nil).Once()
+		mockRepo.On("CreateDeliveries", ctx, mock.MatchedBy(func(deliveries []*domain.WebhookDelivery) bool {
+			return len(deliveries) == 2 && deliveries[0].WebhookID == 1 && deliveries[1].WebhookID == 4
+		})).Return(nil).Once()
+
+		err := dispatcher.HandleEvent(ctx, domain.NewBlogCreatedEvent(&domain.Blog{ID: 1}))
+
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("SuccessResetsFailures", func(t *testing.T) {
+		mockRepo := new(MockWebhookRepository)
+		mockSender := new(MockWebhookSender)
+		dispatcher := services.NewWebhookDispatcher(mockRepo, mockSender, new(nopUOW), cfg)
+
+		webhook, delivery := newWebhook(2), newPending(1)
+		mockRepo.On("DueDeliveries", ctx, mock.Anything, 10).Return([]*domain.WebhookDelivery{delivery}, nil).Once()
+		mockRepo.On("GetByID", ctx, uint(1)).Return(webhook, nil).Twice()
+		mockSender.On("Send", ctx, webhook, delivery).Return(200, nil).Once()
+		mockRepo.On("Update", ctx, webhook).Return(nil).Once()
+		mockRepo.On("AddAttempt", ctx, mock.MatchedBy(func(a *domain.WebhookAttempt) bool {
+			return a.Attempt == 2 && a.StatusCode == 200 && a.Error == ""
+		})).Return(nil).Once()
+		// Once to claim it, and once to record the result.
+		mockRepo.On("UpdateDelivery", ctx, delivery).Return(nil).Twice()
+
+		n, err := dispatcher.DispatchOnce(ctx)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, n)
+		assert.Equal(t, domain.DeliverySucceeded, delivery.Status)
+		assert.Zero(t, webhook.ConsecutiveFailures)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("FailureSchedulesRetry", func(t *testing.T) {
+		mockRepo := new(MockWebhookRepository)
+		mockSender := new(MockWebhookSender)
+		dispatcher := services.NewWebhookDispatcher(mockRepo, mockSender, new(nopUOW), cfg)
+
+		webhook, delivery := newWebhook(0), newPending(1)
+		start := time.Now()
+		mockRepo.On("DueDeliveries", ctx, mock.Anything, 10).Return([]*domain.WebhookDelivery{delivery}, nil).Once()
+		mockRepo.On("GetByID", ctx, uint(1)).Return(webhook, nil).Twice()
+		mockSender.On("Send", ctx, webhook, delivery).Return(503, nil).Once()
+		mockRepo.On("Update", ctx, webhook).Return(nil).Once()
+		mockRepo.On("AddAttempt", ctx, mock.MatchedBy(func(a *domain.WebhookAttempt) bool {
+			return a.StatusCode == 503 && a.Error == "unexpected status code 503"
+		})).Return(nil).Once()
+		mockRepo.On("UpdateDelivery", ctx, delivery).Return(nil).Twice()
+
+		_, err := dispatcher.DispatchOnce(ctx)
+
+		assert.NoError(t, err)
+		assert.Equal(t, domain.DeliveryPending, delivery.Status)
+		assert.Equal(t, 2, delivery.Attempts)
+		assert.WithinDuration(t, start.Add(2*time.Second), delivery.NextAttemptAt, time.Second)
+		assert.Equal(t, 1, webhook.ConsecutiveFailures)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("LastAttemptFailsDeliveryAndDisablesWebhook", func(t *testing.T) {
+		mockRepo := new(MockWebhookRepository)
+		mockSender := new(MockWebhookSender)
+		dispatcher := services.NewWebhookDispatcher(mockRepo, mockSender, new(nopUOW), cfg)
+
+		webhook, delivery := newWebhook(4), newPending(2)
+		mockRepo.On("DueDeliveries", ctx, mock.Anything, 10).Return([]*domain.WebhookDelivery{delivery}, nil).Once()
+		mockRepo.On("GetByID", ctx, uint(1)).Return(webhook, nil).Twice()
+		mockSender.On("Send", ctx, webhook, delivery).Return(0, stderrors.New("connection refused")).Once()
+		mockRepo.On("Update", ctx, webhook).Return(nil).Once()
+		mockRepo.On("AddAttempt", ctx, mock.Anything).Return(nil).Once()
+		mockRepo.On("UpdateDelivery", ctx, delivery).Return(nil).Twice()
+
+		_, err := dispatcher.DispatchOnce(ctx)
+
+		assert.NoError(t, err)
+		assert.Equal(t, domain.DeliveryFailed, delivery.Status)
+		assert.False(t, webhook.Enabled)
+		assert.NotNil(t, webhook.DisabledAt)
+		assert.Contains(t, webhook.DisabledReason, "connection refused")
+		mockRepo.AssertExpectations(t)
+	})
+	t.Run("SendsOutsideUnitsOfWork", func(t *testing.T) {
+		mockRepo := new(MockWebhookRepository)
+		mockSender := new(MockWebhookSender)
+		uow := new(trackingUOW)
+		dispatcher := services.NewWebhookDispatcher(mockRepo, mockSender, uow, cfg)
+
+		webhook, delivery := newWebhook(0), newPending(0)
+		start := time.Now()
+		mockRepo.On("DueDeliveries", ctx, mock.Anything, 10).Return([]*domain.WebhookDelivery{delivery}, nil).Once()
+		mockRepo.On("UpdateDelivery", ctx, delivery).Run(func(mock.Arguments) {
+			assert.True(t, uow.open)
+		}).Return(nil).Twice()
+		mockRepo.On("GetByID", ctx, uint(1)).Return(webhook, nil).Twice()
+		mockSender.On("Send", ctx, webhook, delivery).Run(func(mock.Arguments) {
+			assert.False(t, uow.open)
+			// The delivery is leased while it is being sent.
+			assert.WithinDuration(t, start.Add(time.Minute), delivery.NextAttemptAt, time.Second)
+		}).Return(204, nil).Once()
+		mockRepo.On("AddAttempt", ctx, mock.Anything).Return(nil).Once()
+
+		_, err := dispatcher.DispatchOnce(ctx)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 2, uow.calls)
+		mockRepo.AssertExpectations(t)
+		mockSender.AssertExpectations(t)
+	})
+
+	t.Run("DeletedWebhookFailsDelivery", func(t *testing.T) {
+		mockRepo := new(MockWebhookRepository)
+		dispatcher := services.NewWebhookDispatcher(mockRepo, new(MockWebhookSender), new(nopUOW), cfg)
+
+		delivery := newPending(0)
+		mockRepo.On("DueDeliveries", ctx, mock.Anything, 10).Return([]*domain.WebhookDelivery{delivery}, nil).Once()
+		mockRepo.On("UpdateDelivery", ctx, delivery).Return(nil).Twice()
+		mockRepo.On("GetByID", ctx, uint(1)).Return((*domain.Webhook)(nil), errors.NewNotFoundError("Webhook with ID 1 not found")).Once()
+
+		_, err := dispatcher.DispatchOnce(ctx)
+
+		assert.NoError(t, err)
+		assert.Equal(t, domain.DeliveryFailed, delivery.Status)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("LookupErrorLeavesDeliveryLeased", func(t *testing.T) {
+		mockRepo := new(MockWebhookRepository)
+		dispatcher := services.NewWebhookDispatcher(mockRepo, new(MockWebhookSender), new(nopUOW), cfg)
+
+		delivery := newPending(0)
+		start := time.Now()
+		mockRepo.On("DueDeliveries", ctx, mock.Anything, 10).Return([]*domain.WebhookDelivery{delivery}, nil).Once()
+		mockRepo.On("UpdateDelivery", ctx, delivery).Return(nil).Once()
+		mockRepo.On("GetByID", ctx, uint(1)).Return((*domain.Webhook)(nil), stderrors.New("connection reset")).Once()
+
+		n, err := dispatcher.DispatchOnce(ctx)
+
+		assert.ErrorContains(t, err, "connection reset")
+		assert.Equal(t, 1, n)
+		assert.Equal(t, domain.DeliveryPending, delivery.Status)
+		assert.WithinDuration(t, start.Add(time.Minute), delivery.NextAttemptAt, time.Second)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+// trackingUOW records whether a unit of work is open.
+type trackingUOW struct {
+	open  bool
+	calls int
+}
+
+func (u *trackingUOW) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	u.open = true
+	u.calls++
+	defer func() { u.open = false }()
+	return fn(ctx)
+}
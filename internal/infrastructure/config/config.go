@@ -1,27 +1,425 @@
 package config
 
-import (
-	"github.com/spf13/viper"
-)
+import "time"
 
+// Config is the complete application configuration. Every leaf field is
+// addressable by its dotted mapstructure key (e.g. "http.address"), by the
+// matching upper-case environment variable (HTTP_ADDRESS) and by a CLI flag
+// (--http.address). An optional `env` tag lists legacy variable names that
+// are still honoured. Fields tagged `secret:"true"` are redacted when printed.
+// Fields or sections tagged `reload:"true"` are applied by Store.Reload at
+// runtime; changes to any other field only take effect after a restart. A
+// field tagged `reload:"false"` opts out of its section's tag.
 type Config struct {
-	DBDriver      string `mapstructure:"DB_DRIVER"`
-	DBSource      string `mapstructure:"DB_SOURCE"`
-	ServerAddress string `mapstructure:"SERVER_ADDRESS"`
+	Service     ServiceConfig     `mapstructure:"service"`
+	HTTP        HTTPConfig        `mapstructure:"http"`
+	GRPC        GRPCConfig        `mapstructure:"grpc"`
+	Database    DatabaseConfig    `mapstructure:"database"`
+	Log         LogConfig         `mapstructure:"log"`
+	Auth        AuthConfig        `mapstructure:"auth"`
+	CORS        CORSConfig        `mapstructure:"cors" reload:"true"`
+	RateLimit   RateLimitConfig   `mapstructure:"rate_limit" reload:"true"`
+	Maintenance MaintenanceConfig `mapstructure:"maintenance" reload:"true"`
+	Tracing     TracingConfig     `mapstructure:"tracing"`
+	Health      HealthConfig      `mapstructure:"health"`
+	Outbox      OutboxConfig      `mapstructure:"outbox"`
+	EventBus    EventBusConfig    `mapstructure:"event_bus"`
+	Webhooks    WebhooksConfig    `mapstructure:"webhooks"`
+	Stream      StreamConfig      `mapstructure:"stream"`
+	Batch       BatchConfig       `mapstructure:"batch"`
+	Import      ImportConfig      `mapstructure:"import"`
+	Content     ContentConfig     `mapstructure:"content"`
+	Attachments AttachmentsConfig `mapstructure:"attachments"`
+	Feeds       FeedsConfig       `mapstructure:"feeds"`
+	Idempotency IdempotencyConfig `mapstructure:"idempotency"`
+	Cache       CacheConfig       `mapstructure:"cache"`
+	HTTPCache   HTTPCacheConfig   `mapstructure:"http_cache" reload:"true"`
+	Features    FeatureFlags      `mapstructure:"features"`
 }
 
-func LoadConfig() (config Config, err error) {
-	viper.AddConfigPath(".")
-	viper.SetConfigName("app")
-	viper.SetConfigType("env")
+type ServiceConfig struct {
+	Name        string `mapstructure:"name"`
+	Environment string `mapstructure:"environment"`
+	// ShutdownTimeout bounds how long in-flight requests and streams are
+	// given to finish after SIGINT or SIGTERM.
+	ShutdownTimeout time.Duration `mapstructure:"shutdown_timeout"`
+}
 
-	viper.AutomaticEnv()
+type HTTPConfig struct {
+	Address      string        `mapstructure:"address" env:"SERVER_ADDRESS"`
+	ReadTimeout  time.Duration `mapstructure:"read_timeout"`
+	WriteTimeout time.Duration `mapstructure:"write_timeout"`
+	IdleTimeout  time.Duration `mapstructure:"idle_timeout"`
+	BodyLimit    int           `mapstructure:"body_limit"`
+}
 
-	err = viper.ReadInConfig()
-	if err != nil {
-		return
-	}
+type GRPCConfig struct {
+	Address        string `mapstructure:"address"`
+	MaxRecvMsgSize int    `mapstructure:"max_recv_msg_size"`
+	MaxSendMsgSize int    `mapstructure:"max_send_msg_size"`
+}
+
+type DatabaseConfig struct {
+	Driver            string        `mapstructure:"driver" env:"DB_DRIVER"`
+	Source            string        `mapstructure:"source" env:"DB_SOURCE" secret:"true"`
+	Replicas          []string      `mapstructure:"replicas" secret:"true"`
+	MaxOpenConns      int           `mapstructure:"max_open_conns"`
+	MaxIdleConns      int           `mapstructure:"max_idle_conns"`
+	ConnMaxLifetime   time.Duration `mapstructure:"conn_max_lifetime"`
+	ConnMaxIdleTime   time.Duration `mapstructure:"conn_max_idle_time"`
+	ConnectRetries    int           `mapstructure:"connect_retries"`
+	ConnectBackoff    time.Duration `mapstructure:"connect_backoff"`
+	ConnectMaxBackoff time.Duration `mapstructure:"connect_max_backoff"`
+}
+
+type LogConfig struct {
+	Level  string `mapstructure:"level" reload:"true"`
+	Format string `mapstructure:"format"`
+}
+
+// AuthConfig controls API key authentication. Admin keys can call every
+// endpoint; the /api/v1/admin endpoints accept only them.
+type AuthConfig struct {
+	Enabled      bool     `mapstructure:"enabled"`
+	APIKeys      []string `mapstructure:"api_keys" secret:"true"`
+	AdminAPIKeys []string `mapstructure:"admin_api_keys" secret:"true"`
+}
+
+// CORSConfig sets the CORS headers. ExposeHeaders lists the response
+// headers, beyond the safelisted ones, that browser clients may read.
+type CORSConfig struct {
+	AllowOrigins     []string `mapstructure:"allow_origins"`
+	AllowMethods     []string `mapstructure:"allow_methods"`
+	AllowHeaders     []string `mapstructure:"allow_headers"`
+	ExposeHeaders    []string `mapstructure:"expose_headers"`
+	AllowCredentials bool     `mapstructure:"allow_credentials"`
+	MaxAge           int      `mapstructure:"max_age"`
+}
+
+// RateLimitConfig limits requests with token buckets. Policies are tried in
+// order, e.g. "POST /api/v1/blogs 10/1m burst=20 by=api_key" or
+// "/blog.BlogService/ 50/1m by=ip"; requests matching none share the default
+// policy of Max tokens per Window, up to Burst (Max if zero), per Identity.
+// Store is "memory" for limits per instance or "postgres" for limits shared
+// by every instance.
+type RateLimitConfig struct {
+	Enabled         bool          `mapstructure:"enabled"`
+	Store           string        `mapstructure:"store" reload:"false"`
+	Max             int           `mapstructure:"max"`
+	Window          time.Duration `mapstructure:"window"`
+	Burst           int           `mapstructure:"burst"`
+	Identity        string        `mapstructure:"identity"`
+	UserHeader      string        `mapstructure:"user_header" reload:"false"`
+	Policies        []string      `mapstructure:"policies"`
+	CleanupInterval time.Duration `mapstructure:"cleanup_interval" reload:"false"`
+}
+
+// MaintenanceConfig puts the API into maintenance mode, rejecting requests with 503.
+type MaintenanceConfig struct {
+	Enabled bool   `mapstructure:"enabled"`
+	Message string `mapstructure:"message"`
+}
+
+type TracingConfig struct {
+	Exporter     string  `mapstructure:"exporter"`
+	OTLPEndpoint string  `mapstructure:"otlp_endpoint"`
+	OTLPInsecure bool    `mapstructure:"otlp_insecure"`
+	File         string  `mapstructure:"file"`
+	SampleRatio  float64 `mapstructure:"sample_ratio"`
+}
+
+type HealthConfig struct {
+	CheckTimeout  time.Duration `mapstructure:"check_timeout"`
+	CheckInterval time.Duration `mapstructure:"check_interval"`
+}
+
+// OutboxConfig controls the relay that publishes domain events stored in the
+// outbox table.
+type OutboxConfig struct {
+	RelayEnabled    bool          `mapstructure:"relay_enabled"`
+	PollInterval    time.Duration `mapstructure:"poll_interval"`
+	BatchSize       int           `mapstructure:"batch_size"`
+	MaxAttempts     int           `mapstructure:"max_attempts"`
+	RetryBackoff    time.Duration `mapstructure:"retry_backoff"`
+	MaxRetryBackoff time.Duration `mapstructure:"max_retry_backoff"`
+}
+
+// EventBusConfig selects how in-process subscribers receive domain events:
+// "sync" runs them in the relay, "async" on a pool of Workers fed by a queue
+// of QueueSize deliveries.
+type EventBusConfig struct {
+	Mode      string `mapstructure:"mode"`
+	Workers   int    `mapstructure:"workers"`
+	QueueSize int    `mapstructure:"queue_size"`
+}
 
-	err = viper.Unmarshal(&config)
-	return
+// WebhooksConfig controls delivery of blog events to registered webhooks.
+type WebhooksConfig struct {
+	Enabled         bool          `mapstructure:"enabled"`
+	Timeout         time.Duration `mapstructure:"timeout"`
+	PollInterval    time.Duration `mapstructure:"poll_interval"`
+	BatchSize       int           `mapstructure:"batch_size"`
+	MaxAttempts     int           `mapstructure:"max_attempts"`
+	RetryBackoff    time.Duration `mapstructure:"retry_backoff"`
+	MaxRetryBackoff time.Duration `mapstructure:"max_retry_backoff"`
+	DisableAfter    int           `mapstructure:"disable_after"`
+	// Lease is how long a batch of deliveries stays claimed by the instance
+	// sending it. Deliveries are sent one after another, so it must cover
+	// batch_size requests.
+	Lease time.Duration `mapstructure:"lease"`
+}
+
+// StreamConfig controls the live blog change feed. BufferSize changes are
+// kept for clients resuming after a disconnect; a client more than
+// SubscriberBuffer changes behind is disconnected. The feed reads the outbox
+// every PollInterval and waits up to GapTimeout for a missing outbox ID.
+type StreamConfig struct {
+	BufferSize       int           `mapstructure:"buffer_size"`
+	SubscriberBuffer int           `mapstructure:"subscriber_buffer"`
+	Heartbeat        time.Duration `mapstructure:"heartbeat"`
+	PollInterval     time.Duration `mapstructure:"poll_interval"`
+	GapTimeout       time.Duration `mapstructure:"gap_timeout"`
+}
+
+// BatchConfig limits the batch create, update and delete endpoints.
+type BatchConfig struct {
+	MaxItems int `mapstructure:"max_items"`
+}
+
+// ImportConfig limits bulk imports. A Markdown archive whose files unzip to
+// more than MaxUnzippedSize bytes is rejected.
+type ImportConfig struct {
+	MaxUnzippedSize int `mapstructure:"max_unzipped_size"`
+}
+
+// ContentConfig tells how the metadata of a blog is derived from its
+// content: the excerpt is at most ExcerptLength characters, and reading time
+// assumes WordsPerMinute.
+type ContentConfig struct {
+	ExcerptLength  int `mapstructure:"excerpt_length"`
+	WordsPerMinute int `mapstructure:"words_per_minute"`
+}
+
+// AttachmentsConfig controls files uploaded to blogs. Storage "local" keeps
+// them under Dir. Uploads of up to MaxSize bytes are accepted when the type
+// sniffed from their content is one of AllowedTypes, and images get a
+// thumbnail that fits in ThumbnailSize pixels square. Attachments of deleted
+// blogs are looked for every CleanupInterval.
+type AttachmentsConfig struct {
+	Enabled         bool          `mapstructure:"enabled"`
+	Storage         string        `mapstructure:"storage"`
+	Dir             string        `mapstructure:"dir"`
+	MaxSize         int           `mapstructure:"max_size"`
+	AllowedTypes    []string      `mapstructure:"allowed_types"`
+	ThumbnailSize   int           `mapstructure:"thumbnail_size"`
+	CleanupInterval time.Duration `mapstructure:"cleanup_interval"`
+}
+
+// FeedsConfig controls the RSS, Atom and JSON Feed endpoints. Feed links
+// are resolved against BaseURL, or the URL of each request when it is empty;
+// ItemPath is the link of a blog, naming it with {id} and optionally {slug}.
+// Feeds hold the Limit newest blogs, or up to MaxLimit when asked, with
+// their content as HTML when FullContent is set and their excerpt otherwise.
+type FeedsConfig struct {
+	Enabled      bool   `mapstructure:"enabled"`
+	Title        string `mapstructure:"title"`
+	Description  string `mapstructure:"description"`
+	BaseURL      string `mapstructure:"base_url"`
+	ItemPath     string `mapstructure:"item_path"`
+	Limit        int    `mapstructure:"limit"`
+	MaxLimit     int    `mapstructure:"max_limit"`
+	FullContent  bool   `mapstructure:"full_content"`
+	CacheControl string `mapstructure:"cache_control"`
+}
+
+// IdempotencyConfig controls replay of POST requests and gRPC create calls
+// sent with an idempotency key. Responses are replayed for TTL; a request
+// still in progress after LockTimeout is presumed lost and its key freed.
+// A repeat waits up to Wait for the first request before it is rejected.
+// Store is "postgres", shared by all instances, or "memory", per instance.
+type IdempotencyConfig struct {
+	Enabled         bool          `mapstructure:"enabled"`
+	Store           string        `mapstructure:"store"`
+	TTL             time.Duration `mapstructure:"ttl"`
+	LockTimeout     time.Duration `mapstructure:"lock_timeout"`
+	Wait            time.Duration `mapstructure:"wait"`
+	PollInterval    time.Duration `mapstructure:"poll_interval"`
+	CleanupInterval time.Duration `mapstructure:"cleanup_interval"`
+}
+
+// CacheConfig controls the in-process cache of blog reads by ID. Size blogs
+// are kept for up to TTL, and IDs that were not found for NegativeTTL.
+// RenderSize renderings of blog content are kept as well.
+type CacheConfig struct {
+	Enabled     bool          `mapstructure:"enabled"`
+	Size        int           `mapstructure:"size"`
+	TTL         time.Duration `mapstructure:"ttl"`
+	NegativeTTL time.Duration `mapstructure:"negative_ttl"`
+	RenderSize  int           `mapstructure:"render_size"`
+}
+
+// HTTPCacheConfig sets the Cache-Control header of blog reads, e.g.
+// "public, max-age=60", so that browsers and CDNs can keep them. An empty
+// value sends none.
+type HTTPCacheConfig struct {
+	GetBlog   string `mapstructure:"get_blog"`
+	ListBlogs string `mapstructure:"list_blogs"`
+}
+
+type FeatureFlags struct {
+	Metrics        bool `mapstructure:"metrics" reload:"true"`
+	GRPCReflection bool `mapstructure:"grpc_reflection"`
+}
+
+// Default returns the configuration used when no other source sets a value.
+func Default() Config {
+	return Config{
+		Service: ServiceConfig{
+			Name:            "go-hexagonal-example",
+			Environment:     "development",
+			ShutdownTimeout: 15 * time.Second,
+		},
+		HTTP: HTTPConfig{
+			Address:      ":8080",
+			ReadTimeout:  10 * time.Second,
+			WriteTimeout: 10 * time.Second,
+			IdleTimeout:  60 * time.Second,
+			BodyLimit:    4 * 1024 * 1024,
+		},
+		GRPC: GRPCConfig{
+			Address:        ":9090",
+			MaxRecvMsgSize: 4 * 1024 * 1024,
+			MaxSendMsgSize: 4 * 1024 * 1024,
+		},
+		Database: DatabaseConfig{
+			Driver:            "postgres",
+			MaxOpenConns:      25,
+			MaxIdleConns:      25,
+			ConnMaxLifetime:   30 * time.Minute,
+			ConnMaxIdleTime:   5 * time.Minute,
+			ConnectRetries:    10,
+			ConnectBackoff:    500 * time.Millisecond,
+			ConnectMaxBackoff: 30 * time.Second,
+		},
+		Log: LogConfig{
+			Level:  "info",
+			Format: "text",
+		},
+		CORS: CORSConfig{
+			AllowOrigins: []string{"*"},
+			AllowMethods: []string{"GET", "POST", "HEAD", "PUT", "DELETE", "PATCH"},
+			AllowHeaders: []string{
+				"Origin", "Content-Type", "Accept", "Traceparent", "Tracestate",
+				"If-None-Match", "If-Modified-Since", "If-Match", "Idempotency-Key", "X-API-Key",
+			},
+			ExposeHeaders: []string{
+				"ETag", "Last-Modified", "Retry-After", "Idempotent-Replayed",
+				"RateLimit-Policy", "RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset",
+			},
+		},
+		RateLimit: RateLimitConfig{
+			Store:           "memory",
+			Max:             100,
+			Window:          time.Minute,
+			Identity:        "api_key",
+			UserHeader:      "X-User-ID",
+			CleanupInterval: 10 * time.Minute,
+		},
+		Maintenance: MaintenanceConfig{
+			Message: "Service is under maintenance, please retry later",
+		},
+		Tracing: TracingConfig{
+			Exporter:     "none",
+			OTLPEndpoint: "localhost:4317",
+			OTLPInsecure: true,
+			SampleRatio:  1.0,
+		},
+		Health: HealthConfig{
+			CheckTimeout:  2 * time.Second,
+			CheckInterval: 10 * time.Second,
+		},
+		Outbox: OutboxConfig{
+			RelayEnabled:    true,
+			PollInterval:    time.Second,
+			BatchSize:       100,
+			MaxAttempts:     10,
+			RetryBackoff:    time.Second,
+			MaxRetryBackoff: 5 * time.Minute,
+		},
+		EventBus: EventBusConfig{
+			Mode:      "async",
+			Workers:   4,
+			QueueSize: 256,
+		},
+		Webhooks: WebhooksConfig{
+			Enabled:         true,
+			Timeout:         10 * time.Second,
+			PollInterval:    time.Second,
+			BatchSize:       20,
+			MaxAttempts:     8,
+			RetryBackoff:    10 * time.Second,
+			MaxRetryBackoff: time.Hour,
+			DisableAfter:    50,
+			Lease:           5 * time.Minute,
+		},
+		Stream: StreamConfig{
+			BufferSize:       1000,
+			SubscriberBuffer: 64,
+			Heartbeat:        15 * time.Second,
+			PollInterval:     500 * time.Millisecond,
+			GapTimeout:       5 * time.Second,
+		},
+		Batch: BatchConfig{
+			MaxItems: 500,
+		},
+		Import: ImportConfig{
+			MaxUnzippedSize: 64 * 1024 * 1024,
+		},
+		Content: ContentConfig{
+			ExcerptLength:  200,
+			WordsPerMinute: 200,
+		},
+		Attachments: AttachmentsConfig{
+			Enabled:         true,
+			Storage:         "local",
+			Dir:             "data/attachments",
+			MaxSize:         3 * 1024 * 1024,
+			AllowedTypes:    []string{"image/jpeg", "image/png", "image/gif", "image/webp", "application/pdf"},
+			ThumbnailSize:   320,
+			CleanupInterval: time.Hour,
+		},
+		Feeds: FeedsConfig{
+			Enabled:      true,
+			Title:        "Blog",
+			Description:  "The latest posts",
+			ItemPath:     "/api/v1/blogs/{id}",
+			Limit:        20,
+			MaxLimit:     100,
+			FullContent:  true,
+			CacheControl: "public, max-age=300",
+		},
+		Idempotency: IdempotencyConfig{
+			Enabled:         true,
+			Store:           "postgres",
+			TTL:             24 * time.Hour,
+			LockTimeout:     time.Minute,
+			Wait:            5 * time.Second,
+			PollInterval:    100 * time.Millisecond,
+			CleanupInterval: 10 * time.Minute,
+		},
+		Cache: CacheConfig{
+			Enabled:     true,
+			Size:        10000,
+			TTL:         time.Minute,
+			NegativeTTL: 10 * time.Second,
+			RenderSize:  1000,
+		},
+		HTTPCache: HTTPCacheConfig{
+			GetBlog:   "public, max-age=60",
+			ListBlogs: "public, max-age=10",
+		},
+		Features: FeatureFlags{
+			Metrics: true,
+		},
+	}
 }
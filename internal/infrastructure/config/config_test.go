@@ -0,0 +1,146 @@
+package config_test
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/toffysoft/go-hexagonal-example/internal/infrastructure/config"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeFile(t *testing.T, name, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	assert.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+// chdir moves into an empty directory so no app.* file from the repo is picked up.
+func chdir(t *testing.T) {
+	wd, err := os.Getwd()
+	assert.NoError(t, err)
+	assert.NoError(t, os.Chdir(t.TempDir()))
+	t.Cleanup(func() { _ = os.Chdir(wd) })
+}
+
+func TestLoadConfigPrecedence(t *testing.T) {
+	chdir(t)
+
+	t.Run("Defaults", func(t *testing.T) {
+		t.Setenv("DATABASE_SOURCE", "host=localhost")
+
+		cfg, err := config.LoadConfig(nil)
+
+		assert.NoError(t, err)
+		assert.Equal(t, ":8080", cfg.HTTP.Address)
+		assert.Equal(t, 2*time.Second, cfg.Health.CheckTimeout)
+		assert.Equal(t, []string{"*"}, cfg.CORS.AllowOrigins)
+		assert.Contains(t, cfg.CORS.AllowHeaders, "Idempotency-Key")
+		assert.Contains(t, cfg.CORS.ExposeHeaders, "ETag")
+	})
+
+	t.Run("YAMLFile", func(t *testing.T) {
+		path := writeFile(t, "app.yaml", `
+database:
+  source: host=yaml
+http:
+  address: ":7000"
+cors:
+  allow_origins: ["https://a.example", "https://b.example"]
+`)
+
+		cfg, err := config.LoadConfig([]string{"--config", path})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "host=yaml", cfg.Database.Source)
+		assert.Equal(t, ":7000", cfg.HTTP.Address)
+		assert.Equal(t, []string{"https://a.example", "https://b.example"}, cfg.CORS.AllowOrigins)
+	})
+
+	t.Run("EnvFileWithLegacyNames", func(t *testing.T) {
+		path := writeFile(t, "app.env", "DB_SOURCE=host=envfile\nSERVER_ADDRESS=:7001\nHEALTH_CHECK_TIMEOUT=5s\n")
+		t.Setenv("CONFIG_FILE", path)
+
+		cfg, err := config.LoadConfig(nil)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "host=envfile", cfg.Database.Source)
+		assert.Equal(t, ":7001", cfg.HTTP.Address)
+		assert.Equal(t, 5*time.Second, cfg.Health.CheckTimeout)
+	})
+
+	t.Run("EnvironmentOverridesFile", func(t *testing.T) {
+		path := writeFile(t, "app.env", "DB_SOURCE=host=envfile\nHTTP_ADDRESS=:7001\n")
+		t.Setenv("HTTP_ADDRESS", ":7002")
+		t.Setenv("CORS_ALLOW_ORIGINS", "https://a.example,https://b.example")
+
+		cfg, err := config.LoadConfig([]string{"--config=" + path})
+
+		assert.NoError(t, err)
+		assert.Equal(t, ":7002", cfg.HTTP.Address)
+		assert.Equal(t, []string{"https://a.example", "https://b.example"}, cfg.CORS.AllowOrigins)
+	})
+
+	t.Run("FlagsOverrideEnvironment", func(t *testing.T) {
+		t.Setenv("DATABASE_SOURCE", "host=localhost")
+		t.Setenv("HTTP_ADDRESS", ":7002")
+
+		cfg, err := config.LoadConfig([]string{"--http.address=:7003", "--log.level", "debug"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, ":7003", cfg.HTTP.Address)
+		assert.Equal(t, "debug", cfg.Log.Level)
+	})
+
+	t.Run("MissingExplicitFile", func(t *testing.T) {
+		_, err := config.LoadConfig([]string{"--config", filepath.Join(t.TempDir(), "missing.yaml")})
+
+		assert.Error(t, err)
+	})
+}
+
+func TestValidateReportsAllProblems(t *testing.T) {
+	cfg := config.Default()
+	cfg.HTTP.Address = "8080"
+	cfg.Database.MaxIdleConns = 100
+	cfg.Log.Level = "verbose"
+	cfg.Auth.Enabled = true
+	cfg.CORS.AllowCredentials = true
+	cfg.RateLimit.Enabled = true
+	cfg.RateLimit.Policies = []string{"POST /api/v1/blogs 10/1m by=session"}
+	cfg.Idempotency.Store = "redis"
+
+	err := cfg.Validate()
+
+	var validationErr *config.ValidationError
+	assert.True(t, errors.As(err, &validationErr))
+	assert.Len(t, validationErr.Problems, 8)
+	assert.Contains(t, err.Error(), "http.address")
+	assert.Contains(t, err.Error(), "database.source is required")
+	assert.Contains(t, err.Error(), "database.max_idle_conns")
+	assert.Contains(t, err.Error(), "log.level")
+	assert.Contains(t, err.Error(), "auth.api_keys")
+	assert.Contains(t, err.Error(), "cors.allow_origins")
+	assert.Contains(t, err.Error(), "rate_limit.policies")
+	assert.Contains(t, err.Error(), "idempotency.store")
+}
+
+func TestPrintRedactsSecrets(t *testing.T) {
+	cfg := config.Default()
+	cfg.Database.Source = "host=db password=hunter2"
+	cfg.Auth.APIKeys = []string{"key-1", "key-2"}
+
+	for _, format := range []string{"yaml", "env"} {
+		var out bytes.Buffer
+		assert.NoError(t, cfg.Print(&out, format))
+
+		assert.NotContains(t, out.String(), "hunter2")
+		assert.NotContains(t, out.String(), "key-1")
+		assert.Contains(t, out.String(), "[REDACTED]")
+	}
+	assert.NotContains(t, cfg.String(), "hunter2")
+}
@@ -0,0 +1,215 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	"github.com/subosito/gotenv"
+)
+
+// defaultConfigFiles are looked up in the working directory, in order, when no
+// file is given through --config or CONFIG_FILE.
+var defaultConfigFiles = []string{"app.yaml", "app.yml", "app.env"}
+
+// LoadConfig builds the configuration from, in increasing precedence: the
+// defaults, a YAML or .env file, the environment and the command-line flags in
+// args. A missing default file is not an error. The result is validated and
+// every problem is reported at once.
+func LoadConfig(args []string) (Config, error) {
+	cfg, _, err := load(args)
+	return cfg, err
+}
+
+// load is LoadConfig that also reports which config file was read, if any.
+func load(args []string) (Config, string, error) {
+	v := viper.New()
+	flags := pflag.NewFlagSet("api", pflag.ContinueOnError)
+	configFile := flags.String("config", "", "path to a YAML or .env configuration file (env CONFIG_FILE)")
+
+	defaults := Default()
+	fields := leafFields(reflect.ValueOf(defaults), "")
+	for _, f := range fields {
+		v.SetDefault(f.key, f.value.Interface())
+		if err := v.BindEnv(append([]string{f.key}, f.envNames()...)...); err != nil {
+			return Config{}, "", err
+		}
+		addFlag(flags, f)
+		if err := v.BindPFlag(f.key, flags.Lookup(f.flagName())); err != nil {
+			return Config{}, "", err
+		}
+	}
+
+	if err := flags.Parse(args); err != nil {
+		return Config{}, "", err
+	}
+
+	path := *configFile
+	if path == "" {
+		path = os.Getenv("CONFIG_FILE")
+	}
+	path, err := readConfigFile(v, path, fields)
+	if err != nil {
+		return Config{}, "", err
+	}
+
+	var cfg Config
+	if err := v.Unmarshal(&cfg); err != nil {
+		return Config{}, "", fmt.Errorf("decode configuration: %w", err)
+	}
+	return cfg, path, cfg.Validate()
+}
+
+// readConfigFile reads path, or the first existing default file when path is
+// empty, and returns the file that was read.
+func readConfigFile(v *viper.Viper, path string, fields []leafField) (string, error) {
+	if path == "" {
+		for _, name := range defaultConfigFiles {
+			if _, err := os.Stat(name); err == nil {
+				path = name
+				break
+			}
+		}
+		if path == "" {
+			return "", nil
+		}
+	}
+
+	if isEnvFile(path) {
+		return path, readEnvFile(v, path, fields)
+	}
+
+	v.SetConfigFile(path)
+	if err := v.ReadInConfig(); err != nil {
+		return "", fmt.Errorf("read config file %s: %w", path, err)
+	}
+	return path, nil
+}
+
+func isEnvFile(path string) bool {
+	ext := filepath.Ext(path)
+	return ext == ".env" || filepath.Base(path) == ".env"
+}
+
+// readEnvFile loads KEY=VALUE pairs at config-file precedence, so variables
+// that are actually set in the environment still win.
+func readEnvFile(v *viper.Viper, path string, fields []leafField) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("read config file %s: %w", path, err)
+	}
+	defer f.Close()
+
+	values, err := gotenv.StrictParse(f)
+	if err != nil {
+		return fmt.Errorf("parse config file %s: %w", path, err)
+	}
+
+	settings := make(map[string]interface{})
+	for _, field := range fields {
+		for _, name := range field.envNames() {
+			if value, ok := values[name]; ok {
+				setNested(settings, field.key, value)
+				break
+			}
+		}
+	}
+	return v.MergeConfigMap(settings)
+}
+
+func setNested(m map[string]interface{}, key string, value interface{}) {
+	parts := strings.Split(key, ".")
+	for _, part := range parts[:len(parts)-1] {
+		next, ok := m[part].(map[string]interface{})
+		if !ok {
+			next = make(map[string]interface{})
+			m[part] = next
+		}
+		m = next
+	}
+	m[parts[len(parts)-1]] = value
+}
+
+// leafField is a single scalar setting of Config.
+type leafField struct {
+	key        string
+	index      []int
+	value      reflect.Value
+	field      reflect.StructField
+	secret     bool
+	reloadable bool
+}
+
+func (f leafField) envNames() []string {
+	names := []string{strings.ToUpper(strings.ReplaceAll(f.key, ".", "_"))}
+	if legacy := f.field.Tag.Get("env"); legacy != "" {
+		names = append(names, strings.Split(legacy, ",")...)
+	}
+	return names
+}
+
+func (f leafField) flagName() string {
+	return strings.ReplaceAll(f.key, "_", "-")
+}
+
+func leafFields(v reflect.Value, prefix string) []leafField {
+	return collectLeafFields(v, prefix, nil, false)
+}
+
+// collectLeafFields walks nested structs; a `reload:"true"` tag on a section
+// marks every field below it as reloadable, except those tagged `reload:"false"`.
+func collectLeafFields(v reflect.Value, prefix string, index []int, reloadable bool) []leafField {
+	var fields []leafField
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		key := sf.Tag.Get("mapstructure")
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+		fieldIndex := append(append([]int{}, index...), i)
+		fieldReloadable := (reloadable || sf.Tag.Get("reload") == "true") && sf.Tag.Get("reload") != "false"
+
+		fv := v.Field(i)
+		if fv.Kind() == reflect.Struct {
+			fields = append(fields, collectLeafFields(fv, key, fieldIndex, fieldReloadable)...)
+			continue
+		}
+		fields = append(fields, leafField{
+			key:        key,
+			index:      fieldIndex,
+			value:      fv,
+			field:      sf,
+			secret:     sf.Tag.Get("secret") == "true",
+			reloadable: fieldReloadable,
+		})
+	}
+	return fields
+}
+
+func addFlag(flags *pflag.FlagSet, f leafField) {
+	name := f.flagName()
+	usage := fmt.Sprintf("sets %s (env %s)", f.key, strings.Join(f.envNames(), ", "))
+
+	switch value := f.value.Interface().(type) {
+	case string:
+		flags.String(name, value, usage)
+	case bool:
+		flags.Bool(name, value, usage)
+	case int:
+		flags.Int(name, value, usage)
+	case float64:
+		flags.Float64(name, value, usage)
+	case time.Duration:
+		flags.Duration(name, value, usage)
+	case []string:
+		flags.StringSlice(name, value, usage)
+	default:
+		panic("config: unsupported field type for " + f.key)
+	}
+}
@@ -0,0 +1,78 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const redacted = "[REDACTED]"
+
+// Redacted returns the configuration as a nested map keyed like the YAML file,
+// with every secret field masked.
+func (c Config) Redacted() map[string]interface{} {
+	settings := make(map[string]interface{})
+	for _, f := range leafFields(reflect.ValueOf(c), "") {
+		setNested(settings, f.key, printable(f))
+	}
+	return settings
+}
+
+// String renders the redacted configuration, so logging a Config never leaks secrets.
+func (c Config) String() string {
+	out, err := yaml.Marshal(c.Redacted())
+	if err != nil {
+		return err.Error()
+	}
+	return string(out)
+}
+
+// Print writes the redacted configuration as "yaml" or as "env" assignments.
+func (c Config) Print(w io.Writer, format string) error {
+	switch format {
+	case "", "yaml":
+		_, err := io.WriteString(w, c.String())
+		return err
+	case "env":
+		fields := leafFields(reflect.ValueOf(c), "")
+		sort.Slice(fields, func(i, j int) bool { return fields[i].key < fields[j].key })
+		for _, f := range fields {
+			if _, err := fmt.Fprintf(w, "%s=%s\n", f.envNames()[0], envValue(printable(f))); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown format %q, expected yaml or env", format)
+	}
+}
+
+func printable(f leafField) interface{} {
+	value := f.value.Interface()
+	if f.secret && !f.value.IsZero() {
+		if _, ok := value.([]string); ok {
+			masked := make([]string, f.value.Len())
+			for i := range masked {
+				masked[i] = redacted
+			}
+			return masked
+		}
+		return redacted
+	}
+	if d, ok := value.(time.Duration); ok {
+		return d.String()
+	}
+	return value
+}
+
+func envValue(value interface{}) string {
+	if list, ok := value.([]string); ok {
+		return strings.Join(list, ",")
+	}
+	return fmt.Sprint(value)
+}
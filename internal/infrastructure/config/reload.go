@@ -0,0 +1,187 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// reloadDebounce coalesces the burst of events editors emit when saving a file.
+const reloadDebounce = 100 * time.Millisecond
+
+// Change describes one reloadable setting that was applied.
+type Change struct {
+	Key string
+	Old interface{}
+	New interface{}
+}
+
+// Subscriber is called after a reload has been applied, with the previous and
+// the new configuration.
+type Subscriber func(old, new Config)
+
+// Store holds the live configuration. Reloads are validated first and then
+// swapped in atomically, so readers always see a complete, valid Config.
+type Store struct {
+	current atomic.Pointer[Config]
+	args    []string
+	path    string
+
+	mu          sync.Mutex
+	subscribers []Subscriber
+}
+
+// NewStore loads the configuration from args exactly like LoadConfig and keeps
+// the args so that Reload re-reads the same sources.
+func NewStore(args []string) (*Store, error) {
+	cfg, path, err := load(args)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Store{args: args, path: path}
+	s.current.Store(&cfg)
+	return s, nil
+}
+
+// Current returns the configuration in effect.
+func (s *Store) Current() Config {
+	return *s.current.Load()
+}
+
+// Path returns the config file being watched, or "" if none was read.
+func (s *Store) Path() string {
+	return s.path
+}
+
+// Subscribe registers fn to be called after every applied reload.
+func (s *Store) Subscribe(fn Subscriber) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.subscribers = append(s.subscribers, fn)
+}
+
+// Reload re-reads every source and applies the reloadable settings that
+// changed. Changes to other settings are logged and ignored until restart. If
+// the new configuration is invalid nothing is applied and the error is returned.
+// Subscribers are called after the store is unlocked, so they may read it or
+// subscribe.
+func (s *Store) Reload(trigger string) ([]Change, error) {
+	old, next, changes, subscribers, err := s.swap(trigger)
+	if err != nil || len(changes) == 0 {
+		return nil, err
+	}
+	for _, fn := range subscribers {
+		fn(old, next)
+	}
+	return changes, nil
+}
+
+// swap applies a reload and returns the configurations before and after it,
+// with the subscribers to notify.
+func (s *Store) swap(trigger string) (old, next Config, changes []Change, subscribers []Subscriber, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	loaded, _, err := load(s.args)
+	if err != nil {
+		slog.Error("config reload rejected", "trigger", trigger, "error", err)
+		return old, next, nil, nil, err
+	}
+
+	old = s.Current()
+	next = old
+	nextValue := reflect.ValueOf(&next).Elem()
+	loadedValue := reflect.ValueOf(loaded)
+
+	for _, f := range leafFields(reflect.ValueOf(old), "") {
+		newValue := loadedValue.FieldByIndex(f.index)
+		if reflect.DeepEqual(f.value.Interface(), newValue.Interface()) {
+			continue
+		}
+		if !f.reloadable {
+			slog.Warn("config change requires restart", "trigger", trigger, "key", f.key)
+			continue
+		}
+
+		nextValue.FieldByIndex(f.index).Set(newValue)
+		changes = append(changes, Change{
+			Key: f.key,
+			Old: printable(f),
+			New: printable(leafField{value: newValue, secret: f.secret}),
+		})
+	}
+
+	if len(changes) == 0 {
+		return old, next, nil, nil, nil
+	}
+
+	// Only the reloadable settings of loaded were taken, and they must be
+	// valid together with the settings kept from before.
+	if err := next.Validate(); err != nil {
+		slog.Error("config reload rejected", "trigger", trigger, "error", err)
+		return old, next, nil, nil, err
+	}
+
+	s.current.Store(&next)
+	for _, c := range changes {
+		slog.Info("config change applied", "trigger", trigger, "key", c.Key, "old", c.Old, "new", c.New)
+	}
+	return old, next, changes, slices.Clone(s.subscribers), nil
+}
+
+// Watch reloads on SIGHUP and whenever the config file changes, until ctx is
+// cancelled. Without a config file only SIGHUP triggers a reload.
+func (s *Store) Watch(ctx context.Context) error {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	var fileEvents <-chan fsnotify.Event
+	var fileErrors <-chan error
+	if s.path != "" {
+		watcher, err := fsnotify.NewWatcher()
+		if err != nil {
+			return err
+		}
+		defer watcher.Close()
+
+		// Watch the directory rather than the file: editors and config-map
+		// mounts replace the file, which drops a watch on the file itself.
+		if err := watcher.Add(filepath.Dir(s.path)); err != nil {
+			return fmt.Errorf("watch %s: %w", s.path, err)
+		}
+		fileEvents, fileErrors = watcher.Events, watcher.Errors
+	}
+
+	target := filepath.Clean(s.path)
+	var debounce <-chan time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-hup:
+			_, _ = s.Reload("SIGHUP")
+		case event := <-fileEvents:
+			if filepath.Clean(event.Name) == target && event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Rename) != 0 {
+				debounce = time.After(reloadDebounce)
+			}
+		case <-debounce:
+			debounce = nil
+			_, _ = s.Reload("file")
+		case err := <-fileErrors:
+			slog.Error("config watcher error", "error", err)
+		}
+	}
+}
@@ -0,0 +1,169 @@
+package config_test
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/toffysoft/go-hexagonal-example/internal/infrastructure/config"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const baseYAML = `
+database:
+  source: host=localhost
+http:
+  address: ":8080"
+log:
+  level: info
+`
+
+func TestStoreReload(t *testing.T) {
+	chdir(t)
+
+	t.Run("AppliesReloadableChanges", func(t *testing.T) {
+		path := writeFile(t, "app.yaml", baseYAML)
+		store, err := config.NewStore([]string{"--config", path})
+		assert.NoError(t, err)
+
+		var notified []config.Config
+		store.Subscribe(func(old, new config.Config) {
+			notified = append(notified, old, new)
+		})
+
+		assert.NoError(t, os.WriteFile(path, []byte(baseYAML+`
+maintenance:
+  enabled: true
+cors:
+  allow_origins: ["https://a.example"]
+`), 0o600))
+		changes, err := store.Reload("test")
+
+		assert.NoError(t, err)
+		assert.Len(t, changes, 2)
+		assert.True(t, store.Current().Maintenance.Enabled)
+		assert.Equal(t, []string{"https://a.example"}, store.Current().CORS.AllowOrigins)
+		assert.Len(t, notified, 2)
+		assert.False(t, notified[0].Maintenance.Enabled)
+		assert.True(t, notified[1].Maintenance.Enabled)
+	})
+
+	t.Run("IgnoresRestartOnlyChanges", func(t *testing.T) {
+		path := writeFile(t, "app.yaml", baseYAML)
+		store, err := config.NewStore([]string{"--config", path})
+		assert.NoError(t, err)
+
+		assert.NoError(t, os.WriteFile(path, []byte(`
+database:
+  source: host=localhost
+http:
+  address: ":9999"
+log:
+  level: debug
+rate_limit:
+  store: postgres
+`), 0o600))
+		changes, err := store.Reload("test")
+
+		assert.NoError(t, err)
+		assert.Len(t, changes, 1)
+		assert.Equal(t, "log.level", changes[0].Key)
+		assert.Equal(t, "debug", store.Current().Log.Level)
+		assert.Equal(t, ":8080", store.Current().HTTP.Address)
+		assert.Equal(t, "memory", store.Current().RateLimit.Store)
+	})
+
+	t.Run("RejectsInvalidConfig", func(t *testing.T) {
+		path := writeFile(t, "app.yaml", baseYAML)
+		store, err := config.NewStore([]string{"--config", path})
+		assert.NoError(t, err)
+
+		assert.NoError(t, os.WriteFile(path, []byte(`
+database:
+  source: host=localhost
+log:
+  level: loud
+maintenance:
+  enabled: true
+`), 0o600))
+		changes, err := store.Reload("test")
+
+		assert.Error(t, err)
+		assert.Empty(t, changes)
+		assert.Equal(t, "info", store.Current().Log.Level)
+		assert.False(t, store.Current().Maintenance.Enabled)
+	})
+
+	t.Run("ValidatesMergedConfig", func(t *testing.T) {
+		path := writeFile(t, "app.yaml", baseYAML+`
+rate_limit:
+  cleanup_interval: 0s
+`)
+		store, err := config.NewStore([]string{"--config", path})
+		assert.NoError(t, err)
+
+		// The file is valid, but cleanup_interval needs a restart, and the
+		// running value is invalid once rate limiting is enabled.
+		assert.NoError(t, os.WriteFile(path, []byte(baseYAML+`
+rate_limit:
+  enabled: true
+  cleanup_interval: 1m
+`), 0o600))
+		changes, err := store.Reload("test")
+
+		assert.ErrorContains(t, err, "rate_limit.cleanup_interval")
+		assert.Empty(t, changes)
+		assert.False(t, store.Current().RateLimit.Enabled)
+	})
+
+	t.Run("SubscribersMayUseTheStore", func(t *testing.T) {
+		path := writeFile(t, "app.yaml", baseYAML)
+		store, err := config.NewStore([]string{"--config", path})
+		assert.NoError(t, err)
+
+		var current config.Config
+		store.Subscribe(func(old, new config.Config) {
+			current = store.Current()
+			store.Subscribe(func(old, new config.Config) {})
+		})
+
+		assert.NoError(t, os.WriteFile(path, []byte(baseYAML+`
+maintenance:
+  enabled: true
+`), 0o600))
+		done := make(chan struct{})
+		go func() {
+			_, err = store.Reload("test")
+			close(done)
+		}()
+
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+			t.Fatal("Reload deadlocked in a subscriber")
+		}
+		assert.NoError(t, err)
+		assert.True(t, current.Maintenance.Enabled)
+	})
+}
+
+func TestStoreWatch(t *testing.T) {
+	chdir(t)
+	path := writeFile(t, "app.yaml", baseYAML)
+	store, err := config.NewStore([]string{"--config", path})
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() { _ = store.Watch(ctx) }()
+
+	assert.Eventually(t, func() bool {
+		_ = os.WriteFile(path, []byte(baseYAML+`
+rate_limit:
+  enabled: true
+`), 0o600)
+		return store.Current().RateLimit.Enabled
+	}, 5*time.Second, 200*time.Millisecond)
+}
@@ -0,0 +1,255 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/toffysoft/go-hexagonal-example/internal/core/domain"
+)
+
+var (
+	logLevels           = []string{"debug", "info", "warn", "error"}
+	logFormats          = []string{"text", "json"}
+	tracingExporters    = []string{"none", "otlp", "stdout"}
+	eventBusModes       = []string{"sync", "async"}
+	rateLimitStores     = []string{"memory", "postgres"}
+	idempotencyStores   = []string{"memory", "postgres"}
+	rateLimitIdentities = []string{"ip", "api_key", "user"}
+	attachmentStorages  = []string{"local"}
+)
+
+// ValidationError lists every problem found in a Config.
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return "invalid configuration:\n  - " + strings.Join(e.Problems, "\n  - ")
+}
+
+type validator struct {
+	problems []string
+}
+
+func (v *validator) addf(format string, args ...interface{}) {
+	v.problems = append(v.problems, fmt.Sprintf(format, args...))
+}
+
+func (v *validator) address(key, value string) {
+	if value == "" {
+		v.addf("%s is required", key)
+		return
+	}
+	if _, _, err := net.SplitHostPort(value); err != nil {
+		v.addf("%s %q is not a valid host:port", key, value)
+	}
+}
+
+func (v *validator) positive(key string, value int64) {
+	if value <= 0 {
+		v.addf("%s must be greater than zero", key)
+	}
+}
+
+func (v *validator) nonNegative(key string, value int64) {
+	if value < 0 {
+		v.addf("%s must not be negative", key)
+	}
+}
+
+func (v *validator) oneOf(key, value string, allowed []string) {
+	for _, a := range allowed {
+		if value == a {
+			return
+		}
+	}
+	v.addf("%s %q must be one of %s", key, value, strings.Join(allowed, ", "))
+}
+
+// Validate checks the whole configuration and returns a *ValidationError
+// listing every problem, or nil if there are none.
+func (c Config) Validate() error {
+	v := &validator{}
+
+	if c.Service.Name == "" {
+		v.addf("service.name is required")
+	}
+	v.positive("service.shutdown_timeout", int64(c.Service.ShutdownTimeout))
+
+	v.address("http.address", c.HTTP.Address)
+	v.nonNegative("http.read_timeout", int64(c.HTTP.ReadTimeout))
+	v.nonNegative("http.write_timeout", int64(c.HTTP.WriteTimeout))
+	v.nonNegative("http.idle_timeout", int64(c.HTTP.IdleTimeout))
+	v.positive("http.body_limit", int64(c.HTTP.BodyLimit))
+
+	v.address("grpc.address", c.GRPC.Address)
+	v.positive("grpc.max_recv_msg_size", int64(c.GRPC.MaxRecvMsgSize))
+	v.positive("grpc.max_send_msg_size", int64(c.GRPC.MaxSendMsgSize))
+
+	v.oneOf("database.driver", c.Database.Driver, []string{"postgres"})
+	if c.Database.Source == "" {
+		v.addf("database.source is required")
+	}
+	v.nonNegative("database.max_open_conns", int64(c.Database.MaxOpenConns))
+	v.nonNegative("database.max_idle_conns", int64(c.Database.MaxIdleConns))
+	if c.Database.MaxOpenConns > 0 && c.Database.MaxIdleConns > c.Database.MaxOpenConns {
+		v.addf("database.max_idle_conns (%d) must not exceed database.max_open_conns (%d)", c.Database.MaxIdleConns, c.Database.MaxOpenConns)
+	}
+	v.nonNegative("database.conn_max_lifetime", int64(c.Database.ConnMaxLifetime))
+	v.nonNegative("database.conn_max_idle_time", int64(c.Database.ConnMaxIdleTime))
+	for i, replica := range c.Database.Replicas {
+		if replica == "" {
+			v.addf("database.replicas[%d] must not be empty", i)
+		}
+	}
+	v.nonNegative("database.connect_retries", int64(c.Database.ConnectRetries))
+	if c.Database.ConnectRetries > 0 {
+		v.positive("database.connect_backoff", int64(c.Database.ConnectBackoff))
+		if c.Database.ConnectMaxBackoff < c.Database.ConnectBackoff {
+			v.addf("database.connect_max_backoff must not be less than database.connect_backoff")
+		}
+	}
+
+	v.oneOf("log.level", c.Log.Level, logLevels)
+	v.oneOf("log.format", c.Log.Format, logFormats)
+
+	if c.Auth.Enabled && len(c.Auth.APIKeys) == 0 {
+		v.addf("auth.api_keys must not be empty when auth.enabled is true")
+	}
+
+	if c.CORS.AllowCredentials {
+		for _, origin := range c.CORS.AllowOrigins {
+			if origin == "*" {
+				v.addf("cors.allow_origins must not contain \"*\" when cors.allow_credentials is true")
+				break
+			}
+		}
+	}
+	v.nonNegative("cors.max_age", int64(c.CORS.MaxAge))
+
+	if c.RateLimit.Enabled {
+		v.oneOf("rate_limit.store", c.RateLimit.Store, rateLimitStores)
+		v.positive("rate_limit.max", int64(c.RateLimit.Max))
+		v.positive("rate_limit.window", int64(c.RateLimit.Window))
+		v.nonNegative("rate_limit.burst", int64(c.RateLimit.Burst))
+		v.oneOf("rate_limit.identity", c.RateLimit.Identity, rateLimitIdentities)
+		v.positive("rate_limit.cleanup_interval", int64(c.RateLimit.CleanupInterval))
+		for _, spec := range c.RateLimit.Policies {
+			if _, err := domain.ParseRateLimitPolicy(spec); err != nil {
+				v.addf("rate_limit.policies: %v", err)
+			}
+		}
+	}
+
+	v.oneOf("tracing.exporter", c.Tracing.Exporter, tracingExporters)
+	if c.Tracing.Exporter == "otlp" && c.Tracing.OTLPEndpoint == "" {
+		v.addf("tracing.otlp_endpoint is required when tracing.exporter is otlp")
+	}
+	if c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1 {
+		v.addf("tracing.sample_ratio must be between 0 and 1")
+	}
+
+	v.positive("health.check_timeout", int64(c.Health.CheckTimeout))
+	v.positive("health.check_interval", int64(c.Health.CheckInterval))
+
+	if c.Outbox.RelayEnabled {
+		v.positive("outbox.poll_interval", int64(c.Outbox.PollInterval))
+		v.positive("outbox.batch_size", int64(c.Outbox.BatchSize))
+		v.positive("outbox.max_attempts", int64(c.Outbox.MaxAttempts))
+		v.positive("outbox.retry_backoff", int64(c.Outbox.RetryBackoff))
+		if c.Outbox.MaxRetryBackoff < c.Outbox.RetryBackoff {
+			v.addf("outbox.max_retry_backoff must not be less than outbox.retry_backoff")
+		}
+	}
+
+	v.oneOf("event_bus.mode", c.EventBus.Mode, eventBusModes)
+	if c.EventBus.Mode == "async" {
+		v.positive("event_bus.workers", int64(c.EventBus.Workers))
+		v.positive("event_bus.queue_size", int64(c.EventBus.QueueSize))
+	}
+
+	if c.Webhooks.Enabled {
+		v.positive("webhooks.timeout", int64(c.Webhooks.Timeout))
+		v.positive("webhooks.poll_interval", int64(c.Webhooks.PollInterval))
+		v.positive("webhooks.batch_size", int64(c.Webhooks.BatchSize))
+		v.positive("webhooks.max_attempts", int64(c.Webhooks.MaxAttempts))
+		v.positive("webhooks.retry_backoff", int64(c.Webhooks.RetryBackoff))
+		if c.Webhooks.MaxRetryBackoff < c.Webhooks.RetryBackoff {
+			v.addf("webhooks.max_retry_backoff must not be less than webhooks.retry_backoff")
+		}
+		v.nonNegative("webhooks.disable_after", int64(c.Webhooks.DisableAfter))
+		if c.Webhooks.Lease <= time.Duration(c.Webhooks.BatchSize)*c.Webhooks.Timeout {
+			v.addf("webhooks.lease must be longer than webhooks.batch_size times webhooks.timeout")
+		}
+	}
+
+	v.positive("stream.buffer_size", int64(c.Stream.BufferSize))
+	v.positive("stream.subscriber_buffer", int64(c.Stream.SubscriberBuffer))
+	v.positive("stream.heartbeat", int64(c.Stream.Heartbeat))
+	v.positive("stream.poll_interval", int64(c.Stream.PollInterval))
+	v.positive("stream.gap_timeout", int64(c.Stream.GapTimeout))
+
+	v.positive("batch.max_items", int64(c.Batch.MaxItems))
+	v.positive("import.max_unzipped_size", int64(c.Import.MaxUnzippedSize))
+	v.positive("content.excerpt_length", int64(c.Content.ExcerptLength))
+	v.positive("content.words_per_minute", int64(c.Content.WordsPerMinute))
+
+	if c.Attachments.Enabled {
+		v.oneOf("attachments.storage", c.Attachments.Storage, attachmentStorages)
+		if c.Attachments.Storage == "local" && c.Attachments.Dir == "" {
+			v.addf("attachments.dir is required when attachments.storage is local")
+		}
+		v.positive("attachments.max_size", int64(c.Attachments.MaxSize))
+		if c.Attachments.MaxSize >= c.HTTP.BodyLimit {
+			v.addf("attachments.max_size must be less than http.body_limit, which bounds the whole upload")
+		}
+		if len(c.Attachments.AllowedTypes) == 0 {
+			v.addf("attachments.allowed_types must not be empty when attachments.enabled is true")
+		}
+		v.positive("attachments.thumbnail_size", int64(c.Attachments.ThumbnailSize))
+		v.positive("attachments.cleanup_interval", int64(c.Attachments.CleanupInterval))
+	}
+
+	if c.Feeds.Enabled {
+		if c.Feeds.Title == "" {
+			v.addf("feeds.title is required when feeds.enabled is true")
+		}
+		if c.Feeds.BaseURL != "" {
+			if u, err := url.Parse(c.Feeds.BaseURL); err != nil || !u.IsAbs() || u.Host == "" {
+				v.addf("feeds.base_url %q is not an absolute URL", c.Feeds.BaseURL)
+			}
+		}
+		// Links identify the items of feeds, and slugs are not unique.
+		if !strings.Contains(c.Feeds.ItemPath, "{id}") {
+			v.addf("feeds.item_path must contain {id}")
+		}
+		v.positive("feeds.limit", int64(c.Feeds.Limit))
+		if c.Feeds.MaxLimit < c.Feeds.Limit {
+			v.addf("feeds.max_limit must not be less than feeds.limit")
+		}
+	}
+
+	if c.Idempotency.Enabled {
+		v.oneOf("idempotency.store", c.Idempotency.Store, idempotencyStores)
+		v.positive("idempotency.ttl", int64(c.Idempotency.TTL))
+		v.positive("idempotency.lock_timeout", int64(c.Idempotency.LockTimeout))
+		v.nonNegative("idempotency.wait", int64(c.Idempotency.Wait))
+		v.positive("idempotency.poll_interval", int64(c.Idempotency.PollInterval))
+		v.positive("idempotency.cleanup_interval", int64(c.Idempotency.CleanupInterval))
+	}
+
+	if c.Cache.Enabled {
+		v.positive("cache.size", int64(c.Cache.Size))
+		v.positive("cache.ttl", int64(c.Cache.TTL))
+		v.positive("cache.negative_ttl", int64(c.Cache.NegativeTTL))
+		v.positive("cache.render_size", int64(c.Cache.RenderSize))
+	}
+
+	if len(v.problems) > 0 {
+		return &ValidationError{Problems: v.problems}
+	}
+	return nil
+}
@@ -1,34 +1,122 @@
 package database
 
 import (
+	"log"
+	"time"
+
 	"github.com/toffysoft/go-hexagonal-example/internal/core/domain"
 	"github.com/toffysoft/go-hexagonal-example/internal/infrastructure/config"
 
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
+	"gorm.io/plugin/dbresolver"
 )
 
+// InitDB connects to the primary database, retrying with exponential backoff
+// while it is unavailable, applies the pool settings, routes reads to the
+// runs the migrations and routes reads to the configured replicas.
 func InitDB(cfg config.Config) (*gorm.DB, error) {
-	db, err := gorm.Open(postgres.Open(cfg.DBSource), &gorm.Config{})
+	var db *gorm.DB
+	err := Retry(cfg.Database.ConnectRetries, cfg.Database.ConnectBackoff, cfg.Database.ConnectMaxBackoff, func(attempt int) error {
+		var err error
+		db, err = gorm.Open(postgres.Open(cfg.Database.Source), &gorm.Config{})
+		if err != nil {
+			log.Printf("Database connection attempt %d failed: %v", attempt, err)
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sqlDB, err := db.DB()
 	if err != nil {
 		return nil, err
 	}
+	sqlDB.SetMaxOpenConns(cfg.Database.MaxOpenConns)
+	sqlDB.SetMaxIdleConns(cfg.Database.MaxIdleConns)
+	sqlDB.SetConnMaxLifetime(cfg.Database.ConnMaxLifetime)
+	sqlDB.SetConnMaxIdleTime(cfg.Database.ConnMaxIdleTime)
 
-	err = db.AutoMigrate(&domain.Blog{})
+	// Migrate before the replicas are registered: AutoMigrate inspects the
+	// schema with queries, and a lagging replica would report stale tables.
+	err = db.AutoMigrate(Models()...)
 	if err != nil {
 		return nil, err
 	}
 
+	if len(cfg.Database.Replicas) > 0 {
+		if err := useReplicas(db, cfg.Database); err != nil {
+			return nil, err
+		}
+	}
+
 	return db, nil
 }
 
+// useReplicas sends queries to the replicas and everything else to the
+// primary. Reads can be pinned to the primary with the dbresolver.Write clause.
+func useReplicas(db *gorm.DB, cfg config.DatabaseConfig) error {
+	replicas := make([]gorm.Dialector, len(cfg.Replicas))
+	for i, dsn := range cfg.Replicas {
+		replicas[i] = postgres.Open(dsn)
+	}
+
+	resolver := dbresolver.Register(dbresolver.Config{
+		Replicas: replicas,
+		Policy:   dbresolver.RandomPolicy{},
+	}).
+		SetMaxOpenConns(cfg.MaxOpenConns).
+		SetMaxIdleConns(cfg.MaxIdleConns).
+		SetConnMaxLifetime(cfg.ConnMaxLifetime).
+		SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
+
+	return db.Use(resolver)
+}
+
+// Retry calls fn until it succeeds or it has been retried retries times,
+// sleeping between attempts for a delay that starts at initial and doubles up
+// to max. It returns the last error.
+func Retry(retries int, initial, max time.Duration, fn func(attempt int) error) error {
+	return retry(retries, initial, max, fn, time.Sleep)
+}
+
+func retry(retries int, initial, max time.Duration, fn func(attempt int) error, sleep func(time.Duration)) error {
+	delay := initial
+	var err error
+	for attempt := 1; ; attempt++ {
+		if err = fn(attempt); err == nil || attempt > retries {
+			return err
+		}
+		sleep(delay)
+		delay *= 2
+		if delay > max {
+			delay = max
+		}
+	}
+}
+
+// Models returns every model managed by AutoMigrate.
+func Models() []interface{} {
+	return []interface{}{
+		&domain.Blog{},
+		&domain.Attachment{},
+		&domain.OutboxMessage{},
+		&domain.Webhook{},
+		&domain.WebhookDelivery{},
+		&domain.WebhookAttempt{},
+		&domain.IdempotencyRecord{},
+		&domain.TokenBucket{},
+	}
+}
+
 func InitTestDB() (*gorm.DB, error) {
 	db, err := gorm.Open(postgres.Open("host=localhost user=test password=test dbname=test_db port=5432 sslmode=disable"), &gorm.Config{})
 	if err != nil {
 		return nil, err
 	}
 
-	err = db.AutoMigrate(&domain.Blog{})
+	err = db.AutoMigrate(Models()...)
 	if err != nil {
 		return nil, err
 	}
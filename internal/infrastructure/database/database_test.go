@@ -0,0 +1,48 @@
+package database
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetry(t *testing.T) {
+	errDown := errors.New("connection refused")
+
+	t.Run("BacksOffExponentiallyUpToMax", func(t *testing.T) {
+		var delays []time.Duration
+		attempts := 0
+		err := retry(5, 100*time.Millisecond, 300*time.Millisecond, func(attempt int) error {
+			attempts = attempt
+			if attempt < 5 {
+				return errDown
+			}
+			return nil
+		}, func(d time.Duration) { delays = append(delays, d) })
+
+		assert.NoError(t, err)
+		assert.Equal(t, 5, attempts)
+		assert.Equal(t, []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 300 * time.Millisecond, 300 * time.Millisecond}, delays)
+	})
+
+	t.Run("ReturnsLastErrorWhenExhausted", func(t *testing.T) {
+		attempts := 0
+		err := retry(2, time.Millisecond, time.Millisecond, func(attempt int) error {
+			attempts = attempt
+			return errDown
+		}, func(time.Duration) {})
+
+		assert.ErrorIs(t, err, errDown)
+		assert.Equal(t, 3, attempts)
+	})
+
+	t.Run("NoRetries", func(t *testing.T) {
+		slept := false
+		err := retry(0, time.Second, time.Second, func(int) error { return errDown }, func(time.Duration) { slept = true })
+
+		assert.ErrorIs(t, err, errDown)
+		assert.False(t, slept)
+	})
+}
@@ -0,0 +1,42 @@
+package logging
+
+import (
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/toffysoft/go-hexagonal-example/internal/infrastructure/config"
+)
+
+// Setup installs a slog logger built from cfg as the process default, which
+// also routes the standard log package through it. The returned LevelVar
+// controls the level at runtime.
+func Setup(cfg config.LogConfig) *slog.LevelVar {
+	level := new(slog.LevelVar)
+	level.Set(ParseLevel(cfg.Level))
+
+	opts := &slog.HandlerOptions{Level: level}
+	var handler slog.Handler
+	if cfg.Format == "json" {
+		handler = slog.NewJSONHandler(os.Stderr, opts)
+	} else {
+		handler = slog.NewTextHandler(os.Stderr, opts)
+	}
+
+	slog.SetDefault(slog.New(handler))
+	return level
+}
+
+// ParseLevel maps a configured level name to a slog.Level, defaulting to info.
+func ParseLevel(level string) slog.Level {
+	switch strings.ToLower(level) {
+	case "debug":
+		return slog.LevelDebug
+	case "warn":
+		return slog.LevelWarn
+	case "error":
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
+}
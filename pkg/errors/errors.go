@@ -14,6 +14,10 @@ const (
 	InternalServer ErrorType = "INTERNAL_SERVER_ERROR"
 	Unauthorized   ErrorType = "UNAUTHORIZED"
 	Forbidden      ErrorType = "FORBIDDEN"
+	Conflict       ErrorType = "CONFLICT"
+	Unprocessable  ErrorType = "UNPROCESSABLE"
+	TooLarge       ErrorType = "TOO_LARGE"
+	Unsupported    ErrorType = "UNSUPPORTED_MEDIA_TYPE"
 )
 
 type AppError struct {
@@ -35,6 +39,14 @@ func (e AppError) StatusCode() int {
 		return http.StatusUnauthorized
 	case Forbidden:
 		return http.StatusForbidden
+	case Conflict:
+		return http.StatusConflict
+	case Unprocessable:
+		return http.StatusUnprocessableEntity
+	case TooLarge:
+		return http.StatusRequestEntityTooLarge
+	case Unsupported:
+		return http.StatusUnsupportedMediaType
 	case InternalServer:
 		return http.StatusInternalServerError
 	default:
@@ -68,3 +80,19 @@ func NewUnauthorizedError(message string) AppError {
 func NewForbiddenError(message string) AppError {
 	return NewAppError(Forbidden, message)
 }
+
+func NewConflictError(message string) AppError {
+	return NewAppError(Conflict, message)
+}
+
+func NewUnprocessableError(message string) AppError {
+	return NewAppError(Unprocessable, message)
+}
+
+func NewTooLargeError(message string) AppError {
+	return NewAppError(TooLarge, message)
+}
+
+func NewUnsupportedError(message string) AppError {
+	return NewAppError(Unsupported, message)
+}
@@ -0,0 +1,211 @@
+package utils
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/tinylib/msgp/msgp"
+)
+
+// ErrUnsupportedMediaType is returned by ParseBody for a request body it
+// cannot decode.
+var ErrUnsupportedMediaType = errors.New("Content-Type must be " + MIMEJSON + ", " + MIMEXML + " or " + MIMEMsgPack)
+
+// ParseBody decodes the request body into out according to its
+// Content-Type: JSON, XML or MessagePack. An XML or MessagePack body has the
+// shape of the JSON one, with the same field names; in XML, array items are
+// child elements of any name, e.g. <items><item>...</item></items>.
+func ParseBody(c *fiber.Ctx, out interface{}) error {
+	mediaType, _, _ := strings.Cut(c.Get(fiber.HeaderContentType), ";")
+	mediaType = strings.ToLower(strings.TrimSpace(mediaType))
+	switch {
+	case mediaType == MIMEJSON || strings.HasSuffix(mediaType, "+json"):
+		return json.Unmarshal(c.Body(), out)
+	case mediaType == MIMEXML || mediaType == fiber.MIMETextXML || strings.HasSuffix(mediaType, "+xml"):
+		return decodeXML(c.Body(), out)
+	case mediaType == MIMEMsgPack || mediaType == "application/x-msgpack" || mediaType == "application/vnd.msgpack":
+		return decodeMsgPack(c.Body(), out)
+	}
+	return ErrUnsupportedMediaType
+}
+
+// SendBodyError responds to a request whose body ParseBody could not decode:
+// 415 with the accepted media types for an unsupported Content-Type, 400
+// otherwise.
+func SendBodyError(c *fiber.Ctx, err error) error {
+	if errors.Is(err, ErrUnsupportedMediaType) {
+		c.Set(fiber.HeaderAccept, MIMEJSON+", "+MIMEXML+", "+MIMEMsgPack)
+		return SendErrorResponse(c, fiber.StatusUnsupportedMediaType, err.Error())
+	}
+	return SendErrorResponse(c, fiber.StatusBadRequest, "Invalid request body")
+}
+
+func decodeMsgPack(body []byte, out interface{}) error {
+	value, err := msgp.NewReader(bytes.NewReader(body)).ReadIntf()
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, out)
+}
+
+// element is a parsed XML element.
+type element struct {
+	name     string
+	text     string
+	children []*element
+}
+
+// decodeXML decodes an XML document into out by converting it to the JSON
+// document out would be decoded from. XML has no types, so the conversion
+// follows the type of out.
+func decodeXML(body []byte, out interface{}) error {
+	root, err := parseXML(body)
+	if err != nil {
+		return err
+	}
+	value, err := xmlToJSON(root, reflect.TypeOf(out))
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, out)
+}
+
+func parseXML(body []byte) (*element, error) {
+	dec := xml.NewDecoder(bytes.NewReader(body))
+	var stack []*element
+	var root *element
+	for {
+		token, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch token := token.(type) {
+		case xml.StartElement:
+			e := &element{name: token.Name.Local}
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				parent.children = append(parent.children, e)
+			} else if root == nil {
+				root = e
+			}
+			stack = append(stack, e)
+		case xml.EndElement:
+			stack = stack[:len(stack)-1]
+		case xml.CharData:
+			if len(stack) > 0 {
+				stack[len(stack)-1].text += string(token)
+			}
+		}
+	}
+	if root == nil {
+		return nil, errors.New("empty XML document")
+	}
+	return root, nil
+}
+
+var textUnmarshaler = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+
+// xmlToJSON converts e to the JSON value of a t.
+func xmlToJSON(e *element, t reflect.Type) (interface{}, error) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	text := strings.TrimSpace(e.text)
+	if reflect.PointerTo(t).Implements(textUnmarshaler) {
+		return text, nil
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		fields := map[string]reflect.Type{}
+		collectFields(t, fields)
+		obj := map[string]interface{}{}
+		for _, child := range e.children {
+			if ft, ok := fields[child.name]; ok {
+				value, err := xmlToJSON(child, ft)
+				if err != nil {
+					return nil, err
+				}
+				obj[child.name] = value
+			}
+		}
+		return obj, nil
+	case reflect.Map:
+		obj := map[string]interface{}{}
+		for _, child := range e.children {
+			value, err := xmlToJSON(child, t.Elem())
+			if err != nil {
+				return nil, err
+			}
+			obj[child.name] = value
+		}
+		return obj, nil
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return text, nil
+		}
+		array := []interface{}{}
+		for _, child := range e.children {
+			value, err := xmlToJSON(child, t.Elem())
+			if err != nil {
+				return nil, err
+			}
+			array = append(array, value)
+		}
+		return array, nil
+	case reflect.Bool:
+		return strconv.ParseBool(text)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		if _, err := strconv.ParseFloat(text, 64); err != nil {
+			return nil, fmt.Errorf("%s: %q is not a number", e.name, text)
+		}
+		return json.Number(text), nil
+	case reflect.Interface:
+		if len(e.children) > 0 {
+			return nil, fmt.Errorf("%s: nested elements are not supported here", e.name)
+		}
+	}
+	return text, nil
+}
+
+// collectFields maps the JSON names of the fields of a struct type, including
+// promoted ones, to their types.
+func collectFields(t reflect.Type, fields map[string]reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, embedded, ok := jsonField(field)
+		switch {
+		case !ok:
+		case embedded:
+			ft := field.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			collectFields(ft, fields)
+		default:
+			fields[name] = field.Type
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/xml"
+	"reflect"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Media types of the representations responses can be sent in. CSV is only
+// produced for lists.
+const (
+	MIMEJSON    = fiber.MIMEApplicationJSON
+	MIMEXML     = fiber.MIMEApplicationXML
+	MIMEMsgPack = "application/msgpack"
+	MIMECSV     = "text/csv"
+)
+
+// offers lists the media types matched against Accept, in order of
+// preference, with the representation each one selects.
+var offers = []struct {
+	mediaType, format string
+}{
+	{MIMEJSON, MIMEJSON},
+	{MIMEXML, MIMEXML},
+	{fiber.MIMETextXML, MIMEXML},
+	{MIMEMsgPack, MIMEMsgPack},
+	{"application/x-msgpack", MIMEMsgPack},
+	{"application/vnd.msgpack", MIMEMsgPack},
+	{MIMECSV, MIMECSV},
+}
+
+// Negotiate returns the representation, one of the MIME constants, that best
+// matches the request's Accept header, or "" if none is acceptable. Without
+// Accept it is JSON. list allows CSV.
+func Negotiate(c *fiber.Ctx, list bool) string {
+	mediaTypes := make([]string, 0, len(offers))
+	for _, offer := range offers {
+		if offer.format != MIMECSV || list {
+			mediaTypes = append(mediaTypes, offer.mediaType)
+		}
+	}
+	accepted := c.Accepts(mediaTypes...)
+	for _, offer := range offers {
+		if offer.mediaType == accepted {
+			return offer.format
+		}
+	}
+	return ""
+}
+
+// isList reports whether data is sent as a list, which can be CSV.
+func isList(data interface{}) bool {
+	if data == nil {
+		return false
+	}
+	kind := reflect.TypeOf(data).Kind()
+	return kind == reflect.Slice || kind == reflect.Array
+}
+
+// send writes body in the representation negotiated for it, or responds
+// 406 Not Acceptable. data is the payload of body, which is all a CSV
+// representation holds.
+func send(c *fiber.Ctx, statusCode int, body, data interface{}) error {
+	c.Vary(fiber.HeaderAccept)
+	format := Negotiate(c, isList(data))
+	if format == "" {
+		return SendNotAcceptable(c, isList(data))
+	}
+	return write(c.Status(statusCode), format, body, data)
+}
+
+// sendError writes an error in the negotiated representation. An error is
+// better than none, so it falls back to JSON rather than 406.
+func sendError(c *fiber.Ctx, statusCode int, body interface{}) error {
+	c.Vary(fiber.HeaderAccept)
+	format := Negotiate(c, false)
+	if format == "" {
+		format = MIMEJSON
+	}
+	return write(c.Status(statusCode), format, body, nil)
+}
+
+// SendNotAcceptable responds 406 with the representations that are
+// available, including CSV for a list.
+func SendNotAcceptable(c *fiber.Ctx, list bool) error {
+	available := []string{MIMEJSON, MIMEXML, MIMEMsgPack}
+	if list {
+		available = append(available, MIMECSV)
+	}
+	return c.Status(fiber.StatusNotAcceptable).JSON(ErrorResponse{
+		Success: false,
+		Message: "Not acceptable: responses are available as " + strings.Join(available, ", "),
+	})
+}
+
+func write(c *fiber.Ctx, format string, body, data interface{}) error {
+	if format == MIMEJSON {
+		return c.JSON(body)
+	}
+
+	var value interface{} = body
+	if format == MIMECSV {
+		value = data
+	}
+	tree, err := toTree(value)
+	if err != nil {
+		return err
+	}
+
+	var b bytes.Buffer
+	switch format {
+	case MIMEXML:
+		c.Set(fiber.HeaderContentType, fiber.MIMEApplicationXMLCharsetUTF8)
+		b.WriteString(xml.Header)
+		writeXML(&b, "response", tree)
+	case MIMEMsgPack:
+		c.Set(fiber.HeaderContentType, MIMEMsgPack)
+		b.Write(appendMsgPack(nil, tree))
+	case MIMECSV:
+		c.Set(fiber.HeaderContentType, MIMECSV+"; charset=utf-8")
+		list, _ := tree.([]interface{})
+		if err := writeCSV(&b, list, data); err != nil {
+			return err
+		}
+	}
+	return c.Send(b.Bytes())
+}
@@ -0,0 +1,168 @@
+package utils_test
+
+import (
+	"bytes"
+	"encoding/csv"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/toffysoft/go-hexagonal-example/pkg/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tinylib/msgp/msgp"
+)
+
+type tag struct {
+	ID     uint     `json:"id"`
+	Name   string   `json:"name"`
+	Hidden string   `json:"-"`
+	Labels []string `json:"labels,omitempty"`
+}
+
+type namedTag struct {
+	tag
+	Enabled *bool `json:"enabled"`
+}
+
+func newApp() *fiber.App {
+	app := fiber.New()
+	app.Get("/tag", func(c *fiber.Ctx) error {
+		return utils.SendSuccessResponse(c, fiber.StatusOK, "ok", tag{ID: 1, Name: "go & <xml>", Labels: []string{"a", "b"}})
+	})
+	app.Get("/tags", func(c *fiber.Ctx) error {
+		return utils.SendSuccessResponse(c, fiber.StatusOK, "ok", []tag{
+			{ID: 1, Name: "one", Labels: []string{"a"}},
+			{ID: 2, Name: "two, \"quoted\""},
+		})
+	})
+	app.Get("/none", func(c *fiber.Ctx) error {
+		return utils.SendSuccessResponse(c, fiber.StatusOK, "ok", []tag{})
+	})
+	app.Get("/missing", func(c *fiber.Ctx) error {
+		return utils.SendErrorResponse(c, fiber.StatusNotFound, "not found")
+	})
+	app.Post("/tags", func(c *fiber.Ctx) error {
+		var req namedTag
+		if err := utils.ParseBody(c, &req); err != nil {
+			return utils.SendBodyError(c, err)
+		}
+		return utils.SendSuccessResponse(c, fiber.StatusCreated, "created", req)
+	})
+	return app
+}
+
+func do(t *testing.T, method, target string, header http.Header, body []byte) (*http.Response, []byte) {
+	req := httptest.NewRequest(method, target, bytes.NewReader(body))
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	resp, err := newApp().Test(req)
+	require.NoError(t, err)
+	data, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return resp, data
+}
+
+func TestNegotiation(t *testing.T) {
+	t.Run("DefaultsToJSON", func(t *testing.T) {
+		resp, body := do(t, "GET", "/tag", nil, nil)
+
+		assert.Equal(t, fiber.MIMEApplicationJSON, resp.Header.Get("Content-Type"))
+		assert.JSONEq(t, `{"success": true, "message": "ok", "data": {"id": 1, "name": "go & <xml>", "labels": ["a", "b"]}}`, string(body))
+		assert.Equal(t, "Accept", resp.Header.Get("Vary"))
+	})
+
+	t.Run("XML", func(t *testing.T) {
+		resp, body := do(t, "GET", "/tag", http.Header{"Accept": {"text/html;q=0.9, application/xml"}}, nil)
+
+		assert.Equal(t, fiber.MIMEApplicationXMLCharsetUTF8, resp.Header.Get("Content-Type"))
+		assert.Equal(t, `<?xml version="1.0" encoding="UTF-8"?>`+"\n"+
+			`<response><success>true</success><message>ok</message><data><id>1</id><name>go &amp; &lt;xml&gt;</name>`+
+			`<labels><item>a</item><item>b</item></labels></data></response>`, string(body))
+	})
+
+	t.Run("MessagePack", func(t *testing.T) {
+		resp, body := do(t, "GET", "/tag", http.Header{"Accept": {"application/msgpack"}}, nil)
+
+		assert.Equal(t, utils.MIMEMsgPack, resp.Header.Get("Content-Type"))
+		decoded, _, err := msgp.ReadIntfBytes(body)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]interface{}{
+			"success": true,
+			"message": "ok",
+			"data":    map[string]interface{}{"id": int64(1), "name": "go & <xml>", "labels": []interface{}{"a", "b"}},
+		}, decoded)
+	})
+
+	t.Run("CSVList", func(t *testing.T) {
+		resp, body := do(t, "GET", "/tags", http.Header{"Accept": {"text/csv"}}, nil)
+
+		require.Equal(t, fiber.StatusOK, resp.StatusCode)
+		assert.Equal(t, "text/csv; charset=utf-8", resp.Header.Get("Content-Type"))
+		records, err := csv.NewReader(bytes.NewReader(body)).ReadAll()
+		require.NoError(t, err)
+		assert.Equal(t, [][]string{
+			{"id", "name", "labels"},
+			{"1", "one", `["a"]`},
+			{"2", `two, "quoted"`, ""},
+		}, records)
+	})
+
+	t.Run("CSVEmptyListHasHeader", func(t *testing.T) {
+		_, body := do(t, "GET", "/none", http.Header{"Accept": {"text/csv"}}, nil)
+
+		assert.Equal(t, "id,name,labels\n", string(body))
+	})
+
+	t.Run("CSVOnlyForLists", func(t *testing.T) {
+		resp, _ := do(t, "GET", "/tag", http.Header{"Accept": {"text/csv"}}, nil)
+
+		assert.Equal(t, fiber.StatusNotAcceptable, resp.StatusCode)
+	})
+
+	t.Run("ErrorsFallBackToJSON", func(t *testing.T) {
+		resp, body := do(t, "GET", "/missing", http.Header{"Accept": {"image/png"}}, nil)
+
+		assert.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+		assert.JSONEq(t, `{"success": false, "message": "not found"}`, string(body))
+	})
+}
+
+func TestParseBody(t *testing.T) {
+	t.Run("XML", func(t *testing.T) {
+		resp, body := do(t, "POST", "/tags", http.Header{"Content-Type": {"application/xml"}, "Accept": {"application/json"}},
+			[]byte(`<tag><id>7</id><name>seven</name><Hidden>x</Hidden><labels><item>a</item><item>b</item></labels><enabled>true</enabled></tag>`))
+
+		require.Equal(t, fiber.StatusCreated, resp.StatusCode)
+		assert.JSONEq(t, `{"success": true, "message": "created", "data": {"id": 7, "name": "seven", "labels": ["a", "b"], "enabled": true}}`, string(body))
+	})
+
+	t.Run("XMLRejectsBadNumber", func(t *testing.T) {
+		resp, _ := do(t, "POST", "/tags", http.Header{"Content-Type": {"text/xml"}}, []byte(`<tag><id>seven</id></tag>`))
+
+		assert.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("MessagePack", func(t *testing.T) {
+		b := msgp.AppendMapHeader(nil, 2)
+		b = msgp.AppendString(b, "id")
+		b = msgp.AppendInt64(b, 7)
+		b = msgp.AppendString(b, "name")
+		b = msgp.AppendString(b, "seven")
+		resp, body := do(t, "POST", "/tags", http.Header{"Content-Type": {"application/x-msgpack"}}, b)
+
+		require.Equal(t, fiber.StatusCreated, resp.StatusCode)
+		assert.JSONEq(t, `{"success": true, "message": "created", "data": {"id": 7, "name": "seven", "enabled": null}}`, string(body))
+	})
+
+	t.Run("UnsupportedMediaType", func(t *testing.T) {
+		resp, _ := do(t, "POST", "/tags", http.Header{"Content-Type": {"application/x-www-form-urlencoded"}}, []byte("id=7"))
+
+		assert.Equal(t, fiber.StatusUnsupportedMediaType, resp.StatusCode)
+		assert.Contains(t, resp.Header.Get("Accept"), utils.MIMEMsgPack)
+	})
+}
@@ -18,21 +18,33 @@ type SuccessResponse struct {
 	Data    interface{} `json:"data,omitempty"`
 }
 
-// SendErrorResponse sends a JSON error response
+// SendErrorResponse sends an error response in the representation the
+// request accepts, or JSON
 func SendErrorResponse(c *fiber.Ctx, statusCode int, message string) error {
-	return c.Status(statusCode).JSON(ErrorResponse{
+	return sendError(c, statusCode, ErrorResponse{
 		Success: false,
 		Message: message,
 	})
 }
 
-// SendSuccessResponse sends a JSON success response
+// SendSuccessResponse sends a success response in the representation the
+// request accepts, or 406 if there is none
 func SendSuccessResponse(c *fiber.Ctx, statusCode int, message string, data interface{}) error {
-	return c.Status(statusCode).JSON(SuccessResponse{
+	return send(c, statusCode, SuccessResponse{
 		Success: true,
 		Message: message,
 		Data:    data,
-	})
+	}, data)
+}
+
+// SendResponse sends a response with data whose success flag is chosen by
+// the caller, for results that can succeed in part
+func SendResponse(c *fiber.Ctx, statusCode int, success bool, message string, data interface{}) error {
+	return send(c, statusCode, SuccessResponse{
+		Success: success,
+		Message: message,
+		Data:    data,
+	}, data)
 }
 
 func ValidatorErrors(err error) string {
@@ -0,0 +1,305 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/tinylib/msgp/msgp"
+)
+
+// The XML, MessagePack and CSV representations are written from the JSON
+// one, so that every format has the same field names and omits the same
+// fields. A value is first decoded into a tree of object, []interface{},
+// string, json.Number, bool and nil.
+
+// object is a JSON object with its members in document order.
+type object []member
+
+type member struct {
+	name  string
+	value interface{}
+}
+
+// toTree returns the JSON representation of v as a tree.
+func toTree(v interface{}) (interface{}, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	return decodeTree(dec)
+}
+
+func decodeTree(dec *json.Decoder) (interface{}, error) {
+	token, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch token {
+	case json.Delim('{'):
+		obj := object{}
+		for dec.More() {
+			name, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			value, err := decodeTree(dec)
+			if err != nil {
+				return nil, err
+			}
+			obj = append(obj, member{name: name.(string), value: value})
+		}
+		_, err = dec.Token()
+		return obj, err
+	case json.Delim('['):
+		array := []interface{}{}
+		for dec.More() {
+			value, err := decodeTree(dec)
+			if err != nil {
+				return nil, err
+			}
+			array = append(array, value)
+		}
+		_, err = dec.Token()
+		return array, err
+	}
+	return token, nil
+}
+
+// writeXML writes v as an element called name. Object members become child
+// elements and array items <item> elements.
+func writeXML(w *bytes.Buffer, name string, v interface{}) {
+	start := name
+	if !isXMLName(name) {
+		start = `member name="` + escapeXML(name) + `"`
+		name = "member"
+	}
+	if v == nil {
+		w.WriteString("<" + start + "/>")
+		return
+	}
+	w.WriteString("<" + start + ">")
+	switch v := v.(type) {
+	case object:
+		for _, m := range v {
+			writeXML(w, m.name, m.value)
+		}
+	case []interface{}:
+		for _, item := range v {
+			writeXML(w, "item", item)
+		}
+	default:
+		w.WriteString(escapeXML(scalarString(v)))
+	}
+	w.WriteString("</" + name + ">")
+}
+
+func escapeXML(s string) string {
+	var b strings.Builder
+	_ = xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+// isXMLName reports whether name can be used as an element name as is.
+func isXMLName(name string) bool {
+	if name == "" || strings.HasPrefix(strings.ToLower(name), "xml") {
+		return false
+	}
+	for i, r := range name {
+		letter := r == '_' || 'a' <= r && r <= 'z' || 'A' <= r && r <= 'Z'
+		if !letter && (i == 0 || r != '-' && r != '.' && (r < '0' || r > '9')) {
+			return false
+		}
+	}
+	return true
+}
+
+// appendMsgPack appends v to b as MessagePack. Integers are written as
+// integers, and times as the strings of the JSON representation.
+func appendMsgPack(b []byte, v interface{}) []byte {
+	switch v := v.(type) {
+	case object:
+		b = msgp.AppendMapHeader(b, uint32(len(v)))
+		for _, m := range v {
+			b = msgp.AppendString(b, m.name)
+			b = appendMsgPack(b, m.value)
+		}
+		return b
+	case []interface{}:
+		b = msgp.AppendArrayHeader(b, uint32(len(v)))
+		for _, item := range v {
+			b = appendMsgPack(b, item)
+		}
+		return b
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			return msgp.AppendInt64(b, n)
+		}
+		if n, err := strconv.ParseUint(string(v), 10, 64); err == nil {
+			return msgp.AppendUint64(b, n)
+		}
+		n, _ := v.Float64()
+		return msgp.AppendFloat64(b, n)
+	case string:
+		return msgp.AppendString(b, v)
+	case bool:
+		return msgp.AppendBool(b, v)
+	}
+	return msgp.AppendNil(b)
+}
+
+// writeCSV writes the items of list as rows under a header of their member
+// names, in the order they first appear. Nested objects and arrays are
+// written as JSON. When the list is empty, the columns are the JSON field
+// names of the element type of data.
+func writeCSV(w io.Writer, list []interface{}, data interface{}) error {
+	var columns []string
+	index := map[string]int{}
+	for _, item := range list {
+		if obj, ok := item.(object); ok {
+			for _, m := range obj {
+				if _, ok := index[m.name]; !ok {
+					index[m.name] = len(columns)
+					columns = append(columns, m.name)
+				}
+			}
+		}
+	}
+	if len(list) == 0 {
+		columns = jsonFieldNames(reflect.TypeOf(data).Elem())
+	}
+	if len(columns) == 0 && len(list) > 0 {
+		columns = []string{"value"}
+	}
+
+	out := csv.NewWriter(w)
+	if err := out.Write(columns); err != nil {
+		return err
+	}
+	for _, item := range list {
+		row := make([]string, len(columns))
+		if obj, ok := item.(object); ok {
+			for _, m := range obj {
+				row[index[m.name]] = cell(m.value)
+			}
+		} else {
+			row[0] = cell(item)
+		}
+		if err := out.Write(row); err != nil {
+			return err
+		}
+	}
+	out.Flush()
+	return out.Error()
+}
+
+func cell(v interface{}) string {
+	switch v.(type) {
+	case object, []interface{}:
+		var b bytes.Buffer
+		writeJSON(&b, v)
+		return b.String()
+	}
+	return scalarString(v)
+}
+
+// writeJSON writes a tree back as compact JSON.
+func writeJSON(w *bytes.Buffer, v interface{}) {
+	switch v := v.(type) {
+	case object:
+		w.WriteByte('{')
+		for i, m := range v {
+			if i > 0 {
+				w.WriteByte(',')
+			}
+			name, _ := json.Marshal(m.name)
+			w.Write(name)
+			w.WriteByte(':')
+			writeJSON(w, m.value)
+		}
+		w.WriteByte('}')
+	case []interface{}:
+		w.WriteByte('[')
+		for i, item := range v {
+			if i > 0 {
+				w.WriteByte(',')
+			}
+			writeJSON(w, item)
+		}
+		w.WriteByte(']')
+	default:
+		data, _ := json.Marshal(v)
+		w.Write(data)
+	}
+}
+
+func scalarString(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	}
+	return fmt.Sprint(v)
+}
+
+// jsonFieldNames returns the names of the fields encoding/json writes for a
+// struct type, or nothing for other types.
+func jsonFieldNames(t reflect.Type) []string {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+	var names []string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, embedded, ok := jsonField(field)
+		switch {
+		case !ok:
+		case embedded:
+			names = append(names, jsonFieldNames(field.Type)...)
+		default:
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// jsonField returns the JSON name of a struct field, or embedded for an
+// embedded struct whose fields are promoted. ok is false for fields
+// encoding/json skips.
+func jsonField(field reflect.StructField) (name string, embedded, ok bool) {
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "", false, false
+	}
+	name, _, _ = strings.Cut(tag, ",")
+	if field.Anonymous && name == "" {
+		t := field.Type
+		if t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+		if t.Kind() == reflect.Struct {
+			return "", true, true
+		}
+	}
+	if !field.IsExported() {
+		return "", false, false
+	}
+	if name == "" {
+		name = field.Name
+	}
+	return name, false, true
+}
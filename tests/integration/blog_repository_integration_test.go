@@ -0,0 +1,95 @@
+package integration
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/toffysoft/go-hexagonal-example/internal/adapters/repositories"
+	"github.com/toffysoft/go-hexagonal-example/internal/core/domain"
+	"github.com/toffysoft/go-hexagonal-example/internal/core/services"
+	"github.com/toffysoft/go-hexagonal-example/internal/infrastructure/database"
+	"github.com/toffysoft/go-hexagonal-example/pkg/errors"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// appendPatch appends to the title, so that a lost update shows as a
+// missing letter.
+type appendPatch string
+
+func (p appendPatch) Apply(blog *domain.Blog) error {
+	blog.Title += string(p)
+	return nil
+}
+
+func TestConcurrentPatchesAreSerialised(t *testing.T) {
+	db, err := database.InitTestDB()
+	require.NoError(t, err)
+	blogService := services.NewBlogService(repositories.NewBlogRepository(db),
+		services.WithUnitOfWork(repositories.NewUnitOfWork(db)))
+	ctx := context.Background()
+	blog := &domain.Blog{Title: "Title", Content: "Some content", Author: "Author"}
+	require.NoError(t, blogService.CreateBlog(ctx, blog))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := blogService.PatchBlog(ctx, blog.ID, appendPatch("x"))
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	got, err := blogService.GetBlog(ctx, blog.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Titlexxxxxxxxxx", got.Title)
+	assert.True(t, got.CreatedAt.Equal(blog.CreatedAt))
+}
+
+func TestUpdateDoesNotRecreateDeletedBlog(t *testing.T) {
+	db, err := database.InitTestDB()
+	require.NoError(t, err)
+	repo := repositories.NewBlogRepository(db)
+	ctx := context.Background()
+	blog := &domain.Blog{Title: "Title", Content: "Some content", Author: "Author"}
+	require.NoError(t, repo.Create(ctx, blog))
+	require.NoError(t, repo.Delete(ctx, blog.ID))
+
+	err = repo.Update(ctx, blog)
+
+	appErr, ok := err.(errors.AppError)
+	require.True(t, ok, "%v", err)
+	assert.Equal(t, errors.NotFound, appErr.Type)
+	_, err = repo.GetByID(ctx, blog.ID)
+	assert.Error(t, err)
+}
+
+func TestListLatestFiltersByTag(t *testing.T) {
+	db, err := database.InitTestDB()
+	require.NoError(t, err)
+	repo := repositories.NewBlogRepository(db)
+	ctx := context.Background()
+	for _, blog := range []*domain.Blog{
+		{Title: "One", Content: "Some content", Author: "alice", Tags: []string{"latest-test"}},
+		{Title: "Two", Content: "Some content", Author: "bob", Tags: []string{"other", "latest-test"}},
+		{Title: "Three", Content: "Some content", Author: "alice", Tags: []string{"other"}},
+	} {
+		require.NoError(t, repo.Create(ctx, blog))
+	}
+
+	blogs, err := repo.ListLatest(ctx, domain.BlogFilter{Tag: "latest-test"}, domain.FieldMask{domain.FieldTitle, domain.FieldTags}, 10)
+	require.NoError(t, err)
+	require.Len(t, blogs, 2)
+	assert.Equal(t, "Two", blogs[0].Title)
+	assert.Equal(t, []string{"other", "latest-test"}, blogs[0].Tags)
+	assert.Equal(t, "One", blogs[1].Title)
+
+	blogs, err = repo.ListLatest(ctx, domain.BlogFilter{Tag: "latest-test", Author: "alice"}, nil, 1)
+	require.NoError(t, err)
+	require.Len(t, blogs, 1)
+	assert.Equal(t, "One", blogs[0].Title)
+}